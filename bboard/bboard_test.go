@@ -0,0 +1,197 @@
+package minboard
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/easychessanimations/gochess/bengine"
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+// white mates with Qxf7
+const SCHOLARS_MATE_FEN = "r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4"
+
+// newTestBoard returns a standard board set up from fen that keeps its info lines quiet
+func newTestBoard(t *testing.T, fen string) *Board {
+	b := &Board{
+		Variant:             utils.VARIANT_STANDARD,
+		LogFunc:             func(string) {},
+		LogAnalysisInfoFunc: func(string) {},
+	}
+
+	pos, err := butils.PositionFromFENAndVariant(fen, b.Variant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.SetPosition(pos)
+
+	return b
+}
+
+// captureBestMoves redirects stdout, where the bestmove lines are printed, until the returned function is called
+func captureBestMoves(t *testing.T) (<-chan string, func()) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+
+	bestMoves := make(chan string, 8)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if strings.HasPrefix(scanner.Text(), "bestmove") {
+				bestMoves <- scanner.Text()
+			}
+		}
+	}()
+
+	return bestMoves, func() {
+		os.Stdout = stdout
+		w.Close()
+	}
+}
+
+// waitBestMove returns the next bestmove line, it fails the test if there is none within timeout
+func waitBestMove(t *testing.T, bestMoves <-chan string, timeout time.Duration) string {
+	select {
+	case line := <-bestMoves:
+		return line
+	case <-time.After(timeout):
+		t.Fatalf("no bestmove within %v", timeout)
+	}
+
+	return ""
+}
+
+func TestGo(t *testing.T) {
+	bestMoves, restore := captureBestMoves(t)
+	defer restore()
+
+	b := newTestBoard(t, SCHOLARS_MATE_FEN)
+
+	move, score := b.Go(3)
+	if uci := b.Pos.MoveToUCI(move); uci != "h5f7" || score < bengine.KnownWinScore {
+		t.Errorf("expected the mate h5f7, got %s with score %d", uci, score)
+	}
+	if line := waitBestMove(t, bestMoves, time.Second); !strings.HasPrefix(line, "bestmove h5f7") {
+		t.Errorf("expected bestmove h5f7, got %s", line)
+	}
+
+	// the mated side has no move to report
+	b.MakeAlgebMove("h5f7", false)
+	if move, _ := b.Go(3); move != butils.NullMove {
+		t.Errorf("expected no move when mated, got %s", b.Pos.MoveToUCI(move))
+	}
+	if line := waitBestMove(t, bestMoves, time.Second); line != "bestmove "+NULL_MOVE_UCI {
+		t.Errorf("expected bestmove %s, got %s", NULL_MOVE_UCI, line)
+	}
+}
+
+func TestStartSearchStop(t *testing.T) {
+	bestMoves, restore := captureBestMoves(t)
+	defer restore()
+
+	b := newTestBoard(t, utils.STANDARD_START_FEN)
+
+	// an infinite search only reports after stop
+	b.StartSearch(bengine.NewTimeControl(b.Pos, false), nil, false, true)
+
+	select {
+	case line := <-bestMoves:
+		t.Fatalf("infinite search reported %s before stop", line)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	b.Stop()
+
+	line := waitBestMove(t, bestMoves, 5*time.Second)
+	fields := strings.Fields(line)
+	if len(fields) < 2 || b.AlgebToMove(fields[1]) == butils.NullMove {
+		t.Errorf("expected a legal bestmove, got %s", line)
+	}
+
+	// a search restricted to root moves reports one of them
+	e2e4 := b.AlgebToMove("e2e4")
+	b.StartSearch(bengine.NewDeadlineTimeControl(b.Pos, 100*time.Millisecond), []butils.Move{e2e4}, false, false)
+
+	if line := waitBestMove(t, bestMoves, 5*time.Second); !strings.HasPrefix(line, "bestmove e2e4") {
+		t.Errorf("expected bestmove e2e4, got %s", line)
+	}
+
+	// stop after the search ended does nothing
+	b.Stop()
+}
+
+func TestPonder(t *testing.T) {
+	bestMoves, restore := captureBestMoves(t)
+	defer restore()
+
+	b := newTestBoard(t, SCHOLARS_MATE_FEN)
+
+	// a ponder search ignores its deadline and only reports after ponderhit
+	b.StartSearch(bengine.NewDeadlineTimeControl(b.Pos, 100*time.Millisecond), nil, true, false)
+
+	select {
+	case line := <-bestMoves:
+		t.Fatalf("ponder search reported %s before ponderhit", line)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	b.PonderHit()
+
+	if line := waitBestMove(t, bestMoves, 5*time.Second); !strings.HasPrefix(line, "bestmove h5f7") {
+		t.Errorf("expected bestmove h5f7 after ponderhit, got %s", line)
+	}
+
+	// stop ends a ponder search without ponderhit
+	b.StartSearch(bengine.NewDeadlineTimeControl(b.Pos, 100*time.Millisecond), nil, true, false)
+
+	select {
+	case line := <-bestMoves:
+		t.Fatalf("ponder search reported %s before stop", line)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	b.Stop()
+
+	if line := waitBestMove(t, bestMoves, 5*time.Second); !strings.HasPrefix(line, "bestmove h5f7") {
+		t.Errorf("expected bestmove h5f7 after stop, got %s", line)
+	}
+}
+
+func TestSetEvalFromUciOptions(t *testing.T) {
+	defer func(old bool) { bengine.RandomBonus = old }(bengine.RandomBonus)
+
+	b := newTestBoard(t, utils.STANDARD_START_FEN)
+
+	for _, randomBonus := range []bool{false, true} {
+		b.GetUciOptionByNameWithDefaultFunc = func(name string, uo utils.UciOption) utils.UciOption {
+			if name == "RandomBonus" {
+				uo.ValueBool = randomBonus
+			}
+			return uo
+		}
+
+		b.SetEvalFromUciOptions()
+
+		if bengine.RandomBonus != randomBonus {
+			t.Errorf("RandomBonus option %v set RandomBonus to %v", randomBonus, bengine.RandomBonus)
+		}
+	}
+
+	// the random bonus is on by default
+	bengine.RandomBonus = false
+	b.GetUciOptionByNameWithDefaultFunc = nil
+	b.SetEvalFromUciOptions()
+
+	if !bengine.RandomBonus {
+		t.Errorf("RandomBonus is off by default")
+	}
+}
@@ -4,6 +4,9 @@ package minboard
 // imports
 
 import (
+	"time"
+
+	"github.com/easychessanimations/gochess/bengine"
 	"github.com/easychessanimations/gochess/utils"
 )
 
@@ -16,8 +19,18 @@ const ADD_SAN = true
 
 const DEFAULT_SEARCH_DEPTH = 10
 
+const SEARCH_POLL_INTERVAL = 10 * time.Millisecond
+
+const NULL_MOVE_UCI = "0000"
+
 const DEFAULT_UCI_VARIANT_STRING = "standard"
 
+const DEFAULT_BOOK_FILE = "book.bin"
+
+const DEFAULT_THREADS = 1
+
+const DEFAULT_TABLEBASE_PATH = ""
+
 var UCI_OPTIONS = []utils.UciOption{
 	{
 		Kind:      "combo",
@@ -32,6 +45,65 @@ var UCI_OPTIONS = []utils.UciOption{
 			"eightpiece",
 		},
 	},
+	{
+		Kind:        "check",
+		Name:        "UCI_Chess960",
+		ValueKind:   "bool",
+		DefaultBool: false,
+		ValueBool:   false,
+	},
+	{
+		Kind:        "check",
+		Name:        "OwnBook",
+		ValueKind:   "bool",
+		DefaultBool: false,
+		ValueBool:   false,
+	},
+	{
+		Kind:      "string",
+		Name:      "BookFile",
+		ValueKind: "string",
+		Default:   DEFAULT_BOOK_FILE,
+		Value:     DEFAULT_BOOK_FILE,
+	},
+	{
+		Kind:        "check",
+		Name:        "BookBestMove",
+		ValueKind:   "bool",
+		DefaultBool: false,
+		ValueBool:   false,
+	},
+	{
+		Kind:        "check",
+		Name:        "RandomBonus",
+		ValueKind:   "bool",
+		DefaultBool: true,
+		ValueBool:   true,
+	},
+	{
+		Kind:       "spin",
+		Name:       "Threads",
+		ValueKind:  "int",
+		MinInt:     1,
+		MaxInt:     bengine.MaxThreads,
+		DefaultInt: DEFAULT_THREADS,
+		ValueInt:   DEFAULT_THREADS,
+	},
+	{
+		// the gui only sends go ponder when this is set
+		Kind:        "check",
+		Name:        "Ponder",
+		ValueKind:   "bool",
+		DefaultBool: false,
+		ValueBool:   false,
+	},
+	{
+		Kind:      "string",
+		Name:      "TablebasePath",
+		ValueKind: "string",
+		Default:   DEFAULT_TABLEBASE_PATH,
+		Value:     DEFAULT_TABLEBASE_PATH,
+	},
 }
 
 var UCI_COMMAND_ALIASES = map[string]string{
@@ -6,6 +6,8 @@ package minboard
 import (
 	"math/rand"
 	"strconv"
+
+	"github.com/easychessanimations/gochess/butils"
 )
 
 /////////////////////////////////////////////////////////////////////
@@ -15,7 +17,19 @@ import (
 
 func (b *Board) ExecCommand(command string) bool {
 	if command == "d" {
-		b.Pos.UndoMoveSafe()
+		b.Undo()
+
+		b.Print()
+
+		return true
+	}
+
+	if command == "g" {
+		bm, _ := b.Go(DEFAULT_SEARCH_DEPTH)
+
+		if bm != butils.NullMove {
+			b.Pos.DoMove(bm)
+		}
 
 		b.Print()
 
@@ -24,9 +38,17 @@ func (b *Board) ExecCommand(command string) bool {
 
 	b.SortedSanMoveBuff = b.Pos.SortedLegalMoves()
 
+	if len(b.SortedSanMoveBuff) == 0 {
+		return false
+	}
+
 	i, err := strconv.ParseInt(command, 10, 32)
 
 	if err == nil {
+		if (i < 1) || (int(i) > len(b.SortedSanMoveBuff)) {
+			return false
+		}
+
 		move := b.SortedSanMoveBuff[i-1].Move
 
 		b.Pos.DoMove(move)
@@ -36,7 +58,33 @@ func (b *Board) ExecCommand(command string) bool {
 		return true
 	}
 
-	move := b.SortedSanMoveBuff[rand.Intn(len(b.SortedSanMoveBuff))].Move
+	if command == "" {
+		move := b.SortedSanMoveBuff[rand.Intn(len(b.SortedSanMoveBuff))].Move
+
+		b.Pos.DoMove(move)
+
+		b.Print()
+
+		return true
+	}
+
+	for _, mbi := range b.SortedSanMoveBuff {
+		if mbi.Algeb == command {
+			b.Pos.DoMove(mbi.Move)
+
+			b.Print()
+
+			return true
+		}
+	}
+
+	move, err := b.Pos.SanToMove(command)
+
+	if err != nil {
+		b.Log(err.Error())
+
+		return false
+	}
 
 	b.Pos.DoMove(move)
 
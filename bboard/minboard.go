@@ -29,29 +29,74 @@ func (b *Board) Init(variant utils.VariantKey) {
 }
 
 func (b *Board) Reset() {
-	b.Pos, _ = butils.PositionFromFEN(butils.FENStartPos)
-}
-
-func (b *Board) Go(depth int) {
-	score := bengine.Score{}
+	pos, _ := butils.PositionFromFENAndVariant(utils.StartFenForVariant(b.Variant), b.Variant)
 
-	fmt.Println(score)
+	b.SetPosition(pos)
 }
 
-func (b *Board) Stop() {
+func (b *Board) SetPosition(pos *butils.Position) {
+	pos.Chess960 = b.Chess960
+
+	b.Pos = pos
 
+	if b.Engine == nil {
+		b.Engine = bengine.NewEngine(pos, &searchLogger{board: b}, bengine.Options{})
+	} else {
+		b.Engine.SetPosition(pos)
+	}
 }
 
 func (b *Board) Print() {
 	b.Log(b.Pos.PrettyPrintString())
 }
 
+func (b *Board) LogAnalysisInfo(content string) {
+	if b.LogAnalysisInfoFunc != nil {
+		b.LogAnalysisInfoFunc(content)
+	} else {
+		fmt.Println(content)
+	}
+}
+
 func (b *Board) SetFromVariantUciOptionAndFen(fen string) {
+	b.ResetVariantFromUciOption()
+
+	pos, err := butils.PositionFromFENAndVariant(fen, b.Variant)
 
+	if err != nil {
+		b.Log(fmt.Sprintf("invalid fen %s : %v", fen, err))
+		return
+	}
+
+	b.SetPosition(pos)
+}
+
+// AlgebToMove returns the legal move matching algeb, or NullMove if there is no such move
+func (b *Board) AlgebToMove(algeb string) butils.Move {
+	for _, lm := range b.Pos.LegalMoves() {
+		if b.Pos.MoveToUCI(lm) == algeb {
+			return lm
+		}
+	}
+
+	return butils.NullMove
 }
 
+// MakeAlgebMove makes a move given in UCI notation, illegal moves are reported and ignored
+// SAN is computed on demand from the position, so addSan has no effect
 func (b *Board) MakeAlgebMove(algeb string, addSan bool) {
+	move := b.AlgebToMove(algeb)
+
+	if move == butils.NullMove {
+		b.Log(fmt.Sprintf("illegal move %s", algeb))
+		return
+	}
+
+	b.Pos.DoMove(move)
+}
 
+func (b *Board) Undo() {
+	b.Pos.UndoMoveSafe()
 }
 
 func (b *Board) ResetVariantFromUciOption() {
@@ -61,9 +106,63 @@ func (b *Board) ResetVariantFromUciOption() {
 
 	b.Variant = utils.VariantKeyStringToVariantKey(variantUciOption.Value)
 
+	b.Chess960 = b.GetUciOptionByNameWithDefault("UCI_Chess960", utils.UciOption{}).ValueBool
+
+	if _, err := butils.GetVariantDescriptor(b.Variant); err != nil {
+		b.Log(fmt.Sprintf("%v, falling back to standard", err))
+
+		b.Variant = utils.VARIANT_STANDARD
+	}
+
 	b.Reset()
 }
 
+// SetBookFromUciOptions sets the book options of the engine and loads the book file if the engine uses its own book
+func (b *Board) SetBookFromUciOptions() {
+	b.Engine.Options.OwnBook = b.GetUciOptionByNameWithDefault("OwnBook", utils.UciOption{}).ValueBool
+	b.Engine.Options.BookBestMove = b.GetUciOptionByNameWithDefault("BookBestMove", utils.UciOption{}).ValueBool
+
+	if !b.Engine.Options.OwnBook {
+		return
+	}
+
+	bookFile := b.GetUciOptionByNameWithDefault("BookFile", utils.UciOption{
+		Value: DEFAULT_BOOK_FILE,
+	}).Value
+
+	bk, err := b.bookLoader.Load(bookFile)
+	if err != nil {
+		b.Log(fmt.Sprintf("could not load book %s : %v", bookFile, err))
+	}
+
+	b.Engine.Book = bk
+}
+
+// SetEvalFromUciOptions sets the evaluation options of the engine
+func (b *Board) SetEvalFromUciOptions() {
+	bengine.RandomBonus = b.GetUciOptionByNameWithDefault("RandomBonus", utils.UciOption{
+		ValueBool: true,
+	}).ValueBool
+}
+
+// SetThreadsFromUciOptions sets the number of search threads of the engine
+func (b *Board) SetThreadsFromUciOptions() {
+	b.Engine.Options.Threads = b.GetUciOptionByNameWithDefault("Threads", utils.UciOption{
+		ValueInt: DEFAULT_THREADS,
+	}).ValueInt
+}
+
+// SetTablebaseFromUciOptions loads the tables of the tablebase directory, an empty path disables the tablebase
+func (b *Board) SetTablebaseFromUciOptions() {
+	path := b.GetUciOptionByNameWithDefault("TablebasePath", utils.UciOption{
+		Value: DEFAULT_TABLEBASE_PATH,
+	}).Value
+
+	if err := b.Engine.SetTablebasePath(path); err != nil {
+		b.Log(fmt.Sprintf("could not load tablebase %s : %v", path, err))
+	}
+}
+
 func (b *Board) GetUciOptionByNameWithDefault(name string, uciOption utils.UciOption) utils.UciOption {
 	if b.GetUciOptionByNameWithDefaultFunc != nil {
 		return b.GetUciOptionByNameWithDefaultFunc(name, uciOption)
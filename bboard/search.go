@@ -0,0 +1,143 @@
+package minboard
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/easychessanimations/gochess/bengine"
+	"github.com/easychessanimations/gochess/butils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// search logger
+
+func (sl *searchLogger) BeginSearch() {
+	sl.start = time.Now()
+}
+
+func (sl *searchLogger) EndSearch() {
+}
+
+func (sl *searchLogger) PrintPV(stats bengine.Stats, multiPV int, score int32, pv []butils.Move) {
+	buff := fmt.Sprintf("depth %d seldepth %d multipv %d ", stats.Depth, stats.SelDepth, multiPV)
+
+	if score > bengine.KnownWinScore {
+		buff += fmt.Sprintf("score mate %d ", (bengine.MateScore-score+1)/2)
+	} else if score < bengine.KnownLossScore {
+		buff += fmt.Sprintf("score mate %d ", (bengine.MatedScore-score)/2)
+	} else {
+		buff += fmt.Sprintf("score cp %d ", score)
+	}
+
+	elapsed := time.Now().Sub(sl.start)
+	if elapsed < time.Microsecond {
+		elapsed = time.Microsecond
+	}
+
+	nps := stats.Nodes * uint64(time.Second) / uint64(elapsed)
+
+	buff += fmt.Sprintf("nodes %d time %d nps %d pv", stats.Nodes, elapsed/time.Millisecond, nps)
+
+	for _, m := range pv {
+		buff += " " + sl.board.Pos.MoveToUCI(m)
+	}
+
+	sl.board.LogAnalysisInfo(buff)
+}
+
+func (sl *searchLogger) CurrMove(depth int, move butils.Move, num int) {
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Go searches the current position with iterative deepening up to depth
+// prints info lines during the search and the bestmove at the end
+func (b *Board) Go(depth int) (butils.Move, int32) {
+	b.beginSearch(bengine.NewFixedDepthTimeControl(b.Pos, int32(depth)), false)
+
+	return b.search(nil, false)
+}
+
+// StartSearch starts searching the current position in the background until tc stops the search
+// rootMoves restricts the search to the given moves, all moves are searched if it is empty
+// in infinite mode the bestmove is only reported after Stop, in ponder mode after Stop or PonderHit
+func (b *Board) StartSearch(tc *bengine.TimeControl, rootMoves []butils.Move, ponder bool, infinite bool) {
+	b.beginSearch(tc, ponder)
+
+	go b.search(rootMoves, infinite)
+}
+
+// beginSearch starts the time control, this is done before the search runs so that Stop is never missed
+func (b *Board) beginSearch(tc *bengine.TimeControl, ponder bool) {
+	b.TimeControl = tc
+
+	b.TimeControl.Start(ponder)
+
+	b.SetBookFromUciOptions()
+
+	b.SetEvalFromUciOptions()
+
+	b.SetThreadsFromUciOptions()
+
+	b.SetTablebaseFromUciOptions()
+}
+
+// search runs the search and prints the bestmove, a legal move is reported even if the search was cut short
+// a search that ends while pondering keeps its result until ponderhit, an infinite one until Stop
+func (b *Board) search(rootMoves []butils.Move, infinite bool) (butils.Move, int32) {
+	score, pv := b.Engine.PlayMoves(b.TimeControl, rootMoves)
+
+	if b.TimeControl.Mate > 0 {
+		b.Log(b.Engine.LastMate.Summary(b.Pos, b.TimeControl.Mate))
+	}
+
+	for !b.TimeControl.StopRequested() && (infinite || b.TimeControl.Pondering()) {
+		time.Sleep(SEARCH_POLL_INTERVAL)
+	}
+
+	if len(pv) == 0 {
+		if len(rootMoves) > 0 {
+			pv = rootMoves[:1]
+		} else if legalMoves := b.Pos.LegalMoves(); len(legalMoves) > 0 {
+			pv = legalMoves[:1]
+		}
+	}
+
+	if len(pv) > 1 {
+		fmt.Printf("bestmove %s ponder %s\n", b.Pos.MoveToUCI(pv[0]), b.Pos.MoveToUCI(pv[1]))
+	} else if len(pv) > 0 {
+		fmt.Printf("bestmove %s\n", b.Pos.MoveToUCI(pv[0]))
+	} else {
+		// no legal move, uci has the null move for this
+		fmt.Printf("bestmove %s\n", NULL_MOVE_UCI)
+
+		return butils.NullMove, score
+	}
+
+	return pv[0], score
+}
+
+// PonderHit switches a search started in ponder mode to its time control, the search goes on where it is
+// the time control flags are atomic, so this is safe while the search runs and harmless after it ended
+func (b *Board) PonderHit() {
+	if b.TimeControl != nil {
+		b.TimeControl.PonderHit()
+	}
+}
+
+// Stop stops the search, Go will report the best move found so far
+func (b *Board) Stop() {
+	if b.TimeControl != nil {
+		b.TimeControl.Stop()
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -4,6 +4,10 @@ package minboard
 // imports
 
 import (
+	"time"
+
+	"github.com/easychessanimations/gochess/bengine"
+	"github.com/easychessanimations/gochess/book"
 	"github.com/easychessanimations/gochess/butils"
 	"github.com/easychessanimations/gochess/utils"
 )
@@ -15,11 +19,21 @@ import (
 
 type Board struct {
 	Variant                           utils.VariantKey
+	Chess960                          bool
 	Pos                               *butils.Position
+	Engine                            *bengine.Engine
+	TimeControl                       *bengine.TimeControl
 	SortedSanMoveBuff                 butils.MoveBuff
 	LogFunc                           func(string)
 	LogAnalysisInfoFunc               func(string)
 	GetUciOptionByNameWithDefaultFunc func(string, utils.UciOption) utils.UciOption
+	bookLoader                        book.Loader
+}
+
+// searchLogger reports the progress of the engine search through the board's log functions
+type searchLogger struct {
+	board *Board
+	start time.Time
 }
 
 /////////////////////////////////////////////////////////////////////
@@ -88,6 +88,13 @@ func (eng *Engine) alphaBetaRec(abi AlphaBetaInfo) int32 {
 		}
 	}
 
+	// positions covered by the tablebase have an exact score
+	if abi.CurrentDepth > 0 {
+		if score, ok := eng.probeTablebase(); ok {
+			return score
+		}
+	}
+
 	if abi.CurrentDepth >= abi.MaxDepth {
 		score := eng.Score()
 
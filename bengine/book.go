@@ -0,0 +1,36 @@
+package bengine
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	. "github.com/easychessanimations/gochess/butils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// opening book
+
+// bookMove returns a move from the book for the current position if the engine uses its own book
+// if rootMoves is not nil only those moves are considered
+func (eng *Engine) bookMove(rootMoves []Move) (Move, bool) {
+	if !eng.Options.OwnBook || eng.Book == nil {
+		return NullMove, false
+	}
+
+	move, ok := eng.Book.Probe(eng.Position, eng.Options.BookBestMove)
+	if !ok || rootMoves == nil {
+		return move, ok
+	}
+
+	for _, m := range rootMoves {
+		if m == move {
+			return move, true
+		}
+	}
+
+	return NullMove, false
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -19,6 +19,10 @@ const (
 	initialAspirationWindow = 13
 	futilityMargin          = 75
 	checkpointStep          = 10000
+	mateCheckpointStep      = 1000 // mate search nodes generate all legal moves, so the time is checked more often
+
+	// MaxThreads is the maximum number of search threads
+	MaxThreads = 64
 )
 
 // hash table
@@ -190,6 +194,15 @@ const (
 	fPassedPawnRank             featureType = 178
 	fKingEnemyPassedPawnTropism featureType = 186
 	fKingPassedPawnTropism      featureType = 194
+	fLancer                     featureType = 202
+	fSentry                     featureType = 203
+	fJailer                     featureType = 204
+	fSentryAttack               featureType = 205
+	fJailerAttack               featureType = 206
+	fLancerAttack               featureType = 207
+	fJailedPieces               featureType = 208
+	fKingJailed                 featureType = 209
+	fKingSentryAttackers        featureType = 210
 )
 
 /////////////////////////////////////////////////////////////////////
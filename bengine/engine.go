@@ -5,6 +5,8 @@ package bengine
 
 import (
 	"math/rand"
+	"sync"
+	"sync/atomic"
 
 	. "github.com/easychessanimations/gochess/butils"
 )
@@ -139,8 +141,18 @@ func (eng *Engine) searchTree(α, β, depth int32) int32 {
 	eng.Stats.Nodes++
 	if !eng.stopped && eng.Stats.Nodes >= eng.checkpoint {
 		eng.checkpoint = eng.Stats.Nodes + checkpointStep
-		if eng.timeControl.Stopped() {
-			eng.stopped = true
+		if eng.abort != nil {
+			// helpers only follow the main thread, which owns the time control
+			atomic.StoreUint64(&eng.reportedNodes, eng.Stats.Nodes)
+			eng.stopped = eng.abort.get() || eng.timeControl.StopRequested()
+		} else {
+			if nodes := eng.timeControl.Nodes; nodes != 0 && eng.checkpoint > nodes {
+				// check every node once close to the node limit
+				eng.checkpoint = nodes
+			}
+			if eng.timeControl.Stopped() || eng.timeControl.NodesExceeded(eng.totalNodes()) {
+				eng.stopped = true
+			}
 		}
 	}
 	if eng.stopped {
@@ -159,6 +171,13 @@ func (eng *Engine) searchTree(α, β, depth int32) int32 {
 		return score
 	}
 
+	// positions covered by the tablebase have an exact score
+	if ply != 0 {
+		if score, ok := eng.probeTablebase(); ok {
+			return score
+		}
+	}
+
 	// mate pruning: if an ancestor already has a mate in ply moves then
 	// the search will always fail low so we return the lowest winning score
 	if MateScore-ply <= α {
@@ -430,8 +449,10 @@ func (eng *Engine) searchMultiPV(depth, estimated int32) (int32, []Move) {
 		}
 	}
 
+	stats := eng.Stats
+	stats.Nodes = eng.totalNodes()
 	for i := range pvs {
-		eng.Log.PrintPV(eng.Stats, i+1, pvs[i].score, pvs[i].moves)
+		eng.Log.PrintPV(stats, i+1, pvs[i].score, pvs[i].moves)
 	}
 
 	// for best play return the PV with highest score
@@ -468,20 +489,27 @@ func (eng *Engine) Play(tc *TimeControl) (score int32, moves []Move) {
 //
 // Time control, tc, should already be started
 func (eng *Engine) PlayMoves(tc *TimeControl, rootMoves []Move) (score int32, moves []Move) {
-	if !initialized {
-		initEngine()
+	initOnce.Do(initEngine)
+
+	if tc.Mate > 0 {
+		return eng.playMate(tc, rootMoves)
 	}
 
-	eng.Log.BeginSearch()
-	eng.Stats = Stats{Depth: -1}
+	if move, ok := eng.bookMove(rootMoves); ok {
+		return 0, []Move{move}
+	}
 
-	eng.rootPly = eng.Position.Ply
-	eng.timeControl = tc
-	eng.stopped = false
-	eng.checkpoint = checkpointStep
-	eng.stack.Reset(eng.Position)
-	eng.history.newSearch()
-	eng.onlyRootMoves = rootMoves
+	if score, move, ok := eng.tablebaseMove(rootMoves); ok {
+		eng.Log.BeginSearch()
+		eng.Stats = Stats{}
+		eng.Log.PrintPV(eng.Stats, 1, score, []Move{move})
+		eng.Log.EndSearch()
+		return score, []Move{move}
+	}
+
+	eng.Log.BeginSearch()
+	eng.newSearch(tc, rootMoves)
+	stopHelpers := eng.startHelpers(tc, rootMoves)
 
 	for depth := int32(0); depth < 64; depth++ {
 		if !tc.NextDepth(depth) {
@@ -496,6 +524,9 @@ func (eng *Engine) PlayMoves(tc *TimeControl, rootMoves []Move) (score int32, mo
 		}
 	}
 
+	stopHelpers()
+	eng.Stats.Nodes = eng.totalNodes()
+
 	eng.Log.EndSearch()
 	if len(moves) == 0 && !eng.Position.HasLegalMoves() {
 		return 0, nil
@@ -505,6 +536,93 @@ func (eng *Engine) PlayMoves(tc *TimeControl, rootMoves []Move) (score int32, mo
 	return score, moves
 }
 
+// playMate runs SearchMate for PlayMoves, the mating line is returned as principal variation
+// without a mate the first legal move is returned, so that a move can still be played
+func (eng *Engine) playMate(tc *TimeControl, rootMoves []Move) (int32, []Move) {
+	eng.LastMate = eng.SearchMate(tc, tc.Mate, rootMoves)
+
+	if eng.LastMate.Moves > 0 {
+		return MateScore - (2*eng.LastMate.Moves - 1), eng.LastMate.PV
+	}
+
+	if len(rootMoves) == 0 {
+		rootMoves = eng.Position.LegalMoves()
+	}
+	if len(rootMoves) == 0 {
+		return 0, nil
+	}
+	return 0, rootMoves[:1]
+}
+
+// newSearch resets the search state of eng before searching the current position
+func (eng *Engine) newSearch(tc *TimeControl, rootMoves []Move) {
+	eng.Stats = Stats{Depth: -1}
+
+	eng.rootPly = eng.Position.Ply
+	eng.timeControl = tc
+	eng.stopped = false
+	eng.checkpoint = checkpointStep
+	eng.stack.Reset(eng.Position)
+	eng.history.newSearch()
+	eng.onlyRootMoves = rootMoves
+	atomic.StoreUint64(&eng.reportedNodes, 0)
+}
+
+// startHelpers starts Options.Threads-1 helper threads searching copies of the current position
+// helpers share only the transposition table with eng, the returned function stops and waits for them
+func (eng *Engine) startHelpers(tc *TimeControl, rootMoves []Move) func() {
+	threads := min(int32(eng.Options.Threads), MaxThreads)
+	if threads <= 1 || eng.UseAB {
+		eng.helpers = nil
+		return func() {}
+	}
+
+	for len(eng.helpers) < int(threads)-1 {
+		eng.helpers = append(eng.helpers, NewEngine(nil, nil, Options{}))
+	}
+	eng.helpers = eng.helpers[:threads-1]
+
+	abort := &atomicFlag{}
+	var wg sync.WaitGroup
+	for i, helper := range eng.helpers {
+		helper.Position = eng.Position.Clone()
+		helper.Tablebase = eng.Tablebase
+		helper.abort = abort
+		helper.newSearch(tc, rootMoves)
+
+		wg.Add(1)
+		go func(helper *Engine, offset int32) {
+			defer wg.Done()
+			helper.searchHelper(offset)
+		}(helper, int32(i%2))
+	}
+
+	return func() {
+		abort.set()
+		wg.Wait()
+	}
+}
+
+// searchHelper deepens the search until aborted by the main thread
+// half of the helpers start one depth ahead so that threads search different depths
+func (eng *Engine) searchHelper(offset int32) {
+	score := int32(0)
+	for depth := 1 + offset; depth < 64 && !eng.stopped; depth++ {
+		eng.Stats.Depth = depth
+		score = eng.search(depth, score)
+	}
+	atomic.StoreUint64(&eng.reportedNodes, eng.Stats.Nodes)
+}
+
+// totalNodes returns the number of nodes searched by eng and its helpers
+func (eng *Engine) totalNodes() uint64 {
+	nodes := eng.Stats.Nodes
+	for _, helper := range eng.helpers {
+		nodes += atomic.LoadUint64(&helper.reportedNodes)
+	}
+	return nodes
+}
+
 // ply returns the ply from the beginning of the search
 func (eng *Engine) ply() int32 {
 	return int32(eng.Position.Ply - eng.rootPly)
@@ -533,7 +651,7 @@ func (eng *Engine) UndoMove() {
 
 // Score evaluates current position from current player's POV
 func (eng *Engine) Score() int32 {
-	return Evaluate(eng.Position).GetCentipawnsScore() * eng.Position.Us().Multiplier()
+	return evaluateCached(eng.Position, eng.pawns).GetCentipawnsScore() * eng.Position.Us().Multiplier()
 }
 
 // cachedScore implements a cache on top of Score
@@ -643,6 +761,11 @@ func (eng *Engine) searchQuiescence(α, β int32) int32 {
 		return score
 	}
 
+	if eng.Position.IsAtomic() && Kings(eng.Position, eng.Position.Us()) == 0 {
+		// our king exploded
+		return MatedScore + eng.ply()
+	}
+
 	static := eng.cachedScore(&entry)
 	if static >= β {
 		// stand pat if the static score is already a cut-off
@@ -702,8 +825,6 @@ func initEngine() {
 			futilityFigureBonus[f] = Evaluate(pos).GetCentipawnsScore()
 		}
 	}
-
-	initialized = true
 }
 
 /////////////////////////////////////////////////////////////////////
@@ -4,6 +4,8 @@ package bengine
 // imports
 
 import (
+	"sync/atomic"
+
 	. "github.com/easychessanimations/gochess/butils"
 )
 
@@ -23,10 +25,10 @@ func (ht *HashTable) put(pos *Position, entry hashEntry) {
 	lock, key0, key1 := split(pos.Zobrist(), ht.mask)
 	entry.lock = lock
 
-	if e := &ht.table[key0]; e.lock == lock || e.kind == 0 || e.depth >= entry.depth {
-		ht.table[key0] = entry
+	if e, ok := ht.table[key0].load(); !ok || e.lock == lock || e.kind == 0 || e.depth >= entry.depth {
+		ht.table[key0].store(entry)
 	} else {
-		ht.table[key1] = entry
+		ht.table[key1].store(entry)
 	}
 }
 
@@ -37,11 +39,11 @@ func (ht *HashTable) put(pos *Position, entry hashEntry) {
 // we use 32-bit lock + log_2(len(ht.table)) bits to avoid collisions
 func (ht *HashTable) get(pos *Position) hashEntry {
 	lock, key0, key1 := split(pos.Zobrist(), ht.mask)
-	if ht.table[key0].lock == lock {
-		return ht.table[key0]
+	if e, ok := ht.table[key0].load(); ok && e.lock == lock {
+		return e
 	}
-	if ht.table[key1].lock == lock {
-		return ht.table[key1]
+	if e, ok := ht.table[key1].load(); ok && e.lock == lock {
+		return e
 	}
 	return hashEntry{}
 }
@@ -49,8 +51,36 @@ func (ht *HashTable) get(pos *Position) hashEntry {
 // Clear removes all entries from hash
 func (ht *HashTable) Clear() {
 	for i := range ht.table {
-		ht.table[i] = hashEntry{}
+		ht.table[i].store(hashEntry{})
+	}
+}
+
+// store packs entry into the slot
+func (s *hashSlot) store(entry hashEntry) {
+	move := uint64(entry.move)
+	data := uint64(uint16(entry.score)) | uint64(uint16(entry.static))<<16 |
+		uint64(uint8(entry.depth))<<32 | uint64(entry.kind)<<40
+	atomic.StoreUint64(&s.move, move)
+	atomic.StoreUint64(&s.data, data)
+	atomic.StoreUint64(&s.check, uint64(entry.lock)^move^data)
+}
+
+// load unpacks the entry from the slot, returns false if the slot was torn by concurrent writes
+func (s *hashSlot) load() (hashEntry, bool) {
+	move := atomic.LoadUint64(&s.move)
+	data := atomic.LoadUint64(&s.data)
+	lock := atomic.LoadUint64(&s.check) ^ move ^ data
+	if lock>>32 != 0 {
+		return hashEntry{}, false
 	}
+	return hashEntry{
+		lock:   uint32(lock),
+		move:   Move(move),
+		score:  int16(uint16(data)),
+		static: int16(uint16(data >> 16)),
+		depth:  int8(uint8(data >> 32)),
+		kind:   hashFlags(data >> 40),
+	}, true
 }
 
 /////////////////////////////////////////////////////////////////////
@@ -35,7 +35,7 @@ func (e Eval) GetCentipawnsScore() int32 {
 	return scaleToCentipawns(score)
 }
 
-// EvalExtra calculates additional Eval of extra pieces
+// EvalExtra calculates the additional Eval of eightpiece terms not covered by Weights
 func EvalExtra(pos *Position) Eval {
 	e := Eval{position: pos}
 
@@ -45,28 +45,7 @@ func EvalExtra(pos *Position) Eval {
 	return e
 }
 
-// extra piece values
-const PAWN_VALUE_NATIVE_M = 30000
-const PAWN_VALUE_NATIVE_E = 40000
-const CENTER_PAWN_BONUS_NATIVE_M = 15000
-const CENTER_PAWN_BONUS_NATIVE_E = 0
-const KNIGHT_VALUE_NATIVE_M = 90000
-const KNIGHT_VALUE_NATIVE_E = 90000
-const KNIGHT_ON_EDGE_DEUDCTION_M = 10000
-const KNIGHT_ON_EDGE_DEUDCTION_E = 10000
-const BISHOP_VALUE_NATIVE_M = 90000
-const BISHOP_VALUE_NATIVE_E = 90000
-const ROOK_VALUE_NATIVE_M = 150000
-const ROOK_VALUE_NATIVE_E = 150000
-const QUEEN_VALUE_NATIVE_M = 270000
-const QUEEN_VALUE_NATIVE_E = 270000
-const LANCER_VALUE_NATIVE_M = 200000
-const LANCER_VALUE_NATIVE_E = 200000
-const SENTRY_VALUE_NATIVE_M = 110000
-const SENTRY_VALUE_NATIVE_E = 110000
-const JAILER_VALUE_NATIVE_M = 140000
-const JAILER_VALUE_NATIVE_E = 140000
-
+// lancer deductions and bonuses, the values of the pieces are in Weights
 const LANCER_TOWARDS_EDGE_DEDUCTION_M = 10000
 const LANCER_TOWARDS_EDGE_DEDUCTION_E = 10000
 
@@ -76,8 +55,6 @@ const BASE_LANCER_BONUS_E = 0
 const RANDOM_BONUS_NATIVE_M = 10000
 const RANDOM_BONUS_NATIVE_E = 10000
 
-var CENTER_PAWN_MASK = SquareE4.Bitboard() | SquareD4.Bitboard() | SquareE5.Bitboard() | SquareD5.Bitboard()
-
 // PawnStartRank tells the pawn start rank for a given color
 func PawnStartRank(color Color) Bitboard {
 	if color == Black {
@@ -86,81 +63,16 @@ func PawnStartRank(color Color) Bitboard {
 	return BbPawnStartRankWhite
 }
 
-// evaluateExtra calculates additional Accum of extra pieces for a side
+// evaluateExtra calculates the eightpiece terms which are not expressed as weights for a side
+// and adds the random bonus if it is enabled
 func evaluateExtra(pos *Position, us Color) Accum {
 	var accum Accum
 
-	pawns := pos.ByPiece(us, Pawn)
-
-	numPawns := pawns.Count()
-
-	accum.M += numPawns * PAWN_VALUE_NATIVE_M
-	accum.E += numPawns * PAWN_VALUE_NATIVE_E
-
-	centerPawns := pawns & CENTER_PAWN_MASK
-
-	numCenterPawns := centerPawns.Count()
-
-	accum.M += numCenterPawns * CENTER_PAWN_BONUS_NATIVE_M
-	accum.E += numCenterPawns * CENTER_PAWN_BONUS_NATIVE_E
-
-	knights := pos.ByPiece(us, Knight)
-
-	numKnights := knights.Count()
-
-	accum.M += numKnights * KNIGHT_VALUE_NATIVE_M
-	accum.E += numKnights * KNIGHT_VALUE_NATIVE_E
-
-	edgeKnights := knights & BbBorder
-
-	numEdgeKnights := edgeKnights.Count()
-
-	accum.M -= numEdgeKnights * KNIGHT_ON_EDGE_DEUDCTION_M
-	accum.E -= numEdgeKnights * KNIGHT_ON_EDGE_DEUDCTION_E
-
-	bishops := pos.ByPiece(us, Bishop)
-
-	numBishops := bishops.Count()
-
-	accum.M += numBishops * BISHOP_VALUE_NATIVE_M
-	accum.E += numBishops * BISHOP_VALUE_NATIVE_E
-
-	rooks := pos.ByPiece(us, Rook)
-
-	numRooks := rooks.Count()
-
-	accum.M += numRooks * ROOK_VALUE_NATIVE_M
-	accum.E += numRooks * ROOK_VALUE_NATIVE_E
-
-	queens := pos.ByPiece(us, Queen)
-
-	numQueens := queens.Count()
-
-	accum.M += numQueens * QUEEN_VALUE_NATIVE_M
-	accum.E += numQueens * QUEEN_VALUE_NATIVE_E
-
-	sentries := pos.ByPiece(us, Sentry)
-
-	numSentries := sentries.Count()
-
-	accum.M += numSentries * SENTRY_VALUE_NATIVE_M
-	accum.E += numSentries * SENTRY_VALUE_NATIVE_E
-
-	jailers := pos.ByPiece(us, Jailer)
-
-	numJailers := jailers.Count()
-
-	accum.M += numJailers * JAILER_VALUE_NATIVE_M
-	accum.E += numJailers * JAILER_VALUE_NATIVE_E
+	lancerValue := Weights[fLancer]
 
 	for ld := 0; ld < NUM_LANCER_DIRECTIONS; ld++ {
 		lancers := pos.ByPiece(us, MakeLancer(us, ld).Figure())
 
-		numLancers := lancers.Count()
-
-		accum.M += numLancers * LANCER_VALUE_NATIVE_M
-		accum.E += numLancers * LANCER_VALUE_NATIVE_E
-
 		// deductions for lancer facing the edge of the board
 		for bb := lancers; bb != 0; {
 			sq := bb.Pop()
@@ -202,8 +114,8 @@ func evaluateExtra(pos *Position, us Color) Accum {
 			}
 
 			if superDeduction {
-				accum.M -= LANCER_VALUE_NATIVE_M
-				accum.E -= LANCER_VALUE_NATIVE_E
+				accum.M -= lancerValue.M
+				accum.E -= lancerValue.E
 			} else {
 				accum.M -= int32(deduction * LANCER_TOWARDS_EDGE_DEDUCTION_M)
 				accum.E -= int32(deduction * LANCER_TOWARDS_EDGE_DEDUCTION_E)
@@ -225,27 +137,31 @@ func evaluateExtra(pos *Position, us Color) Accum {
 		}
 	}
 
-	accum.M += int32(rand.Intn(RANDOM_BONUS_NATIVE_M))
-	accum.E += int32(rand.Intn(RANDOM_BONUS_NATIVE_E))
+	if RandomBonus {
+		accum.M += int32(rand.Intn(RANDOM_BONUS_NATIVE_M))
+		accum.E += int32(rand.Intn(RANDOM_BONUS_NATIVE_E))
+	}
 
 	return accum
 }
 
 // Evaluate evaluates the position pos
 func Evaluate(pos *Position) Eval {
+	return evaluateCached(pos, &pawnsAndShelterCache)
+}
+
+// evaluateCached evaluates the position pos looking up the pawn structure in pawns
+// each search thread has its own pawns cache
+func evaluateCached(pos *Position, pawns *pawnsTable) Eval {
 	e := Eval{position: pos}
 
-	/*e.Accum[White] = evaluate(pos, White)
+	e.Accum[White] = evaluate(pos, White)
 	e.Accum[Black] = evaluate(pos, Black)
 
-	wps, bps := pawnsAndShelterCache.load(pos)
+	wps, bps := pawns.load(pos)
 	e.Accum[White].merge(wps)
 	e.Accum[Black].merge(bps)
 
-	// extra
-	// TODO: original evaluation is ignored here
-	e = Eval{position: pos}*/
-
 	ee := EvalExtra(pos)
 	e.Accum[White].merge(ee.Accum[White])
 	e.Accum[Black].merge(ee.Accum[Black])
@@ -255,6 +171,20 @@ func Evaluate(pos *Position) Eval {
 	return e
 }
 
+// Features returns the inputs of the network for pos from White's point of view
+// the evaluation of pos is the sum of the inputs multiplied by the weights interpolated by Phase
+func Features(pos *Position) []int32 {
+	var accum [ColorArraySize]Accum
+	for _, col := range []Color{White, Black} {
+		accum[col].Values = resize(nil)
+		evaluateSide(pos, col, &accum[col])
+		evaluatePawns(pos, col, &accum[col])
+		evaluateShelter(pos, col, &accum[col])
+	}
+	accum[White].deduct(accum[Black])
+	return accum[White].Values
+}
+
 // evaluatePawnsAndShelter evaluates pawns and shelter
 func evaluatePawnsAndShelter(pos *Position, us Color) (accum Accum) {
 	evaluatePawns(pos, us, &accum)
@@ -309,6 +239,12 @@ func evaluateShelter(pos *Position, us Color, accum *Accum) {
 // evaluate evaluates position for a single side
 func evaluate(pos *Position, us Color) Accum {
 	var accum Accum
+	evaluateSide(pos, us, &accum)
+	return accum
+}
+
+// evaluateSide evaluates pieces of a single side
+func evaluateSide(pos *Position, us Color, accum *Accum) {
 	them := us.Opposite()
 	all := pos.ByColor(White) | pos.ByColor(Black)
 	danger := PawnThreats(pos, them)
@@ -316,21 +252,24 @@ func evaluate(pos *Position, us Color) Accum {
 	theirPawns := pos.ByPiece(them, Pawn)
 	theirKingArea := KingArea(pos, them)
 
-	groupByBoard(fNoFigure, BbEmpty, &accum)
-	groupByBoard(fPawn, Pawns(pos, us), &accum)
-	groupByBoard(fKnight, Knights(pos, us), &accum)
-	groupByBoard(fBishop, Bishops(pos, us), &accum)
-	groupByBoard(fRook, Rooks(pos, us), &accum)
-	groupByBoard(fQueen, Queens(pos, us), &accum)
-	groupByBoard(fKing, BbEmpty, &accum)
+	groupByBoard(fNoFigure, BbEmpty, accum)
+	groupByBoard(fPawn, Pawns(pos, us), accum)
+	groupByBoard(fKnight, Knights(pos, us), accum)
+	groupByBoard(fBishop, Bishops(pos, us), accum)
+	groupByBoard(fRook, Rooks(pos, us), accum)
+	groupByBoard(fQueen, Queens(pos, us), accum)
+	groupByBoard(fKing, BbEmpty, accum)
+	groupByBoard(fLancer, Lancers(pos, us), accum)
+	groupByBoard(fSentry, Sentries(pos, us), accum)
+	groupByBoard(fJailer, Jailers(pos, us), accum)
 
 	// evaluate various pawn attacks and potential pawn attacks
 	// on the enemy pieces
-	groupByBoard(fPawnMobility, ourPawns&^Backward(us, all), &accum)
-	groupByBoard(fMinorsPawnsAttack, Minors(pos, us)&danger, &accum)
-	groupByBoard(fMajorsPawnsAttack, Majors(pos, us)&danger, &accum)
-	groupByBoard(fMinorsPawnsPotentialAttack, Minors(pos, us)&Backward(us, danger), &accum)
-	groupByBoard(fMajorsPawnsPotentialAttack, Majors(pos, us)&Backward(us, danger), &accum)
+	groupByBoard(fPawnMobility, ourPawns&^Backward(us, all), accum)
+	groupByBoard(fMinorsPawnsAttack, Minors(pos, us)&danger, accum)
+	groupByBoard(fMajorsPawnsAttack, Majors(pos, us)&danger, accum)
+	groupByBoard(fMinorsPawnsPotentialAttack, Minors(pos, us)&Backward(us, danger), accum)
+	groupByBoard(fMajorsPawnsPotentialAttack, Majors(pos, us)&Backward(us, danger), accum)
 
 	numAttackers := 0
 	attacks := PawnThreats(pos, us)
@@ -340,9 +279,9 @@ func evaluate(pos *Position, us Color) Accum {
 		sq := bb.Pop()
 		mobility := KnightMobility(sq) &^ (danger | ourPawns)
 		attacks |= mobility
-		groupByFileSq(fKnightFile, us, sq, &accum)
-		groupByRankSq(fKnightRank, us, sq, &accum)
-		groupByBoard(fKnightAttack, mobility, &accum)
+		groupByFileSq(fKnightFile, us, sq, accum)
+		groupByRankSq(fKnightRank, us, sq, accum)
+		groupByBoard(fKnightAttack, mobility, accum)
 		if mobility&theirKingArea&^theirPawns != 0 {
 			numAttackers++
 		}
@@ -356,9 +295,9 @@ func evaluate(pos *Position, us Color) Accum {
 		attacks |= mobility
 		mobility &^= danger | ourPawns
 		numBishops++
-		groupByFileSq(fBishopFile, us, sq, &accum)
-		groupByRankSq(fBishopRank, us, sq, &accum)
-		groupByBoard(fBishopAttack, mobility, &accum)
+		groupByFileSq(fBishopFile, us, sq, accum)
+		groupByRankSq(fBishopRank, us, sq, accum)
+		groupByBoard(fBishopAttack, mobility, accum)
 		if mobility&theirKingArea&^theirPawns != 0 {
 			numAttackers++
 		}
@@ -370,11 +309,11 @@ func evaluate(pos *Position, us Color) Accum {
 		sq := bb.Pop()
 		mobility := RookMobility(sq, all) &^ (danger | ourPawns)
 		attacks |= mobility
-		groupByFileSq(fRookFile, us, sq, &accum)
-		groupByRankSq(fRookRank, us, sq, &accum)
-		groupByBoard(fRookAttack, mobility, &accum)
-		groupByBool(fRookOnOpenFile, openFiles.Has(sq), &accum)
-		groupByBool(fRookOnSemiOpenFile, semiOpenFiles.Has(sq), &accum)
+		groupByFileSq(fRookFile, us, sq, accum)
+		groupByRankSq(fRookRank, us, sq, accum)
+		groupByBoard(fRookAttack, mobility, accum)
+		groupByBool(fRookOnOpenFile, openFiles.Has(sq), accum)
+		groupByBool(fRookOnSemiOpenFile, semiOpenFiles.Has(sq), accum)
 		if mobility&theirKingArea&^theirPawns != 0 {
 			numAttackers++
 		}
@@ -384,26 +323,63 @@ func evaluate(pos *Position, us Color) Accum {
 		sq := bb.Pop()
 		mobility := QueenMobility(sq, all) &^ (danger | ourPawns)
 		attacks |= mobility
-		groupByFileSq(fQueenFile, us, sq, &accum)
-		groupByRankSq(fQueenRank, us, sq, &accum)
-		groupByBoard(fQueenAttack, mobility, &accum)
+		groupByFileSq(fQueenFile, us, sq, accum)
+		groupByRankSq(fQueenRank, us, sq, accum)
+		groupByBoard(fQueenAttack, mobility, accum)
 		if mobility&theirKingArea&^theirPawns != 0 {
 			numAttackers++
 		}
 
 		dist := distance[sq][Kings(pos, them).AsSquare()]
-		groupByCount(fKingQueenTropism, dist, &accum)
+		groupByCount(fKingQueenTropism, dist, accum)
 	}
 
-	groupByBoard(fAttackedMinors, attacks&Minors(pos, them), &accum)
-	groupByBool(fBishopPair, numBishops == 2, &accum)
+	// lancer
+	ours, theirs := pos.ByColor(us), pos.ByColor(them)
+	jailed := pos.JailedForColor(us)
+	for ld := 0; ld < NUM_LANCER_DIRECTIONS; ld++ {
+		for bb := pos.ByPiece(us, MakeLancer(us, ld).Figure()) &^ jailed; bb > 0; {
+			sq := bb.Pop()
+			mobility := LancerMobility(sq, ld, ours, theirs) &^ danger
+			attacks |= mobility
+			groupByBoard(fLancerAttack, mobility, accum)
+			if mobility&theirKingArea&^theirPawns != 0 {
+				numAttackers++
+			}
+		}
+	}
+	// sentry, it can push the pieces it attacks so it threatens the king shelter too
+	numSentryAttackers := 0
+	for bb := Sentries(pos, us) &^ jailed; bb > 0; {
+		sq := bb.Pop()
+		mobility := BishopMobility(sq, all) &^ (danger | ourPawns)
+		attacks |= mobility
+		groupByBoard(fSentryAttack, mobility, accum)
+		if mobility&theirKingArea != 0 {
+			numAttackers++
+			numSentryAttackers++
+		}
+	}
+	groupByCount(fKingSentryAttackers, int32(numSentryAttackers), accum)
+	// jailer, it does not capture
+	for bb := Jailers(pos, us) &^ jailed; bb > 0; {
+		sq := bb.Pop()
+		mobility := JailerMobility(sq, ours, theirs) &^ danger
+		groupByBoard(fJailerAttack, mobility, accum)
+	}
+
+	// pieces next to an enemy jailer cannot move
+	groupByBoard(fJailedPieces, ours&jailed&^Kings(pos, us), accum)
+	groupByBool(fKingJailed, Kings(pos, us)&jailed != 0, accum)
+
+	groupByBoard(fAttackedMinors, attacks&Minors(pos, them), accum)
+	groupByBool(fBishopPair, numBishops == 2, accum)
 
 	// king's safety is very primitive:
 	// - king's shelter is evaluated by evaluateShelter
 	// - the following counts the number of attackers
 	// TODO: queen tropism which was dropped during the last refactoring
-	groupByBucket(fKingAttackers, numAttackers, 4, &accum)
-	return accum
+	groupByBucket(fKingAttackers, numAttackers, 4, accum)
 }
 
 // phase computes the progress of the game
@@ -0,0 +1,262 @@
+package bengine
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/easychessanimations/gochess/butils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// mate search
+
+// SearchMate searches a forced mate in at most n moves for the side to move, trying only rootMoves if not empty
+// the shortest mate is found first, then all root moves mating in at most n moves are collected
+// so that composed problems can be checked for a unique solution
+// a position is mated when it has no legal moves and is in check, this covers exploded kings in atomic
+// and jailed kings in eightpiece, stalemate, repetitions and the fifty move rule never mate
+//
+// Time control, tc, should already be started
+func (eng *Engine) SearchMate(tc *TimeControl, n int32, rootMoves []Move) MateResult {
+	pos := eng.Position // shortcut
+
+	eng.Log.BeginSearch()
+	eng.newSearch(tc, rootMoves)
+
+	if len(rootMoves) == 0 {
+		rootMoves = pos.LegalMoves()
+	}
+
+	ms := &mateSearch{
+		eng:    eng,
+		tc:     tc,
+		proven: map[uint64]int32{},
+		failed: map[uint64]int32{},
+	}
+
+	result := MateResult{}
+
+	for j := int32(1); j <= n && tc.NextDepth(2*j-1); j++ {
+		eng.Stats.Depth = 2*j - 1
+
+		if move := ms.attackIn(j, rootMoves); move != NullMove {
+			result.Moves = j
+			result.PV = ms.line(move, j)
+			eng.Log.PrintPV(eng.Stats, 1, MateScore-(2*j-1), result.PV)
+			break
+		}
+
+		if ms.stopped {
+			break
+		}
+	}
+
+	if result.Moves > 0 && !ms.stopped {
+		solutions := []Move{}
+		for _, m := range rootMoves {
+			pos.DoMove(m)
+			mates := ms.defend(n-1) >= 0
+			pos.UndoMove()
+
+			if ms.stopped {
+				break
+			}
+			if mates {
+				solutions = append(solutions, m)
+			}
+		}
+		if !ms.stopped {
+			result.Solutions = solutions
+		}
+	}
+
+	eng.Log.EndSearch()
+	return result
+}
+
+// attack returns n if the side to move mates in n moves, n <= k, and 0 otherwise
+func (ms *mateSearch) attack(k int32) int32 {
+	key := ms.eng.Position.Zobrist()
+
+	if n, ok := ms.proven[key]; ok {
+		if n <= k {
+			return n
+		}
+		return 0
+	}
+
+	if ms.failed[key] >= k {
+		return 0
+	}
+
+	moves := ms.eng.Position.LegalMoves()
+
+	// iterative deepening, so that the first mate found is the shortest
+	for j := ms.failed[key] + 1; j <= k; j++ {
+		if ms.attackIn(j, moves) != NullMove {
+			ms.proven[key] = j
+			return j
+		}
+		if ms.stopped {
+			return 0
+		}
+		ms.failed[key] = j
+	}
+
+	return 0
+}
+
+// attackIn returns a move of moves mating in at most j moves, NullMove if there is none
+// checks are tried first, the last move of a mate is always a check
+func (ms *mateSearch) attackIn(j int32, moves []Move) Move {
+	pos := ms.eng.Position // shortcut
+
+	quiet := []Move{}
+
+	for _, m := range moves {
+		pos.DoMove(m)
+		checks := pos.IsChecked(pos.Us())
+		mates := checks && ms.defend(j-1) >= 0
+		pos.UndoMove()
+
+		if mates {
+			return m
+		}
+		if ms.stopped {
+			return NullMove
+		}
+		if !checks {
+			quiet = append(quiet, m)
+		}
+	}
+
+	if j == 1 {
+		return NullMove
+	}
+
+	for _, m := range quiet {
+		pos.DoMove(m)
+		mates := ms.defend(j-1) >= 0
+		pos.UndoMove()
+
+		if mates {
+			return m
+		}
+		if ms.stopped {
+			return NullMove
+		}
+	}
+
+	return NullMove
+}
+
+// defend returns the number of moves the side to move, the defender, survives against the best attack
+// returns 0 if it is mated and -1 if it is not mated in k more moves of the attacker
+func (ms *mateSearch) defend(k int32) int32 {
+	pos := ms.eng.Position // shortcut
+
+	ms.eng.Stats.Nodes++
+	if ms.eng.Stats.Nodes%mateCheckpointStep == 0 && ms.tc.Stopped() {
+		ms.stopped = true
+	}
+	if ms.stopped {
+		return -1
+	}
+
+	checked := pos.IsChecked(pos.Us())
+
+	if k == 0 {
+		if checked && !pos.HasLegalMoves() {
+			return 0
+		}
+		return -1
+	}
+
+	moves := pos.LegalMoves()
+	if len(moves) == 0 {
+		if checked {
+			return 0
+		}
+		return -1 // stalemate
+	}
+
+	longest := int32(0)
+	for _, m := range moves {
+		pos.DoMove(m)
+		n := ms.attack(k)
+		pos.UndoMove()
+
+		if n == 0 {
+			return -1
+		}
+		longest = max(longest, n)
+	}
+
+	return longest
+}
+
+// line returns the mating line starting with move, which mates in n moves
+// the defender plays the reply delaying the mate the longest
+func (ms *mateSearch) line(move Move, n int32) []Move {
+	pos := ms.eng.Position // shortcut
+
+	pv := []Move{}
+
+	for move != NullMove {
+		pos.DoMove(move)
+		pv = append(pv, move)
+
+		reply, longest := NullMove, int32(0)
+		for _, m := range pos.LegalMoves() {
+			pos.DoMove(m)
+			if k := ms.attack(n - 1); k > longest {
+				reply, longest = m, k
+			}
+			pos.UndoMove()
+		}
+
+		if reply == NullMove {
+			break // mated or the search was stopped
+		}
+
+		pos.DoMove(reply)
+		pv = append(pv, reply)
+
+		move, n = ms.attackIn(longest, pos.LegalMoves()), longest
+	}
+
+	for range pv {
+		pos.UndoMove()
+	}
+
+	return pv
+}
+
+// Summary describes the result of a mate search for a mate in at most n moves in pos
+func (mr MateResult) Summary(pos *Position, n int32) string {
+	if mr.Moves == 0 {
+		return fmt.Sprintf("no mate in %d found", n)
+	}
+
+	if mr.Solutions == nil {
+		return fmt.Sprintf("mate in %d, solutions not verified", mr.Moves)
+	}
+
+	solutions := []string{}
+	for _, m := range mr.Solutions {
+		solutions = append(solutions, pos.MoveToUCI(m))
+	}
+
+	if len(solutions) == 1 {
+		return fmt.Sprintf("mate in %d, unique solution %s", mr.Moves, solutions[0])
+	}
+
+	return fmt.Sprintf("mate in %d, %d solutions in %d moves %s", mr.Moves, len(solutions), n, strings.Join(solutions, " "))
+}
+
+/////////////////////////////////////////////////////////////////////
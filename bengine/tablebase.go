@@ -0,0 +1,90 @@
+package bengine
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	. "github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/tablebase"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// endgame tablebase
+
+// SetTablebasePath loads the tables in directory path into eng.Tablebase
+// an empty path removes the tablebase, loading the same path again is a no op
+func (eng *Engine) SetTablebasePath(path string) error {
+	if path == eng.tablebasePath && (eng.Tablebase != nil || path == "") {
+		return nil
+	}
+
+	eng.Tablebase, eng.tablebasePath = nil, ""
+
+	if path == "" {
+		return nil
+	}
+
+	tb, err := tablebase.Open(path)
+	if err != nil {
+		return err
+	}
+
+	eng.Tablebase, eng.tablebasePath = tb, path
+
+	return nil
+}
+
+// probeTablebase returns the score of the current position if a table covers it
+func (eng *Engine) probeTablebase() (int32, bool) {
+	if eng.Tablebase == nil {
+		return 0, false
+	}
+
+	res, ok := eng.Tablebase.Probe(eng.Position)
+	if !ok {
+		return 0, false
+	}
+
+	return tablebaseScore(res, eng.ply()), true
+}
+
+// tablebaseMove returns the best move and the score of the current position if
+// a table with distance to mate covers it and all its moves
+// if rootMoves is not nil only those moves are considered
+func (eng *Engine) tablebaseMove(rootMoves []Move) (int32, Move, bool) {
+	if eng.Tablebase == nil || eng.Options.MultiPV > 1 {
+		return 0, NullMove, false
+	}
+
+	move, res, ok := eng.Tablebase.BestMove(eng.Position, rootMoves)
+	if !ok {
+		return 0, NullMove, false
+	}
+
+	return tablebaseScore(res, 0), move, true
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// tablebaseScore converts a tablebase result of a position at ply to a score
+// outcomes without distance to mate are scored as known wins and losses
+func tablebaseScore(res tablebase.Result, ply int32) int32 {
+	switch {
+	case res.WDL == tablebase.WDL_WIN && res.HasDTM:
+		return MateScore - ply - int32(res.DTM)
+	case res.WDL == tablebase.WDL_LOSS && res.HasDTM:
+		return MatedScore + ply + int32(res.DTM)
+	case res.WDL == tablebase.WDL_WIN:
+		return KnownWinScore
+	case res.WDL == tablebase.WDL_LOSS:
+		return KnownLossScore
+	}
+	return 0
+}
+
+/////////////////////////////////////////////////////////////////////
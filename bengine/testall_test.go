@@ -1,12 +1,20 @@
 package bengine
 
 import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	. "github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/epd"
+	"github.com/easychessanimations/gochess/utils"
 )
 
-const PERFT_4_EXPECTED_NODES = 206603
+// all nodes up to depth 4 from the eightpiece start position
+const PERFT_4_EXPECTED_NODES = 6121608
 
 /*
 testpositions
@@ -31,31 +39,451 @@ func TestPerft(t *testing.T) {
 	}
 }
 
-func BenchmarkPerft(b *testing.B) {
+func TestVariantPerft(t *testing.T) {
+	expected := map[utils.VariantKey]int{
+		utils.VARIANT_STANDARD: 206603,
+		utils.VARIANT_ATOMIC:   206648,
+	}
+
+	for variant, expectedNodes := range expected {
+		pos, err := PositionFromFENAndVariant(utils.StartFenForVariant(variant), variant)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if nodes := pos.Perft(4, false); nodes != expectedNodes {
+			t.Errorf("%s Perft 4 returned nodes %d, expected %d\n", utils.VariantKeyToVariantKeyString(variant), nodes, expectedNodes)
+		}
+	}
+}
+
+// leaf counts agreed by the board and bitboard generators, regression for the sentry and lancer rules
+var EIGHTPIECE_PERFT_SUITE = []struct {
+	FEN   string
+	Nodes []int
+}{
+	{"j1sqkb1r/ppppnppp/8/4Ln3/5ln2/P7/1PPPPPPP/J1SQKBNR w KQkq - 1 4 -", []int{34, 1131, 40457}},
+	{"jlse1qkbnr/ppp1pppp/3pB3/8/8/6Ps/PPPPPP1P/JLneSQK1NR w KQkq - 0 3 -", []int{65, 3344, 163435}},
+	// the sentry on c1 can push the bishop back to its own square
+	{"jlsesqk1nr/pppppppp/8/8/5b2/3P4/PPP1PPPP/JLneSQKBNR w KQkq - 0 1 -", []int{59, 3369, 166138}},
+}
+
+func TestEightpiecePerft(t *testing.T) {
+	for _, d := range EIGHTPIECE_PERFT_SUITE {
+		pos, err := PositionFromFENAndVariant(d.FEN, utils.VARIANT_EIGHTPIECE)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Perft counts all nodes up to depth, sum the expected leaf counts
+		expected := 0
+		for _, nodes := range d.Nodes {
+			expected += nodes
+		}
+
+		if nodes := pos.Perft(len(d.Nodes), false); nodes != expected {
+			t.Errorf("%s Perft %d returned nodes %d, expected %d\n", d.FEN, len(d.Nodes), nodes, expected)
+		}
+
+		if fen := pos.String(); fen != d.FEN {
+			t.Errorf("fen %s changed to %s by perft", d.FEN, fen)
+		}
+	}
+}
+
+// checks that depend on the sentry and lancer rules, the board package tests them on the same positions
+var EIGHTPIECE_CHECK_TESTS = []struct {
+	fen   string
+	check bool
+}{
+	// the lancer on a1 is not the pushed piece, the disabled move in its direction does not restrict it
+	{"k7/8/8/8/4p3/8/8/Ln3K3 b - - 0 1 e4e5", true},
+	// the sentry on h3 checks by pushing the rook on f1 onto the king
+	{"4k3/8/8/8/8/7s/8/5RK1 w - - 0 1 -", true},
+	{"4k3/8/8/8/8/7s/6P1/5RK1 w - - 0 1 -", false},
+}
+
+func TestEightpieceChecks(t *testing.T) {
+	for _, test := range EIGHTPIECE_CHECK_TESTS {
+		pos, err := PositionFromFENAndVariant(test.fen, utils.VARIANT_EIGHTPIECE)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if check := pos.IsChecked(pos.Us()); check != test.check {
+			t.Errorf("%s in check %v, expected %v", test.fen, check, test.check)
+		}
+	}
+}
+
+func TestDisabledMoveZobrist(t *testing.T) {
+	keys := map[uint64]string{}
+
+	for _, fen := range []string{
+		"k7/8/8/8/4p3/8/8/Ln3K3 b - - 0 1 -",
+		"k7/8/8/8/4p3/8/8/Ln3K3 b - - 0 1 e4e5",
+		"k7/8/8/8/4p3/8/8/Ln3K3 b - - 0 1 e4d5",
+	} {
+		pos, err := PositionFromFENAndVariant(fen, utils.VARIANT_EIGHTPIECE)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if other, ok := keys[pos.Zobrist()]; ok {
+			t.Errorf("%s has the key of %s", fen, other)
+		}
+		keys[pos.Zobrist()] = fen
+
+		// clearing the disabled move restores the key without it
+		pos.ClearDisabledMove()
+		if keys[pos.Zobrist()] != "k7/8/8/8/4p3/8/8/Ln3K3 b - - 0 1 -" {
+			t.Errorf("%s without its disabled move has key %x", fen, pos.Zobrist())
+		}
+	}
+}
+
+func TestSentryPushUndo(t *testing.T) {
+	fen := EIGHTPIECE_PERFT_SUITE[2].FEN
+
+	pos, err := PositionFromFENAndVariant(fen, utils.VARIANT_EIGHTPIECE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sentry := pos.Get(SquareC1)
+
+	for _, move := range pos.LegalMoves() {
+		pos.DoMove(move)
+		pos.UndoMove()
+
+		if pos.Get(SquareC1) != sentry || pos.String() != fen {
+			t.Errorf("%s was not undone, got %s", pos.MoveToUCI(move), pos.String())
+		}
+	}
+}
+
+func TestSanDisambiguation(t *testing.T) {
+	for fen, expected := range map[string]map[string]string{
+		// lancers of different directions share the letter
+		"4k3/8/8/8/8/8/8/K1Lne3Lnw1 w - - 0 1 -": {"c1e3lne": "Lce3=Lne", "g1e3lw": "Lge3=Lw"},
+		// en passant lands on an empty square
+		"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1 -": {"e5d6": "exd6"},
+	} {
+		pos, err := PositionFromFENAndVariant(fen, utils.VARIANT_EIGHTPIECE)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pos.CreateLegalMoveBuff()
+
+		for _, mbi := range pos.LegalMoveBuff {
+			uci := pos.MoveToUCI(mbi.Move)
+
+			if san, ok := expected[uci]; ok {
+				if got := pos.MoveToSanBatch(mbi.Move); got != san {
+					t.Errorf("%s: %s written as %s, expected %s", fen, uci, got, san)
+				}
+
+				delete(expected, uci)
+			}
+		}
+
+		for uci := range expected {
+			t.Errorf("%s: %s is not legal", fen, uci)
+		}
+	}
+}
+
+func TestSanToMove(t *testing.T) {
+	pos, err := PositionFromFENAndVariant("r3k2r/1P3ppp/8/3pP3/8/5N2/8/R3K2R w KQkq d6 0 1", utils.VARIANT_STANDARD)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for san, uci := range map[string]string{
+		"exd6 e.p.": "e5d6",
+		"O-O-O+":    "e1c1",
+		"b8Q":       "b7b8q",
+		"Nf3d4":     "f3d4",
+		// long algebraic without piece letter
+		"f3d4":  "f3d4",
+		"e1d1":  "e1d1",
+		"b7b8r": "b7b8r",
+	} {
+		move, err := pos.SanToMove(san)
+		if err != nil {
+			t.Errorf("%s: %v", san, err)
+		} else if pos.MoveToUCI(move) != uci {
+			t.Errorf("%s parsed as %s, expected %s", san, pos.MoveToUCI(move), uci)
+		}
+	}
+
+	for san, expected := range map[string]error{
+		"b7b8": utils.ErrAmbiguousMove,
+		"f3f4": utils.ErrIllegalMove,
+	} {
+		if _, err := pos.SanToMove(san); !errors.Is(err, expected) {
+			t.Errorf("%s: expected %v, got %v", san, expected, err)
+		}
+	}
+}
+
+// leaf counts agreed by the board and bitboard generators
+var ATOMIC_PERFT_SUITE = []struct {
+	FEN   string
+	Nodes []int
+}{
+	// the king cannot take the queen and taking it with a rook explodes the king, Kf1 is the only move
+	{"4k3/8/8/8/8/8/3q4/R3K2R w KQ - 0 1", []int{1, 28, 436, 10717}},
+	// adjacent kings cannot check each other
+	{"8/8/8/3kK3/8/8/8/r6R w - - 0 1", []int{21, 404, 7739, 145564}},
+	// castling rights are lost when a rook explodes
+	{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", []int{26, 593, 14295, 322445}},
+	{"rnb1kbnr/pppp1ppp/8/4p3/4P2q/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3", []int{26, 990, 26797}},
+	{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", []int{48, 1939, 88298}},
+}
+
+func TestAtomicPerft(t *testing.T) {
+	for _, d := range ATOMIC_PERFT_SUITE {
+		pos, err := PositionFromFENAndVariant(d.FEN, utils.VARIANT_ATOMIC)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Perft counts all nodes up to depth, sum the expected leaf counts
+		expected := 0
+		for _, nodes := range d.Nodes {
+			expected += nodes
+		}
+
+		if nodes := pos.Perft(len(d.Nodes), false); nodes != expected {
+			t.Errorf("%s Perft %d returned nodes %d, expected %d\n", d.FEN, len(d.Nodes), nodes, expected)
+		}
+	}
+}
+
+func TestJailerCastling(t *testing.T) {
+	// in eightpiece the jailer takes the place of the queen side rook
+	pos, err := PositionFromFENAndVariant("j3k2r/8/8/8/8/8/8/J3K2R w KQkq - 0 1 -", utils.VARIANT_EIGHTPIECE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	move, err := pos.UCIToMove("e1c1")
+	if err != nil || move.MoveType() != Castling {
+		t.Fatalf("expected e1c1 to castle with the jailer, got %v %v", move, err)
+	}
+
+	pos.DoMove(move)
+	if fen := pos.String(); !strings.HasPrefix(fen, "j3k2r/8/8/8/8/8/8/2KJ3R b kq") {
+		t.Errorf("castling with the jailer resulted in %s", fen)
+	}
+
+	// the castling piece has to be ours
+	if _, err := PositionFromFENAndVariant("j3k2r/8/8/8/8/8/8/j3K2R w KQkq - 0 1 -", utils.VARIANT_EIGHTPIECE); err == nil {
+		t.Errorf("expected an error for castling with a black jailer")
+	}
+}
+
+// https://www.chessprogramming.org/Chess960_Perft_Results
+var CHESS960_PERFT_SUITE = []struct {
+	FEN   string
+	Nodes []int
+}{
+	{"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9", []int{21, 528, 12189, 326672}},
+	{"2nnrbkr/p1qppppp/8/1ppb4/6PP/3PP3/PPP2P2/BQNNRBKR w HEhe - 1 9", []int{21, 807, 18002, 667366}},
+	{"b1q1rrkb/pppppppp/3nn3/8/P7/1PPP4/4PPPP/BQNNRKRB w GE - 1 9", []int{20, 479, 10471, 273318}},
+	{"qbbnnrkr/2pp2pp/p7/1p2pp2/8/P3PP2/1PPP1KPP/QBBNNR1R w hf - 0 9", []int{22, 593, 13440, 382958}},
+	{"1nbbnrkr/p1p1ppp1/3p4/1p3P1p/3Pq2P/8/PPP1P1P1/QNBBNRKR w HFhf - 0 9", []int{28, 1120, 31058, 1171749}},
+	{"qnbnr1kr/ppp1b1pp/4p3/3p1p2/8/2NPP3/PPP1BPPP/QNB1R1KR w HEhe - 1 9", []int{29, 899, 26578, 824055}},
+}
+
+func TestChess960Perft(t *testing.T) {
+	for _, d := range CHESS960_PERFT_SUITE {
+		pos, err := PositionFromFEN(d.FEN)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pos.Chess960 = true
+
+		// Perft counts all nodes up to depth, sum the expected leaf counts
+		expected := 0
+		for _, nodes := range d.Nodes {
+			expected += nodes
+		}
+
+		if nodes := pos.Perft(len(d.Nodes), false); nodes != expected {
+			t.Errorf("%s Perft %d returned nodes %d, expected %d\n", d.FEN, len(d.Nodes), nodes, expected)
+		}
+
+		if fen := pos.String(); fen != d.FEN {
+			t.Errorf("fen %s reported as %s", d.FEN, fen)
+		}
+	}
+}
+
+func TestNodesLimit(t *testing.T) {
+	pos, _ := PositionFromFENAndVariant(utils.STANDARD_START_FEN, utils.VARIANT_STANDARD)
+	eng := NewEngine(pos, nil, Options{})
+
+	tc := NewTimeControl(pos, false)
+	tc.Nodes = 20000
+	tc.Start(false)
+
+	// without the node limit the search would not stop
+	if _, pv := eng.PlayMoves(tc, nil); len(pv) == 0 {
+		t.Fatalf("no move found")
+	}
+
+	if nodes := eng.Stats.Nodes; nodes < tc.Nodes || nodes > tc.Nodes+checkpointStep {
+		t.Errorf("searched %d nodes, expected about %d", nodes, tc.Nodes)
+	}
+
+	if !tc.StopRequested() {
+		t.Errorf("node limit did not stop the search")
+	}
+}
+
+// fixedBook is a book that knows a single move
+type fixedBook string
+
+func (fb fixedBook) Probe(pos *Position, best bool) (Move, bool) {
+	move, err := pos.UCIToMove(string(fb))
+	return move, err == nil
+}
+
+func TestBookMove(t *testing.T) {
 	pos, _ := PositionFromFEN(FENStartPos)
+	eng := NewEngine(pos, nil, Options{OwnBook: true})
+	eng.Book = fixedBook("a2a3")
 
-	pos.Perft(4, false)
+	tc := NewFixedDepthTimeControl(pos, 2)
+	tc.Start(false)
+	if _, pv := eng.PlayMoves(tc, nil); len(pv) != 1 || pos.MoveToUCI(pv[0]) != "a2a3" {
+		t.Errorf("expected the book move a2a3, got %v", pv)
+	}
+
+	// the book move is not among the root moves, so the engine searches
+	e2e4, _ := pos.UCIToMove("e2e4")
+	tc = NewFixedDepthTimeControl(pos, 2)
+	tc.Start(false)
+	if _, pv := eng.PlayMoves(tc, []Move{e2e4}); len(pv) == 0 || pv[0] != e2e4 {
+		t.Errorf("expected the root move e2e4, got %v", pv)
+	}
+
+	eng.Options.OwnBook = false
+	tc = NewFixedDepthTimeControl(pos, 2)
+	tc.Start(false)
+	if _, pv := eng.PlayMoves(tc, nil); len(pv) == 0 || pos.MoveToUCI(pv[0]) == "a2a3" {
+		t.Errorf("expected a searched move without OwnBook, got %v", pv)
+	}
 }
 
-/*
-func TestGame(t *testing.T) {
+func TestHashSlot(t *testing.T) {
+	entry := hashEntry{lock: 0xdeadbeef, move: 0x123456789, score: -1234, static: 567, depth: -3, kind: exact | hasStatic}
+
+	var slot hashSlot
+	slot.store(entry)
+
+	if got, ok := slot.load(); !ok || got != entry {
+		t.Errorf("expected %v, got %v %v", entry, got, ok)
+	}
+
+	// a torn slot holds the data of one entry and the move of another
+	atomic.StoreUint64(&slot.move, 0x987654321)
+	if _, ok := slot.load(); ok {
+		t.Errorf("expected a torn slot to be rejected")
+	}
+}
+
+func TestThreads(t *testing.T) {
 	pos, _ := PositionFromFEN(FENStartPos)
-	eng := NewEngine(pos, nil, Options{})
-	for i := 0; i < 1; i++ {
-		tc := NewFixedDepthTimeControl(pos, 3)
+	eng := NewEngine(pos, nil, Options{Threads: 4})
+
+	for i := 0; i < 4; i++ {
+		tc := NewFixedDepthTimeControl(pos, 5)
 		tc.Start(false)
+
 		_, pv := eng.Play(tc)
+		if len(pv) == 0 {
+			t.Fatalf("expected a pv in %s", pos)
+		}
+		if !pos.IsPseudoLegal(pv[0]) {
+			t.Fatalf("illegal move %v in %s", pv[0], pos)
+		}
+		if len(eng.helpers) != 3 {
+			t.Fatalf("expected 3 helpers, got %d", len(eng.helpers))
+		}
+		if eng.Stats.Nodes <= eng.helpers[0].Stats.Nodes {
+			t.Errorf("expected the nodes of the helpers to be included in %d", eng.Stats.Nodes)
+		}
+
 		eng.DoMove(pv[0])
 	}
 }
 
+// TestConcurrentInit starts the first searches of several engines at once, run it with -race on more than one cpu
+func TestConcurrentInit(t *testing.T) {
+	initOnce = sync.Once{}
+
+	var wg sync.WaitGroup
+
+	// the searches start together once all engines are set up
+	start := make(chan struct{})
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			pos, _ := PositionFromFEN(FENStartPos)
+			eng := NewEngine(pos, nil, Options{Threads: 2})
+
+			tc := NewFixedDepthTimeControl(pos, 2)
+			tc.Start(false)
+
+			<-start
+
+			if _, pv := eng.Play(tc); len(pv) == 0 {
+				t.Errorf("expected a pv in %s", pos)
+			}
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+
+	if futilityFigureBonus[Queen] <= futilityFigureBonus[Pawn] {
+		t.Errorf("futility bonuses were not initialized: %v", futilityFigureBonus)
+	}
+}
+
+// readMateIn1 returns the records of the mate in one suite
+func readMateIn1(t *testing.T) []epd.Record {
+	f, err := os.Open("../epd/suites/matein1.epd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := epd.ReadEPD(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return records
+}
+
 func TestMateIn1(t *testing.T) {
-	for i, d := range MateIn1 {
-		pos, _ := PositionFromFEN(d.FEN)
-		bm, err := pos.UCIToMove(d.BM)
+	for _, r := range readMateIn1(t) {
+		pos, err := PositionFromFEN(r.Fen)
 		if err != nil {
-			t.Errorf("#%d cannot parse move %s", i, d.BM)
-			continue
+			t.Fatalf("%s: %v", r.Id(), err)
+		}
+
+		bm, err := pos.SanToMove(r.BestMoves()[0])
+		if err != nil {
+			t.Fatalf("%s: %v", r.Id(), err)
 		}
 
 		tc := NewFixedDepthTimeControl(pos, 2)
@@ -64,16 +492,203 @@ func TestMateIn1(t *testing.T) {
 		_, pv := eng.Play(tc)
 
 		if len(pv) != 1 {
-			t.Errorf("#%d Expected at most one move, got %d", i, len(pv))
-			t.Errorf("position is %v", pos)
+			t.Errorf("%s expected one move in %s, got %d", r.Id(), r.Fen, len(pv))
 			continue
 		}
 
 		if pv[0] != bm {
-			t.Errorf("#%d expected move %v, got %v", i, bm, pv[0])
-			t.Errorf("position is %v", pos)
-			continue
+			t.Errorf("%s expected move %s in %s, got %s", r.Id(), r.BestMoves()[0], r.Fen, pos.MoveToUCI(pv[0]))
+		}
+	}
+}
+
+func TestSearchMate(t *testing.T) {
+	// the mate in one positions have a unique solution
+	for _, r := range readMateIn1(t) {
+		pos, err := PositionFromFEN(r.Fen)
+		if err != nil {
+			t.Fatalf("%s: %v", r.Id(), err)
+		}
+		eng := NewEngine(pos, nil, Options{})
+
+		bm, err := pos.SanToMove(r.BestMoves()[0])
+		if err != nil {
+			t.Fatalf("%s: %v", r.Id(), err)
+		}
+
+		tc := NewTimeControl(pos, false)
+		tc.Start(false)
+		res := eng.SearchMate(tc, 1, nil)
+
+		if res.Moves != 1 || len(res.Solutions) != 1 || res.Solutions[0] != bm {
+			t.Errorf("%s expected the unique solution %s in %s, got %s", r.Id(), r.BestMoves()[0], r.Fen, res.Summary(pos, 1))
+		}
+	}
+
+	for _, d := range []struct {
+		variant   utils.VariantKey
+		fen       string
+		n         int32
+		moves     int32
+		pv        string
+		solutions int
+	}{
+		{utils.VARIANT_STANDARD, "r2qkb1r/pp2nppp/3p4/2pNN1B1/2BnP3/3P4/PPP2PPP/R2bK2R w KQkq - 1 1", 2, 2, "d5f6 g7f6 c4f7", 1},
+		{utils.VARIANT_STANDARD, "7k/8/5K2/8/8/8/8/R7 w - - 0 1", 2, 2, "f6g6 h8g8 a1a8", 2},
+		{utils.VARIANT_STANDARD, "6k1/5ppp/8/8/8/8/5PPP/3RR1K1 w - - 0 1", 1, 1, "d1d8", 2},
+		{utils.VARIANT_STANDARD, "8/8/8/3k4/8/8/8/3QK3 w - - 0 1", 3, 0, "", 0},
+		// the king explodes next to the captured pawn
+		{utils.VARIANT_ATOMIC, "k7/1p6/8/8/8/8/8/KQ6 w - - 0 1", 1, 1, "b1b7", 1},
+		// the jailed king cannot leave the check
+		{utils.VARIANT_EIGHTPIECE, "4k3/4J3/8/8/8/8/8/R3K3 w - - 0 1", 1, 1, "a1a8", 1},
+		{utils.VARIANT_EIGHTPIECE, "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", 1, 0, "", 0},
+	} {
+		pos, err := PositionFromFENAndVariant(d.fen, d.variant)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eng := NewEngine(pos, nil, Options{})
+
+		tc := NewTimeControl(pos, false)
+		tc.Mate = d.n
+		tc.Start(false)
+		score, pv := eng.PlayMoves(tc, nil)
+
+		line := []string{}
+		for _, m := range eng.LastMate.PV {
+			line = append(line, pos.MoveToUCI(m))
+			pos.DoMove(m)
+		}
+		for range line {
+			pos.UndoMove()
+		}
+
+		res := eng.LastMate
+		if res.Moves != d.moves || strings.Join(line, " ") != d.pv || len(res.Solutions) != d.solutions {
+			t.Errorf("%s: expected mate in %d %q with %d solutions, got %s %q", d.fen, d.moves, d.pv, d.solutions, res.Summary(pos, d.n), line)
 		}
+		if d.moves > 0 && score != MateScore-(2*d.moves-1) {
+			t.Errorf("%s: expected the score of a mate in %d, got %d", d.fen, d.moves, score)
+		}
+		if len(pv) == 0 {
+			t.Errorf("%s: expected a move to play", d.fen)
+		}
+	}
+}
+
+func BenchmarkPerft(b *testing.B) {
+	pos, _ := PositionFromFEN(FENStartPos)
+
+	pos.Perft(4, false)
+}
+
+// withRandomBonus runs f with RandomBonus set to on
+func withRandomBonus(on bool, f func()) {
+	defer func(old bool) { RandomBonus = old }(RandomBonus)
+	RandomBonus = on
+	f()
+}
+
+func TestEvaluate(t *testing.T) {
+	withRandomBonus(false, func() {
+		for _, fen := range []string{
+			FENStartPos,
+			"jlsesqk1nr/pppppppp/8/8/5b2/3P4/PPP1PPPP/JLneSQKBNR w KQkq - 0 1 -",
+			"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		} {
+			pos, err := PositionFromFEN(fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the evaluation is the weighted sum of the features plus the eightpiece extras
+			var expected Accum
+			for i, n := range Features(pos) {
+				expected.M += Weights[i].M * n
+				expected.E += Weights[i].E * n
+			}
+
+			extra := EvalExtra(pos)
+			expected.merge(extra.Accum[White])
+			expected.deduct(extra.Accum[Black])
+
+			e := Evaluate(pos)
+			if e.Accum[NoColor].M != expected.M || e.Accum[NoColor].E != expected.E {
+				t.Errorf("%s: evaluated %+v, expected %+v", fen, e.Accum[NoColor], expected)
+			}
+
+			// mid and end game scores are blended by the phase of the game
+			phase := Phase(pos)
+			if score := e.GetCentipawnsScore(); score != scaleToCentipawns((expected.M*(256-phase)+expected.E*phase)/256) {
+				t.Errorf("%s: score %d does not blend %+v at phase %d", fen, score, expected, phase)
+			}
+
+			// without the random bonus the evaluation is repeatable
+			if again := Evaluate(pos); again.Accum[NoColor].M != e.Accum[NoColor].M || again.Accum[NoColor].E != e.Accum[NoColor].E {
+				t.Errorf("%s: evaluated %+v and %+v without random bonus", fen, e.Accum[NoColor], again.Accum[NoColor])
+			}
+		}
+	})
+
+	pos, _ := PositionFromFEN(FENStartPos)
+
+	var deterministic Accum
+	withRandomBonus(false, func() { deterministic = Evaluate(pos).Accum[NoColor] })
+
+	withRandomBonus(true, func() {
+		seen := make(map[int32]bool)
+
+		for i := 0; i < 20; i++ {
+			e := Evaluate(pos).Accum[NoColor]
+			seen[e.M] = true
+
+			// the bonus of each side is below the native random bonus
+			if d := e.M - deterministic.M; d <= -RANDOM_BONUS_NATIVE_M || d >= RANDOM_BONUS_NATIVE_M {
+				t.Errorf("random bonus %d out of range", d)
+			}
+		}
+
+		if len(seen) < 2 {
+			t.Errorf("random bonus did not change the evaluation")
+		}
+	})
+}
+
+func TestEvaluateJailerAndSentry(t *testing.T) {
+	for _, d := range []struct {
+		fen      string
+		feature  featureType
+		expected int32
+	}{
+		// the black jailer jails the knight next to it
+		{"4k3/8/8/3j4/3N4/8/8/4K3 w - - 0 1 -", fJailedPieces, 1},
+		{"4k3/8/8/3j4/8/8/8/N3K3 w - - 0 1 -", fJailedPieces, 0},
+		{"4k3/8/8/8/8/8/8/3jK3 w - - 0 1 -", fKingJailed, 1},
+		// the white sentry eyes the black king area
+		{"4k3/8/8/8/S7/8/8/4K3 w - - 0 1 -", fKingSentryAttackers, 1},
+		{"4k3/8/8/8/8/8/8/S3K3 w - - 0 1 -", fKingSentryAttackers, 0},
+	} {
+		pos, err := PositionFromFEN(d.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Features are from White's point of view, black terms count negative
+		if got := Features(pos)[d.feature]; got != d.expected {
+			t.Errorf("%s: feature %d is %d, expected %d", d.fen, d.feature, got, d.expected)
+		}
+	}
+}
+
+/*
+func TestGame(t *testing.T) {
+	pos, _ := PositionFromFEN(FENStartPos)
+	eng := NewEngine(pos, nil, Options{})
+	for i := 0; i < 1; i++ {
+		tc := NewFixedDepthTimeControl(pos, 3)
+		tc.Start(false)
+		_, pv := eng.Play(tc)
+		eng.DoMove(pv[0])
 	}
 }
 
@@ -72,7 +72,6 @@ func (tc *TimeControl) Start(ponder bool) {
 
 func (tc *TimeControl) updateDeadlines() {
 	now := time.Now()
-	tc.searchDeadline = now.Add(tc.searchTime / time.Duration(tc.branch/16))
 
 	// stopDeadline is when to abort the search in case of an explosion
 	// we give a large overhead here so the search is not aborted very often
@@ -80,14 +79,26 @@ func (tc *TimeControl) updateDeadlines() {
 	if deadline > tc.limit {
 		deadline = tc.limit
 	}
+
+	tc.deadlineLock.Lock()
+	tc.searchDeadline = now.Add(tc.searchTime / time.Duration(tc.branch/16))
 	tc.stopDeadline = now.Add(deadline)
+	tc.deadlineLock.Unlock()
+}
+
+// deadlines returns the search and stop deadlines
+func (tc *TimeControl) deadlines() (time.Time, time.Time) {
+	tc.deadlineLock.Lock()
+	defer tc.deadlineLock.Unlock()
+	return tc.searchDeadline, tc.stopDeadline
 }
 
 // NextDepth returns true if search can start at depth
 // in any case Stopped() will return false
 func (tc *TimeControl) NextDepth(depth int32) bool {
 	tc.currDepth = depth
-	return tc.currDepth <= tc.Depth && !tc.hasStopped(tc.searchDeadline)
+	searchDeadline, _ := tc.deadlines()
+	return tc.currDepth <= tc.Depth && !tc.hasStopped(searchDeadline)
 }
 
 // PonderHit switch to our time control
@@ -96,11 +107,21 @@ func (tc *TimeControl) PonderHit() {
 	tc.ponderhit.set()
 }
 
+// Pondering returns true if the search was started in ponder mode and PonderHit was not called yet
+func (tc *TimeControl) Pondering() bool {
+	return !tc.ponderhit.get()
+}
+
 // Stop marks the search as stopped
 func (tc *TimeControl) Stop() {
 	tc.stopped.set()
 }
 
+// StopRequested returns true if Stop was called or a search limit was reached, regardless of the searched depth
+func (tc *TimeControl) StopRequested() bool {
+	return tc.stopped.get()
+}
+
 func (tc *TimeControl) hasStopped(deadline time.Time) bool {
 	if tc.currDepth <= 2 {
 		// run for at few depths at least otherwise mates can be missed
@@ -117,10 +138,19 @@ func (tc *TimeControl) hasStopped(deadline time.Time) bool {
 	return false
 }
 
+// NodesExceeded returns true and stops the search if more than the allowed number of nodes were searched
+func (tc *TimeControl) NodesExceeded(nodes uint64) bool {
+	if tc.Nodes == 0 || nodes < tc.Nodes || tc.currDepth <= 2 {
+		return false
+	}
+	tc.stopped.set()
+	return true
+}
+
 // Stopped returns true if the search has stopped because
 // Stop() was called or the time has ran out
 func (tc *TimeControl) Stopped() bool {
-	if !tc.hasStopped(tc.stopDeadline) {
+	if _, stopDeadline := tc.deadlines(); !tc.hasStopped(stopDeadline) {
 		return false
 	}
 	// time has ran out so flip the stopped flag
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	. "github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/tablebase"
 )
 
 /////////////////////////////////////////////////////////////////////
@@ -36,8 +37,8 @@ type Score struct {
 
 // Accum accumulates scores
 type Accum struct {
-	M, E int32 // mid game, end game
-	//Values []int8 // input values
+	M, E   int32   // mid game, end game
+	Values []int32 // input values, only collected if not nil
 }
 
 // Options keeps engine's options
@@ -45,6 +46,9 @@ type Options struct {
 	AnalyseMode   bool // true to display info strings
 	MultiPV       int  // number of principal variation lines to compute
 	HandicapLevel int
+	OwnBook       bool // true to play moves from Book before searching
+	BookBestMove  bool // true to play the book move of highest weight instead of a weighted random one
+	Threads       int  // number of search threads, one main thread and Threads-1 helpers
 }
 
 // Stats stores statistics about the search
@@ -76,6 +80,28 @@ func (nl *NulLogger) EndSearch()                                               {
 func (nl *NulLogger) PrintPV(stats Stats, multiPV int, score int32, pv []Move) {}
 func (nl *NulLogger) CurrMove(depth int, move Move, num int)                   {}
 
+// Book is an opening book, it finds the moves of a position by its Polyglot key
+type Book interface {
+	// Probe returns a book move for pos, the one with the highest weight if best is true
+	Probe(pos *Position, best bool) (Move, bool)
+}
+
+// MateResult is the result of a mate search
+type MateResult struct {
+	Moves     int32  // the side to move mates in Moves moves, 0 if no mate was found
+	PV        []Move // the mating line, the defender playing the longest defense
+	Solutions []Move // root moves mating in at most the searched number of moves, nil if not verified
+}
+
+// mateSearch proves mates with a depth limited and-or search, the attacker is the side to move at the root
+type mateSearch struct {
+	eng     *Engine
+	tc      *TimeControl
+	proven  map[uint64]int32 // attacker positions mating in exactly this many moves
+	failed  map[uint64]int32 // attacker positions not mating in this many moves or less
+	stopped bool             // the time control stopped the search, failures are not proven
+}
+
 // historyEntry keeps counts of how well move performed in the past
 type historyEntry struct {
 	stat int32
@@ -157,6 +183,8 @@ type TimeControl struct {
 	BTime, BInc time.Duration // time and increment for black
 	Depth       int32         // maximum depth search (including)
 	MovesToGo   int32         // number of remaining moves, defaults to defaultMovesToGo
+	Nodes       uint64        // maximum number of nodes to search, 0 for no limit
+	Mate        int32         // search only a mate in at most Mate moves, 0 for a normal search
 
 	sideToMove Color
 	time, inc  time.Duration // time and increment for us
@@ -169,16 +197,20 @@ type TimeControl struct {
 	ponderhit atomicFlag // true if ponder was successful
 
 	searchTime     time.Duration // alocated time for this move
+	deadlineLock   sync.Mutex    // guards the deadlines, ponderhit updates them while searching
 	searchDeadline time.Time     // don't go to the next depth after this deadline
 	stopDeadline   time.Time     // abort search after this deadline
 }
 
 // Engine implements the logic to search for the best move for a position
 type Engine struct {
-	Options  Options   // engine options
-	Log      Logger    // logger
-	Stats    Stats     // search statistics
-	Position *Position // current Position
+	Options   Options              // engine options
+	Log       Logger               // logger
+	Stats     Stats                // search statistics
+	Position  *Position            // current Position
+	Book      Book                 // opening book consulted if Options.OwnBook is set
+	Tablebase *tablebase.Tablebase // endgame tables probed at the root and in the search, nil for none
+	LastMate  MateResult           // result of the last search with TimeControl.Mate set
 
 	rootPly         int           // position's ply at the start of the search
 	stack           stack         // stack of moves
@@ -188,6 +220,11 @@ type Engine struct {
 	history         *historyTable // keeps history of moves
 	ignoreRootMoves []Move        // moves to ignore at root
 	onlyRootMoves   []Move        // search only these root moves
+	tablebasePath   string        // directory of the loaded tablebase
+	pawns           *pawnsTable   // pawn structure cache of this search thread
+	helpers         []*Engine     // helper threads of lazy smp, searching the same position
+	abort           *atomicFlag   // set by the main thread to stop its helpers, nil for the main thread
+	reportedNodes   uint64        // nodes searched so far, read by the main thread while helpers search
 
 	timeControl *TimeControl
 	stopped     bool   // true if timeControl stopped the clock
@@ -206,12 +243,20 @@ type hashEntry struct {
 	kind   hashFlags // type of hash
 }
 
+// hashSlot stores a packed hashEntry so that it can be shared by search threads without locks
+// check is the lock xor-ed with the other two words, a slot torn by concurrent writes fails the check
+type hashSlot struct {
+	move  uint64 // best move
+	data  uint64 // score, static, depth and kind
+	check uint64 // lock ^ move ^ data
+}
+
 // HashTable is a transposition table
 // Engine uses this table to cache position scores so
 // it doesn't have to research them again
 type HashTable struct {
-	table []hashEntry // len(table) is a power of two and equals mask+1
-	mask  uint32      // mask is used to determine the index in the table
+	table []hashSlot // len(table) is a power of two and equals mask+1
+	mask  uint32     // mask is used to determine the index in the table
 }
 
 // Eval contains necessary information for evaluation
@@ -0,0 +1,38 @@
+package bengine
+
+/////////////////////////////////////////////////////////////////////
+// weights
+
+// Weights stores the network parameters
+// network has train error 0.05679009 and validation error 0.05702872
+var Weights = []Score{
+	{M: -2, E: 0}, {M: 14364, E: 13854}, {M: 64220, E: 49415}, {M: 66592, E: 52025}, {M: 84852, E: 98328}, {M: 222394, E: 169927}, {M: -160, E: 130}, {M: 1199, E: 2146},
+	{M: -6958, E: 77}, {M: -9864, E: 2079}, {M: -2773, E: 114}, {M: -1505, E: 100}, {M: -4315, E: -2085}, {M: -708, E: -88}, {M: 7, E: 1901}, {M: 1161, E: 3481},
+	{M: 1977, E: 2787}, {M: 2058, E: 1354}, {M: 776, E: -44}, {M: -1118, E: -2678}, {M: -534, E: -2756}, {M: 10, E: 25}, {M: 2200, E: 1697}, {M: 4187, E: 3431},
+	{M: 5955, E: 3342}, {M: 4977, E: 1044}, {M: -157, E: -390}, {M: -13491, E: -1993}, {M: 1590, E: 424}, {M: -3039, E: -94}, {M: 991, E: -43}, {M: 1121, E: 222},
+	{M: -726, E: 1148}, {M: 0, E: 1220}, {M: 310, E: 698}, {M: 3442, E: -908}, {M: -1059, E: 13}, {M: -262, E: -338}, {M: 1134, E: -76}, {M: 2662, E: 745},
+	{M: 645, E: 767}, {M: 1004, E: 374}, {M: -66, E: -84}, {M: -7848, E: 1101}, {M: -6984, E: -177}, {M: 1581, E: 667}, {M: -978, E: 386}, {M: -231, E: 594},
+	{M: 326, E: 867}, {M: 2005, E: 19}, {M: 2387, E: -796}, {M: 3694, E: -1037}, {M: -969, E: 199}, {M: -6, E: -1217}, {M: 2252, E: -2485}, {M: -550, E: -1226},
+	{M: 48, E: -1192}, {M: -572, E: 63}, {M: 52, E: 407}, {M: 101, E: 600}, {M: 1303, E: 1319}, {M: -787, E: 1963}, {M: 1339, E: 470}, {M: 8194, E: -1609},
+	{M: 1878, E: 2066}, {M: 418, E: 1069}, {M: -555, E: -63}, {M: -709, E: -537}, {M: -258, E: -208}, {M: 5, E: 87}, {M: 539, E: -13}, {M: 2403, E: 67},
+	{M: 2004, E: 1769}, {M: 13247, E: -6786}, {M: 9471, E: -6878}, {M: 5311, E: -2926}, {M: 414, E: 152}, {M: -3468, E: 2329}, {M: -601, E: -98}, {M: -6549, E: 3325},
+	{M: 36, E: 921}, {M: 529, E: 1485}, {M: -2851, E: -2370}, {M: 2150, E: 4431}, {M: 5406, E: 8787}, {M: -3138, E: 783}, {M: -1358, E: 7}, {M: 16986, E: -4302},
+	{M: 29780, E: 85}, {M: -86, E: -20}, {M: 71, E: -40}, {M: 1, E: -14}, {M: -76, E: 16}, {M: -126, E: 19}, {M: 38, E: 39}, {M: -59, E: 140},
+	{M: 66, E: 107}, {M: -2370, E: 15}, {M: -2166, E: -644}, {M: -1993, E: 537}, {M: -1301, E: -139}, {M: -2122, E: 1284}, {M: 3850, E: -495}, {M: 2909, E: -1932},
+	{M: -1674, E: -2480}, {M: -2015, E: -1135}, {M: -4037, E: -451}, {M: -893, E: -1554}, {M: -2342, E: -239}, {M: -923, E: -112}, {M: -451, E: -425}, {M: 1343, E: -2536},
+	{M: -1497, E: -1896}, {M: -1661, E: 1581}, {M: -3958, E: 841}, {M: 30, E: -982}, {M: 675, E: -1662}, {M: 284, E: -711}, {M: 262, E: -1286}, {M: -2817, E: -301},
+	{M: -2886, E: 2}, {M: 98, E: 2937}, {M: -9, E: 1467}, {M: 103, E: 414}, {M: 1561, E: -187}, {M: 998, E: 319}, {M: 90, E: 506}, {M: -10, E: 1856},
+	{M: -1247, E: 2380}, {M: 4373, E: 5274}, {M: 954, E: 5331}, {M: 3116, E: 3809}, {M: 1007, E: 1454}, {M: 7494, E: 205}, {M: 12094, E: 927}, {M: 2921, E: 4545},
+	{M: 2135, E: 5350}, {M: 123, E: 253}, {M: 126, E: 108}, {M: -97, E: -99}, {M: 44, E: -1229}, {M: 27, E: 1017}, {M: -13, E: -838}, {M: -206, E: -5},
+	{M: -115, E: 1456}, {M: 226, E: -82}, {M: 38, E: 94}, {M: -124, E: -36}, {M: 157, E: -82}, {M: 87, E: -97}, {M: -69, E: 123}, {M: -105, E: 159},
+	{M: 172, E: 40}, {M: -2908, E: -2023}, {M: 2162, E: 958}, {M: -1525, E: 45}, {M: -1515, E: -1395}, {M: 2855, E: 757}, {M: -1183, E: -4363}, {M: 3153, E: -1831},
+	{M: 566, E: 293}, {M: -9013, E: 2241}, {M: -520, E: 1088}, {M: -4066, E: 2623}, {M: 3714, E: -20}, {M: 1372, E: -3061}, {M: -3598, E: -2663}, {M: -5, E: -938},
+	{M: 11, E: 62}, {M: -3350, E: -10}, {M: -33, E: 352}, {M: 6186, E: -18}, {M: 22, E: 94}, {M: -37, E: -2029}, {M: -775, E: 596}, {M: 565, E: -568},
+	{M: 3402, E: -1948}, {M: 2374, E: -66}, {M: -123, E: 142}, {M: 202, E: 2363}, {M: 260, E: 2843}, {M: -688, E: 6686}, {M: 49, E: 3610}, {M: 47, E: 17042},
+	{M: 9455, E: 33952}, {M: -24, E: -176}, {M: 0, E: 33}, {M: -1108, E: 10140}, {M: -7291, E: 5046}, {M: -2197, E: -4209}, {M: 136, E: -12279}, {M: 3, E: -13911},
+	{M: -28, E: -14428}, {M: -50, E: -12908}, {M: 147, E: -85}, {M: 4548, E: 12736}, {M: -43, E: 7638}, {M: 151, E: 1756}, {M: -2061, E: 273}, {M: -285, E: -218},
+	{M: 1997, E: -64}, {M: 226, E: 60}, {M: 113000, E: 131000}, {M: 70000, E: 58000}, {M: 79000, E: 92000}, {M: 0, E: 0}, {M: 0, E: 0}, {M: 1000, E: 800},
+	{M: -4000, E: -4000}, {M: -10000, E: -5000}, {M: 2000, E: 0},
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -22,6 +22,10 @@ func (b *Board) IS_ATOMIC() bool {
 	return b.Variant == utils.VARIANT_ATOMIC
 }
 
+func (b *Board) IS_SEIRAWAN() bool {
+	return b.Variant == utils.VARIANT_SEIRAWAN
+}
+
 func (b *Board) IS_EIGHTPIECE() bool {
 	return b.Variant == utils.VARIANT_EIGHTPIECE
 }
@@ -38,6 +42,8 @@ func (b *Board) IsSquareEmpty(sq utils.Square) bool {
 
 func (b *Board) SetPieceAtSquare(sq utils.Square, p utils.Piece) bool {
 	if b.HasSquare(sq) {
+		b.Pos.Key ^= pieceZobrist(sq, b.Pos.Rep[sq.Rank][sq.File]) ^ pieceZobrist(sq, p)
+
 		b.Pos.Rep[sq.Rank][sq.File] = p
 
 		return true
@@ -64,7 +70,7 @@ func (b *Board) AdjacentSquares(sq utils.Square) []utils.Square {
 	for df = -1; df <= 1; df++ {
 		for dr = -1; dr <= 1; dr++ {
 			if (df != 0) || (dr != 0) {
-				testsq := sq.Add(utils.PieceDirection{df, dr})
+				testsq := sq.Add(utils.PieceDirection{File: df, Rank: dr})
 				if b.HasSquare(testsq) {
 					asqs = append(asqs, testsq)
 				}
@@ -97,7 +103,7 @@ func (b *Board) RookAdjacentSquares(sq utils.Square) []utils.Square {
 	for df = -1; df <= 1; df++ {
 		for dr = -1; dr <= 1; dr++ {
 			if (df*df + dr*dr) == 1 {
-				testsq := sq.Add(utils.PieceDirection{df, dr})
+				testsq := sq.Add(utils.PieceDirection{File: df, Rank: dr})
 				if b.HasSquare(testsq) {
 					rasqs = append(rasqs, testsq)
 				}
@@ -115,7 +121,7 @@ func (b *Board) WhereIsKing(color utils.PieceColor) utils.Square {
 		for file = 0; file < b.NumFiles; file++ {
 			p := b.Pos.Rep[rank][file]
 			if (p.Kind == utils.King) && (p.Color == color) {
-				return utils.Square{file, rank}
+				return utils.Square{File: file, Rank: rank}
 			}
 		}
 	}
@@ -176,6 +182,30 @@ func (b *Board) SquaresInDirection(origSq utils.Square, dir utils.PieceDirection
 	return sqs
 }
 
+// RankSquaresBetween returns the squares on the rank of fromSq from fromSq to toSq, both included
+func (b *Board) RankSquaresBetween(fromSq utils.Square, toSq utils.Square) []utils.Square {
+	sqs := []utils.Square{fromSq}
+
+	if toSq.File == fromSq.File {
+		return sqs
+	}
+
+	var dir int8 = 1
+	if toSq.File < fromSq.File {
+		dir = -1
+	}
+
+	for _, sq := range b.SquaresInDirection(fromSq, utils.PieceDirection{File: dir, Rank: 0}) {
+		sqs = append(sqs, sq)
+
+		if sq.File == toSq.File {
+			break
+		}
+	}
+
+	return sqs
+}
+
 func (b *Board) SquaresForPiece(p utils.Piece) []utils.Square {
 	sqs := []utils.Square{}
 
@@ -183,7 +213,7 @@ func (b *Board) SquaresForPiece(p utils.Piece) []utils.Square {
 	var file int8
 	for rank = 0; rank < b.NumRanks; rank++ {
 		for file = 0; file < b.NumFiles; file++ {
-			sq := utils.Square{file, rank}
+			sq := utils.Square{File: file, Rank: rank}
 			testp := b.PieceAtSquare(sq)
 			if testp.EqualTo(p) {
 				sqs = append(sqs, sq)
@@ -201,7 +231,7 @@ func (b *Board) SquaresForPieceKind(pk utils.PieceKind, color utils.PieceColor)
 	var file int8
 	for rank = 0; rank < b.NumRanks; rank++ {
 		for file = 0; file < b.NumFiles; file++ {
-			sq := utils.Square{file, rank}
+			sq := utils.Square{File: file, Rank: rank}
 			testp := b.PieceAtSquare(sq)
 			if (testp.Kind == pk) && (testp.Color == color) {
 				sqs = append(sqs, sq)
@@ -280,25 +310,25 @@ func (b *Board) CastlingRank(color utils.PieceColor) int8 {
 func (b *Board) RookCastlingTargetSq(color utils.PieceColor, side utils.CastlingSide) utils.Square {
 	rank := b.CastlingRank(color)
 
-	var file int8 = 2
+	var file int8 = 3
 
 	if side == utils.KING_SIDE {
 		file = 5
 	}
 
-	return utils.Square{file, rank}
+	return utils.Square{File: file, Rank: rank}
 }
 
 func (b *Board) KingCastlingTargetSq(color utils.PieceColor, side utils.CastlingSide) utils.Square {
 	rank := b.CastlingRank(color)
 
-	var file int8 = 3
+	var file int8 = 2
 
 	if side == utils.KING_SIDE {
 		file = 6
 	}
 
-	return utils.Square{file, rank}
+	return utils.Square{File: file, Rank: rank}
 }
 
 /////////////////////////////////////////////////////////////////////
@@ -310,7 +340,7 @@ func (b *Board) MovesSortedBySan(moves []utils.Move) utils.MoveBuff {
 		san := b.MoveToSan(move)
 		algeb := b.MoveToAlgeb(move)
 
-		mb = append(mb, utils.MoveBuffItem{move, san, algeb})
+		mb = append(mb, utils.MoveBuffItem{Move: move, San: san, Algeb: algeb})
 	}
 
 	sort.Sort(utils.MoveBuff(mb))
@@ -0,0 +1,479 @@
+package board
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+// https://www.chessprogramming.org/Chess960_Perft_Results
+var CHESS960_PERFT_SUITE = []struct {
+	FEN   string
+	Nodes []int
+}{
+	{"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9", []int{21, 528, 12189}},
+	{"2nnrbkr/p1qppppp/8/1ppb4/6PP/3PP3/PPP2P2/BQNNRBKR w HEhe - 1 9", []int{21, 807, 18002}},
+	{"b1q1rrkb/pppppppp/3nn3/8/P7/1PPP4/4PPPP/BQNNRKRB w GE - 1 9", []int{20, 479, 10471}},
+	{"qbbnnrkr/2pp2pp/p7/1p2pp2/8/P3PP2/1PPP1KPP/QBBNNR1R w hf - 0 9", []int{22, 593, 13440}},
+	{"1nbbnrkr/p1p1ppp1/3p4/1p3P1p/3Pq2P/8/PPP1P1P1/QNBBNRKR w HFhf - 0 9", []int{28, 1120, 31058}},
+	{"qnbnr1kr/ppp1b1pp/4p3/3p1p2/8/2NPP3/PPP1BPPP/QNB1R1KR w HEhe - 1 9", []int{29, 899, 26578}},
+}
+
+func newChess960Board(fen string) *Board {
+	b := &Board{}
+
+	b.Init(utils.VARIANT_STANDARD)
+
+	b.Chess960 = true
+
+	b.SetFromFen(fen)
+
+	return b
+}
+
+func TestChess960Perft(t *testing.T) {
+	for _, d := range CHESS960_PERFT_SUITE {
+		b := newChess960Board(d.FEN)
+
+		for depth, expected := range d.Nodes {
+			if nodes := b.Perft(depth + 1); nodes != expected {
+				t.Errorf("%s perft %d returned nodes %d, expected %d", d.FEN, depth+1, nodes, expected)
+			}
+		}
+
+		if fen := b.ReportFen(); fen != d.FEN {
+			t.Errorf("fen %s reported as %s", d.FEN, fen)
+		}
+	}
+}
+
+// mid-game double fischer random positions, white and black castle with different files
+var DOUBLE_FISCHER_RANDOM_FENS = []string{
+	"qnbnr1kr/ppp1b1pp/4p3/3p1p2/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhe - 0 9",
+	"b1q1rrkb/pppppppp/3nn3/8/6PP/3PP3/PPP2P2/BQNNRBKR w HEf - 0 9",
+	// the king castles short onto the square next to the rook, castling is possible at once for both sides
+	"1r2k1r1/pppppppp/8/8/8/8/PPPPPPPP/R3KR2 w FAgb - 0 1",
+	"rk4r1/pp1ppppp/8/2p5/8/5N2/PPPPPPPP/1R3KR1 w GBga - 0 5",
+}
+
+func TestDoubleFischerRandomPerft(t *testing.T) {
+	for _, fen := range DOUBLE_FISCHER_RANDOM_FENS {
+		b := newChess960Board(fen)
+
+		pos, err := butils.PositionFromFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pos.Chess960 = true
+
+		if reported := pos.String(); reported != fen {
+			t.Errorf("fen %s reported as %s by the bitboard backend", fen, reported)
+		}
+
+		// bitboard perft counts all nodes up to depth
+		cumulated := 0
+
+		for depth := 1; depth <= 4; depth++ {
+			bitboardNodes := pos.Perft(depth, false)
+
+			if nodes := b.Perft(depth); nodes != bitboardNodes-cumulated {
+				t.Errorf("%s perft %d returned nodes %d, bitboard backend %d", fen, depth, nodes, bitboardNodes-cumulated)
+			}
+
+			cumulated = bitboardNodes
+		}
+
+		if reported := b.ReportFen(); reported != fen {
+			t.Errorf("fen %s reported as %s", fen, reported)
+		}
+	}
+
+	// both backends castle king takes rook, also with the rook next to the king
+	fen := DOUBLE_FISCHER_RANDOM_FENS[2]
+
+	pos, _ := butils.PositionFromFEN(fen)
+	pos.Chess960 = true
+
+	b := newChess960Board(fen)
+
+	for _, algeb := range []string{"e1f1", "e1a1"} {
+		if move := b.AlgebToMove(algeb); !move.Castling {
+			t.Errorf("%s should be castling", algeb)
+		}
+
+		if move, err := pos.UCIToMove(algeb); err != nil || move.MoveType() != butils.Castling {
+			t.Errorf("%s should be castling for the bitboard backend, got %v", algeb, err)
+		}
+	}
+}
+
+func TestChess960CastlingNotation(t *testing.T) {
+	b := newChess960Board("qnbnr1kr/ppp1b1pp/4p3/3p1p2/8/2NPP3/PPP1BPPP/QNB1R1KR w HEhe - 1 9")
+
+	move := b.AlgebToMove("g1h1")
+
+	if !move.Castling {
+		t.Fatalf("g1h1 should be castling")
+	}
+
+	b.Push(move, ADD_SAN)
+
+	if fen := b.ReportFen(); fen != "qnbnr1kr/ppp1b1pp/4p3/3p1p2/8/2NPP3/PPP1BPPP/QNB1RRK1 b he - 2 9" {
+		t.Errorf("castling g1h1 resulted in %s", fen)
+	}
+}
+
+func TestSanToMove(t *testing.T) {
+	b := &Board{}
+
+	b.Init(utils.VARIANT_STANDARD)
+
+	b.SetFromFen("r3k2r/1P3ppp/8/3pP3/8/5N2/8/R3K2R w KQkq d6 0 1")
+
+	for san, algeb := range map[string]string{
+		"exd6 e.p.": "e5d6",
+		"0-0":       "e1g1",
+		"O-O-O+":    "e1c1",
+		"b8=Q":      "b7b8q",
+		"b8Q":       "b7b8q",
+		"bxa8n":     "b7a8n",
+		"Nf3d4":     "f3d4",
+		"Rad1":      "a1d1",
+		"Kf1!?":     "e1f1",
+		"f3d4":      "f3d4",
+		"e1d1":      "e1d1",
+		"b7b8r":     "b7b8r",
+		"e5d6":      "e5d6",
+	} {
+		move, err := b.SanToMove(san)
+		if err != nil {
+			t.Errorf("%s: %v", san, err)
+		} else if b.MoveToAlgeb(move) != algeb {
+			t.Errorf("%s parsed as %s, expected %s", san, b.MoveToAlgeb(move), algeb)
+		}
+	}
+
+	if _, err := b.SanToMove("b8"); !errors.Is(err, utils.ErrAmbiguousMove) {
+		t.Errorf("b8 should be ambiguous, got %v", err)
+	}
+
+	if _, err := b.SanToMove("Ke3"); !errors.Is(err, utils.ErrIllegalMove) {
+		t.Errorf("Ke3 should be illegal, got %v", err)
+	}
+
+	// long algebraic without piece letter still needs the promotion piece
+	if _, err := b.SanToMove("b7b8"); !errors.Is(err, utils.ErrAmbiguousMove) {
+		t.Errorf("b7b8 should be ambiguous, got %v", err)
+	}
+
+	if _, err := b.SanToMove("f3f4"); !errors.Is(err, utils.ErrIllegalMove) {
+		t.Errorf("f3f4 should be illegal, got %v", err)
+	}
+}
+
+func TestZobrist(t *testing.T) {
+	b := &Board{}
+	b.Init(utils.VARIANT_EIGHTPIECE)
+	b.Reset()
+
+	start := b.Zobrist()
+
+	// transpositions reach the same key, move clocks are not hashed
+	b.MakeAlgebMove("g1f3", !ADD_SAN)
+	b.MakeAlgebMove("g8f6", !ADD_SAN)
+	b.MakeAlgebMove("f3g1", !ADD_SAN)
+	b.MakeAlgebMove("f6g8", !ADD_SAN)
+
+	if key := b.Zobrist(); key != start {
+		t.Errorf("knight moves back and forth changed key %x to %x", start, key)
+	}
+
+	for i := 0; i < 4; i++ {
+		b.Pop()
+	}
+
+	b.MakeAlgebMove("e2e4", !ADD_SAN)
+
+	if key := b.Zobrist(); key == start {
+		t.Errorf("e2e4 did not change key")
+	}
+
+	b.Pop()
+
+	if key := b.Zobrist(); key != start {
+		t.Errorf("pop gave key %x, expected %x", key, start)
+	}
+}
+
+// checkZobrist compares the incremental key to the key computed from scratch in all positions up to depth
+func checkZobrist(t *testing.T, b *Board, depth int) {
+	if key := b.ComputeZobrist(); b.Zobrist() != key {
+		t.Fatalf("%s: incremental key %x, expected %x", b.ReportFen(), b.Zobrist(), key)
+	}
+
+	if depth == 0 {
+		return
+	}
+
+	for _, move := range b.LegalMovesForAllPieces() {
+		b.Push(move, !ADD_SAN)
+		checkZobrist(t, b, depth-1)
+		b.Pop()
+	}
+}
+
+func TestIncrementalZobrist(t *testing.T) {
+	for variant, fens := range map[utils.VariantKey][]string{
+		utils.VARIANT_STANDARD:   {"r3k2r/1P3ppp/8/3pP3/8/5N2/8/R3K2R w KQkq d6 0 1"},
+		utils.VARIANT_ATOMIC:     {"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"},
+		utils.VARIANT_SEIRAWAN:   {SEIRAWAN_CASTLING_FEN},
+		utils.VARIANT_EIGHTPIECE: {utils.StartFenForVariant(utils.VARIANT_EIGHTPIECE)},
+	} {
+		for _, fen := range fens {
+			b := &Board{}
+			b.Init(variant)
+			b.SetFromFen(fen)
+
+			checkZobrist(t, b, 3)
+		}
+	}
+}
+
+func TestTranspositionTableSearch(t *testing.T) {
+	b := &Board{}
+	b.Init(utils.VARIANT_STANDARD)
+	b.SetFromFen("6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - 0 1")
+	b.LogFunc = func(string) {}
+	b.LogAnalysisInfoFunc = func(string) {}
+
+	b.StartPerf()
+
+	move, score := b.Go(3)
+
+	if algeb := b.MoveToAlgeb(move); algeb != "a1a8" || score != MATE_SCORE-1 {
+		t.Errorf("expected mate in one a1a8, got %s score %d", algeb, score)
+	}
+
+	entry, ok := b.TranspositionTable.Probe(b.Zobrist())
+
+	if !ok || entry.Kind != TT_EXACT || entry.Move != move || entry.Depth != 3 {
+		t.Errorf("wrong root entry %+v %v", entry, ok)
+	}
+}
+
+func newSearchBoard(variant utils.VariantKey, fen string) *Board {
+	b := &Board{}
+	b.Init(variant)
+	b.SetFromFen(fen)
+	b.LogFunc = func(string) {}
+	b.LogAnalysisInfoFunc = func(string) {}
+
+	return b
+}
+
+func TestQuiescence(t *testing.T) {
+	// d5 is defended, taking it loses the queen beyond the horizon of a depth 1 search
+	b := newSearchBoard(utils.VARIANT_STANDARD, "4k3/8/2p5/3p4/8/8/8/3QK3 w - - 0 1")
+
+	b.StartPerf()
+
+	if move, _ := b.Go(1); b.MoveToAlgeb(move) == "d1d5" {
+		t.Errorf("depth 1 search took a defended pawn with the queen")
+	}
+
+	// atomic captures are searched to the end, exploding the king wins
+	b = newSearchBoard(utils.VARIANT_ATOMIC, "4k3/3p4/8/8/8/8/8/3RK3 w - - 0 1")
+
+	b.StartPerf()
+
+	if move, score := b.Go(1); b.MoveToAlgeb(move) != "d1d7" || score < MATE_SCORE/2 {
+		t.Errorf("expected the explosion d1d7, got %s score %d", b.MoveToAlgeb(move), score)
+	}
+}
+
+func TestMoveOrdering(t *testing.T) {
+	b := newSearchBoard(utils.VARIANT_STANDARD, "4k3/8/3q4/2p5/4N3/8/8/4K3 w - - 0 1")
+
+	moves := []utils.Move{}
+	for _, algeb := range []string{"e1e2", "e4c5", "e4d6", "e1f1", "e1f2"} {
+		moves = append(moves, b.AlgebToMove(algeb))
+	}
+
+	b.Killers[0][0] = b.AlgebToMove("e1f1")
+
+	order := []string{}
+	for _, mebi := range b.CreateMoveEvalBuff(moves, b.AlgebToMove("e1f2"), 0) {
+		order = append(order, b.MoveToAlgeb(mebi.Move))
+	}
+
+	// hash move, queen capture, pawn capture, killer, other quiet moves
+	if strings.Join(order, " ") != "e1f2 e4d6 e4c5 e1f1 e1e2" {
+		t.Errorf("unexpected order %v", order)
+	}
+
+	// the knight takes the pawn, explodes the queen and itself
+	b = newSearchBoard(utils.VARIANT_ATOMIC, "4k3/8/2q5/3p4/5N2/8/8/4K3 w - - 0 1")
+
+	if value := b.CaptureValue(b.AlgebToMove("f4d5")); value != PIECE_VALUES[utils.Pawn]+PIECE_VALUES[utils.Queen]-PIECE_VALUES[utils.Knight] {
+		t.Errorf("f4d5 has capture value %d", value)
+	}
+}
+
+const SEIRAWAN_CASTLING_FEN = "r3k2r/pppppppp/8/8/8/8/PPPPPPPP/R3K2R[EHeh] w KQBCDFGkqbcdfg - 0 1"
+
+func newSeirawanBoard(fen string) *Board {
+	b := &Board{}
+	b.Init(utils.VARIANT_SEIRAWAN)
+	b.SetFromFen(fen)
+
+	return b
+}
+
+func TestSeirawanFen(t *testing.T) {
+	for _, fen := range []string{
+		utils.StartFenForVariant(utils.VARIANT_SEIRAWAN),
+		SEIRAWAN_CASTLING_FEN,
+		// the a file can still gate after castling short, the reserve is partly used
+		"r3k2r/pppppppp/8/8/8/8/PPPPPPPP/R3HRK1[Eeh] b ABCDFGkqbcdfg - 1 1",
+		// no gating left
+		"r3k2r/pppppppp/8/8/8/8/PPPPPPPP/R3HRK1[] b - - 1 1",
+	} {
+		if reported := newSeirawanBoard(fen).ReportFen(); reported != fen {
+			t.Errorf("fen %s reported as %s", fen, reported)
+		}
+	}
+}
+
+func TestSeirawanGating(t *testing.T) {
+	b := newSeirawanBoard(utils.StartFenForVariant(utils.VARIANT_SEIRAWAN))
+
+	// 20 moves and the 4 knight moves off the back rank with a hawk or an elephant
+	if nodes := b.Perft(1); nodes != 28 {
+		t.Errorf("start position has %d moves, expected 28", nodes)
+	}
+
+	b.MakeAlgebMove("g1f3h", ADD_SAN)
+
+	if fen := b.ReportFen(); fen != "rnbqkbnr/pppppppp/8/8/8/5N2/PPPPPPPP/RNBQKBHR[Eeh] b KQBCDFkqbcdfg - 1 1" {
+		t.Errorf("g1f3h resulted in %s", fen)
+	}
+
+	// castling gates on the king or on the rook square
+	b = newSeirawanBoard(SEIRAWAN_CASTLING_FEN)
+
+	for algeb, fen := range map[string]string{
+		"e1g1h": "r3k2r/pppppppp/8/8/8/8/PPPPPPPP/R3HRK1[Eeh] b ABCDFGkqbcdfg - 1 1",
+		"h1e1h": "r3k2r/pppppppp/8/8/8/8/PPPPPPPP/R4RKH[Eeh] b ABCDFGkqbcdfg - 1 1",
+		"e1c1e": "r3k2r/pppppppp/8/8/8/8/PPPPPPPP/2KRE2R[Heh] b BCDFGHkqbcdfg - 1 1",
+		"a1e1e": "r3k2r/pppppppp/8/8/8/8/PPPPPPPP/E1KR3R[Heh] b BCDFGHkqbcdfg - 1 1",
+	} {
+		move := b.AlgebToMove(algeb)
+
+		if !move.Castling || move.GatingPiece.Kind == utils.NO_PIECE_KIND {
+			t.Errorf("%s should be castling with gating", algeb)
+			continue
+		}
+
+		b.Push(move, ADD_SAN)
+
+		if reported := b.ReportFen(); reported != fen {
+			t.Errorf("%s resulted in %s, expected %s", algeb, reported, fen)
+		}
+
+		b.Pop()
+	}
+
+	// only back rank moves gate
+	if move := b.AlgebToMove("b2b3h"); move.FromSq.EqualTo(utils.Square{File: 1, Rank: 6}) {
+		t.Errorf("b2b3h should not be legal")
+	}
+}
+
+func TestSeirawanNotation(t *testing.T) {
+	b := newSeirawanBoard(SEIRAWAN_CASTLING_FEN)
+
+	for san, algeb := range map[string]string{
+		"O-O/He1":   "e1g1h",
+		"O-O/Hh1":   "h1e1h",
+		"O-O-O/Ee1": "e1c1e",
+		"O-O-O/Ea1": "a1e1e",
+		"Rb1/H":     "a1b1h",
+		"Kf1/E":     "e1f1e",
+		"O-O":       "e1g1",
+	} {
+		move, err := b.SanToMove(san)
+		if err != nil {
+			t.Errorf("%s: %v", san, err)
+			continue
+		}
+
+		if b.MoveToAlgeb(move) != algeb {
+			t.Errorf("%s parsed as %s, expected %s", san, b.MoveToAlgeb(move), algeb)
+		}
+
+		if reported := b.MoveToSan(b.AlgebToMove(algeb)); reported != san {
+			t.Errorf("%s written as %s, expected %s", algeb, reported, san)
+		}
+	}
+}
+
+func TestSeirawanPerft(t *testing.T) {
+	b := newSeirawanBoard(utils.StartFenForVariant(utils.VARIANT_SEIRAWAN))
+
+	for depth, expected := range []int{28, 784, 24830} {
+		if nodes := b.Perft(depth + 1); nodes != expected {
+			t.Errorf("perft %d returned nodes %d, expected %d", depth+1, nodes, expected)
+		}
+	}
+}
+
+func TestLancerSan(t *testing.T) {
+	b := &Board{}
+	b.Init(utils.VARIANT_EIGHTPIECE)
+	b.SetFromFen("4k3/8/8/8/8/8/8/K1Lne3Lnw1 w - - 0 1 -")
+
+	// lancers of different directions share the letter
+	for algeb, san := range map[string]string{"c1e3lne": "Lce3=Lne", "g1e3lw": "Lge3=Lw"} {
+		if got := b.MoveToSan(b.AlgebToMove(algeb)); got != san {
+			t.Errorf("%s written as %s, expected %s", algeb, got, san)
+		}
+	}
+}
+
+// checks that depend on the sentry and lancer rules
+var EIGHTPIECE_CHECK_TESTS = []struct {
+	fen   string
+	check bool
+}{
+	// the lancer on a1 is not the pushed piece, the disabled move in its direction does not restrict it
+	{"k7/8/8/8/4p3/8/8/Ln3K3 b - - 0 1 e4e5", true},
+	// the sentry on h3 checks by pushing the rook on f1 onto the king
+	{"4k3/8/8/8/8/7s/8/5RK1 w - - 0 1 -", true},
+	{"4k3/8/8/8/8/7s/6P1/5RK1 w - - 0 1 -", false},
+}
+
+func TestEightpieceChecks(t *testing.T) {
+	b := &Board{}
+	b.Init(utils.VARIANT_EIGHTPIECE)
+
+	for _, test := range EIGHTPIECE_CHECK_TESTS {
+		b.SetFromFen(test.fen)
+
+		if check := b.IsInCheck(b.Pos.Turn); check != test.check {
+			t.Errorf("%s in check %v, expected %v", test.fen, check, test.check)
+		}
+	}
+
+	// the disabled move is part of the key
+	b.SetFromFen("k7/8/8/8/4p3/8/8/Ln3K3 b - - 0 1 -")
+	key := b.Zobrist()
+	b.SetFromFen("k7/8/8/8/4p3/8/8/Ln3K3 b - - 0 1 e4e5")
+
+	if b.Zobrist() == key {
+		t.Errorf("disabled move e4e5 did not change key %x", key)
+	}
+}
@@ -41,7 +41,7 @@ func (cr *CastlingRight) SetFromFen(fen string, b *Board) {
 		return
 	}
 
-	sqs := b.SquaresInDirection(wk, utils.PieceDirection{int8(cr.Side*2) - 1, 0})
+	sqs := b.SquaresInDirection(wk, utils.PieceDirection{File: int8(cr.Side*2) - 1, Rank: 0})
 
 	lastSq := utils.NO_SQUARE
 	lastKind := utils.NO_PIECE_KIND
@@ -99,7 +99,7 @@ func (cr *CastlingRight) ToString(b *Board) string {
 		return ""
 	}
 
-	sqs := b.SquaresInDirection(wk, utils.PieceDirection{int8(cr.Side*2) - 1, 0})
+	sqs := b.SquaresInDirection(wk, utils.PieceDirection{File: int8(cr.Side*2) - 1, Rank: 0})
 
 	rcnt := 0
 
@@ -119,8 +119,8 @@ func (cr *CastlingRight) ToString(b *Board) string {
 	if rcnt == 0 {
 		// no rook, no castling
 		return ""
-	} else if rcnt > 1 {
-		// more than one rook, needs x-fen
+	} else if (rcnt > 1) || b.Chess960 {
+		// more than one rook needs x-fen, chess960 uses shredder-fen
 		letter := b.SquareToFileLetter(cr.RookOrigSquare)
 		if cr.Color == utils.WHITE {
 			letter = strings.ToUpper(letter)
@@ -168,67 +168,35 @@ func (cr *CastlingRight) Free(b *Board) bool {
 		}
 	}
 
-	// all the squares between the king's initial and final squares (including the final square) should be empty
-	// except for the king and castling rook ( skip )
-	kctsq := b.KingCastlingTargetSq(cr.Color, cr.Side)
-
-	var dir int8 = 1
-	if kctsq.File < wk.File {
-		dir = -1
+	// the castling rook should still be in place
+	if !cr.RookOrigPiece.KindColorEqualTo(b.PieceAtSquare(cr.RookOrigSquare)) {
+		return false
 	}
 
-	sqs := b.SquaresInDirection(wk, utils.PieceDirection{dir, 0})
+	kctsq := b.KingCastlingTargetSq(cr.Color, cr.Side)
+	rctsq := b.RookCastlingTargetSq(cr.Color, cr.Side)
 
-	for _, sq := range sqs {
-		// passing squares of king should not be under attack
-		if b.IsSquareAttackedByColor(sq, cr.Color.Inverse()) {
-			return false
-		}
+	kingSqs := b.RankSquaresBetween(wk, kctsq)
 
+	// all the squares crossed by the king and the rook (including their final squares) should be empty
+	// except for the king and castling rook ( skip )
+	for _, sq := range append(b.RankSquaresBetween(cr.RookOrigSquare, rctsq), kingSqs...) {
 		skip := sq.EqualTo(wk) || sq.EqualTo(cr.RookOrigSquare)
 
-		if sq.EqualTo(kctsq) {
-			if b.IsSquareEmpty(kctsq) || skip {
-				break
-			} else {
-				return false
-			}
-		}
-
 		if (!b.IsSquareEmpty(sq)) && (!skip) {
 			return false
 		}
 	}
 
-	// all the squares between the rook's initial and final squares (including the final square) should be empty
-	// except for the king and castling rook ( skip )
-	rctsq := b.RookCastlingTargetSq(cr.Color, cr.Side)
-
-	dir = 1
-	if rctsq.File < cr.RookOrigSquare.File {
-		dir = -1
-	}
-
-	sqs = b.SquaresInDirection(cr.RookOrigSquare, utils.PieceDirection{dir, 0})
-
-	for _, sq := range sqs {
-		skip := sq.EqualTo(wk) || sq.EqualTo(cr.RookOrigSquare)
-
-		if sq.EqualTo(rctsq) {
-			if b.IsSquareEmpty(rctsq) || skip {
-				// all tests passed, castling is ok
-				return true
-			} else {
-				return false
-			}
-		}
-
-		if (!b.IsSquareEmpty(sq)) && (!skip) {
+	// passing squares of king should not be under attack
+	for _, sq := range kingSqs {
+		if b.IsSquareAttackedByColor(sq, cr.Color.Inverse()) {
 			return false
 		}
 	}
 
-	return false
+	// all tests passed, castling is ok
+	return true
 }
 
 /////////////////////////////////////////////////////////////////////
@@ -44,15 +44,35 @@ const KNIGHT_CLOSE_TO_EDGE_DEDUCTION = 10
 const CENTER_PAWN_BONUS = 30
 const MOBILITY_BONUS = 2
 const RANDOM_BONUS = 10
-const CAPTURE_BONUS = 2000
-const NON_PAWN_MOVE_BONUS = 1000
+
+// move ordering, captures are ordered by MVV_LVA_MULTIPLIER * material won - attacker value
+const TT_MOVE_BONUS = 1000000
+const CAPTURE_BONUS = 200000
+const KILLER_BONUS = 100000
+const MVV_LVA_MULTIPLIER = 10
+const MAX_HISTORY = 50000
+
+// captures that cannot raise the score above alpha by this margin are pruned in quiescence
+const DELTA_MARGIN = 200
+
+const ASPIRATION_WINDOW = 50
+const ASPIRATION_MIN_DEPTH = 4
 
 const SEARCH_MAX_DEPTH = 100
-const DEFAULT_QUIESCENCE_DEPTH = 0
+const DEFAULT_QUIESCENCE_DEPTH = 0 // maximum plies of the quiescence search, unlimited if 0
 const DEFAULT_UCI_VARIANT_STRING = "standard"
 const DEFAULT_SEARCH_DEPTH = 10
 const MAX_MULTIPV = 500
 const DEFAULT_MULTIPV = 1
+const DEFAULT_HASH_SIZE_MB = 16
+const MAX_HASH_SIZE_MB = 4096
+
+const (
+	TT_NONE TranspositionKind = iota
+	TT_EXACT
+	TT_LOWER
+	TT_UPPER
+)
 
 var UCI_OPTIONS = []utils.UciOption{
 	{
@@ -83,10 +103,19 @@ var UCI_OPTIONS = []utils.UciOption{
 		Name:       "Quiescence Depth",
 		ValueKind:  "int",
 		MinInt:     0,
-		MaxInt:     0,
+		MaxInt:     SEARCH_MAX_DEPTH,
 		DefaultInt: DEFAULT_QUIESCENCE_DEPTH,
 		ValueInt:   DEFAULT_QUIESCENCE_DEPTH,
 	},
+	{
+		Kind:       "spin",
+		Name:       "Hash",
+		ValueKind:  "int",
+		MinInt:     1,
+		MaxInt:     MAX_HASH_SIZE_MB,
+		DefaultInt: DEFAULT_HASH_SIZE_MB,
+		ValueInt:   DEFAULT_HASH_SIZE_MB,
+	},
 }
 
 var UCI_COMMAND_ALIASES = map[string]string{
@@ -98,4 +127,22 @@ var UCI_COMMAND_ALIASES = map[string]string{
 	"m":  "setoption name MultiPV value 5",
 }
 
+const ZOBRIST_SEED = 5
+
+// reserve counts are hashed modulo ZOBRIST_MAX_RESERVE
+const ZOBRIST_MAX_RESERVE = 16
+
+var (
+	zobristPiece        [utils.MAX_PIECE_KINDS][2][MAX_RANKS * MAX_FILES]uint64
+	zobristDirection    [9][MAX_RANKS * MAX_FILES]uint64
+	zobristPushDisabled [MAX_RANKS * MAX_FILES]uint64
+	zobristTurn         uint64
+	zobristCastling     [2][2][MAX_FILES]uint64
+	zobristEpSquare     [MAX_RANKS * MAX_FILES]uint64
+	zobristDisabledFrom [MAX_RANKS * MAX_FILES]uint64
+	zobristDisabledTo   [MAX_RANKS * MAX_FILES]uint64
+	zobristReserve      [2][utils.MAX_PIECE_KINDS][ZOBRIST_MAX_RESERVE]uint64
+	zobristGating       [2][1 << MAX_FILES]uint64
+)
+
 /////////////////////////////////////////////////////////////////////
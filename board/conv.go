@@ -1,6 +1,11 @@
 package board
 
-import "github.com/easychessanimations/gochess/utils"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/easychessanimations/gochess/utils"
+)
 
 /////////////////////////////////////////////////////////////////////
 // imports
@@ -30,7 +35,7 @@ func (b *Board) SquareFromAlgeb(algeb string) utils.Square {
 		return utils.NO_SQUARE
 	}
 
-	return utils.Square{int8(algeb[0] - "a"[0]), int8(byte(b.LastRank) - (algeb[1] - "1"[0]))}
+	return utils.Square{File: int8(algeb[0] - "a"[0]), Rank: int8(byte(b.LastRank) - (algeb[1] - "1"[0]))}
 }
 
 func (b *Board) AlgebToMoveRaw(algeb string) utils.Move {
@@ -68,6 +73,21 @@ func (b *Board) MoveToAlgeb(move utils.Move) string {
 
 	buff := b.SquareToAlgeb(move.FromSq) + b.SquareToAlgeb(move.ToSq)
 
+	if move.Castling && !b.Chess960 {
+		kctsq := b.KingCastlingTargetSq(b.Pos.Turn, move.CastlingSide)
+
+		buff = b.SquareToAlgeb(move.FromSq) + b.SquareToAlgeb(kctsq)
+	}
+
+	if move.Castling && move.IsGating() && move.GatingSquare.EqualTo(move.ToSq) {
+		// gating on the rook square is denoted as rook square to king square
+		buff = b.SquareToAlgeb(move.ToSq) + b.SquareToAlgeb(move.FromSq)
+	}
+
+	if move.IsGating() {
+		buff += move.GatingPiece.LetterLower()
+	}
+
 	if move.PromotionPiece != utils.NO_PIECE {
 		buff += move.PromotionPiece.ToStringLower()
 
@@ -92,12 +112,22 @@ func (b *Board) MoveToSan(move utils.Move) string {
 	}
 	b.Pop()
 
+	gatingStr := ""
+
+	if move.IsGating() {
+		gatingStr = "/" + move.GatingPiece.LetterUpper()
+	}
+
 	if move.Castling {
+		if move.IsGating() {
+			gatingStr += b.SquareToAlgeb(move.GatingSquare)
+		}
+
 		if move.CastlingSide == utils.QUEEN_SIDE {
-			return "O-O-O" + checkStr
+			return "O-O-O" + gatingStr + checkStr
 		}
 
-		return "O-O" + checkStr
+		return "O-O" + gatingStr + checkStr
 	}
 
 	fromAlgeb := b.SquareToAlgeb(move.FromSq)
@@ -135,6 +165,11 @@ func (b *Board) MoveToSan(move utils.Move) string {
 
 		attacks := b.PickLegalMovesFrom(pslAttacks, b.Pos.Turn)
 
+		if fromPiece.Kind == utils.Lancer {
+			// lancer attacks only cover captures, lancers of any direction share the same san letter
+			attacks = b.LancerMovesToSquareFromLegalMoves(move.ToSq)
+		}
+
 		files := make(map[int8]bool, 0)
 		ranks := make(map[int8]bool, 0)
 		samefiles := false
@@ -190,7 +225,106 @@ func (b *Board) MoveToSan(move utils.Move) string {
 		}
 	}
 
-	return buff + checkStr
+	return buff + gatingStr + checkStr
+}
+
+// MoveToSanMove returns the parts of the san of move that tell it apart from other moves
+// unlike MoveToSan it does not look at other moves, so it has no disambiguation and no check mark
+func (b *Board) MoveToSanMove(move utils.Move) utils.SanMove {
+	sm := utils.NewSanMove()
+
+	if move.IsGating() {
+		sm.Gating = move.GatingPiece.LetterUpper()
+	}
+
+	if move.Castling {
+		sm.Castling = true
+		sm.CastlingSide = move.CastlingSide
+
+		if move.IsGating() {
+			sm.GatingFile, sm.GatingRank = move.GatingSquare.File, b.LastRank-move.GatingSquare.Rank
+		}
+
+		return sm
+	}
+
+	fromPiece := b.PieceAtSquare(move.FromSq)
+
+	if fromPiece.Kind != utils.Pawn {
+		sm.Piece = fromPiece.LetterUpper()
+	}
+
+	sm.Capture = move.IsCapture()
+
+	sm.ToFile, sm.ToRank = move.ToSq.File, b.LastRank-move.ToSq.Rank
+
+	if move.IsPromotion() {
+		sm.Promotion = move.PromotionPiece.ToStringUpper()
+
+		if move.PromotionSquare != utils.NO_SQUARE {
+			sm.PushFile, sm.PushRank = move.PromotionSquare.File, b.LastRank-move.PromotionSquare.Rank
+		}
+	}
+
+	return sm
+}
+
+// SanToMove returns the legal move denoted by san, see utils.ParseSan for the accepted notations
+// returns an error wrapping utils.ErrIllegalMove or utils.ErrAmbiguousMove if no or several moves match
+func (b *Board) SanToMove(san string) (utils.Move, error) {
+	sm, err := utils.ParseSan(san)
+	if err != nil {
+		return NO_MOVE, err
+	}
+
+	candidates := []utils.Move{}
+
+	// the same move can be generated more than once
+	seen := make(map[string]bool)
+
+	for _, lm := range b.LegalMovesForAllPieces() {
+		algeb := b.MoveToAlgeb(lm)
+		if seen[algeb] {
+			continue
+		}
+		seen[algeb] = true
+
+		if sm.Matches(b.MoveToSanMove(lm), lm.FromSq.File, b.LastRank-lm.FromSq.Rank) {
+			candidates = append(candidates, lm)
+		}
+	}
+
+	if len(candidates) > 1 {
+		// without promotion given prefer the move that keeps the piece, like a lancer keeping its direction
+		unchanged := []utils.Move{}
+		for _, candidate := range candidates {
+			keptPiece := b.PieceAtSquare(candidate.FromSq)
+			if candidate.SentryPush {
+				keptPiece = b.PieceAtSquare(candidate.ToSq)
+			}
+			pp := candidate.PromotionPiece
+			if pp == utils.NO_PIECE || (pp.Kind == keptPiece.Kind && pp.Color == keptPiece.Color && pp.Direction == keptPiece.Direction) {
+				unchanged = append(unchanged, candidate)
+			}
+		}
+		if len(unchanged) == 1 {
+			candidates = unchanged
+		}
+	}
+
+	if len(candidates) == 0 {
+		return NO_MOVE, fmt.Errorf("%w %s", utils.ErrIllegalMove, san)
+	}
+
+	if len(candidates) > 1 {
+		sans := []string{}
+		for _, candidate := range candidates {
+			sans = append(sans, b.MoveToSan(candidate))
+		}
+		return NO_MOVE, fmt.Errorf("%w %s, could be %s", utils.ErrAmbiguousMove, san, strings.Join(sans, " "))
+	}
+
+	return candidates[0], nil
 }
 
 /////////////////////////////////////////////////////////////////////
@@ -47,6 +47,9 @@ func (b *Board) Push(move utils.Move, addSan bool) {
 
 	//////////////////////////////////////////////
 
+	// the pieces update the key as they are set, the rest of the state is hashed again at the end
+	b.Pos.Key ^= b.stateZobrist()
+
 	b.Pos.DisabledMove = NO_MOVE
 
 	fromp := b.PieceAtSquare(move.FromSq)
@@ -86,6 +89,16 @@ func (b *Board) Push(move utils.Move, addSan bool) {
 		b.SetPieceAtSquare(move.EffectivePromotionSquare(), move.PromotionPiece)
 	}
 
+	if move.IsGating() {
+		b.SetPieceAtSquare(move.GatingSquare, move.GatingPiece)
+
+		b.Pos.Reserve[move.GatingPiece.Color][move.GatingPiece.Kind]--
+	}
+
+	if b.IS_SEIRAWAN() {
+		b.UpdateGatingFiles(move)
+	}
+
 	if b.IS_ATOMIC() {
 		if move.IsCapture() {
 			// atomic explosion
@@ -101,15 +114,18 @@ func (b *Board) Push(move utils.Move, addSan bool) {
 		}
 	}
 
-	var side utils.CastlingSide
-	for side = utils.QUEEN_SIDE; side <= utils.KING_SIDE; side++ {
-		cs := &ccr[side]
-		if cs.CanCastle {
-			rp := b.PieceAtSquare(cs.RookOrigSquare)
-
-			if !cs.RookOrigPiece.KindColorEqualTo(rp) {
-				// rook changed, delete castling right
-				cs.CanCastle = false
+	// rooks can be moved, captured or exploded, check castling rights of both sides
+	for color := range b.Pos.CastlingRights {
+		var side utils.CastlingSide
+		for side = utils.QUEEN_SIDE; side <= utils.KING_SIDE; side++ {
+			cs := &b.Pos.CastlingRights[color][side]
+			if cs.CanCastle {
+				rp := b.PieceAtSquare(cs.RookOrigSquare)
+
+				if !cs.RookOrigPiece.KindColorEqualTo(rp) {
+					// rook changed, delete castling right
+					cs.CanCastle = false
+				}
 			}
 		}
 	}
@@ -132,6 +148,8 @@ func (b *Board) Push(move utils.Move, addSan bool) {
 		b.Pos.FullmoveNumber++
 	}
 
+	b.Pos.Key ^= b.stateZobrist()
+
 	b.MoveStack = append(b.MoveStack, MoveStackItem{
 		oldPos,
 		move,
@@ -183,7 +201,7 @@ func (b *Board) ExecCommand(command string) bool {
 			return true
 		} else if command != "" {
 			for _, mbi := range b.SortedSanMoveBuff {
-				if (mbi.San == command) || (mbi.Algeb == command) {
+				if mbi.Algeb == command {
 					move := mbi.Move
 
 					b.Push(move, ADD_SAN)
@@ -193,6 +211,20 @@ func (b *Board) ExecCommand(command string) bool {
 					return true
 				}
 			}
+
+			move, err := b.SanToMove(command)
+
+			if err != nil {
+				b.Log(err.Error())
+
+				return false
+			}
+
+			b.Push(move, ADD_SAN)
+
+			b.Print()
+
+			return true
 		}
 	}
 
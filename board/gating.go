@@ -0,0 +1,206 @@
+package board
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"strings"
+
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// https://en.wikipedia.org/wiki/Seirawan_chess
+
+func (b *Board) SetReserveFromFen(holdings string) {
+	b.Pos.Reserve = [2]Reserve{}
+
+	for i := 0; i < len(holdings); i++ {
+		chr := holdings[i : i+1]
+
+		if chr == "-" {
+			continue
+		}
+
+		p := utils.PieceLetterToPiece(chr)
+
+		if p.Kind != utils.NO_PIECE_KIND {
+			b.Pos.Reserve[p.Color][p.Kind]++
+		}
+	}
+}
+
+func (b *Board) ReserveToString() string {
+	buff := ""
+
+	for _, color := range []utils.PieceColor{utils.WHITE, utils.BLACK} {
+		// list stronger pieces first
+		for kind := utils.PieceKind(utils.MAX_PIECE_KINDS - 1); kind > utils.NO_PIECE_KIND; kind-- {
+			p := utils.Piece{Kind: kind, Color: color}
+
+			for cnt := b.Pos.Reserve[color][kind]; cnt > 0; cnt-- {
+				buff += p.Letter()
+			}
+		}
+	}
+
+	return "[" + buff + "]"
+}
+
+func (b *Board) HasReserve(color utils.PieceColor) bool {
+	for _, cnt := range b.Pos.Reserve[color] {
+		if cnt > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *Board) GatingFileMask(sq utils.Square) uint8 {
+	return 1 << uint8(sq.File)
+}
+
+// SetGatingFilesFromFen sets the files from which a first move allows gating
+// king and castling rook files are implied by the castling rights, other files are listed by file letter
+func (b *Board) SetGatingFilesFromFen(fen string) {
+	b.Pos.GatingFiles = [2]uint8{}
+
+	for _, color := range []utils.PieceColor{utils.WHITE, utils.BLACK} {
+		wk := b.WhereIsKing(color)
+
+		for _, cr := range b.Pos.CastlingRights[color] {
+			if cr.CanCastle {
+				b.Pos.GatingFiles[color] |= b.GatingFileMask(wk) | b.GatingFileMask(cr.RookOrigSquare)
+			}
+		}
+
+		var file int8
+		for file = 0; file < b.NumFiles; file++ {
+			letter := b.SquareToFileLetter(utils.Square{File: file, Rank: 0})
+
+			if color == utils.WHITE {
+				letter = strings.ToUpper(letter)
+			}
+
+			if strings.Contains(fen, letter) {
+				b.Pos.GatingFiles[color] |= b.GatingFileMask(utils.Square{File: file, Rank: 0})
+			}
+		}
+	}
+}
+
+// CastlingAndGatingToString reports castling rights followed by the gating files not implied by them
+func (b *Board) CastlingAndGatingToString() string {
+	buff := ""
+
+	for _, color := range []utils.PieceColor{utils.WHITE, utils.BLACK} {
+		ccr := b.Pos.CastlingRights[color]
+
+		buff += ccr.ToString(b)
+
+		implied := uint8(0)
+
+		wk := b.WhereIsKing(color)
+
+		for _, cr := range ccr {
+			if cr.CanCastle {
+				implied |= b.GatingFileMask(wk) | b.GatingFileMask(cr.RookOrigSquare)
+			}
+		}
+
+		var file int8
+		for file = 0; file < b.NumFiles; file++ {
+			sq := utils.Square{File: file, Rank: 0}
+
+			mask := b.GatingFileMask(sq)
+
+			if ((b.Pos.GatingFiles[color] & mask) != 0) && ((implied & mask) == 0) {
+				letter := b.SquareToFileLetter(sq)
+
+				if color == utils.WHITE {
+					letter = strings.ToUpper(letter)
+				}
+
+				buff += letter
+			}
+		}
+	}
+
+	if buff == "" {
+		return "-"
+	}
+
+	return buff
+}
+
+func (b *Board) IsGatingSquare(sq utils.Square, color utils.PieceColor) bool {
+	if sq.Rank != b.CastlingRank(color) {
+		return false
+	}
+
+	return (b.Pos.GatingFiles[color] & b.GatingFileMask(sq)) != 0
+}
+
+// AddGatingMoves adds for every move that vacates a gating square the moves that gate a reserve piece there
+// castling can gate on either the king or the rook square
+func (b *Board) AddGatingMoves(pslms []utils.Move, color utils.PieceColor) []utils.Move {
+	if !b.HasReserve(color) {
+		return pslms
+	}
+
+	gatingMoves := []utils.Move{}
+
+	for _, pslm := range pslms {
+		gatingSquares := []utils.Square{}
+
+		if b.IsGatingSquare(pslm.FromSq, color) {
+			gatingSquares = append(gatingSquares, pslm.FromSq)
+		}
+
+		if pslm.Castling {
+			if b.IsGatingSquare(pslm.ToSq, color) {
+				gatingSquares = append(gatingSquares, pslm.ToSq)
+			}
+		}
+
+		for _, gsq := range gatingSquares {
+			if pslm.Castling {
+				// the gating square should be vacated by castling
+				if gsq.EqualTo(b.KingCastlingTargetSq(color, pslm.CastlingSide)) || gsq.EqualTo(b.RookCastlingTargetSq(color, pslm.CastlingSide)) {
+					continue
+				}
+			}
+
+			for kind, cnt := range b.Pos.Reserve[color] {
+				if cnt > 0 {
+					gatingMove := pslm
+
+					gatingMove.GatingPiece = utils.Piece{Kind: utils.PieceKind(kind), Color: color}
+					gatingMove.GatingSquare = gsq
+
+					gatingMoves = append(gatingMoves, gatingMove)
+				}
+			}
+		}
+	}
+
+	return append(pslms, gatingMoves...)
+}
+
+// UpdateGatingFiles clears the gating files of back rank squares touched by move
+func (b *Board) UpdateGatingFiles(move utils.Move) {
+	for _, color := range []utils.PieceColor{utils.WHITE, utils.BLACK} {
+		for _, sq := range []utils.Square{move.FromSq, move.ToSq} {
+			if sq.Rank == b.CastlingRank(color) {
+				b.Pos.GatingFiles[color] &^= b.GatingFileMask(sq)
+			}
+		}
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
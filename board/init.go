@@ -39,6 +39,8 @@ func (b *Board) Init(variant utils.VariantKey) {
 
 	// init position
 	b.Pos.Init(b)
+
+	b.Pos.Key = b.ComputeZobrist()
 }
 
 func (b *Board) SetFromRawFen(fen string) {
@@ -48,7 +50,7 @@ func (b *Board) SetFromRawFen(fen string) {
 		chr := fen[index : index+1]
 		if (chr >= "0") && (chr <= "9") {
 			for cumul := chr[0] - "0"[0]; cumul > 0; cumul-- {
-				b.SetPieceAtSquare(utils.Square{file, rank}, utils.NO_PIECE)
+				b.SetPieceAtSquare(utils.Square{File: file, Rank: rank}, utils.NO_PIECE)
 				file++
 			}
 		} else if chr == "/" {
@@ -60,16 +62,16 @@ func (b *Board) SetFromRawFen(fen string) {
 				index++
 				dirFirst := fen[index : index+1]
 				dirSecond := ""
-				if (dirFirst == "n") || (dirFirst == "s") {
-					index++
-					dirSecond = fen[index : index+1]
-					if (dirSecond != "w") && (dirSecond != "e") {
-						dirSecond = ""
+				if ((dirFirst == "n") || (dirFirst == "s")) && (index+1 < len(fen)) {
+					// only take the next letter if it completes a diagonal direction
+					if next := fen[index+1 : index+2]; (next == "w") || (next == "e") {
+						index++
+						dirSecond = next
 					}
 				}
 				pieceLetter = chr + dirFirst + dirSecond
 			}
-			b.SetPieceAtSquare(utils.Square{file, rank}, utils.PieceLetterToPiece(pieceLetter))
+			b.SetPieceAtSquare(utils.Square{File: file, Rank: rank}, utils.PieceLetterToPiece(pieceLetter))
 			file++
 		}
 		index++
@@ -83,11 +85,36 @@ func (b *Board) Reset() {
 func (b *Board) SetFromFen(fen string) {
 	fenParts := strings.Split(fen, " ")
 
-	b.SetFromRawFen(fenParts[0])
+	rawFen := fenParts[0]
+	holdings := ""
+
+	// holdings are given in brackets after the board, as in RNBQKBNR[EHeh]
+	if index := strings.Index(rawFen, "["); index >= 0 {
+		holdings = strings.Trim(rawFen[index:], "[]")
+		rawFen = rawFen[:index]
+	}
+
+	b.SetFromRawFen(rawFen)
+
+	b.SetReserveFromFen(holdings)
 
 	b.Pos.Turn.SetFromFen(fenParts[1])
 
-	b.Pos.CastlingRights.SetFromFen(fenParts[2], b)
+	if b.IS_SEIRAWAN() {
+		// file letters denote gating files, castling only uses KQkq
+		b.Pos.CastlingRights.SetFromFen(strings.Map(func(r rune) rune {
+			if strings.ContainsRune("KQkq", r) {
+				return r
+			}
+			return -1
+		}, fenParts[2]), b)
+
+		b.SetGatingFilesFromFen(fenParts[2])
+	} else {
+		b.Pos.CastlingRights.SetFromFen(fenParts[2], b)
+
+		b.Pos.GatingFiles = [2]uint8{}
+	}
 
 	b.Pos.EpSquare = b.SquareFromAlgeb(fenParts[3])
 
@@ -102,6 +129,8 @@ func (b *Board) SetFromFen(fen string) {
 	if b.IS_EIGHTPIECE() {
 		b.Pos.DisabledMove = b.AlgebToMoveRaw(fenParts[6])
 	}
+
+	b.Pos.Key = b.ComputeZobrist()
 }
 
 func (b *Board) ResetVariantFromUciOption() {
@@ -109,7 +138,16 @@ func (b *Board) ResetVariantFromUciOption() {
 		Value: "standard",
 	})
 
-	b.Variant = utils.VariantKeyStringToVariantKey(variantUciOption.Value)
+	variant := utils.VariantKeyStringToVariantKey(variantUciOption.Value)
+
+	if variant != b.Variant {
+		// search results of another variant are useless
+		b.TranspositionTable.Clear()
+	}
+
+	b.Variant = variant
+
+	b.Chess960 = b.GetUciOptionByNameWithDefault("UCI_Chess960", utils.UciOption{}).ValueBool
 
 	b.Reset()
 }
@@ -128,7 +166,7 @@ func (b *Board) ReportRawFen() string {
 	var rank int8
 	for rank = 0; rank < b.NumRanks; rank++ {
 		for file = 0; file < b.NumFiles; file++ {
-			p := b.PieceAtSquare(utils.Square{file, rank})
+			p := b.PieceAtSquare(utils.Square{File: file, Rank: rank})
 
 			if p == utils.NO_PIECE {
 				cumul++
@@ -158,9 +196,17 @@ func (b *Board) ReportRawFen() string {
 func (b *Board) ReportFen() string {
 	buff := b.ReportRawFen()
 
+	if b.IS_SEIRAWAN() {
+		buff += b.ReserveToString()
+	}
+
 	buff += " " + b.Pos.Turn.ToString()
 
-	buff += " " + b.Pos.CastlingRights.ToString(b)
+	if b.IS_SEIRAWAN() {
+		buff += " " + b.CastlingAndGatingToString()
+	} else {
+		buff += " " + b.Pos.CastlingRights.ToString(b)
+	}
 
 	buff += " " + b.SquareToAlgeb(b.Pos.EpSquare)
 
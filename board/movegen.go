@@ -131,7 +131,8 @@ func (b *Board) AttacksOnSquareByLancer(sq utils.Square, color utils.PieceColor,
 			ToSq:   sq,
 		}
 
-		nudge := b.Pos.DisabledMove != NO_MOVE
+		// only the pushed lancer is restricted
+		nudge := (b.Pos.DisabledMove != NO_MOVE) && (lsq == b.Pos.DisabledMove.FromSq)
 
 		tmNormDir := testmove.NormalizedDirection()
 
@@ -139,8 +140,6 @@ func (b *Board) AttacksOnSquareByLancer(sq utils.Square, color utils.PieceColor,
 			if tmNormDir == b.Pos.DisabledMove.NormalizedDirection() {
 				continue
 			}
-
-			nudge = lsq == b.Pos.DisabledMove.FromSq
 		}
 
 		if ((tmNormDir.File != 0) || (tmNormDir.Rank != 0)) && ((tmNormDir == lancer.Direction) || nudge) {
@@ -182,6 +181,23 @@ func (b *Board) AttacksOnSquareByLancer(sq utils.Square, color utils.PieceColor,
 	return attacks
 }
 
+// LancerMovesToSquareFromLegalMoves returns one legal lancer move to sq for every lancer that can go there
+func (b *Board) LancerMovesToSquareFromLegalMoves(sq utils.Square) []utils.Move {
+	moves := []utils.Move{}
+
+	seen := make(map[utils.Square]bool)
+
+	for _, lm := range b.LegalMovesForAllPieces() {
+		if lm.ToSq.EqualTo(sq) && (b.PieceAtSquare(lm.FromSq).Kind == utils.Lancer) && !seen[lm.FromSq] {
+			seen[lm.FromSq] = true
+
+			moves = append(moves, lm)
+		}
+	}
+
+	return moves
+}
+
 func (b *Board) AttacksOnSquareByPiece(sq utils.Square, p utils.Piece, stopAtFirst bool) []utils.Move {
 	if p.Kind == utils.Pawn {
 		return b.AttacksOnSquareByPawn(sq, p.Color, stopAtFirst)
@@ -205,7 +221,7 @@ func (b *Board) AttacksOnSquareByPawn(sq utils.Square, color utils.PieceColor, s
 
 	var df int8
 	for df = -1; df <= 1; df += 2 {
-		testsq := sq.Add(utils.PieceDirection{df, rdir})
+		testsq := sq.Add(utils.PieceDirection{File: df, Rank: rdir})
 
 		if b.HasSquare(testsq) {
 			testp := b.PieceAtSquare(testsq)
@@ -298,9 +314,18 @@ func (b *Board) PslmsForVectorPieceAtSquare(p utils.Piece, sq utils.Square) []ut
 		}
 	}
 
-	for _, dir := range directions {
+	numSlidingDirections := len(directions)
+
+	if len(pdesc.LeaperDirections) > 0 {
+		// compound pieces also leap, leaper directions come after the sliding ones
+		directions = append(append([]utils.PieceDirection{}, directions...), pdesc.LeaperDirections...)
+	}
+
+	for i, dir := range directions {
 		ok := true
 
+		sliding := pdesc.Sliding && (i < numSlidingDirections)
+
 		currentSq = sq.Add(dir)
 
 		for ok {
@@ -338,11 +363,17 @@ func (b *Board) PslmsForVectorPieceAtSquare(p utils.Piece, sq utils.Square) []ut
 
 								top.PushDisabled = true
 
+								// the pushed piece cannot push and is not jailed, color inverse does not keep the flag
+								topInv.PushDisabled = true
+
 								// remove sentry for the time of move generation
 								b.SetPieceAtSquare(sq, utils.NO_PIECE)
 
 								pushes := utils.MoveList(b.PslmsForPieceAtSquare(topInv, currentSq))
 
+								// the sentry square is free for a nudge too
+								nudgeSqs := b.EmptyAdjacentSquares(currentSq)
+
 								// put back sentry
 								b.SetPieceAtSquare(sq, p)
 
@@ -352,18 +383,25 @@ func (b *Board) PslmsForVectorPieceAtSquare(p utils.Piece, sq utils.Square) []ut
 
 								if top.Kind == utils.Lancer {
 									// lancer nudge
-									for _, easq := range b.EmptyAdjacentSquares(currentSq) {
+									for _, easq := range nudgeSqs {
+										direction := utils.PieceDirection{
+											File: easq.File - currentSq.File,
+											Rank: easq.Rank - currentSq.Rank,
+										}
+
+										if direction == top.Direction {
+											// already pushed along its own direction
+											continue
+										}
+
 										move := utils.Move{
 											FromSq:     sq,
 											ToSq:       currentSq,
 											SentryPush: true,
 											PromotionPiece: utils.Piece{
-												Kind:  utils.Lancer,
-												Color: top.Color,
-												Direction: utils.PieceDirection{
-													File: easq.File - currentSq.File,
-													Rank: easq.Rank - currentSq.Rank,
-												},
+												Kind:      utils.Lancer,
+												Color:     top.Color,
+												Direction: direction,
 											},
 											PromotionSquare: easq,
 											AsIs:            true,
@@ -404,7 +442,7 @@ func (b *Board) PslmsForVectorPieceAtSquare(p utils.Piece, sq utils.Square) []ut
 					ToSq:   currentSq,
 				}
 
-				if !pdesc.Sliding {
+				if !sliding {
 					ok = false
 				}
 
@@ -433,7 +471,8 @@ func (b *Board) PslmsForVectorPieceAtSquare(p utils.Piece, sq utils.Square) []ut
 		}
 	}
 
-	if b.IS_EIGHTPIECE() && (b.Pos.DisabledMove != NO_MOVE) {
+	if b.IS_EIGHTPIECE() && (b.Pos.DisabledMove != NO_MOVE) && b.Pos.DisabledMove.FromSq.EqualTo(sq) {
+		// only the pushed piece is restricted
 		filteredPslms := []utils.Move{}
 
 		for _, pslm := range pslms {
@@ -462,7 +501,7 @@ func (b *Board) PslmsForPawnAtSquare(p utils.Piece, sq utils.Square) []utils.Mov
 
 	rankDir := b.PawnRankDir(p.Color)
 
-	pushOneSq := sq.Add(utils.PieceDirection{0, rankDir})
+	pushOneSq := sq.Add(utils.PieceDirection{File: 0, Rank: rankDir})
 
 	if b.HasSquare(pushOneSq) {
 		if b.IsSquareEmpty(pushOneSq) {
@@ -486,7 +525,7 @@ func (b *Board) PslmsForPawnAtSquare(p utils.Piece, sq utils.Square) []utils.Mov
 				pslms = append(pslms, move)
 			}
 
-			pushTwoSq := pushOneSq.Add(utils.PieceDirection{0, rankDir})
+			pushTwoSq := pushOneSq.Add(utils.PieceDirection{File: 0, Rank: rankDir})
 
 			if b.HasSquare(pushTwoSq) && (sq.Rank == b.PawnBaseRank(p.Color)) {
 				if b.IsSquareEmpty(pushTwoSq) {
@@ -494,7 +533,7 @@ func (b *Board) PslmsForPawnAtSquare(p utils.Piece, sq utils.Square) []utils.Mov
 
 					var df int8
 					for df = -1; df <= 1; df += 2 {
-						testsq := pushTwoSq.Add(utils.PieceDirection{df, 0})
+						testsq := pushTwoSq.Add(utils.PieceDirection{File: df, Rank: 0})
 						if b.HasSquare(testsq) {
 							tp := b.PieceAtSquare(testsq)
 
@@ -519,7 +558,7 @@ func (b *Board) PslmsForPawnAtSquare(p utils.Piece, sq utils.Square) []utils.Mov
 
 	var fileDir int8
 	for fileDir = -1; fileDir <= 1; fileDir += 2 {
-		captureSquare := sq.Add(utils.PieceDirection{fileDir, rankDir})
+		captureSquare := sq.Add(utils.PieceDirection{File: fileDir, Rank: rankDir})
 
 		if b.HasSquare(captureSquare) {
 			top := b.PieceAtSquare(captureSquare)
@@ -551,7 +590,7 @@ func (b *Board) PslmsForPawnAtSquare(p utils.Piece, sq utils.Square) []utils.Mov
 					FromSq:        sq,
 					ToSq:          captureSquare,
 					EpCapture:     true,
-					EpClearSquare: captureSquare.Add(utils.PieceDirection{0, -rankDir}),
+					EpClearSquare: captureSquare.Add(utils.PieceDirection{File: 0, Rank: -rankDir}),
 				}
 
 				pslms = append(pslms, plm)
@@ -597,7 +636,7 @@ func (b *Board) PslmsForAllPiecesOfColor(color utils.PieceColor) []utils.Move {
 	var file int8
 	for rank = 0; rank < b.NumRanks; rank++ {
 		for file = 0; file < b.NumFiles; file++ {
-			sq := utils.Square{file, rank}
+			sq := utils.Square{File: file, Rank: rank}
 			p := b.PieceAtSquare(sq)
 			if (p.Color == color) && (p != utils.NO_PIECE) {
 				pslms = append(pslms, b.PslmsForPieceAtSquare(p, sq)...)
@@ -623,6 +662,10 @@ func (b *Board) PslmsForAllPiecesOfColor(color utils.PieceColor) []utils.Move {
 		}
 	}
 
+	if b.IS_SEIRAWAN() {
+		pslms = b.AddGatingMoves(pslms, color)
+	}
+
 	return pslms
 }
 
@@ -27,7 +27,7 @@ func (b *Board) Material(color utils.PieceColor) (int, int, int) {
 	var file int8
 	for rank = 0; rank < b.NumRanks; rank++ {
 		for file = 0; file < b.NumFiles; file++ {
-			p := b.PieceAtSquare(utils.Square{file, rank})
+			p := b.PieceAtSquare(utils.Square{File: file, Rank: rank})
 			if (p.Color == color) && (p != utils.NO_PIECE) {
 				material += PIECE_VALUES[p.Kind]
 
@@ -57,6 +57,10 @@ func (b *Board) Material(color utils.PieceColor) (int, int, int) {
 		}
 	}
 
+	for kind, cnt := range b.Pos.Reserve[color] {
+		material += int(cnt) * PIECE_VALUES[kind]
+	}
+
 	pslms := b.PslmsForAllPiecesOfColor(color)
 
 	mobility += MOBILITY_BONUS * len(pslms)
@@ -133,6 +137,29 @@ func (b *Board) PerfRecursive(depth int, maxDepth int) {
 	}
 }
 
+// Perft returns the number of leaf nodes at depth
+func (b *Board) Perft(depth int) int {
+	if depth <= 0 {
+		return 1
+	}
+
+	lms := b.LegalMovesForAllPieces()
+
+	if depth == 1 {
+		return len(lms)
+	}
+
+	nodes := 0
+
+	for _, lm := range lms {
+		b.Push(lm, !ADD_SAN)
+		nodes += b.Perft(depth - 1)
+		b.Pop()
+	}
+
+	return nodes
+}
+
 func (b *Board) Perf(maxDepth int) {
 	b.StartPerf()
 
@@ -159,111 +186,222 @@ func (mpvinfo *MultipvInfo) ToString(multipv int) string {
 	)
 }
 
-func (b *Board) CreateMoveEvalBuff(moves []utils.Move) utils.MoveEvalBuff {
-	meb := utils.MoveEvalBuff{}
+// CaptureValue returns the material won by move, promotions included
+// in atomic the pieces exploded on both sides are counted, the capturing piece always explodes
+func (b *Board) CaptureValue(move utils.Move) int {
+	value := 0
+
+	if move.EpCapture {
+		value = PIECE_VALUES[utils.Pawn]
+	} else if move.SentryPush {
+		// the pushed piece may capture on its target square
+		if p := b.PieceAtSquare(move.EffectivePromotionSquare()); (p != utils.NO_PIECE) && (p.Color != b.Pos.Turn) {
+			value = PIECE_VALUES[p.Kind]
+		}
+	} else if move.IsCapture() {
+		value = PIECE_VALUES[b.PieceAtSquare(move.ToSq).Kind]
+	}
 
-	pe, ok := b.PositionHash.PositionEntries[b.Pos]
+	if b.IsPawnPromotion(move) {
+		value += PIECE_VALUES[move.PromotionPiece.Kind] - PIECE_VALUES[utils.Pawn]
+	}
 
-	if ok {
-		for _, move := range moves {
-			eval := -INFINITE_SCORE
+	if b.IS_ATOMIC() && move.IsCapture() {
+		value -= PIECE_VALUES[b.PieceAtSquare(move.FromSq).Kind]
 
-			me, ok := pe.MoveEntries[move]
+		for _, sq := range b.AdjacentSquares(move.ToSq) {
+			p := b.PieceAtSquare(sq)
 
-			if ok {
-				eval = me.Eval
+			if (sq == move.FromSq) || (p == utils.NO_PIECE) || (p.Kind == utils.Pawn) {
+				continue
 			}
 
-			meb = append(meb, utils.MoveEvalBuffItem{
-				Move: move,
-				Eval: eval,
-			})
+			if p.Color == b.Pos.Turn {
+				value -= PIECE_VALUES[p.Kind]
+			} else {
+				value += PIECE_VALUES[p.Kind]
+			}
 		}
+	}
 
-		sort.Sort(meb)
-	} else {
-		for _, move := range moves {
-			eval := -INFINITE_SCORE
+	return value
+}
 
-			if move.IsCapture() {
-				eval += CAPTURE_BONUS
-			}
+// IsPawnPromotion tells whether move promotes a pawn
+// lancer moves also have a promotion piece, which is the lancer turned to its new direction
+func (b *Board) IsPawnPromotion(move utils.Move) bool {
+	return move.IsPromotion() && move.IsPawnMove()
+}
 
-			if !move.IsPawnMove() {
-				eval += NON_PAWN_MOVE_BONUS
-			}
+// CreateMoveEvalBuff orders moves for the search
+// the transposition table move comes first, then captures and promotions by MVV-LVA,
+// then the killer moves of ply, then quiet moves by history
+func (b *Board) CreateMoveEvalBuff(moves []utils.Move, ttMove utils.Move, ply int) utils.MoveEvalBuff {
+	meb := utils.MoveEvalBuff{}
 
-			meb = append(meb, utils.MoveEvalBuffItem{
-				Move: move,
-				Eval: eval,
-			})
+	for _, move := range moves {
+		eval := 0
+
+		if move == ttMove {
+			eval = TT_MOVE_BONUS
+		} else if move.IsCapture() || b.IsPawnPromotion(move) {
+			eval = CAPTURE_BONUS + MVV_LVA_MULTIPLIER*b.CaptureValue(move) - PIECE_VALUES[b.PieceAtSquare(move.FromSq).Kind]
+		} else if (ply < SEARCH_MAX_DEPTH) && (move == b.Killers[ply][0]) {
+			eval = KILLER_BONUS + 1
+		} else if (ply < SEARCH_MAX_DEPTH) && (move == b.Killers[ply][1]) {
+			eval = KILLER_BONUS
+		} else {
+			eval = *b.HistoryEntry(move)
 		}
+
+		meb = append(meb, utils.MoveEvalBuffItem{
+			Move: move,
+			Eval: eval,
+		})
 	}
 
+	sort.Stable(meb)
+
 	return meb
 }
 
-func (b *Board) GetPv(maxDepth int) (string, []utils.Move) {
-	b.TestBoard = &Board{}
+// HistoryEntry returns the history score of the piece of move going to the target square of move
+func (b *Board) HistoryEntry(move utils.Move) *int {
+	p := b.PieceAtSquare(move.FromSq)
 
-	b.TestBoard.Init(b.Variant)
+	return &b.History[p.Color&1][p.Kind][move.ToSq.Rank][move.ToSq.File]
+}
 
-	b.TestBoard.Pos = b.Pos
+// StoreCutoff remembers the quiet move that caused a beta cutoff at ply in the killer and history tables
+func (b *Board) StoreCutoff(move utils.Move, ply int, depth int) {
+	if move.IsCapture() || b.IsPawnPromotion(move) {
+		return
+	}
 
-	b.TestBoard.PositionHash = b.PositionHash
+	if (ply < SEARCH_MAX_DEPTH) && (b.Killers[ply][0] != move) {
+		b.Killers[ply][1] = b.Killers[ply][0]
+		b.Killers[ply][0] = move
+	}
+
+	entry := b.HistoryEntry(move)
+
+	*entry += depth * depth
+
+	if *entry > MAX_HISTORY {
+		b.AgeHistory()
+	}
+}
+
+// AgeHistory halves the history scores, so that recent cutoffs weigh more
+func (b *Board) AgeHistory() {
+	for color := range b.History {
+		for kind := range b.History[color] {
+			for rank := range b.History[color][kind] {
+				for file := range b.History[color][kind][rank] {
+					b.History[color][kind][rank][file] /= 2
+				}
+			}
+		}
+	}
+}
 
+// GetPv returns the principal variation starting with move, followed by the best moves of the transposition table
+func (b *Board) GetPv(move utils.Move, maxDepth int) (string, []utils.Move) {
 	pv := []string{}
 
 	pvMoves := []utils.Move{}
 
-	for i := 0; i < maxDepth; i++ {
-		lms := b.TestBoard.LegalMovesForAllPieces()
+	if move == (utils.Move{}) {
+		return "", pvMoves
+	}
 
-		meb := b.TestBoard.CreateMoveEvalBuff(lms)
+	for i := 0; i < maxDepth; i++ {
+		pv = append(pv, b.MoveToAlgeb(move))
 
-		if len(meb) > 0 {
-			if meb[0].Eval > -INFINITE_SCORE {
-				pvMove := meb[0].Move
+		pvMoves = append(pvMoves, move)
 
-				pv = append(pv, b.TestBoard.MoveToAlgeb(pvMove))
+		b.Push(move, !ADD_SAN)
 
-				pvMoves = append(pvMoves, pvMove)
+		entry, ok := b.TranspositionTable.Probe(b.Zobrist())
 
-				b.TestBoard.Push(pvMove, !ADD_SAN)
-			} else {
-				break
-			}
-		} else {
+		if !ok || !b.IsLegalMove(entry.Move) {
 			break
 		}
+
+		move = entry.Move
+	}
+
+	for range pvMoves {
+		b.Pop()
 	}
 
 	return strings.Join(pv, " "), pvMoves
 }
 
-// https://www.chessprogramming.org/Alpha-Beta
+// IsLegalMove tells whether move is a legal move in the current position
+func (b *Board) IsLegalMove(move utils.Move) bool {
+	if move == (utils.Move{}) {
+		return false
+	}
+
+	for _, lm := range b.LegalMovesForAllPieces() {
+		if lm == move {
+			return true
+		}
+	}
+
+	return false
+}
+
+// https://www.chessprogramming.org/Principal_Variation_Search
 func (b *Board) AlphaBeta(info AlphaBetaInfo) (utils.Move, int) {
+	bm := utils.Move{}
+
+	if info.CurrentDepth >= info.Depth {
+		return bm, b.Quiescence(info)
+	}
+
 	b.Nodes++
 
 	if info.CurrentDepth > b.SelDepth {
 		b.SelDepth = info.CurrentDepth
 	}
 
-	bm := utils.Move{}
-
 	if !b.Searching {
 		return bm, b.EvalForTurn()
 	}
 
-	if info.CurrentDepth >= info.TotalDepth() {
-		return bm, b.EvalForTurn()
+	key := b.Zobrist()
+
+	ttMove := utils.Move{}
+
+	if entry, ok := b.TranspositionTable.Probe(key); ok {
+		ttMove = entry.Move
+
+		// the root is always searched, so that it yields a move
+		if (info.CurrentDepth > 0) && (entry.Depth >= info.Depth-info.CurrentDepth) {
+			score := entry.ScoreAt(info.CurrentDepth)
+
+			switch entry.Kind {
+			case TT_EXACT:
+				return entry.Move, score
+			case TT_LOWER:
+				if score >= info.Beta {
+					return entry.Move, info.Beta
+				}
+			case TT_UPPER:
+				if score <= info.Alpha {
+					return bm, info.Alpha
+				}
+			}
+		}
 	}
 
 	plms := b.PslmsForAllPiecesOfColor(b.Pos.Turn)
 
-	meb := b.CreateMoveEvalBuff(plms)
+	meb := b.CreateMoveEvalBuff(plms, ttMove, info.CurrentDepth)
 
-	isNormalSearch := info.CurrentDepth < info.Depth
+	origAlpha := info.Alpha
 
 	numLegals := 0
 
@@ -278,73 +416,210 @@ func (b *Board) AlphaBeta(info AlphaBetaInfo) (utils.Move, int) {
 			}
 		}
 
-		if (isNormalSearch || plm.IsCapture()) && (!isMoveExcluded) {
-			b.Push(plm, !ADD_SAN)
+		if isMoveExcluded {
+			continue
+		}
 
-			if !b.IsInCheck(b.Pos.Turn.Inverse()) {
-				numLegals++
+		b.Push(plm, !ADD_SAN)
 
-				newInfo := info
-				newInfo.Alpha = -info.Beta
-				newInfo.Beta = -info.Alpha
-				newInfo.CurrentDepth = info.CurrentDepth + 1
-				newInfo.Line = append(newInfo.Line, b.MoveToAlgeb(plm))
+		if b.IsInCheck(b.Pos.Turn.Inverse()) {
+			b.Pop()
 
-				_, score := b.AlphaBeta(newInfo)
+			continue
+		}
+
+		numLegals++
+
+		newInfo := info
+		newInfo.Alpha = -info.Beta
+		newInfo.Beta = -info.Alpha
+		newInfo.CurrentDepth = info.CurrentDepth + 1
+		newInfo.Line = append(newInfo.Line, b.MoveToAlgeb(plm))
 
-				b.Pop()
+		score := 0
+
+		if numLegals == 1 {
+			_, score = b.AlphaBeta(newInfo)
+
+			score *= -1
+		} else {
+			// moves after the first one only have to be proven worse, which a null window does cheaply
+			nullInfo := newInfo
+			nullInfo.Alpha = -info.Alpha - 1
+
+			_, score = b.AlphaBeta(nullInfo)
+
+			score *= -1
+
+			if (score > info.Alpha) && (score < info.Beta) {
+				_, score = b.AlphaBeta(newInfo)
 
 				score *= -1
+			}
+		}
 
-				if score >= info.Beta {
-					b.Betas++
+		b.Pop()
 
-					return bm, info.Beta
-				}
+		if score >= info.Beta {
+			b.Betas++
 
-				if score > info.Alpha {
-					b.Alphas++
+			b.StoreCutoff(plm, info.CurrentDepth, info.Depth-info.CurrentDepth)
 
-					pe := b.PositionHash.GetPositionEntry(b.Pos)
+			b.StoreTranspositionEntry(key, info, plm, info.Beta, TT_LOWER)
 
-					pe.DecreaseEntries(500)
+			return plm, info.Beta
+		}
 
-					me := pe.GetMoveEntry(plm)
+		if score > info.Alpha {
+			b.Alphas++
 
-					me.Eval = score
+			bm = plm
+			info.Alpha = score
+		}
+	}
 
-					pe.SetMoveEntry(plm, me)
+	if numLegals <= 0 {
+		score := DRAW_SCORE
 
-					bm = plm
-					info.Alpha = score
-				}
-			} else {
-				b.Pop()
-			}
+		if b.IsInCheck(b.Pos.Turn) {
+			score = -(MATE_SCORE - info.CurrentDepth)
 		}
+
+		b.StoreTranspositionEntry(key, info, bm, score, TT_EXACT)
+
+		return bm, score
+	}
+
+	if info.Alpha > origAlpha {
+		b.StoreTranspositionEntry(key, info, bm, info.Alpha, TT_EXACT)
+	} else {
+		b.StoreTranspositionEntry(key, info, bm, info.Alpha, TT_UPPER)
 	}
 
-	eval := b.EvalForTurn()
+	return bm, info.Alpha
+}
+
+// Quiescence searches captures and promotions until the position is quiet
+// the side to move may stand pat unless in check, then all evasions are searched
+// https://www.chessprogramming.org/Quiescence_Search
+func (b *Board) Quiescence(info AlphaBetaInfo) int {
+	b.Nodes++
 
-	if isNormalSearch {
-		if numLegals <= 0 {
-			if b.IsInCheck(b.Pos.Turn) {
-				return bm, -(MATE_SCORE - info.CurrentDepth)
-			} else {
-				return bm, DRAW_SCORE
+	if info.CurrentDepth > b.SelDepth {
+		b.SelDepth = info.CurrentDepth
+	}
+
+	inCheck := b.IsInCheck(b.Pos.Turn)
+
+	standPat := b.EvalForTurn()
+
+	if !b.Searching {
+		return standPat
+	}
+
+	if !inCheck {
+		if standPat >= info.Beta {
+			return info.Beta
+		}
+
+		if standPat > info.Alpha {
+			info.Alpha = standPat
+		}
+	}
+
+	maxDepth := info.Depth + SEARCH_MAX_DEPTH
+	if info.QuiescenceDepth > 0 {
+		maxDepth = info.TotalDepth()
+	}
+
+	if info.CurrentDepth >= maxDepth {
+		return info.Alpha
+	}
+
+	plms := b.PslmsForAllPiecesOfColor(b.Pos.Turn)
+
+	meb := b.CreateMoveEvalBuff(plms, utils.Move{}, info.CurrentDepth)
+
+	numLegals := 0
+
+	for _, mebi := range meb {
+		plm := mebi.Move
+
+		if !inCheck {
+			if !plm.IsCapture() && !b.IsPawnPromotion(plm) {
+				continue
+			}
+
+			// sentry pushes that win no material would shuffle pieces forever
+			if plm.SentryPush && (b.CaptureValue(plm) <= 0) {
+				continue
+			}
+
+			// https://www.chessprogramming.org/Delta_Pruning
+			// explosions in atomic can win more than the captured piece, so they are not pruned
+			if !b.IS_ATOMIC() && (standPat+b.CaptureValue(plm)+DELTA_MARGIN <= info.Alpha) {
+				continue
 			}
 		}
-	} else {
-		if numLegals <= 0 {
-			return bm, eval
+
+		b.Push(plm, !ADD_SAN)
+
+		if b.IsInCheck(b.Pos.Turn.Inverse()) {
+			b.Pop()
+
+			continue
+		}
+
+		numLegals++
+
+		newInfo := info
+		newInfo.Alpha = -info.Beta
+		newInfo.Beta = -info.Alpha
+		newInfo.CurrentDepth = info.CurrentDepth + 1
+
+		score := -b.Quiescence(newInfo)
+
+		b.Pop()
+
+		if score >= info.Beta {
+			return info.Beta
+		}
+
+		if score > info.Alpha {
+			info.Alpha = score
 		}
 	}
 
-	if info.Alpha > -INFINITE_SCORE {
-		return bm, info.Alpha
+	if inCheck && (numLegals <= 0) {
+		return -(MATE_SCORE - info.CurrentDepth)
 	}
 
-	return bm, eval
+	return info.Alpha
+}
+
+// StoreTranspositionEntry stores the result of a normal search node in the transposition table
+// results of interrupted searches and of roots with excluded moves are not stored
+func (b *Board) StoreTranspositionEntry(key uint64, info AlphaBetaInfo, move utils.Move, score int, kind TranspositionKind) {
+	if !b.Searching || (info.CurrentDepth >= info.Depth) {
+		return
+	}
+
+	if (info.CurrentDepth == 0) && (len(b.ExcludedMoves) > 0) {
+		return
+	}
+
+	b.TranspositionTable.Store(key, move, score, kind, info.Depth-info.CurrentDepth, info.CurrentDepth)
+}
+
+// InitTranspositionTable allocates the transposition table if it is missing or the Hash option changed
+func (b *Board) InitTranspositionTable() {
+	hashUciOption := b.GetUciOptionByNameWithDefault("Hash", utils.UciOption{
+		ValueInt: DEFAULT_HASH_SIZE_MB,
+	})
+
+	if (len(b.TranspositionTable.Entries) == 0) || (b.TranspositionTable.SizeMB != hashUciOption.ValueInt) {
+		b.TranspositionTable.Init(hashUciOption.ValueInt)
+	}
 }
 
 func (b *Board) Stop() {
@@ -354,9 +629,9 @@ func (b *Board) Stop() {
 func (b *Board) Go(depth int) (utils.Move, int) {
 	b.StartPerf()
 
-	b.PositionHash = PositionHash{}
+	b.InitTranspositionTable()
 
-	b.PositionHash.Init()
+	b.TranspositionTable.NewSearch()
 
 	bm := utils.Move{}
 
@@ -397,6 +672,10 @@ func (b *Board) Go(depth int) (utils.Move, int) {
 		maxMultipv,
 	))
 
+	b.Killers = [SEARCH_MAX_DEPTH][2]utils.Move{}
+
+	b.AgeHistory()
+
 	for iterDepth := 1; iterDepth <= depth; iterDepth++ {
 		b.ExcludedMoves = []utils.Move{}
 
@@ -411,7 +690,42 @@ func (b *Board) Go(depth int) (utils.Move, int) {
 				CurrentDepth:    0,
 			}
 
-			bm, score = b.AlphaBeta(alphaBetaInfo)
+			// https://www.chessprogramming.org/Aspiration_Windows
+			// the window is centered on the score of the same pv line at the previous depth
+			window := ASPIRATION_WINDOW
+
+			if iterDepth >= ASPIRATION_MIN_DEPTH {
+				prevScore := b.MultipvInfos[multipv-1].Score
+
+				alphaBetaInfo.Alpha = prevScore - window
+				alphaBetaInfo.Beta = prevScore + window
+			}
+
+			for {
+				bm, score = b.AlphaBeta(alphaBetaInfo)
+
+				if !b.Searching {
+					break
+				}
+
+				if (score <= alphaBetaInfo.Alpha) && (alphaBetaInfo.Alpha > -INFINITE_SCORE) {
+					alphaBetaInfo.Alpha -= window
+
+					if alphaBetaInfo.Alpha < -INFINITE_SCORE {
+						alphaBetaInfo.Alpha = -INFINITE_SCORE
+					}
+				} else if (score >= alphaBetaInfo.Beta) && (alphaBetaInfo.Beta < INFINITE_SCORE) {
+					alphaBetaInfo.Beta += window
+
+					if alphaBetaInfo.Beta > INFINITE_SCORE {
+						alphaBetaInfo.Beta = INFINITE_SCORE
+					}
+				} else {
+					break
+				}
+
+				window *= 2
+			}
 
 			if !b.Searching {
 				break
@@ -419,7 +733,7 @@ func (b *Board) Go(depth int) (utils.Move, int) {
 
 			nps, elapsed := b.GetNps()
 
-			bestPv, pvMoves = b.GetPv(iterDepth)
+			bestPv, pvMoves = b.GetPv(bm, iterDepth)
 
 			mpvinfo := MultipvInfo{
 				Depth:    iterDepth,
@@ -436,6 +750,10 @@ func (b *Board) Go(depth int) (utils.Move, int) {
 
 			b.MultipvInfos[multipv-1] = mpvinfo
 
+			if len(pvMoves) == 0 {
+				break
+			}
+
 			b.ExcludedMoves = append(b.ExcludedMoves, pvMoves[0])
 		}
 
@@ -457,7 +775,7 @@ func (b *Board) Go(depth int) (utils.Move, int) {
 	if len(bestPvParts) > 1 {
 		fmt.Println(fmt.Sprintf("bestmove %s ponder %s", bestPvParts[0], bestPvParts[1]))
 	} else if bestPv != "" {
-		fmt.Println(fmt.Sprintf("bestmove %s ponder null", bestPvParts[0]))
+		fmt.Println(fmt.Sprintf("bestmove %s", bestPvParts[0]))
 	} else {
 		fmt.Println(fmt.Sprintf("bestmove null"))
 	}
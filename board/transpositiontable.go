@@ -0,0 +1,121 @@
+package board
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"unsafe"
+
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Init allocates the table with the largest power of two number of entries that fits into sizeMB megabytes
+func (tt *TranspositionTable) Init(sizeMB int) {
+	if sizeMB < 1 {
+		sizeMB = 1
+	}
+
+	numEntries := uint64(sizeMB) << 20 / uint64(unsafe.Sizeof(TranspositionEntry{}))
+
+	for numEntries&(numEntries-1) != 0 {
+		numEntries &= numEntries - 1
+	}
+
+	tt.Entries = make([]TranspositionEntry, numEntries)
+	tt.Mask = numEntries - 1
+	tt.SizeMB = sizeMB
+	tt.Generation = 0
+}
+
+// Clear removes all entries
+func (tt *TranspositionTable) Clear() {
+	for i := range tt.Entries {
+		tt.Entries[i] = TranspositionEntry{}
+	}
+	tt.Generation = 0
+}
+
+// NewSearch marks the entries stored so far as coming from an older search
+func (tt *TranspositionTable) NewSearch() {
+	tt.Generation++
+}
+
+// Probe returns the entry stored for key
+func (tt *TranspositionTable) Probe(key uint64) (TranspositionEntry, bool) {
+	if len(tt.Entries) == 0 {
+		return TranspositionEntry{}, false
+	}
+
+	entry := tt.Entries[key&tt.Mask]
+
+	return entry, entry.Kind != TT_NONE && entry.Key == key
+}
+
+// Store stores the result of a search of depth at ply
+// a deeper entry of the current search is kept if it is for the same position or is exact, so that the pv survives
+func (tt *TranspositionTable) Store(key uint64, move utils.Move, score int, kind TranspositionKind, depth int, ply int) {
+	if len(tt.Entries) == 0 {
+		return
+	}
+
+	entry := &tt.Entries[key&tt.Mask]
+
+	if (entry.Kind != TT_NONE) && (entry.Generation == tt.Generation) && (entry.Depth > depth) {
+		if (entry.Key == key) || ((entry.Kind == TT_EXACT) && (kind != TT_EXACT)) {
+			return
+		}
+	}
+
+	if move == (utils.Move{}) && entry.Key == key {
+		// keep the best move of a previous search of the position
+		move = entry.Move
+	}
+
+	*entry = TranspositionEntry{
+		Key:        key,
+		Move:       move,
+		Score:      scoreToTT(score, ply),
+		Kind:       kind,
+		Depth:      depth,
+		Generation: tt.Generation,
+	}
+}
+
+// ScoreAt returns the score of the entry seen from ply
+func (entry *TranspositionEntry) ScoreAt(ply int) int {
+	return scoreFromTT(entry.Score, ply)
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// scoreToTT converts mate scores counted from the root to mate scores counted from the position at ply
+func scoreToTT(score int, ply int) int {
+	if score > MATE_SCORE-SEARCH_MAX_DEPTH {
+		return score + ply
+	}
+	if score < -(MATE_SCORE - SEARCH_MAX_DEPTH) {
+		return score - ply
+	}
+	return score
+}
+
+// scoreFromTT is the inverse of scoreToTT
+func scoreFromTT(score int, ply int) int {
+	if score > MATE_SCORE-SEARCH_MAX_DEPTH {
+		return score - ply
+	}
+	if score < -(MATE_SCORE - SEARCH_MAX_DEPTH) {
+		return score + ply
+	}
+	return score
+}
+
+/////////////////////////////////////////////////////////////////////
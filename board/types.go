@@ -31,6 +31,9 @@ type ColorCastlingRights [2]CastlingRight
 
 type CastlingRights [2]ColorCastlingRights
 
+// Reserve holds the number of in hand pieces by piece kind
+type Reserve [utils.MAX_PIECE_KINDS]int8
+
 type Pos struct {
 	Rep            BoardRep
 	Turn           utils.PieceColor
@@ -39,6 +42,9 @@ type Pos struct {
 	HalfmoveClock  int
 	FullmoveNumber int
 	DisabledMove   utils.Move
+	Reserve        [2]Reserve
+	GatingFiles    [2]uint8
+	Key            uint64
 }
 
 type MoveStackItem struct {
@@ -49,6 +55,7 @@ type MoveStackItem struct {
 
 type Board struct {
 	Variant                           utils.VariantKey
+	Chess960                          bool
 	NumFiles                          int8
 	LastFile                          int8
 	NumRanks                          int8
@@ -64,11 +71,12 @@ type Board struct {
 	Alphas                            int
 	Betas                             int
 	Searching                         bool
-	PositionHash                      PositionHash
-	TestBoard                         *Board
+	TranspositionTable                TranspositionTable
 	GetUciOptionByNameWithDefaultFunc func(string, utils.UciOption) utils.UciOption
 	MultipvInfos                      MultipvInfos
 	ExcludedMoves                     []utils.Move
+	Killers                           [SEARCH_MAX_DEPTH][2]utils.Move
+	History                           [2][utils.MAX_PIECE_KINDS][MAX_RANKS][MAX_FILES]int
 }
 
 type AlphaBetaInfo struct {
@@ -80,16 +88,25 @@ type AlphaBetaInfo struct {
 	Line            []string
 }
 
-type MoveEntry struct {
-	Eval int
-}
-
-type PositionEntry struct {
-	MoveEntries map[utils.Move]MoveEntry
+// TranspositionKind tells how the score of a transposition entry bounds the score of the position
+type TranspositionKind uint8
+
+// TranspositionEntry is the result of the search of a position
+type TranspositionEntry struct {
+	Key        uint64
+	Move       utils.Move
+	Score      int
+	Depth      int
+	Kind       TranspositionKind
+	Generation uint8
 }
 
-type PositionHash struct {
-	PositionEntries map[Pos]PositionEntry
+// TranspositionTable is a fixed size hash table of search results indexed by Zobrist key
+type TranspositionTable struct {
+	Entries    []TranspositionEntry
+	Mask       uint64
+	SizeMB     int
+	Generation uint8
 }
 
 type MultipvInfo struct {
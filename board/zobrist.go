@@ -0,0 +1,150 @@
+package board
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"math/rand"
+
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Zobrist returns the Zobrist key of the position
+// the key covers pieces, turn, castling rights, en passant square, disabled move, reserve and gating files,
+// but not the move clocks, it is kept up to date by SetPieceAtSquare and Push and restored by Pop
+func (b *Board) Zobrist() uint64 {
+	return b.Pos.Key
+}
+
+// ComputeZobrist computes the Zobrist key of the position from scratch
+func (b *Board) ComputeZobrist() uint64 {
+	key := b.stateZobrist()
+
+	var rank int8
+	var file int8
+	for rank = 0; rank < b.NumRanks; rank++ {
+		for file = 0; file < b.NumFiles; file++ {
+			key ^= pieceZobrist(utils.Square{File: file, Rank: rank}, b.Pos.Rep[rank][file])
+		}
+	}
+
+	return key
+}
+
+// stateZobrist returns the part of the Zobrist key that does not depend on the pieces on the board
+func (b *Board) stateZobrist() uint64 {
+	var key uint64
+
+	if b.Pos.Turn == utils.WHITE {
+		key ^= zobristTurn
+	}
+
+	for color := range b.Pos.CastlingRights {
+		for side, cr := range b.Pos.CastlingRights[color] {
+			if cr.CanCastle {
+				key ^= zobristCastling[color][side][cr.RookOrigSquare.File&(MAX_FILES-1)]
+			}
+		}
+	}
+
+	if b.HasSquare(b.Pos.EpSquare) {
+		key ^= zobristEpSquare[zobristSquareIndex(b.Pos.EpSquare)]
+	}
+
+	if dm := b.Pos.DisabledMove; b.HasSquare(dm.FromSq) && b.HasSquare(dm.ToSq) {
+		key ^= zobristDisabledFrom[zobristSquareIndex(dm.FromSq)] ^ zobristDisabledTo[zobristSquareIndex(dm.ToSq)]
+	}
+
+	for color, reserve := range b.Pos.Reserve {
+		for kind, count := range reserve {
+			if count > 0 {
+				key ^= zobristReserve[color][kind][int(count)&(ZOBRIST_MAX_RESERVE-1)]
+			}
+		}
+	}
+
+	for color, files := range b.Pos.GatingFiles {
+		key ^= zobristGating[color][files]
+	}
+
+	return key
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// pieceZobrist returns the part of the Zobrist key for p standing on sq, zero for no piece
+func pieceZobrist(sq utils.Square, p utils.Piece) uint64 {
+	if p == utils.NO_PIECE {
+		return 0
+	}
+
+	index := zobristSquareIndex(sq)
+
+	key := zobristPiece[p.Kind][p.Color&1][index]
+
+	if p.Direction != (utils.PieceDirection{}) {
+		key ^= zobristDirection[(p.Direction.File+1)*3+p.Direction.Rank+1][index]
+	}
+
+	if p.PushDisabled {
+		key ^= zobristPushDisabled[index]
+	}
+
+	return key
+}
+
+func zobristSquareIndex(sq utils.Square) int {
+	return int(sq.Rank)*MAX_FILES + int(sq.File)
+}
+
+func init() {
+	r := rand.New(rand.NewSource(ZOBRIST_SEED))
+	f := func() uint64 { return uint64(r.Int63())<<32 ^ uint64(r.Int63()) }
+
+	for sq := 0; sq < MAX_RANKS*MAX_FILES; sq++ {
+		for kind := range zobristPiece {
+			zobristPiece[kind][0][sq] = f()
+			zobristPiece[kind][1][sq] = f()
+		}
+
+		for dir := range zobristDirection {
+			zobristDirection[dir][sq] = f()
+		}
+
+		zobristPushDisabled[sq] = f()
+		zobristEpSquare[sq] = f()
+		zobristDisabledFrom[sq] = f()
+		zobristDisabledTo[sq] = f()
+	}
+
+	zobristTurn = f()
+
+	for color := range zobristCastling {
+		for side := range zobristCastling[color] {
+			for file := range zobristCastling[color][side] {
+				zobristCastling[color][side][file] = f()
+			}
+		}
+
+		for kind := range zobristReserve[color] {
+			for count := range zobristReserve[color][kind] {
+				zobristReserve[color][kind][count] = f()
+			}
+		}
+
+		// no gating files hash to zero
+		for files := 1; files < len(zobristGating[color]); files++ {
+			zobristGating[color][files] = f()
+		}
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
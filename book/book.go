@@ -0,0 +1,238 @@
+package book
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"sort"
+
+	"github.com/easychessanimations/gochess/butils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Lookup returns the entries of the position with Polyglot key key
+func (bk *Book) Lookup(key uint64) []Entry {
+	i := sort.Search(len(bk.Entries), func(i int) bool {
+		return bk.Entries[i].Key >= key
+	})
+
+	j := i
+	for j < len(bk.Entries) && bk.Entries[j].Key == key {
+		j++
+	}
+
+	return bk.Entries[i:j]
+}
+
+// Moves returns the legal moves of pos found in the book, a nil book has no moves
+func (bk *Book) Moves(pos *butils.Position) []BookMove {
+	if bk == nil {
+		return nil
+	}
+
+	entries := bk.Lookup(pos.Zobrist())
+	if len(entries) == 0 {
+		return nil
+	}
+
+	bookMoves := []BookMove{}
+
+	legalMoves := pos.LegalMoves()
+
+	for _, entry := range entries {
+		for _, m := range legalMoves {
+			if EncodeMove(pos, m) == entry.Move {
+				bookMoves = append(bookMoves, BookMove{m, entry.Weight})
+				break
+			}
+		}
+	}
+
+	return bookMoves
+}
+
+// Probe returns a book move for pos
+// if best is true the move with the highest weight is returned, otherwise a move is picked at random
+// proportionally to its weight, moves of zero weight are never returned
+func (bk *Book) Probe(pos *butils.Position, best bool) (butils.Move, bool) {
+	total := 0
+	bestIndex := -1
+
+	bookMoves := bk.Moves(pos)
+
+	for i, bm := range bookMoves {
+		total += int(bm.Weight)
+		if bm.Weight > 0 && (bestIndex < 0 || bm.Weight > bookMoves[bestIndex].Weight) {
+			bestIndex = i
+		}
+	}
+
+	if total == 0 {
+		return butils.NullMove, false
+	}
+
+	if best {
+		return bookMoves[bestIndex].Move, true
+	}
+
+	r := rand.Intn(total)
+
+	for _, bm := range bookMoves {
+		if r < int(bm.Weight) {
+			return bm.Move, true
+		}
+		r -= int(bm.Weight)
+	}
+
+	return butils.NullMove, false
+}
+
+// Sort sorts the entries by key, then by descending weight
+func (bk *Book) Sort() {
+	sort.SliceStable(bk.Entries, func(i, j int) bool {
+		if bk.Entries[i].Key != bk.Entries[j].Key {
+			return bk.Entries[i].Key < bk.Entries[j].Key
+		}
+		return bk.Entries[i].Weight > bk.Entries[j].Weight
+	})
+}
+
+// Write writes the book in Polyglot format
+func (bk *Book) Write(w io.Writer) error {
+	buff := make([]byte, ENTRY_SIZE*len(bk.Entries))
+
+	for i, entry := range bk.Entries {
+		b := buff[i*ENTRY_SIZE:]
+		binary.BigEndian.PutUint64(b[0:], entry.Key)
+		binary.BigEndian.PutUint16(b[8:], entry.Move)
+		binary.BigEndian.PutUint16(b[10:], entry.Weight)
+		binary.BigEndian.PutUint32(b[12:], entry.Learn)
+	}
+
+	_, err := w.Write(buff)
+	return err
+}
+
+// Save writes the book to the file at path
+func (bk *Book) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := bk.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Load returns the book at path, nil for an empty path
+// loading the same path again returns the book loaded before without reading the file
+func (l *Loader) Load(path string) (*Book, error) {
+	if path == l.path && (l.book != nil || path == "") {
+		return l.book, nil
+	}
+
+	l.path, l.book = "", nil
+
+	if path == "" {
+		return nil, nil
+	}
+
+	bk, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	l.path, l.book = path, bk
+
+	return bk, nil
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// Read reads a book in Polyglot format
+// the entries are sorted if the book is not sorted already
+func Read(r io.Reader) (*Book, error) {
+	buff, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(buff)%ENTRY_SIZE != 0 {
+		return nil, fmt.Errorf("book size %d is not a multiple of %d", len(buff), ENTRY_SIZE)
+	}
+
+	bk := &Book{Entries: make([]Entry, len(buff)/ENTRY_SIZE)}
+
+	sorted := true
+
+	for i := range bk.Entries {
+		b := buff[i*ENTRY_SIZE:]
+		bk.Entries[i] = Entry{
+			Key:    binary.BigEndian.Uint64(b[0:]),
+			Move:   binary.BigEndian.Uint16(b[8:]),
+			Weight: binary.BigEndian.Uint16(b[10:]),
+			Learn:  binary.BigEndian.Uint32(b[12:]),
+		}
+		if i > 0 && bk.Entries[i].Key < bk.Entries[i-1].Key {
+			sorted = false
+		}
+	}
+
+	if !sorted {
+		bk.Sort()
+	}
+
+	return bk, nil
+}
+
+// Open reads the Polyglot book at path
+func Open(path string) (*Book, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(f)
+}
+
+// EncodeMove returns m made in pos in Polyglot move encoding
+// castling is encoded as king takes rook
+func EncodeMove(pos *butils.Position, m butils.Move) uint16 {
+	from, to := m.From(), m.To()
+
+	if m.MoveType() == butils.Castling {
+		to, _ = pos.CastlingRookSquares(m)
+	}
+
+	encoded := uint16(to.File()) | uint16(to.Rank())<<3 | uint16(from.File())<<6 | uint16(from.Rank())<<9
+
+	if m.MoveType() == butils.Promotion {
+		for i, fig := range PROMOTION_FIGURES {
+			if m.Target().Figure() == fig {
+				encoded |= uint16(i+1) << 12
+			}
+		}
+	}
+
+	return encoded
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,204 @@
+package book
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/pgn"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+// test keys from http://hgm.nubati.net/book_format.html
+var POLYGLOT_TEST_KEYS = []struct {
+	moves string
+	key   uint64
+}{
+	{"", 0x463b96181691fc9c},
+	{"e2e4", 0x823c9b50fd114196},
+	{"e2e4 d7d5", 0x0756b94461c50fb0},
+	{"e2e4 d7d5 e4e5", 0x662fafb965db29d4},
+	{"e2e4 d7d5 e4e5 f7f5", 0x22a48b5a8e47ff78},
+	{"e2e4 d7d5 e4e5 f7f5 e1e2", 0x652a607ca3f242c1},
+	{"e2e4 d7d5 e4e5 f7f5 e1e2 e8f7", 0x00fdd303c946bdd9},
+	{"a2a4 b7b5 h2h4 b5b4 c2c4", 0x3c8123ea7b067637},
+	{"a2a4 b7b5 h2h4 b5b4 c2c4 b4c3 a1a3", 0x5c3f9b829b279560},
+}
+
+const TEST_BOOK_PGN = `[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 1-0
+
+[Result "1-0"]
+
+1. e4 c5 2. Nf3 d6 1-0
+
+[Result "1/2-1/2"]
+
+1. d4 d5 2. c4 1/2-1/2
+
+[Result "0-1"]
+
+1. e4 e5 2. f4 0-1
+`
+
+func newStandardPosition(t *testing.T) *butils.Position {
+	pos, err := butils.PositionFromFENAndVariant(utils.STANDARD_START_FEN, utils.VARIANT_STANDARD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pos
+}
+
+func TestPolyglotKeys(t *testing.T) {
+	for _, test := range POLYGLOT_TEST_KEYS {
+		pos := newStandardPosition(t)
+
+		for _, uci := range strings.Fields(test.moves) {
+			move, err := pos.UCIToMove(uci)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pos.DoMove(move)
+		}
+
+		if key := pos.Zobrist(); key != test.key {
+			t.Errorf("moves %q gave key %016x, expected %016x", test.moves, key, test.key)
+		}
+
+		fenPos, err := butils.PositionFromFENAndVariant(pos.String(), utils.VARIANT_STANDARD)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key := fenPos.Zobrist(); key != test.key {
+			t.Errorf("fen %s gave key %016x, expected %016x", pos.String(), key, test.key)
+		}
+	}
+}
+
+func TestBuildAndProbe(t *testing.T) {
+	games, err := pgn.ParseString(TEST_BOOK_PGN)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	built, err := FromGames(games, DEFAULT_MAX_PLY)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buff bytes.Buffer
+
+	if err := built.Write(&buff); err != nil {
+		t.Fatal(err)
+	}
+
+	bk, err := Read(&buff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bk.Entries) != len(built.Entries) {
+		t.Fatalf("read %d entries, expected %d", len(bk.Entries), len(built.Entries))
+	}
+
+	pos := newStandardPosition(t)
+
+	// e4 won twice and lost once for white, d4 was drawn once
+	bookMoves := bk.Moves(pos)
+	if len(bookMoves) != 2 || bookMoves[0].Move.UCI() != "e2e4" || bookMoves[0].Weight != 2*WIN_WEIGHT || bookMoves[1].Weight != DRAW_WEIGHT {
+		t.Errorf("wrong book moves %v", bookMoves)
+	}
+
+	if move, ok := bk.Probe(pos, true); !ok || move.UCI() != "e2e4" {
+		t.Errorf("best move %v %v, expected e2e4", move, ok)
+	}
+
+	e4, _ := pos.UCIToMove("e2e4")
+	pos.DoMove(e4)
+
+	// e5 only won in the lost game of white
+	if move, ok := bk.Probe(pos, false); !ok || move.UCI() != "e7e5" {
+		t.Errorf("random move %v %v, expected e7e5", move, ok)
+	}
+
+	for _, uci := range []string{"e7e5", "g1f3", "b8c6"} {
+		move, _ := pos.UCIToMove(uci)
+		pos.DoMove(move)
+	}
+
+	if move, ok := bk.Probe(pos, false); !ok || move.UCI() != "f1b5" {
+		t.Errorf("random move %v %v, expected f1b5", move, ok)
+	}
+
+	e2, _ := pos.UCIToMove("e1e2")
+	pos.DoMove(e2)
+
+	if move, ok := bk.Probe(pos, false); ok {
+		t.Errorf("unexpected book move %v", move)
+	}
+}
+
+func TestLoader(t *testing.T) {
+	games, err := pgn.ParseString(TEST_BOOK_PGN)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	built, err := FromGames(games, DEFAULT_MAX_PLY)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "book.bin")
+	if err := built.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	var loader Loader
+
+	bk, err := loader.Load(path)
+	if err != nil || bk == nil || len(bk.Entries) != len(built.Entries) {
+		t.Fatalf("loaded %v %v, expected %d entries", bk, err, len(built.Entries))
+	}
+
+	// the same path is not read again
+	if again, err := loader.Load(path); err != nil || again != bk {
+		t.Errorf("loading %s again returned another book %v %v", path, again, err)
+	}
+
+	if bk, err := loader.Load(""); err != nil || bk != nil {
+		t.Errorf("empty path loaded %v %v", bk, err)
+	}
+
+	if _, err := loader.Load(filepath.Join(t.TempDir(), "missing.bin")); err == nil {
+		t.Errorf("missing book loaded")
+	}
+
+	// a nil book has no moves
+	var none *Book
+	if move, ok := none.Probe(newStandardPosition(t), true); ok {
+		t.Errorf("nil book returned %v", move)
+	}
+}
+
+func TestEncodeCastling(t *testing.T) {
+	pos, err := butils.PositionFromFENAndVariant("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", utils.VARIANT_STANDARD)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for uci, expected := range map[string]uint16{"e1g1": 0x107, "e1c1": 0x100} {
+		move, err := pos.UCIToMove(uci)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if encoded := EncodeMove(pos, move); encoded != expected {
+			t.Errorf("%s encoded as %x, expected %x", uci, encoded, expected)
+		}
+	}
+}
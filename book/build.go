@@ -0,0 +1,113 @@
+package book
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/pgn"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// FromGames builds a book from the first maxPly plies of the main lines of games
+// a move is weighted WIN_WEIGHT for each game won by the side that played it
+// and DRAW_WEIGHT for each drawn or unfinished game, moves of lost games are left out
+// games of variants other than standard chess are skipped
+func FromGames(games []*pgn.Game, maxPly int) (*Book, error) {
+	weights := map[moveKey]int{}
+	keys := []moveKey{}
+
+	for i, g := range games {
+		variant, chess960, err := g.Variant()
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+
+		if variant != utils.VARIANT_STANDARD {
+			continue
+		}
+
+		fen, err := g.StartFen()
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+
+		pos, err := butils.PositionFromFENAndVariant(fen, variant)
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+		pos.Chess960 = chess960
+
+		for ply, m := range g.Moves {
+			if ply >= maxPly {
+				break
+			}
+
+			move, err := pos.SanToMove(m.San)
+			if err != nil {
+				return nil, fmt.Errorf("game %d ply %d: %w", i+1, ply+1, err)
+			}
+
+			if weight := resultWeight(g.Result, pos.Us()); weight > 0 {
+				mk := moveKey{pos.Zobrist(), EncodeMove(pos, move)}
+				if _, ok := weights[mk]; !ok {
+					keys = append(keys, mk)
+				}
+				weights[mk] += weight
+			}
+
+			pos.DoMove(move)
+		}
+	}
+
+	bk := &Book{}
+
+	// scale down the weights of positions that exceed MAX_WEIGHT
+	maxWeights := map[uint64]int{}
+	for _, mk := range keys {
+		if weights[mk] > maxWeights[mk.key] {
+			maxWeights[mk.key] = weights[mk]
+		}
+	}
+
+	for _, mk := range keys {
+		weight := weights[mk]
+		if maxWeight := maxWeights[mk.key]; maxWeight > MAX_WEIGHT {
+			weight = weight * MAX_WEIGHT / maxWeight
+			if weight == 0 {
+				weight = 1
+			}
+		}
+		bk.Entries = append(bk.Entries, Entry{Key: mk.key, Move: mk.move, Weight: uint16(weight)})
+	}
+
+	bk.Sort()
+
+	return bk, nil
+}
+
+// resultWeight returns the weight of a move played by col in a game with result
+func resultWeight(result string, col butils.Color) int {
+	switch result {
+	case "1-0":
+		if col == butils.White {
+			return WIN_WEIGHT
+		}
+		return 0
+	case "0-1":
+		if col == butils.Black {
+			return WIN_WEIGHT
+		}
+		return 0
+	}
+	return DRAW_WEIGHT
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,38 @@
+package book
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"github.com/easychessanimations/gochess/butils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// constants
+
+// ENTRY_SIZE is the size of an encoded book entry in bytes
+const ENTRY_SIZE = 16
+
+// MAX_WEIGHT is the largest weight an entry can hold
+const MAX_WEIGHT = 0xffff
+
+// DEFAULT_MAX_PLY is the number of plies of each game added to a book by default
+const DEFAULT_MAX_PLY = 24
+
+// weights a move gets for each game in which it was played
+const (
+	WIN_WEIGHT  = 2
+	DRAW_WEIGHT = 1
+)
+
+// promotion pieces in Polyglot move encoding, starting at 1
+var PROMOTION_FIGURES = []butils.Figure{
+	butils.Knight,
+	butils.Bishop,
+	butils.Rook,
+	butils.Queen,
+}
+
+/////////////////////////////////////////////////////////////////////
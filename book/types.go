@@ -0,0 +1,47 @@
+package book
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"github.com/easychessanimations/gochess/butils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// types
+
+// Entry is an entry of a Polyglot book
+type Entry struct {
+	Key    uint64 // Polyglot key of the position
+	Move   uint16 // move in Polyglot encoding
+	Weight uint16 // relative weight of the move
+	Learn  uint32 // learning data, unused
+}
+
+// Book is a Polyglot opening book
+// entries are sorted by key, then by descending weight
+type Book struct {
+	Entries []Entry
+}
+
+// Loader loads books from files, it keeps the book loaded last
+type Loader struct {
+	path string
+	book *Book
+}
+
+// BookMove is a legal move of a position found in the book
+type BookMove struct {
+	Move   butils.Move
+	Weight uint16
+}
+
+// moveKey identifies a move of a position while building a book
+type moveKey struct {
+	key  uint64
+	move uint16
+}
+
+/////////////////////////////////////////////////////////////////////
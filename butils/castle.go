@@ -5,6 +5,8 @@ package butils
 
 import (
 	"fmt"
+	"strings"
+	"unicode"
 )
 
 /////////////////////////////////////////////////////////////////////
@@ -19,4 +21,225 @@ func (c Castle) String() string {
 	return castleToString[c]
 }
 
+// https://en.wikipedia.org/wiki/Fischer_random_chess#Castling_rules
+
+// initCastlingSquares sets the standard king and rook origin squares
+func (pos *Position) initCastlingSquares() {
+	for col := ColorMinValue; col <= ColorMaxValue; col++ {
+		rank := HomeRank(col)
+		pos.castlingKingSquare[col] = RankFile(rank, 4)
+		pos.castlingRookSquare[col][QueenSide] = RankFile(rank, 0)
+		pos.castlingRookSquare[col][KingSide] = RankFile(rank, 7)
+	}
+	pos.updateLostCastleRights()
+}
+
+// updateLostCastleRights recalculates which castle rights are lost when pieces are moved
+func (pos *Position) updateLostCastleRights() {
+	pos.lostCastleRights = [SquareArraySize]Castle{}
+	for col := ColorMinValue; col <= ColorMaxValue; col++ {
+		for side := QueenSide; side <= KingSide; side++ {
+			castle := CastleFor(col, side)
+			pos.lostCastleRights[pos.castlingKingSquare[col]] |= castle
+			pos.lostCastleRights[pos.castlingRookSquare[col][side]] |= castle
+		}
+	}
+}
+
+// isCastlingPiece tells whether pi can castle for col
+func isCastlingPiece(pi Piece, col Color) bool {
+	return pi.Color() == col && (pi.Figure() == Rook || pi.Figure() == Jailer)
+}
+
+// outermostCastlingPiece returns the square of the castling piece of col farthest from king on side
+func (pos *Position) outermostCastlingPiece(col Color, king Square, side int) (Square, bool) {
+	rank := HomeRank(col)
+	if side == KingSide {
+		for f := 7; f > king.File(); f-- {
+			if sq := RankFile(rank, f); isCastlingPiece(pos.Get(sq), col) {
+				return sq, true
+			}
+		}
+	} else {
+		for f := 0; f < king.File(); f++ {
+			if sq := RankFile(rank, f); isCastlingPiece(pos.Get(sq), col) {
+				return sq, true
+			}
+		}
+	}
+	return SquareA1, false
+}
+
+// CastlingRookSquares returns the origin and target squares of the rook for castling move m
+func (pos *Position) CastlingRookSquares(m Move) (Square, Square) {
+	col, side := m.Color(), CastlingSideOf(m.To())
+	return pos.castlingRookSquare[col][side], CastlingRookTarget(col, side)
+}
+
+// isCastlingFree tells whether col can castle on side
+// squares crossed by king and rook should be empty and squares crossed by the king should not be attacked
+// the move can still leave the king in check by removing the rook from the home rank
+func (pos *Position) isCastlingFree(col Color, side int) bool {
+	king, rook := pos.castlingKingSquare[col], pos.castlingRookSquare[col][side]
+	if pos.Get(king) != ColorFigure(col, King) || !isCastlingPiece(pos.Get(rook), col) {
+		return false
+	}
+	kingPath := bbRankSegment(king, CastlingKingTarget(col, side))
+	rookPath := bbRankSegment(rook, CastlingRookTarget(col, side))
+	all := pos.ByColor(White) | pos.ByColor(Black)
+	if (kingPath|rookPath)&all&^king.Bitboard()&^rook.Bitboard() != BbEmpty {
+		return false
+	}
+	for bb := kingPath; bb != 0; {
+		if pos.GetAttacker(bb.Pop(), col.Opposite()) != NoFigure {
+			return false
+		}
+	}
+	return true
+}
+
+// castlingLetter returns the fen letter of the castling right of col on side
+// file letters are used in chess960 ( Shredder-FEN ) or if the rook is not the outermost one ( X-FEN )
+func (pos *Position) castlingLetter(col Color, side int) string {
+	rook := pos.castlingRookSquare[col][side]
+	letter := "Q"
+	if side == KingSide {
+		letter = "K"
+	}
+	if outermost, ok := pos.outermostCastlingPiece(col, pos.castlingKingSquare[col], side); pos.Chess960 || !ok || outermost != rook {
+		letter = strings.ToUpper(rook.String()[0:1])
+	}
+	if col == Black {
+		letter = strings.ToLower(letter)
+	}
+	return letter
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// CastleFor returns the castle right of col on side
+func CastleFor(col Color, side int) Castle {
+	if col == White {
+		if side == KingSide {
+			return WhiteOO
+		}
+		return WhiteOOO
+	}
+	if side == KingSide {
+		return BlackOO
+	}
+	return BlackOOO
+}
+
+// CastlingSideOf returns the castling side given the target square of the king
+func CastlingSideOf(kingTarget Square) int {
+	if kingTarget.File() < 4 {
+		return QueenSide
+	}
+	return KingSide
+}
+
+// CastlingKingTarget returns the square of the king after castling
+func CastlingKingTarget(col Color, side int) Square {
+	if side == KingSide {
+		return RankFile(HomeRank(col), 6)
+	}
+	return RankFile(HomeRank(col), 2)
+}
+
+// CastlingRookTarget returns the square of the rook after castling
+func CastlingRookTarget(col Color, side int) Square {
+	if side == KingSide {
+		return RankFile(HomeRank(col), 5)
+	}
+	return RankFile(HomeRank(col), 3)
+}
+
+// bbRankSegment returns the squares between a and b on the same rank, both included
+func bbRankSegment(a, b Square) Bitboard {
+	if a > b {
+		a, b = b, a
+	}
+	return (BbFull << uint(a)) & (BbFull >> uint(63-b))
+}
+
+// ParseCastlingAbility sets castling ability for pos from str
+// accepts KQkq with the outermost rook, X-FEN and Shredder-FEN file letters
+func ParseCastlingAbility(str string, pos *Position) error {
+	pos.initCastlingSquares()
+
+	if str == "-" {
+		pos.SetCastlingAbility(NoCastle)
+		return nil
+	}
+
+	ability := NoCastle
+	for _, p := range str {
+		col := White
+		if unicode.IsLower(p) {
+			col = Black
+		}
+
+		if pos.ByPiece(col, King) == BbEmpty {
+			return fmt.Errorf("invalid castling ability %s, no king", str)
+		}
+		king := pos.WhereIsKing(col)
+		if king.Rank() != HomeRank(col) {
+			return fmt.Errorf("invalid castling ability %s, king not on home rank", str)
+		}
+
+		var side int
+		var rook Square
+		switch upper := unicode.ToUpper(p); {
+		case upper == 'K' || upper == 'Q':
+			side = QueenSide
+			if upper == 'K' {
+				side = KingSide
+			}
+			var ok bool
+			if rook, ok = pos.outermostCastlingPiece(col, king, side); !ok {
+				return fmt.Errorf("invalid castling ability %s, no rook for %c", str, p)
+			}
+		case 'A' <= upper && upper <= 'H':
+			rook = RankFile(HomeRank(col), int(upper-'A'))
+			side = QueenSide
+			if rook.File() > king.File() {
+				side = KingSide
+			}
+			if !isCastlingPiece(pos.Get(rook), col) {
+				return fmt.Errorf("expected rook at %v, got %v", rook, pos.Get(rook))
+			}
+		default:
+			return fmt.Errorf("invalid castling ability %s", str)
+		}
+
+		ability |= CastleFor(col, side)
+		pos.castlingKingSquare[col] = king
+		pos.castlingRookSquare[col][side] = rook
+	}
+	pos.updateLostCastleRights()
+	pos.SetCastlingAbility(ability)
+	return nil
+}
+
+// FormatCastlingAbility returns a string specifying the castling ability
+// KQkq if possible, otherwise file letters
+func FormatCastlingAbility(pos *Position) string {
+	s := ""
+	for _, col := range []Color{White, Black} {
+		for _, side := range []int{KingSide, QueenSide} {
+			if pos.CastlingAbility()&CastleFor(col, side) != 0 {
+				s += pos.castlingLetter(col, side)
+			}
+		}
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 /////////////////////////////////////////////////////////////////////
@@ -3,6 +3,10 @@ package butils
 /////////////////////////////////////////////////////////////////////
 // imports
 
+import (
+	"github.com/easychessanimations/gochess/utils"
+)
+
 /////////////////////////////////////////////////////////////////////
 
 /////////////////////////////////////////////////////////////////////
@@ -70,6 +74,45 @@ var PROMOTION_FIGURES_VIOLENT = []Figure{
 	LancerNW, // 15
 }
 
+var STANDARD_PROMOTION_FIGURES_QUIET = []Figure{
+	Knight, // 2
+	Bishop, // 3
+	Rook,   // 4
+}
+
+var STANDARD_PROMOTION_FIGURES_VIOLENT = []Figure{
+	Queen, // 5
+}
+
+var STANDARD_FIGURES = []Figure{Pawn, Knight, Bishop, Rook, Queen, King}
+
+var EIGHTPIECE_FIGURES = []Figure{Pawn, Knight, Bishop, Rook, Queen, King, Lancer, Sentry, Jailer}
+
+// VARIANT_DESCRIPTORS lists the variants Position can play
+var VARIANT_DESCRIPTORS = map[utils.VariantKey]*VariantDescriptor{
+	utils.VARIANT_STANDARD: {
+		Key:                     utils.VARIANT_STANDARD,
+		Figures:                 STANDARD_FIGURES,
+		PromotionFiguresQuiet:   STANDARD_PROMOTION_FIGURES_QUIET,
+		PromotionFiguresViolent: STANDARD_PROMOTION_FIGURES_VIOLENT,
+	},
+	utils.VARIANT_ATOMIC: {
+		Key:                     utils.VARIANT_ATOMIC,
+		Figures:                 STANDARD_FIGURES,
+		PromotionFiguresQuiet:   STANDARD_PROMOTION_FIGURES_QUIET,
+		PromotionFiguresViolent: STANDARD_PROMOTION_FIGURES_VIOLENT,
+		Atomic:                  true,
+	},
+	utils.VARIANT_EIGHTPIECE: {
+		Key:                     utils.VARIANT_EIGHTPIECE,
+		Figures:                 EIGHTPIECE_FIGURES,
+		PromotionFiguresQuiet:   PROMOTION_FIGURES_QUIET,
+		PromotionFiguresViolent: PROMOTION_FIGURES_VIOLENT,
+		KingPass:                true,
+		DisabledMoveField:       true,
+	},
+}
+
 var FigureToSymbol = []string{
 	".",   // 0
 	"p",   // 1
@@ -294,6 +337,12 @@ const (
 	CastleMaxValue  = AnyCastle
 )
 
+const (
+	// castling sides, used as index of castling rook squares
+	QueenSide = iota
+	KingSide
+)
+
 var castleToString = [...]string{
 	"-", "K", "Q", "KQ", "k", "Kk", "Qk", "KQk", "q", "Kq", "Qq", "KQq", "kq", "Kkq", "Qkq", "KQkq",
 }
@@ -312,16 +361,6 @@ var (
 	//FENStartPos = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
 	FENStartPos = "jlsesqkbnr/pppppppp/8/8/8/8/PPPPPPPP/JLneSQKBNR w KQkq - 0 1 -"
 
-	// which castle rights are lost when pieces are moved
-	lostCastleRights = [64]Castle{
-		SquareA1: WhiteOOO,
-		SquareE1: WhiteOOO | WhiteOO,
-		SquareH1: WhiteOO,
-		SquareA8: BlackOOO,
-		SquareE8: BlackOOO | BlackOO,
-		SquareH8: BlackOO,
-	}
-
 	// the zobrist* arrays contain magic numbers used for Zobrist hashing
 	// more information on Zobrist hashing can be found in the paper:
 	// http://research.cs.wisc.edu/techreports/1970/TR88.pdf
@@ -329,6 +368,9 @@ var (
 	zobristEnpassant [SquareArraySize]uint64
 	zobristCastle    [CastleArraySize]uint64
 	zobristColor     [ColorArraySize]uint64
+	// keys of the from and to squares of the disabled move after a sentry push
+	zobristDisableFrom [SquareArraySize]uint64
+	zobristDisableTo   [SquareArraySize]uint64
 
 	//prettyPieceToSymbol = []string{".", "?", "♟", "♙", "♞", "♘", "♝", "♗", "♜", "♖", "♛", "♕", "♚", "♔"}
 )
@@ -342,29 +384,6 @@ var (
 		"w": White,
 		"b": Black,
 	}
-
-	symbolToCastleInfo = map[rune]castleInfo{
-		'K': {
-			Castle: WhiteOO,
-			Piece:  [2]Piece{WhiteKing, WhiteRook},
-			Square: [2]Square{SquareE1, SquareH1},
-		},
-		'k': {
-			Castle: BlackOO,
-			Piece:  [2]Piece{BlackKing, BlackRook},
-			Square: [2]Square{SquareE8, SquareH8},
-		},
-		'Q': {
-			Castle: WhiteOOO,
-			Piece:  [2]Piece{WhiteKing, WhiteJailer},
-			Square: [2]Square{SquareE1, SquareA1},
-		},
-		'q': {
-			Castle: BlackOOO,
-			Piece:  [2]Piece{BlackKing, BlackJailer},
-			Square: [2]Square{SquareE8, SquareA8},
-		},
-	}
 )
 
 var (
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/easychessanimations/gochess/utils"
 )
 
 /////////////////////////////////////////////////////////////////////
@@ -109,43 +111,48 @@ func SquareFromString(s string) (Square, error) {
 	return RankFile(r, f), nil
 }
 
-// CastlingRook returns the rook moved during castling
-// together with starting and stopping squares
-func CastlingRook(kingEnd Square) (Piece, Square, Square) {
-	// explanation how rookStart works for king on E1
-	// if kingEnd == C1 == b010, then rookStart == A1 == b000
-	// if kingEnd == G1 == b110, then rookStart == H1 == b111
-	// so bit 3 will set bit 2 and bit 1
-	//
-	// explanation how rookEnd works for king on E1
-	// if kingEnd == C1 == b010, then rookEnd == D1 == b011
-	// if kingEnd == G1 == b110, then rookEnd == F1 == b101
-	// so bit 3 will invert bit 2, bit 1 is always set
-	fig := Rook
-	if kingEnd.File() < 4 {
-		fig = Jailer
-	}
-	piece := Piece(fig<<1) + (1 - Piece(kingEnd>>5))
-	rookStart := kingEnd&^3 | (kingEnd & 4 >> 1) | (kingEnd & 4 >> 2)
-	rookEnd := kingEnd ^ (kingEnd & 4 >> 1) | 1
-	return piece, rookStart, rookEnd
-}
-
 // NewPosition returns a new position representing an empty board
 func NewPosition() *Position {
 	pos := &Position{
 		fullmoveCounter: 1,
 		states:          make([]state, 1, 4),
+		Variant:         VARIANT_DESCRIPTORS[utils.VARIANT_STANDARD],
 	}
 	pos.curr = &pos.states[pos.Ply]
+	pos.initCastlingSquares()
 	return pos
 }
 
+// GetVariantDescriptor returns the descriptor of variant, or an error if Position cannot play it
+func GetVariantDescriptor(variant utils.VariantKey) (*VariantDescriptor, error) {
+	vd, ok := VARIANT_DESCRIPTORS[variant]
+	if !ok {
+		return nil, fmt.Errorf("unsupported variant %s", utils.VariantKeyToVariantKeyString(variant))
+	}
+	return vd, nil
+}
+
+// PositionFromFENAndVariant parses fen and returns the position played by the rules of variant
+func PositionFromFENAndVariant(fen string, variant utils.VariantKey) (*Position, error) {
+	vd, err := GetVariantDescriptor(variant)
+	if err != nil {
+		return nil, err
+	}
+	return positionFromFEN(fen, vd)
+}
+
 // PositionFromFEN parses fen and returns the position
+// the variant is inferred from the fen, eightpiece if it has eightpiece figures
+// or a disabled move field, standard otherwise
 //
 // fen must contain the position using Forsyth–Edwards Notation
 // http://en.wikipedia.org/wiki/Forsyth%E2%80%93Edwards_Notation
 func PositionFromFEN(fen string) (*Position, error) {
+	return positionFromFEN(fen, nil)
+}
+
+// positionFromFEN parses fen by the rules of vd, infers the variant if vd is nil
+func positionFromFEN(fen string, vd *VariantDescriptor) (*Position, error) {
 	// split fen into 7 fields
 	// same as string.Fields() but creates much less garbage
 	// the optimization is important when a huge number of positions
@@ -178,6 +185,12 @@ func PositionFromFEN(fen string) (*Position, error) {
 	if err := ParsePiecePlacement(f[0], pos); err != nil {
 		return nil, err
 	}
+	if vd == nil {
+		vd = inferVariant(pos, p)
+	}
+	if err := pos.SetVariantDescriptor(vd); err != nil {
+		return nil, err
+	}
 	if err := ParseSideToMove(f[1], pos); err != nil {
 		return nil, err
 	}
@@ -202,21 +215,22 @@ func PositionFromFEN(fen string) (*Position, error) {
 			return nil, err
 		}
 		if p == 7 {
+			if f[6] != "-" && !vd.DisabledMoveField {
+				return nil, fmt.Errorf("disabled move %s not allowed in %s", f[6], utils.VariantKeyToVariantKeyString(vd.Key))
+			}
 			if f[6] != "-" {
 				if len(f[6]) < 4 {
 					return nil, fmt.Errorf("invalid disabled move %s", f[6])
 				}
-				sq, err := SquareFromString(f[6][0:2])
+				from, err := SquareFromString(f[6][0:2])
 				if err != nil {
 					return nil, err
 				}
-				pos.curr.DisableFromSquare = sq
-				sq, err = SquareFromString(f[6][2:4])
+				to, err := SquareFromString(f[6][2:4])
 				if err != nil {
 					return nil, err
 				}
-				pos.curr.DisableToSquare = sq
-				pos.curr.HasDisabledMove = true
+				pos.SetDisabledMove(from, to)
 			}
 		}
 	}
@@ -230,6 +244,19 @@ func PositionFromFEN(fen string) (*Position, error) {
 	return pos, nil
 }
 
+// inferVariant guesses the variant of a position parsed from a fen with numFields fields
+func inferVariant(pos *Position, numFields int) *VariantDescriptor {
+	if numFields == 7 {
+		return VARIANT_DESCRIPTORS[utils.VARIANT_EIGHTPIECE]
+	}
+	for sq := SquareMinValue; sq <= SquareMaxValue; sq++ {
+		if fig := pos.Get(sq).Figure(); fig != NoFigure && !VARIANT_DESCRIPTORS[utils.VARIANT_STANDARD].HasFigure(fig) {
+			return VARIANT_DESCRIPTORS[utils.VARIANT_EIGHTPIECE]
+		}
+	}
+	return VARIANT_DESCRIPTORS[utils.VARIANT_STANDARD]
+}
+
 // ParsePiecePlacement parse pieces from str (FEN like) into pos
 func ParsePiecePlacement(str string, pos *Position) error {
 	r, f := 0, 0
@@ -422,43 +449,6 @@ func FormatSideToMove(pos *Position) string {
 	return colorToSymbol[pos.Us():][:1]
 }
 
-// ParseCastlingAbility sets castling ability for pos from str
-func ParseCastlingAbility(str string, pos *Position) error {
-	if str == "-" {
-		pos.SetCastlingAbility(NoCastle)
-		return nil
-	}
-
-	ability := NoCastle
-	for _, p := range str {
-		info, ok := symbolToCastleInfo[p]
-		if !ok {
-			return fmt.Errorf("invalid castling ability %s", str)
-		}
-		ability |= info.Castle
-		for i := 0; i < 2; i++ {
-			testP := pos.Get(info.Square[i])
-			if testP.Figure() == Jailer {
-				// temporarily fake jailer as castling piece, so that test passes
-				// TODO: allow castling with jailer in a proper way
-				info.Piece[i] = testP
-			}
-			if info.Piece[i] != testP {
-				return fmt.Errorf("expected %v at %v, got %v",
-					info.Piece[i], info.Square[i], pos.Get(info.Square[i]))
-			}
-		}
-	}
-	pos.SetCastlingAbility(ability)
-	return nil
-}
-
-// FormatCastlingAbility returns a string specifying the castling ability
-// using standard FEN format
-func FormatCastlingAbility(pos *Position) string {
-	return pos.CastlingAbility().String()
-}
-
 // Pawns return the set of pawns of the given color
 func Pawns(pos *Position, us Color) Bitboard {
 	return pos.ByPiece(us, Pawn)
@@ -484,6 +474,25 @@ func Queens(pos *Position, us Color) Bitboard {
 	return pos.ByPiece(us, Queen)
 }
 
+// Lancers return the set of lancers of the given color, in any direction
+func Lancers(pos *Position, us Color) Bitboard {
+	bb := BbEmpty
+	for ld := 0; ld < NUM_LANCER_DIRECTIONS; ld++ {
+		bb |= pos.ByPiece(us, BaseLancerFigure+Figure(ld))
+	}
+	return bb
+}
+
+// Sentries return the set of sentries of the given color
+func Sentries(pos *Position, us Color) Bitboard {
+	return pos.ByPiece(us, Sentry)
+}
+
+// Jailers return the set of jailers of the given color
+func Jailers(pos *Position, us Color) Bitboard {
+	return pos.ByPiece(us, Jailer)
+}
+
 // Kings return the set of kings of the given color
 // normally there is exactly on king for each side
 func Kings(pos *Position, us Color) Bitboard {
@@ -623,6 +632,12 @@ func init() {
 		symbol := FigureToSymbol[i]
 		SymbolToFigureMap[symbol] = Figure(i)
 	}
+
+	for _, vd := range VARIANT_DESCRIPTORS {
+		for _, fig := range vd.Figures {
+			vd.hasFigure[fig] = true
+		}
+	}
 }
 
 /////////////////////////////////////////////////////////////////////
@@ -8,7 +8,10 @@ import (
 	"math/rand"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/easychessanimations/gochess/utils"
 )
 
 /////////////////////////////////////////////////////////////////////
@@ -25,6 +28,43 @@ func (pos *Position) FormatDisabledMove() string {
 	return pos.curr.DisableFromSquare.String() + pos.curr.DisableToSquare.String()
 }
 
+// DisabledMove returns the from and to squares of the disabled move, ok is false if there is none
+func (pos *Position) DisabledMove() (from, to Square, ok bool) {
+	return pos.curr.DisableFromSquare, pos.curr.DisableToSquare, pos.curr.HasDisabledMove
+}
+
+// SetDisabledMove disables the moves of the piece on from towards to
+func (pos *Position) SetDisabledMove(from, to Square) {
+	pos.ClearDisabledMove()
+	pos.curr.DisableFromSquare = from
+	pos.curr.DisableToSquare = to
+	pos.curr.HasDisabledMove = true
+	pos.curr.Zobrist ^= pos.disabledMoveZobrist()
+}
+
+// ClearDisabledMove removes the disabled move
+func (pos *Position) ClearDisabledMove() {
+	pos.curr.Zobrist ^= pos.disabledMoveZobrist()
+	pos.curr.HasDisabledMove = false
+}
+
+// disabledMoveZobrist returns the part of the Zobrist key that belongs to the disabled move
+// it is zero without a disabled move, so the keys of other variants stay polyglot keys
+func (pos *Position) disabledMoveZobrist() uint64 {
+	if !pos.curr.HasDisabledMove {
+		return 0
+	}
+	return zobristDisableFrom[pos.curr.DisableFromSquare] ^ zobristDisableTo[pos.curr.DisableToSquare]
+}
+
+// RecalculateState recalculates the jailed squares and forgets the cached check state
+// should be called after setting up a position with Put and Remove
+func (pos *Position) RecalculateState() {
+	pos.curr.IsCheckedKnown = false
+	pos.curr.GivesCheckMove = NullMove
+	pos.calcJailedSquares()
+}
+
 // String returns position in FEN format
 // for table format use PrettyPrint
 func (pos *Position) String() string {
@@ -34,10 +74,21 @@ func (pos *Position) String() string {
 	s += " " + FormatEnpassantSquare(pos)
 	s += " " + strconv.Itoa(pos.curr.HalfmoveClock)
 	s += " " + strconv.Itoa(pos.fullmoveCounter)
-	s += " " + pos.FormatDisabledMove()
+	if pos.Variant.DisabledMoveField {
+		s += " " + pos.FormatDisabledMove()
+	}
 	return s
 }
 
+// Clone returns a deep copy of pos, including the move history, which can be searched independently
+func (pos *Position) Clone() *Position {
+	clone := *pos
+	clone.states = append(make([]state, 0, cap(pos.states)), pos.states...)
+	clone.curr = &clone.states[len(clone.states)-1]
+	clone.LegalMoveBuff = nil
+	return &clone
+}
+
 // popState pops one ply
 func (pos *Position) popState() {
 	len := len(pos.states) - 1
@@ -108,6 +159,15 @@ func (pos *Position) LastMove() Move {
 	return pos.curr.Move
 }
 
+// MoveHistory returns the moves played since the position was set up, oldest first
+func (pos *Position) MoveHistory() []Move {
+	moves := make([]Move, 0, len(pos.states)-1)
+	for _, st := range pos.states[1:] {
+		moves = append(moves, st.Move)
+	}
+	return moves
+}
+
 // Zobrist returns the zobrist key of the position, never returns 0
 func (pos *Position) Zobrist() uint64 {
 	if pos.curr.Zobrist != 0 {
@@ -125,7 +185,8 @@ func (pos *Position) IsPseudoLegal(m Move) bool {
 	if m == NullMove ||
 		m.Color() != pos.Us() ||
 		pos.Get(m.From()) != m.Piece() ||
-		pos.Get(m.CaptureSquare()) != m.Capture() {
+		(m.MoveType() != Castling && pos.Get(m.CaptureSquare()) != m.Capture()) {
+		// in chess960 the king may castle to the square of the rook
 		return false
 	}
 
@@ -135,7 +196,10 @@ func (pos *Position) IsPseudoLegal(m Move) bool {
 	// use base figure here instead of figure
 	switch m.Figure().BaseFigure() {
 	case Pawn:
-		// pawn move is tested above, promotion is always correct
+		// pawn move is tested above, promotion is correct if the variant has the figure
+		if m.MoveType() == Promotion && !pos.Variant.HasFigure(m.Promotion().Figure()) {
+			return false
+		}
 		if m.MoveType() == Enpassant && !pos.IsEnpassantSquare(m.To()) {
 			return false
 		}
@@ -163,40 +227,20 @@ func (pos *Position) IsPseudoLegal(m Move) bool {
 		}
 	case King:
 		if m.MoveType() == Normal {
+			if pos.IsAtomic() && m.Capture() != NoPiece {
+				// king cannot capture in atomic
+				return false
+			}
 			return bbKingAttack[from].Has(to)
 		}
 
-		// king must be castling, final square is empty
+		// king must be castling, squares crossed by king and rook are checked by isCastlingFree
 		// m.MoveType() == Castling
-
-		if m.Color() == White && m.To() == SquareG1 {
-			if pos.CastlingAbility()&WhiteOO == 0 || all.Has(SquareF1) {
-				return false
-			}
-		}
-		if m.Color() == White && m.To() == SquareC1 {
-			if pos.CastlingAbility()&WhiteOOO == 0 || all.Has(SquareD1) || all.Has(SquareB1) {
-				return false
-			}
-		}
-		if m.Color() == Black && m.To() == SquareG8 {
-			if pos.CastlingAbility()&BlackOO == 0 || all.Has(SquareF8) {
-				return false
-			}
-		}
-		if m.Color() == Black && m.To() == SquareC8 {
-			if pos.CastlingAbility()&BlackOOO == 0 || all.Has(SquareD8) || all.Has(SquareB8) {
-				return false
-			}
-		}
-		rook, start, end := CastlingRook(m.To())
-		if pos.Get(start) != rook {
-			return false
-		}
-		them := m.Color().Opposite()
-		if pos.GetAttacker(m.From(), them) != NoFigure ||
-			pos.GetAttacker(end, them) != NoFigure ||
-			pos.GetAttacker(m.To(), them) != NoFigure {
+		side := CastlingSideOf(m.To())
+		if pos.CastlingAbility()&CastleFor(m.Color(), side) == 0 ||
+			from != pos.castlingKingSquare[m.Color()] ||
+			to != CastlingKingTarget(m.Color(), side) ||
+			!pos.isCastlingFree(m.Color(), side) {
 			return false
 		}
 	case Lancer:
@@ -416,7 +460,7 @@ func (pos *Position) Perft(maxDepth int, verbose bool) int {
 	for _, move := range lms {
 		pos.DoMove(move)
 		if verbose {
-			fmt.Printf("perft %s : ", move.UCI())
+			fmt.Printf("perft %s : ", pos.MoveToUCI(move))
 		}
 		nodesOrig := pos.Nodes
 		pos.PerftRec(0, maxDepth-1)
@@ -445,7 +489,7 @@ func (pos *Position) CreateLegalMoveBuff() {
 		mbi := MoveBuffItem{
 			Move:  lm,
 			San:   lm.LAN(), // use LAN for meaningful initialization
-			Algeb: lm.UCI(),
+			Algeb: pos.MoveToUCI(lm),
 			Lan:   lm.LAN(),
 		}
 
@@ -465,6 +509,12 @@ func (pos *Position) InitMoveToSan() {
 	sort.Sort(MoveBuffBySan(pos.LegalMoveBuff))
 }
 
+// SortedLegalMoves returns the legal moves of the position with SAN and UCI, sorted by SAN
+func (pos *Position) SortedLegalMoves() MoveBuff {
+	pos.InitMoveToSan()
+	return pos.LegalMoveBuff
+}
+
 // MoveToSanBatch returns the move in SAN notation
 // provided that InitMoveToSan was called for the position
 func (pos *Position) MoveToSanBatch(move Move) string {
@@ -473,7 +523,9 @@ func (pos *Position) MoveToSanBatch(move Move) string {
 	seenSquares := make(map[Square]bool)
 
 	for _, mbi := range pos.LegalMoveBuff {
-		if (mbi.Move.Piece() == move.Piece()) && (mbi.Move.To() == move.To()) {
+		// lancers of any direction share the same san letter
+		samePiece := mbi.Move.Piece().Color() == move.Piece().Color() && mbi.Move.Figure().BaseFigure() == move.Figure().BaseFigure()
+		if samePiece && (mbi.Move.To() == move.To()) {
 			_, seen := seenSquares[mbi.Move.From()]
 			if !seen {
 				seenSquares[mbi.Move.From()] = true
@@ -533,7 +585,7 @@ func (pos *Position) MoveToSanBatch(move Move) string {
 		}
 	}
 
-	if move.Piece().Figure() == Pawn && !pos.IsSquareEmpty(move.To()) {
+	if move.Piece().Figure() == Pawn && move.Capture() != NoPiece {
 		qualifier = move.From().String()[0:1]
 	}
 
@@ -588,6 +640,54 @@ func (pos *Position) MoveToSan(move Move) string {
 	return pos.MoveToSanBatch(move)
 }
 
+// SanToMove returns the legal move denoted by san, see utils.ParseSan for the accepted notations
+// returns an error wrapping utils.ErrIllegalMove or utils.ErrAmbiguousMove if no or several moves match
+func (pos *Position) SanToMove(san string) (Move, error) {
+	sm, err := utils.ParseSan(san)
+	if err != nil {
+		return NullMove, err
+	}
+
+	pos.InitMoveToSan()
+
+	candidates := MoveBuff{}
+
+	for _, mbi := range pos.LegalMoveBuff {
+		gen, err := utils.ParseSan(mbi.San)
+		if err == nil && sm.Matches(gen, int8(mbi.Move.From().File()), int8(mbi.Move.From().Rank())) {
+			candidates = append(candidates, mbi)
+		}
+	}
+
+	if len(candidates) > 1 {
+		// without promotion given prefer the move that keeps the piece, like a lancer keeping its direction
+		unchanged := MoveBuff{}
+		for _, mbi := range candidates {
+			m := mbi.Move
+			if m.Target() == m.Piece() || (m.MoveType() == SentryPush && m.Target() == m.Capture()) {
+				unchanged = append(unchanged, mbi)
+			}
+		}
+		if len(unchanged) == 1 {
+			candidates = unchanged
+		}
+	}
+
+	if len(candidates) == 0 {
+		return NullMove, fmt.Errorf("%w %s", utils.ErrIllegalMove, san)
+	}
+
+	if len(candidates) > 1 {
+		sans := []string{}
+		for _, mbi := range candidates {
+			sans = append(sans, mbi.San)
+		}
+		return NullMove, fmt.Errorf("%w %s, could be %s", utils.ErrAmbiguousMove, san, strings.Join(sans, " "))
+	}
+
+	return candidates[0].Move, nil
+}
+
 // InsufficientMaterial returns true if the position is theoretical draw
 func (pos *Position) InsufficientMaterial() bool {
 	// K vs K is draw
@@ -656,6 +756,9 @@ func (pos *Position) IsChecked(col Color) bool {
 	if pos.Us() == col && pos.curr.IsCheckedKnown {
 		return pos.curr.IsChecked
 	}
+	if pos.IsAtomic() {
+		return pos.isCheckedAtomic(col)
+	}
 	kingSq := pos.WhereIsKing(col)
 	isChecked := pos.GetAttacker(kingSq, col.Opposite()) != NoFigure
 	if pos.Us() == col {
@@ -665,10 +768,31 @@ func (pos *Position) IsChecked(col Color) bool {
 	return isChecked
 }
 
+// isCheckedAtomic tells whether col is in check under atomic rules
+// an exploded king counts as checked, so that the move exploding it is illegal
+// adjacent kings cancel checks, because capturing the king would explode the capturer's king
+func (pos *Position) isCheckedAtomic(col Color) bool {
+	king := pos.ByPiece(col, King)
+	isChecked := true
+	if king != 0 {
+		kingSq := king.AsSquare()
+		if pos.ByPiece(col.Opposite(), King) == 0 || KingMobility(kingSq)&pos.ByPiece(col.Opposite(), King) != 0 {
+			isChecked = false
+		} else {
+			isChecked = pos.GetAttacker(kingSq, col.Opposite()) != NoFigure
+		}
+	}
+	if pos.Us() == col {
+		pos.curr.IsCheckedKnown = true
+		pos.curr.IsChecked = isChecked
+	}
+	return isChecked
+}
+
 // GivesCheck returns true if the opposite side is in check after m is executed
 // assumes that the position is legal and opposite side is not already in check
 func (pos *Position) GivesCheck(m Move) bool {
-	if m.MoveType() == Castling {
+	if m.MoveType() == Castling || pos.IsAtomic() {
 		// TODO: bail out on castling because it can check via rook and king
 		pos.curr.GivesCheckMove, pos.curr.GivesCheckResult = NullMove, false
 		pos.DoMove(m)
@@ -800,11 +924,21 @@ func (pos *Position) UCIToMove(s string) (Move, error) {
 		moveType = Enpassant
 		capt = ColorFigure(pos.Them(), Pawn)
 	}
-	if pi == WhiteKing && from == SquareE1 && (to == SquareC1 || to == SquareG1) {
-		moveType = Castling
-	}
-	if pi == BlackKing && from == SquareE8 && (to == SquareC8 || to == SquareG8) {
-		moveType = Castling
+	if pi.Figure() == King && from == pos.castlingKingSquare[pi.Color()] {
+		for side := QueenSide; side <= KingSide; side++ {
+			if pos.CastlingAbility()&CastleFor(pi.Color(), side) == 0 {
+				continue
+			}
+			// king takes rook, or the king target square outside chess960
+			kingTakesRook := to == pos.castlingRookSquare[pi.Color()][side]
+			kingTarget := !pos.Chess960 && to == CastlingKingTarget(pi.Color(), side) && (to.File()-from.File() == 2 || from.File()-to.File() == 2)
+			if kingTakesRook || kingTarget {
+				moveType = Castling
+				to = CastlingKingTarget(pi.Color(), side)
+				capt = NoPiece
+				target = pi
+			}
+		}
 	}
 	if pi.Figure() == Pawn && (to.Rank() == 0 || to.Rank() == 7) {
 		if len(s) != 5 {
@@ -825,6 +959,16 @@ func (pos *Position) UCIToMove(s string) (Move, error) {
 	return move, nil
 }
 
+// MoveToUCI converts a move to UCI format
+// in chess960 castling is written as king takes rook
+func (pos *Position) MoveToUCI(m Move) string {
+	if pos.Chess960 && m.MoveType() == Castling {
+		start, _ := pos.CastlingRookSquares(m)
+		return m.From().String() + start.String()
+	}
+	return m.UCI()
+}
+
 // DoMove executes a legal move
 func (pos *Position) DoMove(move Move) {
 	pos.pushState()
@@ -833,9 +977,9 @@ func (pos *Position) DoMove(move Move) {
 	// update castling rights
 	pi := move.Piece()
 	if pi != NoPiece { // nullmove cannot change castling ability
-		pos.SetCastlingAbility(curr.CastlingAbility &^ lostCastleRights[move.From()] &^ lostCastleRights[move.To()])
+		pos.SetCastlingAbility(curr.CastlingAbility &^ pos.lostCastleRights[move.From()] &^ pos.lostCastleRights[move.To()])
 		if move.MoveType() == SentryPush {
-			pos.SetCastlingAbility(curr.CastlingAbility &^ lostCastleRights[move.PromotionSquare()])
+			pos.SetCastlingAbility(curr.CastlingAbility &^ pos.lostCastleRights[move.PromotionSquare()])
 		}
 	}
 	// update fullmove counter
@@ -853,15 +997,8 @@ func (pos *Position) DoMove(move Move) {
 	} else if pos.EnpassantSquare() != SquareA1 {
 		pos.SetEnpassantSquare(SquareA1)
 	}
-	// move rook on castling
-	if move.MoveType() == Castling {
-		rook, start, end := CastlingRook(move.To())
-		pos.Remove(start, rook)
-		pos.Put(end, rook)
-	}
-
 	// delete any former disabled move
-	pos.curr.HasDisabledMove = false
+	pos.ClearDisabledMove()
 
 	// update the pieces on the chess board
 	if move.MoveType() == SentryPush {
@@ -875,9 +1012,16 @@ func (pos *Position) DoMove(move Move) {
 		pos.Put(promSq, move.Target())
 
 		// set disabled move
-		pos.curr.HasDisabledMove = true
-		pos.curr.DisableFromSquare = move.PromotionSquare()
-		pos.curr.DisableToSquare = move.To()
+		pos.SetDisabledMove(move.PromotionSquare(), move.To())
+	} else if move.MoveType() == Castling {
+		// the castling piece is whatever stands on the rook square
+		// in chess960 king and rook squares can overlap, so lift both before putting them back
+		start, end := pos.CastlingRookSquares(move)
+		rook := pos.Get(start)
+		pos.Remove(move.From(), pi)
+		pos.Remove(start, rook)
+		pos.Put(move.To(), move.Target())
+		pos.Put(end, rook)
 	} else {
 		pos.Remove(move.From(), pi)
 
@@ -885,6 +1029,10 @@ func (pos *Position) DoMove(move Move) {
 		pos.Put(move.To(), move.Target())
 	}
 
+	if pos.IsAtomic() && move.Capture() != NoPiece {
+		pos.explode(move.To())
+	}
+
 	// invert side to move
 	pos.InvertSideToMove()
 
@@ -908,25 +1056,71 @@ func (pos *Position) UndoMove() {
 	move := pos.LastMove()
 	pos.InvertSideToMove()
 
-	if move != NullMove {
-		pos.pieces[move.From()] = move.Piece()
+	if move != NullMove && move.MoveType() != Castling {
 		pos.pieces[move.To()] = NoPiece
 		pos.pieces[move.CaptureSquare()] = move.Capture()
 
 		if move.MoveType() == SentryPush {
 			pos.pieces[move.PromotionSquare()] = move.PromotionCapture()
 		}
-	}
-	if move.MoveType() == Castling {
-		rook, start, end := CastlingRook(move.To())
-		pos.pieces[start] = rook
-		pos.pieces[end] = NoPiece
+
+		// the sentry can push a piece to its own origin square, restore the sentry last
+		pos.pieces[move.From()] = move.Piece()
 	}
 
 	if pos.Us() == Black {
 		pos.fullmoveCounter--
 	}
 	pos.popState()
+
+	if move.MoveType() == Castling {
+		// king and rook squares can overlap, restore them from the bitboards
+		start, end := pos.CastlingRookSquares(move)
+		for _, sq := range []Square{move.From(), move.To(), start, end} {
+			pos.pieces[sq] = pos.pieceFromBitboards(sq)
+		}
+	}
+
+	if pos.IsAtomic() && move.Capture() != NoPiece {
+		// exploded pieces are not recorded in the move, restore them from the bitboards
+		for bb := bbKingAttack[move.To()] | move.To().Bitboard(); bb != 0; {
+			sq := bb.Pop()
+			pos.pieces[sq] = pos.pieceFromBitboards(sq)
+		}
+	}
+}
+
+// explode removes the piece on sq and all non pawn pieces around it
+// updates castling rights for exploded kings and rooks
+func (pos *Position) explode(sq Square) {
+	pos.Remove(sq, pos.Get(sq))
+	pos.SetCastlingAbility(pos.curr.CastlingAbility &^ pos.lostCastleRights[sq])
+
+	all := pos.ByColor(White) | pos.ByColor(Black)
+	for bb := bbKingAttack[sq] & all &^ pos.ByFigure(Pawn); bb != 0; {
+		esq := bb.Pop()
+		pos.Remove(esq, pos.Get(esq))
+		pos.SetCastlingAbility(pos.curr.CastlingAbility &^ pos.lostCastleRights[esq])
+	}
+}
+
+// pieceFromBitboards returns the piece at sq as recorded by the bitboards
+func (pos *Position) pieceFromBitboards(sq Square) Piece {
+	for col := ColorMinValue; col <= ColorMaxValue; col++ {
+		if pos.ByColor(col).Has(sq) {
+			for fig := Figure(1); int(fig) < FigureArraySize; fig++ {
+				if pos.ByFigure(fig).Has(sq) {
+					return ColorFigure(col, fig)
+				}
+			}
+		}
+	}
+	return NoPiece
+}
+
+// IsAtomic tells whether the position is played by atomic rules
+func (pos *Position) IsAtomic() bool {
+	return pos.Variant.Atomic
 }
 
 // UndoMoveSafe takes back the last move, does nothing if there is no move on the stack
@@ -1032,10 +1226,10 @@ func (pos *Position) AppendMove(move Move, moves *[]Move) {
 func (pos *Position) genPawnPromotions(kind int, moves *[]Move, limitFrom Bitboard) {
 	promFigures := []Figure{}
 	if kind&Violent != 0 {
-		promFigures = append(promFigures, PROMOTION_FIGURES_VIOLENT...)
+		promFigures = append(promFigures, pos.Variant.PromotionFiguresViolent...)
 	}
 	if kind&Quiet != 0 {
-		promFigures = append(promFigures, PROMOTION_FIGURES_QUIET...)
+		promFigures = append(promFigures, pos.Variant.PromotionFiguresQuiet...)
 	}
 
 	// get the pawns that can be promoted
@@ -1194,7 +1388,8 @@ func (pos *Position) getMask(kind int) Bitboard {
 		// generate all non-attacks
 		mask |= ^(pos.ByColor(White) | pos.ByColor(Black))
 	}
-	if pos.curr.IsCheckedKnown && pos.curr.IsChecked {
+	if pos.curr.IsCheckedKnown && pos.curr.IsChecked && !pos.IsAtomic() {
+		// in atomic a check can also be answered by exploding their king
 		// if the king is in check we can only move to block or avoid the check
 		king := pos.ByPiece(pos.Us(), King).AsSquare()
 		mask &= (pos.ByFigure(Knight) & bbKnightAttack[king]) | bbSuperAttack[king]
@@ -1222,6 +1417,10 @@ func (pos *Position) genPieceMoves(fig Figure, mask Bitboard, moves *[]Move, lim
 			att = QueenMobility(from, all)
 		case King:
 			att = KingMobility(from)
+			if pos.IsAtomic() {
+				// king cannot capture in atomic, it would explode
+				att &^= pos.ThemBb()
+			}
 		case Jailer:
 			att = JailerMobility(from, pos.UsBb(), pos.ThemBb())
 		}
@@ -1405,38 +1604,11 @@ func (pos *Position) genKingCastles(kind int, moves *[]Move) {
 		return
 	}
 
-	rank := HomeRank(pos.Us())
-	oo, ooo := WhiteOO, WhiteOOO
-	if pos.Us() == Black {
-		oo, ooo = BlackOO, BlackOOO
-	}
-
-	// castle king side
-	if pos.curr.CastlingAbility&oo != 0 {
-		r5 := RankFile(rank, 5)
-		r6 := RankFile(rank, 6)
-		if pos.Get(r5) == NoPiece && pos.Get(r6) == NoPiece {
-			r4 := RankFile(rank, 4)
-			if pos.GetAttacker(r4, pos.Them()) == NoFigure &&
-				pos.GetAttacker(r5, pos.Them()) == NoFigure &&
-				pos.GetAttacker(r6, pos.Them()) == NoFigure {
-				pos.AppendMove(MakeMove(Castling, r4, r6, ColorFigure(pos.Us(), King), NoPiece, ColorFigure(pos.Us(), King), NO_SQUARE, NoPiece), moves)
-			}
-		}
-	}
-
-	// castle queen side
-	if pos.curr.CastlingAbility&ooo != 0 {
-		r3 := RankFile(rank, 3)
-		r2 := RankFile(rank, 2)
-		r1 := RankFile(rank, 1)
-		if pos.Get(r3) == NoPiece && pos.Get(r2) == NoPiece && pos.Get(r1) == NoPiece {
-			r4 := RankFile(rank, 4)
-			if pos.GetAttacker(r4, pos.Them()) == NoFigure &&
-				pos.GetAttacker(r3, pos.Them()) == NoFigure &&
-				pos.GetAttacker(r2, pos.Them()) == NoFigure {
-				pos.AppendMove(MakeMove(Castling, r4, r2, ColorFigure(pos.Us(), King), NoPiece, ColorFigure(pos.Us(), King), NO_SQUARE, NoPiece), moves)
-			}
+	us := pos.Us()
+	king := ColorFigure(us, King)
+	for side := QueenSide; side <= KingSide; side++ {
+		if pos.curr.CastlingAbility&CastleFor(us, side) != 0 && pos.isCastlingFree(us, side) {
+			pos.AppendMove(MakeMove(Castling, pos.castlingKingSquare[us], CastlingKingTarget(us, side), king, NoPiece, king, NO_SQUARE, NoPiece), moves)
 		}
 	}
 }
@@ -1455,7 +1627,7 @@ func (pos *Position) RetrieveSideToMove() {
 
 // GetAttacker returns the smallest figure of color them that attacks sq
 func (pos *Position) GetAttacker(sq Square, them Color) Figure {
-	enemy := pos.ByColor(them) &^ pos.JailedForThem()
+	enemy := pos.ByColor(them) &^ pos.curr.JailedForColor[them]
 	if PawnThreats(pos, them).Has(sq) {
 		return Pawn
 	}
@@ -1466,7 +1638,8 @@ func (pos *Position) GetAttacker(sq Square, them Color) Figure {
 	// exclude pawns and knights because they were already tested
 	enemy &^= pos.ByFigure(Pawn)
 	enemy &^= pos.ByFigure(Knight)
-	if enemy&bbSuperAttack[sq] == 0 {
+	// sentries attack through the piece they push, which can stand anywhere
+	if enemy&bbSuperAttack[sq] == 0 && enemy&pos.ByFigure(Sentry) == 0 {
 		return NoFigure
 	}
 	all := pos.ByColor(White) | pos.ByColor(Black)
@@ -1480,7 +1653,8 @@ func (pos *Position) GetAttacker(sq Square, them Color) Figure {
 	}
 
 	// save side to move for lancer and sentry checks and set it to them
-	pos.SaveSideToMove()
+	// kept in a local, move generation can call GetAttacker recursively
+	us := pos.Us()
 	pos.SetSideToMove(them)
 
 	// lancer checks, expensive
@@ -1490,7 +1664,7 @@ func (pos *Position) GetAttacker(sq Square, them Color) Figure {
 	for _, move := range moves {
 		if move.To() == sq {
 			// retrieve side to move
-			pos.RetrieveSideToMove()
+			pos.SetSideToMove(us)
 			return Lancer
 		}
 	}
@@ -1501,18 +1675,18 @@ func (pos *Position) GetAttacker(sq Square, them Color) Figure {
 	for _, move := range moves {
 		if move.PromotionSquare() == sq {
 			// retrieve side to move
-			pos.RetrieveSideToMove()
+			pos.SetSideToMove(us)
 			return Sentry
 		}
 	}
 
 	// retrieve side to move
-	pos.RetrieveSideToMove()
+	pos.SetSideToMove(us)
 
 	if enemy&pos.ByFigure(Queen)&(bishop|rook) != 0 {
 		return Queen
 	}
-	if enemy&bbKingAttack[sq]&pos.ByFigure(King) != 0 {
+	if enemy&bbKingAttack[sq]&pos.ByFigure(King) != 0 && !pos.IsAtomic() {
 		return King
 	}
 	return NoFigure
@@ -1547,11 +1721,17 @@ func (pos *Position) GenerateMoves(kind int, moves *[]Move) {
 	pos.genKingPassMove(kind, moves)
 	pos.genPieceMoves(Queen, mask, moves, BbFull)
 
-	pos.genAllLancerMoves(mask, moves, BbFull)
+	if pos.Variant.HasFigure(Lancer) {
+		pos.genAllLancerMoves(mask, moves, BbFull)
+	}
 
-	pos.genSentryMoves(mask, moves, BbFull)
+	if pos.Variant.HasFigure(Sentry) {
+		pos.genSentryMoves(mask, moves, BbFull)
+	}
 
-	pos.genPieceMoves(Jailer, mask, moves, BbFull)
+	if pos.Variant.HasFigure(Jailer) {
+		pos.genPieceMoves(Jailer, mask, moves, BbFull)
+	}
 
 	pos.genPieceMoves(Rook, mask, moves, BbFull)
 	pos.genPieceMoves(Bishop, mask, moves, BbFull)
@@ -1565,6 +1745,9 @@ func (pos *Position) GenerateMoves(kind int, moves *[]Move) {
 // kind is Quiet or Violent, or both
 // limitFrom limits from squares
 func (pos *Position) GenerateFigureMoves(fig Figure, kind int, moves *[]Move, limitFrom Bitboard) {
+	if !pos.Variant.HasFigure(fig) {
+		return
+	}
 	mask := pos.getMask(kind)
 	switch fig.BaseFigure() {
 	case Pawn:
@@ -1590,7 +1773,7 @@ func (pos *Position) GenerateFigureMoves(fig Figure, kind int, moves *[]Move, li
 }
 
 func (pos *Position) genKingPassMove(kind int, moves *[]Move) {
-	if kind&Quiet == 0 {
+	if kind&Quiet == 0 || !pos.Variant.KingPass {
 		return
 	}
 	if pos.IsOurKingJailed() {
@@ -1608,6 +1791,8 @@ func init() {
 	initZobristEnpassant(f)
 	initZobristCastle(f)
 	initZobristColor(f)
+	initZobristDisabledMove(f)
+	initZobristPolyglot()
 	//fmt.Println("position init done")
 }
 
@@ -1648,4 +1833,11 @@ func initZobristColor(f func() uint64) {
 	zobristColor[White] = f()
 }
 
+func initZobristDisabledMove(f func() uint64) {
+	for sq := SquareMinValue; sq <= SquareMaxValue; sq++ {
+		zobristDisableFrom[sq] = f()
+		zobristDisableTo[sq] = f()
+	}
+}
+
 /////////////////////////////////////////////////////////////////////
@@ -3,6 +3,10 @@ package butils
 /////////////////////////////////////////////////////////////////////
 // imports
 
+import (
+	"github.com/easychessanimations/gochess/utils"
+)
+
 /////////////////////////////////////////////////////////////////////
 
 /////////////////////////////////////////////////////////////////////
@@ -89,6 +93,13 @@ type Position struct {
 	Ply           int   // current ply
 	Nodes         int   // Perft nodes
 
+	Variant *VariantDescriptor // rule set of the position
+
+	Chess960           bool                      // castling is written king takes rook, fen castling uses file letters
+	castlingKingSquare [ColorArraySize]Square    // king origin squares for castling
+	castlingRookSquare [ColorArraySize][2]Square // castling rook origin squares by color and side, 0 = queen side
+	lostCastleRights   [SquareArraySize]Castle   // which castle rights are lost when pieces are moved from or to a square
+
 	pieces          [SquareArraySize]Piece // tracks pieces at each square
 	fullmoveCounter int                    // fullmove counter, incremented after black move
 	states          []state                // a state for each Ply
@@ -96,11 +107,17 @@ type Position struct {
 	LegalMoveBuff   MoveBuff               // buffer to store legal moves; for sorting by SAN
 }
 
-// castle info
-type castleInfo struct {
-	Castle Castle
-	Piece  [2]Piece
-	Square [2]Square
+// VariantDescriptor describes which figures, promotions and special moves a variant allows
+type VariantDescriptor struct {
+	Key                     utils.VariantKey
+	Figures                 []Figure // figures that can appear on the board, lancers listed by base figure
+	PromotionFiguresQuiet   []Figure // minor promotions
+	PromotionFiguresViolent []Figure // major promotions
+	KingPass                bool     // jailed king may pass
+	DisabledMoveField       bool     // FEN has a seventh field for the disabled move
+	Atomic                  bool     // captures explode
+
+	hasFigure [FigureArraySize]bool // lookup table built from Figures
 }
 
 // MoveBuffItem hold a move together with its SAN and algebraic representation
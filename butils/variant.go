@@ -0,0 +1,43 @@
+package butils
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// HasFigure tells whether fig is allowed in the variant, lancers are checked by base figure
+func (vd *VariantDescriptor) HasFigure(fig Figure) bool {
+	return vd.hasFigure[fig.BaseFigure()]
+}
+
+// SetVariant sets the rules of the position to variant
+func (pos *Position) SetVariant(variant utils.VariantKey) error {
+	vd, err := GetVariantDescriptor(variant)
+	if err != nil {
+		return err
+	}
+	return pos.SetVariantDescriptor(vd)
+}
+
+// SetVariantDescriptor sets the rules of the position to vd
+// fails if the position has figures not allowed by vd
+func (pos *Position) SetVariantDescriptor(vd *VariantDescriptor) error {
+	for sq := SquareMinValue; sq <= SquareMaxValue; sq++ {
+		if fig := pos.Get(sq).Figure(); fig != NoFigure && !vd.HasFigure(fig) {
+			return fmt.Errorf("figure %s at %v not allowed in %s", fig.Symbol(), sq, utils.VariantKeyToVariantKeyString(vd.Key))
+		}
+	}
+	pos.Variant = vd
+	return nil
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,25 @@
+package epd
+
+/////////////////////////////////////////////////////////////////////
+// constants
+
+// opcodes read by the accessors of Record
+const OPCODE_BEST_MOVE = "bm"
+const OPCODE_AVOID_MOVE = "am"
+const OPCODE_ID = "id"
+const OPCODE_COMMENT = "c0"
+const OPCODE_RESULT = "c9"
+const OPCODE_EVAL = "ce"
+const OPCODE_DEPTH = "acd"
+const OPCODE_PREDICTED_MOVE = "pm"
+const OPCODE_PERFT = "perft"
+const OPCODE_HALFMOVE_CLOCK = "hmvc"
+const OPCODE_FULLMOVE_NUMBER = "fmvn"
+
+// FEN_FIELDS is the number of fields of the position of a record, the move counters are optional
+const FEN_FIELDS = 4
+
+// MAX_FEN_FIELDS allows for the move counters and the disabled move of eightpiece
+const MAX_FEN_FIELDS = 7
+
+/////////////////////////////////////////////////////////////////////
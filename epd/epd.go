@@ -0,0 +1,294 @@
+package epd
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/easychessanimations/gochess/butils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Get returns the operands of the first operation with opcode, nil if there is none
+func (r Record) Get(opcode string) []string {
+	for _, op := range r.Operations {
+		if op.Opcode == opcode {
+			if op.Operands == nil {
+				return []string{}
+			}
+			return op.Operands
+		}
+	}
+
+	return nil
+}
+
+// Has tells whether r has an operation with opcode
+func (r Record) Has(opcode string) bool {
+	return r.Get(opcode) != nil
+}
+
+// Id returns the id of r, empty if it has none
+func (r Record) Id() string {
+	return strings.Join(r.Get(OPCODE_ID), " ")
+}
+
+// Comment returns the c0 comment of r, empty if it has none
+func (r Record) Comment() string {
+	return strings.Join(r.Get(OPCODE_COMMENT), " ")
+}
+
+// BestMoves returns the moves of the bm operation, in san or uci as written
+func (r Record) BestMoves() []string {
+	return r.Get(OPCODE_BEST_MOVE)
+}
+
+// AvoidMoves returns the moves of the am operation, in san or uci as written
+func (r Record) AvoidMoves() []string {
+	return r.Get(OPCODE_AVOID_MOVE)
+}
+
+// PredictedMove returns the move of the pm operation, empty if there is none
+func (r Record) PredictedMove() string {
+	if pm := r.Get(OPCODE_PREDICTED_MOVE); len(pm) > 0 {
+		return pm[0]
+	}
+
+	return ""
+}
+
+// Eval returns the centipawn evaluation of the ce operation
+func (r Record) Eval() (int, bool) {
+	return r.intOperand(OPCODE_EVAL)
+}
+
+// Depth returns the analysis depth of the acd operation
+func (r Record) Depth() (int, bool) {
+	return r.intOperand(OPCODE_DEPTH)
+}
+
+// Perft returns the expected perft counts, Perft()[d-1] is the count at depth d, 0 if not given
+// the counts are read from perft d n operations and from the ;D1 20 ;D2 400 operations of the perft suites
+func (r Record) Perft() []uint64 {
+	counts := []uint64{}
+
+	for _, op := range r.Operations {
+		var depthStr, nodesStr string
+		switch {
+		case op.Opcode == OPCODE_PERFT && len(op.Operands) == 2:
+			depthStr, nodesStr = op.Operands[0], op.Operands[1]
+		case strings.HasPrefix(op.Opcode, "D") && len(op.Operands) == 1:
+			depthStr, nodesStr = op.Opcode[1:], op.Operands[0]
+		default:
+			continue
+		}
+
+		depth, err := strconv.Atoi(depthStr)
+		if err != nil || depth <= 0 {
+			continue
+		}
+		nodes, err := strconv.ParseUint(nodesStr, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		for len(counts) < depth {
+			counts = append(counts, 0)
+		}
+		counts[depth-1] = nodes
+	}
+
+	return counts
+}
+
+// String returns r as an epd line
+func (r Record) String() string {
+	buff := strings.Builder{}
+
+	buff.WriteString(r.Fen)
+
+	for _, op := range r.Operations {
+		buff.WriteString(" " + op.Opcode)
+		for _, operand := range op.Operands {
+			if op.Opcode == OPCODE_ID || isCommentOpcode(op.Opcode) || operand == "" || strings.ContainsAny(operand, " \t;") {
+				operand = `"` + operand + `"`
+			}
+			buff.WriteString(" " + operand)
+		}
+		buff.WriteString(";")
+	}
+
+	return buff.String()
+}
+
+// intOperand returns the first operand of opcode as an integer
+func (r Record) intOperand(opcode string) (int, bool) {
+	operands := r.Get(opcode)
+	if len(operands) == 0 {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(operands[0])
+
+	return n, err == nil
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// ParseEPD parses an epd line, the position may be followed by the move counters and the disabled move of eightpiece
+// the last operation may omit its semicolon and empty operations are skipped
+func ParseEPD(line string) (Record, error) {
+	tokens, err := tokenize(line)
+	if err != nil {
+		return Record{}, fmt.Errorf("%w in %q", err, line)
+	}
+
+	n := 0
+	for n < len(tokens) && n < FEN_FIELDS && !tokens[n].quoted && tokens[n].text != ";" {
+		n++
+	}
+	if n < FEN_FIELDS {
+		return Record{}, fmt.Errorf("too few position fields in %q", line)
+	}
+	for n < len(tokens) && n < MAX_FEN_FIELDS && !tokens[n].quoted && isFenField(tokens[n].text, n) {
+		n++
+	}
+
+	fields := []string{}
+	for _, t := range tokens[:n] {
+		fields = append(fields, t.text)
+	}
+
+	r := Record{}
+
+	var op *Operation
+	for _, t := range tokens[n:] {
+		switch {
+		case !t.quoted && t.text == ";":
+			// empty operations are skipped, the perft suites start each operation with a semicolon
+			if op != nil {
+				r.Operations = append(r.Operations, *op)
+			}
+			op = nil
+		case op == nil:
+			if t.quoted || !unicode.IsLetter(rune(t.text[0])) {
+				return Record{}, fmt.Errorf("invalid opcode %s in %q", t.text, line)
+			}
+			op = &Operation{Opcode: t.text}
+		default:
+			op.Operands = append(op.Operands, t.text)
+		}
+	}
+	if op != nil {
+		r.Operations = append(r.Operations, *op)
+	}
+
+	// the move counters go before the disabled move
+	if len(fields) == FEN_FIELDS || len(fields) == FEN_FIELDS+1 {
+		hmvc, fmvn := "0", "1"
+		if len(fields) == FEN_FIELDS+1 {
+			hmvc = fields[FEN_FIELDS]
+		} else if clock, ok := r.intOperand(OPCODE_HALFMOVE_CLOCK); ok {
+			hmvc = strconv.Itoa(clock)
+		}
+		if number, ok := r.intOperand(OPCODE_FULLMOVE_NUMBER); ok {
+			fmvn = strconv.Itoa(number)
+		}
+		fields = append(fields[:FEN_FIELDS], hmvc, fmvn)
+	}
+
+	r.Fen = strings.Join(fields, " ")
+
+	return r, nil
+}
+
+// ReadEPD reads the records of r, empty lines and lines starting with # are skipped
+func ReadEPD(r io.Reader) ([]Record, error) {
+	records := []Record{}
+
+	scanner := bufio.NewScanner(r)
+	for num := 1; scanner.Scan(); num++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		record, err := ParseEPD(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", num, err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+// tokenize splits an epd line into tokens
+func tokenize(line string) ([]token, error) {
+	tokens := []token{}
+
+	for i := 0; i < len(line); {
+		switch c := line[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == ';':
+			tokens = append(tokens, token{text: ";"})
+			i++
+		case c == '"':
+			end := strings.IndexByte(line[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, token{text: line[i+1 : i+1+end], quoted: true})
+			i += end + 2
+		default:
+			start := i
+			for i < len(line) && !strings.ContainsRune(" \t;\"", rune(line[i])) {
+				i++
+			}
+			tokens = append(tokens, token{text: line[start:i]})
+		}
+	}
+
+	return tokens, nil
+}
+
+// isFenField tells whether token can be the field at index n of a FEN following the first four fields
+func isFenField(token string, n int) bool {
+	if n < 6 {
+		_, err := strconv.Atoi(token)
+		return err == nil
+	}
+
+	// disabled move field of eightpiece
+	if token == "-" {
+		return true
+	}
+	if len(token) < 4 {
+		return false
+	}
+	_, errFrom := butils.SquareFromString(token[0:2])
+	_, errTo := butils.SquareFromString(token[2:4])
+	return errFrom == nil && errTo == nil
+}
+
+// isCommentOpcode tells whether opcode is one of the comments c0 to c9
+func isCommentOpcode(opcode string) bool {
+	return len(opcode) == 2 && opcode[0] == 'c' && opcode[1] >= '0' && opcode[1] <= '9'
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,78 @@
+package epd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEPD(t *testing.T) {
+	r, err := ParseEPD(`r1bqk1nr/pppp1ppp/2n5/2b1p3/2B1P3/5N2/PPPP1PPP/RNBQK2R w KQkq - bm O-O Nc3; am Ng5; id "italian.001"; c0 "developing; castles"; ce +35; acd 12; pm O-O; perft 2 1000; fmvn 4`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Fen != "r1bqk1nr/pppp1ppp/2n5/2b1p3/2B1P3/5N2/PPPP1PPP/RNBQK2R w KQkq - 0 4" {
+		t.Errorf("unexpected fen %s", r.Fen)
+	}
+	if strings.Join(r.BestMoves(), " ") != "O-O Nc3" || strings.Join(r.AvoidMoves(), " ") != "Ng5" || r.PredictedMove() != "O-O" {
+		t.Errorf("unexpected moves bm %v am %v pm %s", r.BestMoves(), r.AvoidMoves(), r.PredictedMove())
+	}
+	if r.Id() != "italian.001" || r.Comment() != "developing; castles" {
+		t.Errorf("unexpected id %q comment %q", r.Id(), r.Comment())
+	}
+	if ce, ok := r.Eval(); !ok || ce != 35 {
+		t.Errorf("expected ce 35, got %d %v", ce, ok)
+	}
+	if acd, ok := r.Depth(); !ok || acd != 12 {
+		t.Errorf("expected acd 12, got %d %v", acd, ok)
+	}
+	if counts := r.Perft(); len(counts) != 2 || counts[0] != 0 || counts[1] != 1000 {
+		t.Errorf("unexpected perft counts %v", counts)
+	}
+
+	// String writes a line parsed to the same record
+	again, err := ParseEPD(r.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != r.String() || again.Comment() != r.Comment() {
+		t.Errorf("round trip changed\n%s\n%s", r, again)
+	}
+
+	// the operations of the perft suites and the disabled move of eightpiece
+	r, err = ParseEPD("jlse1qkbnr/ppp1pppp/3pB3/8/8/6Ps/PPPPPP1P/JLneSQK1NR w KQkq - 0 3 e6h3 ;D1 65 ;D2 3000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(r.Fen, " 0 3 e6h3") || len(r.Operations) != 2 {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if counts := r.Perft(); len(counts) != 2 || counts[0] != 65 || counts[1] != 3000 {
+		t.Errorf("unexpected perft counts %v", counts)
+	}
+
+	for _, line := range []string{
+		"8/8/8/8 w",
+		`8/8/8/8/8/8/8/K6k w - - id "unterminated;`,
+		"8/8/8/8/8/8/8/K6k w - - 0 1 +3;",
+	} {
+		if _, err := ParseEPD(line); err == nil {
+			t.Errorf("expected an error for %s", line)
+		}
+	}
+}
+
+func TestReadEPD(t *testing.T) {
+	records, err := ReadEPD(strings.NewReader("# comment\n\n8/8/8/8/8/8/8/K6k w - - bm Kb2;\n8/8/8/8/8/8/8/K6k b - - 5 60\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 || records[0].Fen != "8/8/8/8/8/8/8/K6k w - - 0 1" || records[1].Fen != "8/8/8/8/8/8/8/K6k b - - 5 60" || records[1].Has(OPCODE_BEST_MOVE) {
+		t.Errorf("unexpected records %+v", records)
+	}
+
+	if _, err := ReadEPD(strings.NewReader("8/8/8/8/8/8/8/K6k w - - bm Kb2;\nbroken\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected an error on line 2, got %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package epd
+
+/////////////////////////////////////////////////////////////////////
+// types
+
+// Operation is an opcode with its operands, quoted operands are unquoted
+type Operation struct {
+	Opcode   string
+	Operands []string
+}
+
+// Record is a line of an epd file
+type Record struct {
+	Fen        string      // the position with the move counters, 0 1 if neither the fields nor hmvc and fmvn give them
+	Operations []Operation // in the order of the line
+}
+
+// token is a word, a quoted string or a semicolon of an epd line
+type token struct {
+	text   string
+	quoted bool
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,482 @@
+package lichess
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/easychessanimations/gochess/bengine"
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Error returns the status with the message of the api
+func (e *APIError) Error() string {
+	return fmt.Sprintf("lichess answered %d %s: %s", e.Status, http.StatusText(e.Status), e.Message)
+}
+
+// Run plays the challenges of the event stream until ctx is done, the stream is opened again when it breaks
+// it returns an error if the token is not accepted, after the games being played have ended
+func (b *Bot) Run(ctx context.Context) error {
+	defer b.wg.Wait()
+
+	account, err := b.Account(ctx)
+	if err != nil {
+		return err
+	}
+	b.id = strings.ToLower(account.ID)
+	b.log(fmt.Sprintf("playing as %s", account.Username))
+
+	for {
+		err := b.streamEvents(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		delay := RECONNECT_DELAY
+		if apiErr, ok := err.(*APIError); ok {
+			switch apiErr.Status {
+			case http.StatusUnauthorized, http.StatusForbidden:
+				return err
+			case http.StatusTooManyRequests:
+				delay = RATE_LIMIT_DELAY
+			}
+		}
+		b.log(fmt.Sprintf("event stream closed: %v, reconnecting in %v", err, delay))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Account returns the account of the token
+func (b *Bot) Account(ctx context.Context) (Account, error) {
+	account := Account{}
+
+	res, err := b.request(ctx, http.MethodGet, "/api/account", nil)
+	if err != nil {
+		return account, err
+	}
+	defer res.Body.Close()
+
+	err = json.NewDecoder(res.Body).Decode(&account)
+
+	return account, err
+}
+
+// PlayGame plays the game with id until it is over or ctx is done
+func (b *Bot) PlayGame(ctx context.Context, id string) error {
+	res, err := b.request(ctx, http.MethodGet, "/api/bot/game/stream/"+id, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	eng := bengine.NewEngine(nil, nil, bengine.Options{Threads: b.options.Threads})
+
+	game := GameFull{}
+	answered := -1
+
+	return readStream(res.Body, func(line []byte) error {
+		state := GameState{}
+		if err := json.Unmarshal(line, &state); err != nil {
+			return err
+		}
+
+		switch state.Type {
+		case EVENT_GAME_FULL:
+			if err := json.Unmarshal(line, &game); err != nil {
+				return err
+			}
+			state = game.State
+		case EVENT_GAME_STATE:
+		default:
+			// chat lines and opponent gone notices
+			return nil
+		}
+
+		if state.Status != STATUS_STARTED && state.Status != STATUS_CREATED {
+			b.log(fmt.Sprintf("game %s ended by %s, winner %s", id, state.Status, state.Winner))
+			return io.EOF
+		}
+
+		color, err := b.colorOf(game)
+		if err != nil {
+			return err
+		}
+
+		pos, err := GamePosition(game, state.Moves)
+		if err != nil {
+			return err
+		}
+
+		plies := len(strings.Fields(state.Moves))
+		if pos.Us() != color || plies == answered {
+			return nil
+		}
+
+		move, ok := b.search(ctx, eng, pos, game, state)
+		if !ok {
+			return nil
+		}
+
+		uci := pos.MoveToUCI(move)
+		if err := b.post(ctx, "/api/bot/game/"+id+"/move/"+uci, nil); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			b.log(fmt.Sprintf("game %s: move %s not accepted: %v", id, uci, err))
+			return nil
+		}
+		answered = plies
+
+		return nil
+	})
+}
+
+// streamEvents reads the event stream until it breaks or ctx is done
+func (b *Bot) streamEvents(ctx context.Context) error {
+	res, err := b.request(ctx, http.MethodGet, "/api/stream/event", nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	err = readStream(res.Body, func(line []byte) error {
+		event := Event{}
+		if err := json.Unmarshal(line, &event); err != nil {
+			return err
+		}
+
+		switch event.Type {
+		case EVENT_CHALLENGE:
+			if event.Challenge != nil {
+				b.handleChallenge(ctx, *event.Challenge)
+			}
+		case EVENT_CHALLENGE_CANCELED, EVENT_CHALLENGE_DECLINED:
+			if event.Challenge != nil {
+				b.mu.Lock()
+				if played, ok := b.games[event.Challenge.ID]; ok && !played {
+					delete(b.games, event.Challenge.ID)
+				}
+				b.mu.Unlock()
+			}
+		case EVENT_GAME_START:
+			if event.Game != nil {
+				b.startGame(ctx, event.Game.gameID())
+			}
+		}
+
+		return nil
+	})
+	if err == nil {
+		err = fmt.Errorf("end of stream")
+	}
+
+	return err
+}
+
+// handleChallenge accepts a challenge of a variant the bot plays when it has a free game, it declines it otherwise
+func (b *Bot) handleChallenge(ctx context.Context, c Challenge) {
+	if strings.ToLower(c.Challenger.ID) == b.id {
+		// challenges sent by the bot
+		return
+	}
+
+	reason := ""
+	if _, _, ok := VariantOf(c.Variant.Key); !ok || !b.playsVariant(c.Variant.Key) {
+		reason = DECLINE_VARIANT
+	}
+
+	b.mu.Lock()
+	if reason == "" && len(b.games) >= b.options.MaxGames {
+		reason = DECLINE_LATER
+	}
+	if reason == "" {
+		b.games[c.ID] = false
+	}
+	b.mu.Unlock()
+
+	if reason != "" {
+		b.log(fmt.Sprintf("declining %s %s challenge %s of %s: %s", c.Variant.Key, c.Speed, c.ID, c.Challenger.ID, reason))
+		if err := b.post(ctx, "/api/challenge/"+c.ID+"/decline", url.Values{"reason": {reason}}); err != nil {
+			b.log(fmt.Sprintf("declining challenge %s: %v", c.ID, err))
+		}
+		return
+	}
+
+	b.log(fmt.Sprintf("accepting %s %s challenge %s of %s", c.Variant.Key, c.Speed, c.ID, c.Challenger.ID))
+	if err := b.post(ctx, "/api/challenge/"+c.ID+"/accept", nil); err != nil {
+		b.log(fmt.Sprintf("accepting challenge %s: %v", c.ID, err))
+		b.mu.Lock()
+		delete(b.games, c.ID)
+		b.mu.Unlock()
+	}
+}
+
+// startGame plays the game with id unless it is already being played, lichess sends the games in progress again on reconnection
+func (b *Bot) startGame(ctx context.Context, id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.games[id] {
+		return
+	}
+	b.games[id] = true
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+
+		b.log(fmt.Sprintf("game %s started", id))
+		if err := b.PlayGame(ctx, id); err != nil && ctx.Err() == nil {
+			b.log(fmt.Sprintf("game %s: %v", id, err))
+		}
+
+		b.mu.Lock()
+		delete(b.games, id)
+		b.mu.Unlock()
+	}()
+}
+
+// search returns the move of the engine in pos, it is false if there is none or ctx is done
+// games with a clock are searched with the clocks of state less the move overhead, others for the move time
+func (b *Bot) search(ctx context.Context, eng *bengine.Engine, pos *butils.Position, game GameFull, state GameState) (butils.Move, bool) {
+	tc := bengine.NewTimeControl(pos, false)
+	if game.Clock != nil {
+		tc.WTime, tc.WInc = b.clockTime(state.WTime), time.Duration(state.WInc)*time.Millisecond
+		tc.BTime, tc.BInc = b.clockTime(state.BTime), time.Duration(state.BInc)*time.Millisecond
+	} else {
+		tc.WTime, tc.BTime, tc.MovesToGo = b.options.MoveTime, b.options.MoveTime, 1
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			tc.Stop()
+		case <-done:
+		}
+	}()
+
+	eng.SetPosition(pos)
+	tc.Start(false)
+	_, pv := eng.PlayMoves(tc, nil)
+
+	if len(pv) == 0 || ctx.Err() != nil {
+		return butils.NullMove, false
+	}
+
+	return pv[0], true
+}
+
+// clockTime returns the time of a clock in milliseconds less the move overhead
+func (b *Bot) clockTime(millis int64) time.Duration {
+	t := time.Duration(millis)*time.Millisecond - b.options.MoveOverhead
+	if t < 0 {
+		return 0
+	}
+	return t
+}
+
+// colorOf returns the color of the bot in game
+func (b *Bot) colorOf(game GameFull) (butils.Color, error) {
+	switch b.id {
+	case strings.ToLower(game.White.ID):
+		return butils.White, nil
+	case strings.ToLower(game.Black.ID):
+		return butils.Black, nil
+	}
+	return butils.NoColor, fmt.Errorf("%s does not play game %s", b.id, game.ID)
+}
+
+// playsVariant tells whether the options accept the variant with lichess key
+func (b *Bot) playsVariant(key string) bool {
+	for _, variant := range b.options.Variants {
+		if variant == key {
+			return true
+		}
+	}
+	return false
+}
+
+// request calls the api, an answer other than 200 is returned as an *APIError
+func (b *Bot) request(ctx context.Context, method string, path string, form url.Values) (*http.Response, error) {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, b.options.URL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+b.options.Token)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	res, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		message, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, &APIError{Status: res.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+
+	return res, nil
+}
+
+// post calls the api with a form and discards the answer
+func (b *Bot) post(ctx context.Context, path string, form url.Values) error {
+	res, err := b.request(ctx, http.MethodPost, path, form)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// log writes a line to the log of the bot if there is one
+func (b *Bot) log(line string) {
+	if b.options.Log != nil {
+		b.options.Log(line)
+	}
+}
+
+// gameID returns the id of the game of an event, older versions of the api only send id
+func (g *GameEventInfo) gameID() string {
+	if g.GameID != "" {
+		return g.GameID
+	}
+	return g.ID
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// NewBot returns a bot playing with the token of options
+func NewBot(options Options) *Bot {
+	if options.URL == "" {
+		options.URL = LICHESS_URL
+	}
+	options.URL = strings.TrimSuffix(options.URL, "/")
+	if options.MaxGames <= 0 {
+		options.MaxGames = 1
+	}
+
+	return &Bot{
+		options: options,
+		client:  &http.Client{},
+		games:   map[string]bool{},
+	}
+}
+
+// VariantOf returns the variant of a lichess variant key and whether castling is written king takes rook
+func VariantOf(key string) (utils.VariantKey, bool, bool) {
+	switch key {
+	case "standard", "fromPosition":
+		return utils.VARIANT_STANDARD, false, true
+	case "chess960":
+		return utils.VARIANT_STANDARD, true, true
+	case "atomic":
+		return utils.VARIANT_ATOMIC, false, true
+	}
+
+	return utils.VARIANT_STANDARD, false, false
+}
+
+// GamePosition returns the position of game after its uci moves separated by spaces
+func GamePosition(game GameFull, moves string) (*butils.Position, error) {
+	variant, chess960, ok := VariantOf(game.Variant.Key)
+	if !ok {
+		return nil, fmt.Errorf("unsupported variant %s", game.Variant.Key)
+	}
+
+	fen := game.InitialFen
+	if fen == "" || fen == INITIAL_FEN_STARTPOS {
+		fen = utils.StartFenForVariant(variant)
+	}
+
+	pos, err := butils.PositionFromFENAndVariant(fen, variant)
+	if err != nil {
+		return nil, err
+	}
+	pos.Chess960 = chess960
+
+	for _, s := range strings.Fields(moves) {
+		move, err := ParseUCIMove(pos, s)
+		if err != nil {
+			return nil, err
+		}
+		pos.DoMove(move)
+	}
+
+	return pos, nil
+}
+
+// ParseUCIMove returns the legal move of pos written s in uci
+// castling may also be written with the king target square in chess960, as lichess does in some api versions
+func ParseUCIMove(pos *butils.Position, s string) (butils.Move, error) {
+	legal := pos.LegalMoves()
+
+	for _, move := range legal {
+		if pos.MoveToUCI(move) == s {
+			return move, nil
+		}
+	}
+	for _, move := range legal {
+		if move.UCI() == s {
+			return move, nil
+		}
+	}
+
+	return butils.NullMove, fmt.Errorf("%w %s in %s", utils.ErrIllegalMove, s, pos)
+}
+
+// readStream calls handle with the lines of an ndjson stream, empty keep alive lines are skipped
+// it returns nil at the end of the stream or when handle returns io.EOF
+func readStream(r io.Reader, handle func(line []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), MAX_LINE_SIZE)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		if err := handle(line); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,68 @@
+package lichess
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import "time"
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// constants
+
+// LICHESS_URL is the default address of the api
+const LICHESS_URL = "https://lichess.org"
+
+// types of the events of the account stream
+const EVENT_CHALLENGE = "challenge"
+const EVENT_CHALLENGE_CANCELED = "challengeCanceled"
+const EVENT_CHALLENGE_DECLINED = "challengeDeclined"
+const EVENT_GAME_START = "gameStart"
+const EVENT_GAME_FINISH = "gameFinish"
+
+// types of the events of a game stream
+const EVENT_GAME_FULL = "gameFull"
+const EVENT_GAME_STATE = "gameState"
+
+// statuses of a game that is not over
+const STATUS_CREATED = "created"
+const STATUS_STARTED = "started"
+
+// statuses of a finished game
+const STATUS_MATE = "mate"
+const STATUS_RESIGN = "resign"
+const STATUS_STALEMATE = "stalemate"
+const STATUS_DRAW = "draw"
+const STATUS_OUT_OF_TIME = "outoftime"
+const STATUS_VARIANT_END = "variantEnd"
+
+// CHALLENGE_ACCEPTED is the status of an accepted challenge
+const CHALLENGE_ACCEPTED = "accepted"
+
+// reasons of declining a challenge
+const DECLINE_VARIANT = "variant"
+const DECLINE_LATER = "later"
+
+// INITIAL_FEN_STARTPOS is the initial fen of a game from the start position of its variant
+const INITIAL_FEN_STARTPOS = "startpos"
+
+// RECONNECT_DELAY is the wait before the event stream is opened again after it broke
+const RECONNECT_DELAY = 5 * time.Second
+
+// RATE_LIMIT_DELAY is the wait after lichess answered too many requests
+const RATE_LIMIT_DELAY = time.Minute
+
+// MAX_LINE_SIZE is the longest line of a stream, a gameFull of a long game has thousands of moves
+const MAX_LINE_SIZE = 1 << 20
+
+// defaults of the bot command
+var DEFAULT_OPTIONS = Options{
+	URL:          LICHESS_URL,
+	Variants:     []string{"standard", "chess960", "atomic"},
+	MaxGames:     1,
+	MoveOverhead: 300 * time.Millisecond,
+	MoveTime:     5 * time.Second,
+	Threads:      1,
+}
+
+/////////////////////////////////////////////////////////////////////
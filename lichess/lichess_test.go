@@ -0,0 +1,87 @@
+package lichess
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/easychessanimations/gochess/butils"
+)
+
+func TestBot(t *testing.T) {
+	mock := NewMockServer("secret", "gochess-bot")
+	defer mock.Close()
+	mock.MaxPlies = 16
+
+	options := DEFAULT_OPTIONS
+	options.URL, options.Token, options.MaxGames, options.MoveTime = mock.URL, "secret", 3, 50*time.Millisecond
+	bot := NewBot(options)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() {
+		stopped <- bot.Run(ctx)
+	}()
+
+	challenges := []Challenge{
+		{Variant: Variant{Key: "standard"}, TimeControl: ChallengeTimeControl{Type: "clock", Limit: 3}, Color: "white"},
+		{Variant: Variant{Key: "chess960"}, Color: "black", InitialFen: "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w KQkq - 0 1"},
+		{Variant: Variant{Key: "atomic"}, TimeControl: ChallengeTimeControl{Type: "clock", Limit: 2, Increment: 1}, Color: "black"},
+	}
+
+	ids := []string{}
+	for _, c := range challenges {
+		ids = append(ids, mock.Challenge(c))
+	}
+	declined := mock.Challenge(Challenge{Variant: Variant{Key: "crazyhouse"}})
+
+	for i, id := range ids {
+		game, err := mock.WaitGame(id, 30*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if game.Status == STATUS_OUT_OF_TIME || (game.Winner != "" && game.Winner != colorName(game.BotColor)) {
+			t.Errorf("%s: the bot lost by %s after %v", challenges[i].Variant.Key, game.Status, game.Moves)
+		}
+		if game.BotColor == butils.White && challenges[i].Color != "black" || game.BotColor == butils.Black && challenges[i].Color != "white" {
+			t.Errorf("%s: the bot played %v against a %s challenger", challenges[i].Variant.Key, game.BotColor, challenges[i].Color)
+		}
+	}
+
+	if _, err := mock.WaitGame(declined, 10*time.Second); err == nil || mock.ChallengeStatus(declined) != DECLINE_VARIANT {
+		t.Errorf("crazyhouse challenge: expected declined for the variant, got %s", mock.ChallengeStatus(declined))
+	}
+
+	cancel()
+	if err := <-stopped; err != nil {
+		t.Error(err)
+	}
+
+	// a wrong token stops the bot
+	options.Token = "wrong"
+	if err := NewBot(options).Run(context.Background()); err == nil {
+		t.Error("expected an error with a wrong token")
+	}
+}
+
+func TestGamePosition(t *testing.T) {
+	game := GameFull{Variant: Variant{Key: "chess960"}, InitialFen: "bqnbrkrn/pppppppp/8/8/8/8/PPPPPPPP/BQNBRKRN w KQkq - 0 1"}
+
+	// the king on f1 castles with the rook on g1, which lichess writes as the king taking the rook
+	pos, err := GamePosition(game, "f1g1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g1, _ := butils.SquareFromString("g1")
+	f1, _ := butils.SquareFromString("f1")
+	if pos.Get(g1).Figure() != butils.King || pos.Get(f1).Figure() != butils.Rook {
+		t.Errorf("expected the king castled, got %s", pos)
+	}
+
+	if _, err := GamePosition(game, "e2e4 e1e2"); err == nil {
+		t.Error("expected an error for an illegal move")
+	}
+	if _, err := GamePosition(GameFull{Variant: Variant{Key: "crazyhouse"}}, ""); err == nil {
+		t.Error("expected an error for an unsupported variant")
+	}
+}
@@ -0,0 +1,517 @@
+package lichess
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/easychessanimations/gochess/butils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Close ends the streams and shuts the server down
+func (m *MockServer) Close() {
+	close(m.done)
+	m.server.Close()
+}
+
+// Challenge challenges the bot, the missing fields of c get defaults: an unlimited standard game
+// of a random color with a generated id, it returns the id which is also the id of the game
+func (m *MockServer) Challenge(c Challenge) string {
+	m.mu.Lock()
+	if c.ID == "" {
+		m.next++
+		c.ID = fmt.Sprintf("mock%04d", m.next)
+	}
+	if c.Challenger.ID == "" {
+		c.Challenger = User{ID: "sparring", Name: "Sparring"}
+	}
+	c.DestUser = User{ID: m.BotID, Name: m.BotID}
+	if c.Variant.Key == "" {
+		c.Variant.Key = "standard"
+	}
+	if c.TimeControl.Type == "" {
+		c.TimeControl.Type = "unlimited"
+	}
+	if c.Speed == "" {
+		c.Speed = mockSpeed(c.TimeControl)
+	}
+	if c.Color == "" {
+		c.Color = "random"
+	}
+	m.challenges[c.ID] = c
+	m.statuses[c.ID] = STATUS_CREATED
+	m.mu.Unlock()
+
+	m.events <- Event{Type: EVENT_CHALLENGE, Challenge: &c}
+
+	return c.ID
+}
+
+// ChallengeStatus returns created, accepted or the decline reason of the challenge with id
+func (m *MockServer) ChallengeStatus(id string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.statuses[id]
+}
+
+// Game returns the game with id
+func (m *MockServer) Game(id string) (MockGame, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g, ok := m.games[id]
+	if !ok {
+		return MockGame{}, false
+	}
+
+	game := g.MockGame
+	game.Moves = append([]string{}, g.Moves...)
+
+	return game, true
+}
+
+// WaitGame waits until the game with id is over, it fails at once if its challenge was declined
+func (m *MockServer) WaitGame(id string, timeout time.Duration) (MockGame, error) {
+	expired := time.After(timeout)
+
+	for {
+		m.mu.Lock()
+		changed := m.changed
+		status, ok := m.statuses[id]
+		m.mu.Unlock()
+
+		if ok && status != STATUS_CREATED && status != CHALLENGE_ACCEPTED {
+			return MockGame{}, fmt.Errorf("challenge %s declined: %s", id, status)
+		}
+
+		if game, ok := m.Game(id); ok && game.Status != STATUS_STARTED {
+			return game, nil
+		}
+
+		select {
+		case <-changed:
+		case <-expired:
+			game, _ := m.Game(id)
+			return game, fmt.Errorf("game %s not over after %v, %d moves played", id, timeout, len(game.Moves))
+		}
+	}
+}
+
+// ServeHTTP answers the calls of the bot
+func (m *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+m.Token {
+		mockError(w, http.StatusUnauthorized, "No such token")
+		return
+	}
+
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	route := strings.Join(path, "/")
+
+	switch {
+	case r.Method == http.MethodGet && route == "api/account":
+		json.NewEncoder(w).Encode(Account{ID: m.BotID, Username: m.BotID, Title: "BOT"})
+	case r.Method == http.MethodGet && route == "api/stream/event":
+		m.streamEvents(w, r)
+	case r.Method == http.MethodPost && len(path) == 4 && path[1] == "challenge" && (path[3] == "accept" || path[3] == "decline"):
+		m.answerChallenge(w, r, path[2], path[3] == "accept")
+	case r.Method == http.MethodGet && len(path) == 5 && strings.HasPrefix(route, "api/bot/game/stream/"):
+		m.streamGame(w, r, path[4])
+	case r.Method == http.MethodPost && len(path) == 6 && path[1] == "bot" && path[2] == "game" && path[4] == "move":
+		m.move(w, path[3], path[5])
+	default:
+		mockError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// streamEvents sends the events of the bot account until the bot goes away
+func (m *MockServer) streamEvents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+
+	for {
+		select {
+		case event := <-m.events:
+			writeLine(w, event)
+		case <-r.Context().Done():
+			return
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// answerChallenge accepts or declines the challenge with id, an accepted challenge starts its game
+func (m *MockServer) answerChallenge(w http.ResponseWriter, r *http.Request, id string, accept bool) {
+	m.mu.Lock()
+
+	c, ok := m.challenges[id]
+	if !ok || m.statuses[id] != STATUS_CREATED {
+		m.mu.Unlock()
+		mockError(w, http.StatusNotFound, "Challenge not found")
+		return
+	}
+
+	if !accept {
+		m.statuses[id] = r.FormValue("reason")
+		m.notify()
+		m.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+		m.events <- Event{Type: EVENT_CHALLENGE_DECLINED, Challenge: &c}
+		return
+	}
+
+	g, err := m.newGame(c)
+	if err != nil {
+		m.mu.Unlock()
+		mockError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	m.statuses[id] = CHALLENGE_ACCEPTED
+	m.games[id] = g
+
+	if g.pos.Us() != g.BotColor {
+		m.opponentMove(g)
+	}
+
+	m.mu.Unlock()
+
+	json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+	m.events <- Event{Type: EVENT_GAME_START, Game: &GameEventInfo{ID: id, GameID: id, Color: colorName(g.BotColor)}}
+}
+
+// streamGame sends the game with id and its states until it is over
+func (m *MockServer) streamGame(w http.ResponseWriter, r *http.Request, id string) {
+	m.mu.Lock()
+
+	g, ok := m.games[id]
+	if !ok {
+		m.mu.Unlock()
+		mockError(w, http.StatusNotFound, "Game not found")
+		return
+	}
+
+	full := g.full
+	full.State = m.state(g)
+
+	states := make(chan GameState, 1024)
+	if g.Status == STATUS_STARTED {
+		g.streams = append(g.streams, states)
+	} else {
+		close(states)
+	}
+
+	m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	writeLine(w, full)
+
+	for {
+		select {
+		case state, ok := <-states:
+			if !ok {
+				return
+			}
+			writeLine(w, state)
+		case <-r.Context().Done():
+			return
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// move plays the move of the bot written uci in the game with id, the opponent answers at once
+func (m *MockServer) move(w http.ResponseWriter, id string, uci string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g, ok := m.games[id]
+	if !ok {
+		mockError(w, http.StatusNotFound, "Game not found")
+		return
+	}
+	if g.Status != STATUS_STARTED || g.pos.Us() != g.BotColor {
+		mockError(w, http.StatusBadRequest, "Not your turn, or game already over")
+		return
+	}
+
+	move, err := ParseUCIMove(g.pos, uci)
+	if err != nil {
+		mockError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	m.play(g, move)
+
+	if g.Status == STATUS_STARTED && m.MaxPlies > 0 && len(g.Moves) >= m.MaxPlies {
+		g.Status, g.Winner = STATUS_RESIGN, colorName(g.BotColor)
+		m.update(g)
+	}
+	if g.Status == STATUS_STARTED {
+		m.opponentMove(g)
+	}
+
+	json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+}
+
+// newGame returns the game of challenge c
+func (m *MockServer) newGame(c Challenge) (*mockGame, error) {
+	variant, chess960, ok := VariantOf(c.Variant.Key)
+	if !ok {
+		return nil, fmt.Errorf("unsupported variant %s", c.Variant.Key)
+	}
+
+	g := &mockGame{MockGame: MockGame{ID: c.ID, Variant: variant, Chess960: chess960, Status: STATUS_STARTED}}
+
+	g.full = GameFull{
+		Type:       EVENT_GAME_FULL,
+		ID:         c.ID,
+		Variant:    c.Variant,
+		Speed:      c.Speed,
+		Rated:      c.Rated,
+		InitialFen: c.InitialFen,
+	}
+	if g.full.InitialFen == "" {
+		g.full.InitialFen = INITIAL_FEN_STARTPOS
+	}
+
+	pos, err := GamePosition(g.full, "")
+	if err != nil {
+		return nil, err
+	}
+	g.pos = pos
+
+	switch c.Color {
+	case "white":
+		g.BotColor = butils.Black
+	case "black":
+		g.BotColor = butils.White
+	default:
+		g.BotColor = []butils.Color{butils.White, butils.Black}[m.rng.Intn(2)]
+	}
+	bot, opponent := User{ID: m.BotID, Name: m.BotID, Title: "BOT"}, c.Challenger
+	if g.BotColor == butils.White {
+		g.full.White, g.full.Black = bot, opponent
+	} else {
+		g.full.White, g.full.Black = opponent, bot
+	}
+
+	if c.TimeControl.Type == "clock" {
+		g.full.Clock = &Clock{Initial: int64(c.TimeControl.Limit) * 1000, Increment: int64(c.TimeControl.Increment) * 1000}
+		g.WTime = time.Duration(c.TimeControl.Limit) * time.Second
+		g.BTime = g.WTime
+		g.inc = time.Duration(c.TimeControl.Increment) * time.Second
+	}
+
+	g.turnStart = time.Now()
+
+	return g, nil
+}
+
+// opponentMove plays the move of the opponent, an opponent without a legal move resigns
+func (m *MockServer) opponentMove(g *mockGame) {
+	legal := g.pos.LegalMoves()
+
+	move := butils.NullMove
+	if m.Opponent != nil {
+		move = m.Opponent(g.pos.Clone())
+	} else if len(legal) > 0 {
+		move = legal[m.rng.Intn(len(legal))]
+	}
+
+	for _, l := range legal {
+		if l == move {
+			m.play(g, move)
+			return
+		}
+	}
+
+	g.Status, g.Winner = STATUS_RESIGN, colorName(g.BotColor)
+	m.update(g)
+}
+
+// play plays move in g, the clock of the mover runs from the second move of each side
+// a move played after the clock ran out loses on time
+func (m *MockServer) play(g *mockGame, move butils.Move) {
+	us := g.pos.Us()
+
+	if g.full.Clock != nil && len(g.Moves) >= 2 {
+		clock := &g.WTime
+		if us == butils.Black {
+			clock = &g.BTime
+		}
+		*clock -= time.Since(g.turnStart)
+		if *clock < 0 {
+			*clock = 0
+			g.Status, g.Winner = STATUS_OUT_OF_TIME, colorName(us.Opposite())
+			m.update(g)
+			return
+		}
+		*clock += g.inc
+	}
+
+	g.Moves = append(g.Moves, g.pos.MoveToUCI(move))
+	g.pos.DoMove(move)
+	g.turnStart = time.Now()
+
+	g.Status, g.Winner = mockStatus(g.pos)
+
+	m.update(g)
+}
+
+// update sends the state of g to its streams, they are closed when the game is over
+func (m *MockServer) update(g *mockGame) {
+	state := m.state(g)
+
+	for _, stream := range g.streams {
+		select {
+		case stream <- state:
+		default:
+		}
+		if g.Status != STATUS_STARTED {
+			close(stream)
+		}
+	}
+	if g.Status != STATUS_STARTED {
+		g.streams = nil
+	}
+
+	m.notify()
+}
+
+// notify wakes up the callers of WaitGame
+func (m *MockServer) notify() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+// state returns the game state of g, the clocks of games without one are sent as lichess does for correspondence games
+func (m *MockServer) state(g *mockGame) GameState {
+	state := GameState{
+		Type:   EVENT_GAME_STATE,
+		Moves:  strings.Join(g.Moves, " "),
+		WTime:  g.WTime.Milliseconds(),
+		BTime:  g.BTime.Milliseconds(),
+		WInc:   g.inc.Milliseconds(),
+		BInc:   g.inc.Milliseconds(),
+		Status: g.Status,
+		Winner: g.Winner,
+	}
+
+	if g.full.Clock == nil {
+		state.WTime, state.BTime = 2147483647, 2147483647
+	}
+
+	return state
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// NewMockServer starts a mock server for the bot account botID with token
+func NewMockServer(token string, botID string) *MockServer {
+	m := &MockServer{
+		Token:      token,
+		BotID:      botID,
+		events:     make(chan Event, 64),
+		rng:        rand.New(rand.NewSource(1)),
+		challenges: map[string]Challenge{},
+		statuses:   map[string]string{},
+		games:      map[string]*mockGame{},
+		changed:    make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+
+	m.server = httptest.NewServer(m)
+	m.URL = m.server.URL
+
+	return m
+}
+
+// mockStatus returns the status of the game in pos with its winner
+func mockStatus(pos *butils.Position) (string, string) {
+	us := pos.Us()
+
+	// atomic games end with the king of the side to move exploded
+	if pos.ByPiece(us, butils.King) == 0 {
+		return STATUS_VARIANT_END, colorName(us.Opposite())
+	}
+
+	if !pos.HasLegalMoves() {
+		if pos.IsChecked(us) {
+			return STATUS_MATE, colorName(us.Opposite())
+		}
+		return STATUS_STALEMATE, ""
+	}
+
+	if pos.InsufficientMaterial() || pos.FiftyMoveRule() || pos.ThreeFoldRepetition() >= 3 {
+		return STATUS_DRAW, ""
+	}
+
+	return STATUS_STARTED, ""
+}
+
+// mockSpeed returns the speed of a time control, as lichess estimates it from the time of 40 moves
+func mockSpeed(tc ChallengeTimeControl) string {
+	if tc.Type != "clock" {
+		return "correspondence"
+	}
+
+	switch estimate := tc.Limit + 40*tc.Increment; {
+	case estimate < 30:
+		return "ultraBullet"
+	case estimate < 180:
+		return "bullet"
+	case estimate < 480:
+		return "blitz"
+	case estimate < 1500:
+		return "rapid"
+	}
+
+	return "classical"
+}
+
+// colorName returns white or black
+func colorName(color butils.Color) string {
+	if color == butils.White {
+		return "white"
+	}
+	return "black"
+}
+
+// writeLine writes v as a line of an ndjson stream
+func writeLine(w http.ResponseWriter, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+
+	w.Write(append(data, '\n'))
+	w.(http.Flusher).Flush()
+}
+
+// mockError answers an error as lichess does
+func mockError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,178 @@
+package lichess
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// types
+
+// Options controls a bot
+type Options struct {
+	URL          string        // address of the api, LICHESS_URL or a mock server
+	Token        string        // api token of the bot account
+	Variants     []string      // lichess keys of the variants accepted, of standard, fromPosition, chess960 and atomic
+	MaxGames     int           // games played at the same time, further challenges are declined
+	MoveOverhead time.Duration // kept off the clock for the network lag
+	MoveTime     time.Duration // search time per move of games without a clock
+	Threads      int           // search threads of a game
+	Log          func(string)  // called with what the bot does, nil for none
+}
+
+// Bot plays the games of a lichess bot account with the engine
+type Bot struct {
+	options Options
+	client  *http.Client
+	id      string // of the bot account
+	mu      sync.Mutex
+	games   map[string]bool // accepted or playing games by id, true once played
+	wg      sync.WaitGroup
+}
+
+// User is a player of a challenge or a game
+type User struct {
+	ID    string `json:"id"`
+	Name  string `json:"name,omitempty"`
+	Title string `json:"title,omitempty"`
+}
+
+// Account is the account of the token
+type Account struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Title    string `json:"title,omitempty"`
+}
+
+// Variant is the variant of a challenge or a game
+type Variant struct {
+	Key  string `json:"key"`
+	Name string `json:"name,omitempty"`
+}
+
+// ChallengeTimeControl is the time control of a challenge, in seconds
+type ChallengeTimeControl struct {
+	Type      string `json:"type"` // clock, correspondence or unlimited
+	Limit     int    `json:"limit,omitempty"`
+	Increment int    `json:"increment,omitempty"`
+}
+
+// Challenge is a challenge sent to the bot
+type Challenge struct {
+	ID          string               `json:"id"`
+	Challenger  User                 `json:"challenger"`
+	DestUser    User                 `json:"destUser"`
+	Variant     Variant              `json:"variant"`
+	Rated       bool                 `json:"rated"`
+	Speed       string               `json:"speed,omitempty"`
+	TimeControl ChallengeTimeControl `json:"timeControl"`
+	Color       string               `json:"color"` // of the challenger, white, black or random
+	InitialFen  string               `json:"initialFen,omitempty"`
+}
+
+// GameEventInfo is the game of a gameStart or gameFinish event
+type GameEventInfo struct {
+	ID     string `json:"id"`
+	GameID string `json:"gameId"`
+	Color  string `json:"color,omitempty"` // of the bot
+}
+
+// Event is an event of the account stream
+type Event struct {
+	Type      string         `json:"type"`
+	Challenge *Challenge     `json:"challenge,omitempty"`
+	Game      *GameEventInfo `json:"game,omitempty"`
+}
+
+// Clock is the clock of a game, in milliseconds
+type Clock struct {
+	Initial   int64 `json:"initial"`
+	Increment int64 `json:"increment"`
+}
+
+// GameState is the moves and clocks of a game, times are in milliseconds
+type GameState struct {
+	Type   string `json:"type"`
+	Moves  string `json:"moves"` // uci moves from the initial fen separated by spaces
+	WTime  int64  `json:"wtime"`
+	BTime  int64  `json:"btime"`
+	WInc   int64  `json:"winc"`
+	BInc   int64  `json:"binc"`
+	Status string `json:"status"`
+	Winner string `json:"winner,omitempty"`
+}
+
+// GameFull is the first event of a game stream
+type GameFull struct {
+	Type       string    `json:"type"`
+	ID         string    `json:"id"`
+	Variant    Variant   `json:"variant"`
+	Clock      *Clock    `json:"clock"` // nil for correspondence and unlimited games
+	Speed      string    `json:"speed,omitempty"`
+	Rated      bool      `json:"rated"`
+	White      User      `json:"white"`
+	Black      User      `json:"black"`
+	InitialFen string    `json:"initialFen"`
+	State      GameState `json:"state"`
+}
+
+// APIError is an answer of the api other than 200
+type APIError struct {
+	Status  int
+	Message string
+}
+
+// MockServer is a local stand-in for the lichess bot api, it challenges the bot and plays against it
+type MockServer struct {
+	URL        string
+	Token      string
+	BotID      string
+	MaxPlies   int                                    // the opponent resigns once a game has this many plies, never if 0
+	Opponent   func(pos *butils.Position) butils.Move // chooses the moves of the opponent, random legal moves if nil
+	server     *httptest.Server
+	mu         sync.Mutex
+	events     chan Event
+	next       int        // number of the next challenge id
+	rng        *rand.Rand // of the random opponent
+	challenges map[string]Challenge
+	statuses   map[string]string // of the challenges by id, created, accepted or the decline reason
+	games      map[string]*mockGame
+	changed    chan struct{} // closed and replaced when a game or a challenge changes
+	done       chan struct{} // closed when the server closes, to end the streams
+}
+
+// MockGame is a game of the mock server as seen by the tests
+type MockGame struct {
+	ID       string
+	Variant  utils.VariantKey
+	Chess960 bool
+	BotColor butils.Color
+	Moves    []string
+	Status   string
+	Winner   string
+	WTime    time.Duration
+	BTime    time.Duration
+}
+
+// mockGame is a game being played on the mock server
+type mockGame struct {
+	MockGame
+	full      GameFull
+	pos       *butils.Position
+	inc       time.Duration
+	turnStart time.Time
+	streams   []chan GameState
+}
+
+/////////////////////////////////////////////////////////////////////
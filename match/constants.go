@@ -0,0 +1,46 @@
+package match
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import "time"
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// constants
+
+// DEFAULT_GAMES is the default number of games of a match
+const DEFAULT_GAMES = 100
+
+// DEFAULT_MAX_PLIES is the default number of plies after which a game is adjudicated a draw
+const DEFAULT_MAX_PLIES = 400
+
+// TIME_MARGIN is the time a player may exceed its clock by before it loses on time
+const TIME_MARGIN = 50 * time.Millisecond
+
+// UCI_TIMEOUT is how long an uci engine may take to answer a command that is not a search
+const UCI_TIMEOUT = 10 * time.Second
+
+// INTERNAL_ENGINE is the command of a player that runs the engine of this repository in process
+const INTERNAL_ENGINE = "internal"
+
+// SPRT outcomes
+const SPRT_CONTINUE = 0
+const SPRT_H0 = -1 // the first player is no more than Elo0 stronger
+const SPRT_H1 = 1  // the first player is at least Elo1 stronger
+
+// default SPRT bounds and error rates
+const DEFAULT_SPRT_ELO0 = 0
+const DEFAULT_SPRT_ELO1 = 5
+const DEFAULT_SPRT_ALPHA = 0.05
+const DEFAULT_SPRT_BETA = 0.05
+
+// terminations written to the Termination tag
+const TERMINATION_NORMAL = "normal"
+const TERMINATION_ADJUDICATION = "adjudication"
+const TERMINATION_TIME_FORFEIT = "time forfeit"
+const TERMINATION_RULES_INFRACTION = "rules infraction"
+const TERMINATION_ABANDONED = "abandoned"
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,197 @@
+package match
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/pgn"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// playGame plays game number with players[white] as white from opening
+func (m *Match) playGame(number int, white int, players [2]Player, opening Opening) GameResult {
+	opts := m.Options
+
+	res := GameResult{Number: number, White: white, Result: pgn.RESULT_UNKNOWN}
+
+	sides := [butils.ColorArraySize]Player{}
+	sides[butils.White], sides[butils.Black] = players[white], players[1-white]
+
+	game := &pgn.Game{Result: pgn.RESULT_UNKNOWN}
+	game.SetTag("Event", opts.Event)
+	game.SetTag("Date", time.Now().Format("2006.01.02"))
+	game.SetTag("Round", strconv.Itoa(number))
+	game.SetTag("White", sides[butils.White].Name())
+	game.SetTag("Black", sides[butils.Black].Name())
+	game.SetVariant(opts.Variant, opts.Chess960)
+	game.SetTag("TimeControl", opts.TimeControl.String())
+	res.Game = game
+
+	fen := opening.Fen
+	if fen == "" {
+		fen = utils.StartFenForVariant(opts.Variant)
+	}
+
+	pos, err := butils.PositionFromFENAndVariant(fen, opts.Variant)
+	if err != nil {
+		res.Err = fmt.Errorf("opening %s: %v", fen, err)
+		return res
+	}
+	pos.Chess960 = opts.Chess960
+
+	if opening.Fen != "" {
+		game.SetStartFen(opts.Variant, pos.String())
+	}
+
+	for _, san := range opening.Moves {
+		move, err := pos.SanToMove(san)
+		if err != nil {
+			res.Err = fmt.Errorf("opening %s: %v", fen, err)
+			return res
+		}
+		game.Moves = append(game.Moves, &pgn.Move{San: pos.MoveToSan(move), Comment: "book"})
+		pos.DoMove(move)
+	}
+
+	for _, player := range players {
+		if err := player.NewGame(opts.Variant, opts.Chess960); err != nil {
+			res.Err = err
+			return res
+		}
+	}
+
+	clk := newClock(opts.TimeControl)
+
+	for plies := 0; ; plies++ {
+		if res.Result, res.Termination, res.Reason = adjudicate(pos); res.Result != "" {
+			break
+		}
+
+		if opts.MaxPlies > 0 && plies >= opts.MaxPlies {
+			res.Result, res.Termination, res.Reason = "1/2-1/2", TERMINATION_ADJUDICATION, "maximum number of plies"
+			break
+		}
+
+		us := pos.Us()
+		start := time.Now()
+		info, err := sides[us].Move(pos, clk.limits(us))
+		elapsed := time.Since(start)
+
+		if err == nil && !isLegal(pos, info.Move) {
+			err = fmt.Errorf("%w %v", utils.ErrIllegalMove, info.Move)
+		}
+
+		if err != nil {
+			res.Result, res.Err = lossOf(us), fmt.Errorf("%s: %v", sides[us].Name(), err)
+			if errors.Is(err, utils.ErrIllegalMove) {
+				res.Termination, res.Reason = TERMINATION_RULES_INFRACTION, "illegal move"
+			} else {
+				res.Termination, res.Reason = TERMINATION_ABANDONED, "player error"
+			}
+			break
+		}
+
+		if !clk.spend(us, elapsed) {
+			res.Result, res.Termination, res.Reason = lossOf(us), TERMINATION_TIME_FORFEIT, "time forfeit"
+			break
+		}
+
+		game.Moves = append(game.Moves, &pgn.Move{San: pos.MoveToSan(info.Move), Comment: moveComment(info, elapsed)})
+		pos.DoMove(info.Move)
+	}
+
+	game.Result = res.Result
+	game.SetTag("Result", res.Result)
+	game.SetTag("Termination", res.Termination)
+	if len(game.Moves) > 0 {
+		game.Moves[len(game.Moves)-1].Comment = joinComment(game.Moves[len(game.Moves)-1].Comment, res.Reason)
+	} else {
+		game.Comment = res.Reason
+	}
+
+	return res
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// adjudicate returns the result, termination and reason of a finished game in pos
+// the result is empty if the game goes on
+func adjudicate(pos *butils.Position) (string, string, string) {
+	us := pos.Us()
+
+	// eightpiece and atomic games can end with the king of the side to move gone
+	if pos.ByPiece(us, butils.King) == 0 {
+		return lossOf(us), TERMINATION_NORMAL, "king captured"
+	}
+
+	if !pos.HasLegalMoves() {
+		if pos.IsChecked(us) {
+			return lossOf(us), TERMINATION_NORMAL, "checkmate"
+		}
+		return "1/2-1/2", TERMINATION_NORMAL, "stalemate"
+	}
+
+	switch {
+	case pos.InsufficientMaterial():
+		return "1/2-1/2", TERMINATION_NORMAL, "insufficient material"
+	case pos.FiftyMoveRule():
+		return "1/2-1/2", TERMINATION_NORMAL, "fifty move rule"
+	case pos.ThreeFoldRepetition() >= 3:
+		return "1/2-1/2", TERMINATION_NORMAL, "threefold repetition"
+	}
+
+	return "", "", ""
+}
+
+// lossOf returns the pgn result of a game lost by color
+func lossOf(color butils.Color) string {
+	if color == butils.White {
+		return "0-1"
+	}
+	return "1-0"
+}
+
+// isLegal returns whether move is a legal move in pos
+func isLegal(pos *butils.Position, move butils.Move) bool {
+	for _, legal := range pos.LegalMoves() {
+		if legal == move {
+			return true
+		}
+	}
+	return false
+}
+
+// moveComment returns the pgn comment of a move, as in +0.35/12 0.52s or -M3/20 1.2s
+func moveComment(info MoveInfo, elapsed time.Duration) string {
+	score := fmt.Sprintf("%+.2f", float64(info.Score)/100)
+	if info.Mate > 0 {
+		score = fmt.Sprintf("+M%d", info.Mate)
+	} else if info.Mate < 0 {
+		score = fmt.Sprintf("-M%d", -info.Mate)
+	}
+	return fmt.Sprintf("%s/%d %.2fs", score, info.Depth, elapsed.Seconds())
+}
+
+// joinComment appends comment b to comment a
+func joinComment(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + ", " + b
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,211 @@
+package match
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Run plays the games of the match, each opening is played twice with colors swapped
+// returns when all games are played or the sprt is decided
+func (m *Match) Run() error {
+	opts := &m.Options
+
+	tc := opts.TimeControl
+	if tc.Time == 0 && tc.MoveTime == 0 && tc.Depth == 0 && tc.Nodes == 0 {
+		return fmt.Errorf("the match has no time, depth or nodes limit")
+	}
+
+	if opts.Games <= 0 {
+		opts.Games = DEFAULT_GAMES
+	}
+	opts.Games += opts.Games % 2
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	jobs := make(chan int, opts.Games)
+	for i := 0; i < opts.Games; i++ {
+		jobs <- i
+	}
+	close(jobs)
+
+	errs := make(chan error, opts.Concurrency)
+	wg := sync.WaitGroup{}
+
+	for w := 0; w < opts.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- m.work(jobs)
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	m.log(m.Summary())
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Summary returns the score, elo and sprt state of the first player
+func (m *Match) Summary() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.summary()
+}
+
+// SPRTStatus returns SPRT_H0 or SPRT_H1 if the sprt was decided, SPRT_CONTINUE otherwise
+func (m *Match) SPRTStatus() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.sprt
+}
+
+// work plays the games of jobs with its own players until jobs is empty or the match is stopped
+func (m *Match) work(jobs <-chan int) error {
+	players := [2]Player{}
+
+	closePlayers := func() {
+		for i, player := range players {
+			if player != nil {
+				player.Close()
+				players[i] = nil
+			}
+		}
+	}
+	defer closePlayers()
+
+	for i := range jobs {
+		if m.isStopped() {
+			return nil
+		}
+
+		// a player is started again after it failed a game
+		for j := range players {
+			if players[j] != nil {
+				continue
+			}
+			player, err := NewPlayer(m.Players[j])
+			if err != nil {
+				m.stop()
+				return fmt.Errorf("starting player %d: %v", j+1, err)
+			}
+			players[j] = player
+		}
+
+		opening := Opening{}
+		if len(m.Options.Openings) > 0 {
+			opening = m.Options.Openings[(i/2)%len(m.Options.Openings)]
+		}
+
+		res := m.playGame(i+1, i%2, players, opening)
+		m.record(res)
+
+		if res.Termination == TERMINATION_ABANDONED || res.Termination == "" {
+			closePlayers()
+		}
+	}
+
+	return nil
+}
+
+// record counts a finished game, writes it to the pgn and checks the sprt
+func (m *Match) record(res GameResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Results = append(m.Results, res)
+
+	if res.Err != nil {
+		m.log(fmt.Sprintf("game %d: %v", res.Number, res.Err))
+	}
+
+	if res.Termination == "" {
+		// the game could not be started
+		return
+	}
+
+	m.Score.Add(res.Result, res.White == 0)
+
+	if m.Options.PGN != nil && res.Game != nil {
+		// games are separated by empty lines as by pgn.Write
+		err := res.Game.Write(m.Options.PGN)
+		if err == nil {
+			_, err = io.WriteString(m.Options.PGN, "\n")
+		}
+		if err != nil {
+			m.log(fmt.Sprintf("writing game %d: %v", res.Number, err))
+		}
+	}
+
+	m.log(fmt.Sprintf("game %d %s - %s %s {%s}, %s",
+		res.Number, res.Game.Tag("White"), res.Game.Tag("Black"), res.Result, res.Reason, m.summary()))
+
+	if m.Options.SPRT != nil && !m.stopped {
+		if status := m.Options.SPRT.Status(m.Score); status != SPRT_CONTINUE {
+			m.stopped, m.sprt = true, status
+		}
+	}
+}
+
+// summary returns the summary of the match, the caller holds mu
+func (m *Match) summary() string {
+	elo, margin := m.Score.Elo()
+	s := fmt.Sprintf("score %s %.1f/%d elo %.1f +- %.1f", m.Score, m.Score.Points(), m.Score.Games(), elo, margin)
+
+	if t := m.Options.SPRT; t != nil {
+		lower, upper := t.Bounds()
+		s += fmt.Sprintf(" llr %.2f (%.2f, %.2f)", t.LLR(m.Score), lower, upper)
+		switch m.sprt {
+		case SPRT_H0:
+			s += " H0 accepted"
+		case SPRT_H1:
+			s += " H1 accepted"
+		}
+	}
+
+	return s
+}
+
+// isStopped tells whether no more games should be started
+func (m *Match) isStopped() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stopped
+}
+
+// stop keeps the other workers from starting more games
+func (m *Match) stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stopped = true
+}
+
+// log writes a line to the log of the match if there is one
+func (m *Match) log(line string) {
+	if m.Options.Log != nil {
+		m.Options.Log(line)
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,204 @@
+package match
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/pgn"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+func TestParseTimeControl(t *testing.T) {
+	for s, expected := range map[string]TimeControl{
+		"40/60+0.6": {Moves: 40, Time: time.Minute, Inc: 600 * time.Millisecond},
+		"10+0.1":    {Time: 10 * time.Second, Inc: 100 * time.Millisecond},
+		"300":       {Time: 5 * time.Minute},
+		"-":         {},
+	} {
+		tc, err := ParseTimeControl(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc != expected {
+			t.Errorf("%s: expected %+v, got %+v", s, expected, tc)
+		}
+		if tc.String() != s {
+			t.Errorf("%s: formatted as %s", s, tc)
+		}
+	}
+
+	for _, s := range []string{"x", "0", "40/", "/60", "10+x", "-1"} {
+		if _, err := ParseTimeControl(s); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+func TestClock(t *testing.T) {
+	c := newClock(TimeControl{Moves: 2, Time: time.Second, Inc: 100 * time.Millisecond})
+
+	if l := c.limits(butils.White); l.WTime != time.Second || l.MovesToGo != 2 || l.WInc != 100*time.Millisecond {
+		t.Errorf("unexpected limits %+v", l)
+	}
+	if !c.spend(butils.White, 600*time.Millisecond) {
+		t.Fatal("flagged too early")
+	}
+	if l := c.limits(butils.White); l.WTime != 500*time.Millisecond || l.MovesToGo != 1 {
+		t.Errorf("unexpected limits %+v", l)
+	}
+	if !c.spend(butils.White, 400*time.Millisecond) {
+		t.Fatal("flagged too early")
+	}
+	// the new period adds time
+	if l := c.limits(butils.White); l.WTime != 1200*time.Millisecond || l.MovesToGo != 2 {
+		t.Errorf("unexpected limits %+v", l)
+	}
+	if c.spend(butils.White, 2*time.Second) {
+		t.Error("expected flag")
+	}
+}
+
+func TestElo(t *testing.T) {
+	elo, margin := Score{Wins: 30, Draws: 40, Losses: 30}.Elo()
+	if elo != 0 || margin <= 0 {
+		t.Errorf("expected 0 elo with a margin, got %f %f", elo, margin)
+	}
+
+	// 75% is 190.8 elo
+	if elo, _ := (Score{Wins: 50, Draws: 50}).Elo(); math.Abs(elo-190.8) > 0.1 {
+		t.Errorf("expected 190.8 elo, got %f", elo)
+	}
+
+	if elo, _ := (Score{Wins: 3}).Elo(); !math.IsInf(elo, 1) {
+		t.Errorf("expected infinite elo, got %f", elo)
+	}
+}
+
+func TestSPRT(t *testing.T) {
+	sprt := NewSPRT()
+
+	lower, upper := sprt.Bounds()
+	if math.Abs(lower+2.944) > 0.001 || math.Abs(upper-2.944) > 0.001 {
+		t.Errorf("unexpected bounds %f %f", lower, upper)
+	}
+
+	for _, c := range []struct {
+		score  Score
+		status int
+	}{
+		{Score{Wins: 10, Draws: 10, Losses: 10}, SPRT_CONTINUE},
+		{Score{Wins: 600, Draws: 1000, Losses: 400}, SPRT_H1},
+		{Score{Wins: 400, Draws: 1000, Losses: 600}, SPRT_H0},
+		{Score{Wins: 1000, Losses: 1000}, SPRT_CONTINUE},
+	} {
+		if status := sprt.Status(c.score); status != c.status {
+			t.Errorf("%s: expected %d, got %d, llr %f", c.score, c.status, status, sprt.LLR(c.score))
+		}
+	}
+}
+
+func TestAdjudicate(t *testing.T) {
+	for _, c := range []struct {
+		variant utils.VariantKey
+		fen     string
+		result  string
+		reason  string
+	}{
+		{utils.VARIANT_STANDARD, "7k/6Q1/6K1/8/8/8/8/8 b - - 0 1", "1-0", "checkmate"},
+		{utils.VARIANT_STANDARD, "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", "1/2-1/2", "stalemate"},
+		{utils.VARIANT_STANDARD, "7k/8/6K1/8/8/8/8/6N1 b - - 0 1", "1/2-1/2", "insufficient material"},
+		{utils.VARIANT_STANDARD, "7k/8/6K1/8/8/8/8/6R1 b - - 100 80", "1/2-1/2", "fifty move rule"},
+		{utils.VARIANT_EIGHTPIECE, "8/8/8/8/8/8/7k/6S1 w - - 0 1 -", "0-1", "king captured"},
+		{utils.VARIANT_STANDARD, "7k/8/6K1/8/8/8/8/6R1 b - - 0 1", "", ""},
+	} {
+		pos, err := butils.PositionFromFENAndVariant(c.fen, c.variant)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result, _, reason := adjudicate(pos); result != c.result || reason != c.reason {
+			t.Errorf("%s: expected %q %q, got %q %q", c.fen, c.result, c.reason, result, reason)
+		}
+	}
+}
+
+func TestReadOpenings(t *testing.T) {
+	openings, err := ReadEPDOpenings(strings.NewReader(
+		"# openings\n\nrnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 id \"e4\";\n"), utils.VARIANT_STANDARD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(openings) != 1 || !strings.HasPrefix(openings[0].Fen, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq") {
+		t.Errorf("unexpected openings %+v", openings)
+	}
+
+	openings, err = ReadPGNOpenings(strings.NewReader("1. e4 e5 2. Nf3 Nc6 3. Bb5 *\n\n1. d4 d5 *\n"), utils.VARIANT_STANDARD, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(openings) != 2 || strings.Join(openings[0].Moves, " ") != "e4 e5 Nf3 Nc6" || len(openings[1].Moves) != 2 {
+		t.Errorf("unexpected openings %+v", openings)
+	}
+
+	if _, err := ReadPGNOpenings(strings.NewReader("1. e4 e4 *\n"), utils.VARIANT_STANDARD, 0); err == nil {
+		t.Error("expected error for an illegal opening move")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	buff := &bytes.Buffer{}
+
+	m := &Match{
+		Options: Options{
+			TimeControl: TimeControl{Depth: 1},
+			Openings:    []Opening{{Moves: []string{"e4", "e5"}}, {Moves: []string{"d4", "d5"}}},
+			Games:       3,
+			Concurrency: 2,
+			MaxPlies:    20,
+			Event:       "test",
+			PGN:         buff,
+		},
+		Players: [2]PlayerConfig{
+			{Name: "first", Command: INTERNAL_ENGINE},
+			{Name: "second", Command: INTERNAL_ENGINE},
+		},
+	}
+
+	if err := m.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(m.Results) != 4 || m.Score.Games() != 4 {
+		t.Fatalf("expected 4 games, got %d results and score %s", len(m.Results), m.Score)
+	}
+
+	games, err := pgn.Parse(buff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 4 {
+		t.Fatalf("expected 4 games in the pgn, got %d", len(games))
+	}
+
+	for _, g := range games {
+		if g.Tag("Event") != "test" || g.Tag("Termination") == "" {
+			t.Errorf("unexpected tags %+v", g.Tags)
+		}
+		if _, err := g.ReplayPosition(); err != nil {
+			t.Errorf("round %s: %v", g.Tag("Round"), err)
+		}
+		if g.Moves[0].Comment != "book" {
+			t.Errorf("round %s: expected book comment, got %q", g.Tag("Round"), g.Moves[0].Comment)
+		}
+	}
+
+	// colors alternate
+	for _, res := range m.Results {
+		if res.White != (res.Number-1)%2 {
+			t.Errorf("game %d: white is player %d", res.Number, res.White)
+		}
+	}
+}
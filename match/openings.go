@@ -0,0 +1,104 @@
+package match
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/epd"
+	"github.com/easychessanimations/gochess/pgn"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// ReadEPDOpenings reads one opening position per line of r, operations after the position are ignored
+// empty lines and lines starting with # are skipped
+func ReadEPDOpenings(r io.Reader, variant utils.VariantKey) ([]Opening, error) {
+	openings := []Opening{}
+
+	scanner := bufio.NewScanner(r)
+	for num := 1; scanner.Scan(); num++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		record, err := epd.ParseEPD(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", num, err)
+		}
+
+		pos, err := butils.PositionFromFENAndVariant(record.Fen, variant)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", num, err)
+		}
+
+		openings = append(openings, Opening{Fen: pos.String()})
+	}
+
+	return openings, scanner.Err()
+}
+
+// ReadPGNOpenings reads the first maxPlies plies of the main line of each game of r, all plies if maxPlies is 0
+// games of other variants than variant are skipped
+func ReadPGNOpenings(r io.Reader, variant utils.VariantKey, maxPlies int) ([]Opening, error) {
+	games, err := pgn.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	openings := []Opening{}
+
+	for i, g := range games {
+		gameVariant, chess960, err := g.Variant()
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+		if gameVariant != variant {
+			continue
+		}
+
+		opening := Opening{Fen: g.Tag("FEN")}
+
+		fen, err := g.StartFen()
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+
+		// replay the moves so that a broken opening is reported before the match starts
+		pos, err := butils.PositionFromFENAndVariant(fen, variant)
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+		pos.Chess960 = chess960
+
+		for ply, m := range g.Moves {
+			if maxPlies > 0 && ply >= maxPlies {
+				break
+			}
+
+			move, err := pos.SanToMove(m.San)
+			if err != nil {
+				return nil, fmt.Errorf("game %d ply %d: %w", i+1, ply+1, err)
+			}
+
+			opening.Moves = append(opening.Moves, pos.MoveToSan(move))
+			pos.DoMove(move)
+		}
+
+		openings = append(openings, opening)
+	}
+
+	return openings, nil
+}
+
+/////////////////////////////////////////////////////////////////////
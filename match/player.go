@@ -0,0 +1,453 @@
+package match
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/easychessanimations/gochess/bengine"
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Name returns the name of the player
+func (p *EnginePlayer) Name() string {
+	return p.name
+}
+
+// NewGame does nothing, the engine keeps no state between moves apart from the hash table
+func (p *EnginePlayer) NewGame(variant utils.VariantKey, chess960 bool) error {
+	return nil
+}
+
+// Move searches pos with the engine
+func (p *EnginePlayer) Move(pos *butils.Position, limits Limits) (MoveInfo, error) {
+	p.Engine.SetPosition(pos.Clone())
+
+	tc := bengine.NewTimeControl(p.Engine.Position, false)
+	if limits.WTime > 0 {
+		tc.WTime, tc.WInc = limits.WTime, limits.WInc
+		tc.BTime, tc.BInc = limits.BTime, limits.BInc
+	}
+	if limits.MovesToGo > 0 {
+		tc.MovesToGo = int32(limits.MovesToGo)
+	}
+	if limits.MoveTime > 0 {
+		tc.WTime, tc.BTime, tc.MovesToGo = limits.MoveTime, limits.MoveTime, 1
+	}
+	if limits.Depth > 0 {
+		tc.Depth = int32(limits.Depth)
+	}
+	tc.Nodes = limits.Nodes
+	tc.Start(false)
+
+	score, pv := p.Engine.PlayMoves(tc, nil)
+	if len(pv) == 0 {
+		return MoveInfo{}, fmt.Errorf("%s found no move in %s", p.name, pos)
+	}
+
+	info := MoveInfo{Move: pv[0], Score: score, Depth: int(p.Engine.Stats.Depth)}
+	if score > bengine.KnownWinScore {
+		info.Mate = int(bengine.MateScore-score+1) / 2
+	} else if score < bengine.KnownLossScore {
+		info.Mate = int(bengine.MatedScore-score) / 2
+	}
+
+	return info, nil
+}
+
+// Close does nothing
+func (p *EnginePlayer) Close() error {
+	return nil
+}
+
+// setOption sets an option of the engine, the names are those of the uci options
+func (p *EnginePlayer) setOption(name string, value string) error {
+	eng := p.Engine
+
+	switch name {
+	case "BookFile":
+		bk, err := p.bookLoader.Load(value)
+		if err != nil {
+			return err
+		}
+		eng.Book = bk
+	case "TablebasePath":
+		return eng.SetTablebasePath(value)
+	case "Threads", "HandicapLevel", "Handicap Level":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		if name == "Threads" {
+			eng.Options.Threads = n
+		} else {
+			eng.Options.HandicapLevel = n
+		}
+	case "OwnBook", "UseAB":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		if name == "OwnBook" {
+			eng.Options.OwnBook = b
+		} else {
+			eng.UseAB = b
+		}
+	default:
+		return fmt.Errorf("unknown option %s of the internal engine", name)
+	}
+
+	return nil
+}
+
+// Name returns the name of the player, the id name of the engine if the config has none
+func (p *UCIPlayer) Name() string {
+	return p.name
+}
+
+// NewGame sets the variant options and starts a new game
+func (p *UCIPlayer) NewGame(variant utils.VariantKey, chess960 bool) error {
+	if variant != utils.VARIANT_STANDARD {
+		p.send("setoption name UCI_Variant value " + utils.VariantKeyToVariantKeyString(variant))
+	}
+	if chess960 {
+		p.send("setoption name UCI_Chess960 value true")
+	}
+
+	p.send("ucinewgame")
+
+	return p.isReady()
+}
+
+// Move sends pos with its moves to the engine and waits for its best move
+func (p *UCIPlayer) Move(pos *butils.Position, limits Limits) (MoveInfo, error) {
+	p.send(positionCommand(pos))
+
+	cmd := "go"
+	if limits.WTime > 0 {
+		cmd += fmt.Sprintf(" wtime %d btime %d", limits.WTime.Milliseconds(), limits.BTime.Milliseconds())
+		cmd += fmt.Sprintf(" winc %d binc %d", limits.WInc.Milliseconds(), limits.BInc.Milliseconds())
+	}
+	if limits.MovesToGo > 0 {
+		cmd += fmt.Sprintf(" movestogo %d", limits.MovesToGo)
+	}
+	if limits.MoveTime > 0 {
+		cmd += fmt.Sprintf(" movetime %d", limits.MoveTime.Milliseconds())
+	}
+	if limits.Depth > 0 {
+		cmd += fmt.Sprintf(" depth %d", limits.Depth)
+	}
+	if limits.Nodes > 0 {
+		cmd += fmt.Sprintf(" nodes %d", limits.Nodes)
+	}
+	p.send(cmd)
+
+	// an engine that does not answer long after its time ran out is hung
+	timeout := time.Duration(0)
+	if limits.MoveTime > 0 {
+		timeout = limits.MoveTime + UCI_TIMEOUT
+	} else if limits.WTime > 0 {
+		timeout = limits.WTime + UCI_TIMEOUT
+		if pos.Us() == butils.Black {
+			timeout = limits.BTime + UCI_TIMEOUT
+		}
+	}
+
+	info := MoveInfo{}
+	for {
+		line, err := p.readLine(timeout)
+		if err != nil {
+			return MoveInfo{}, err
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "info":
+			parseInfo(fields[1:], &info)
+		case "bestmove":
+			if len(fields) < 2 {
+				return MoveInfo{}, fmt.Errorf("%s sent bestmove without a move", p.name)
+			}
+			move, err := legalMoveFromUCI(pos, fields[1])
+			if err != nil {
+				return MoveInfo{}, fmt.Errorf("%s played %s: %v", p.name, fields[1], err)
+			}
+			info.Move = move
+			return info, nil
+		}
+	}
+}
+
+// Close asks the engine to quit and waits for it to exit
+func (p *UCIPlayer) Close() error {
+	p.send("quit")
+	p.stdin.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- p.cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(UCI_TIMEOUT):
+		p.cmd.Process.Kill()
+		return fmt.Errorf("%s did not quit", p.name)
+	}
+}
+
+// send writes a command to the engine, errors show up as a closed output
+func (p *UCIPlayer) send(cmd string) {
+	io.WriteString(p.stdin, cmd+"\n")
+}
+
+// readLine returns the next line written by the engine
+// timeout 0 waits forever
+func (p *UCIPlayer) readLine(timeout time.Duration) (string, error) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case line, ok := <-p.lines:
+		if !ok {
+			return "", fmt.Errorf("%s exited", p.name)
+		}
+		return line, nil
+	case <-expired:
+		return "", fmt.Errorf("%s did not answer in %v", p.name, timeout)
+	}
+}
+
+// waitFor reads lines until one starts with token
+func (p *UCIPlayer) waitFor(token string, timeout time.Duration) (string, error) {
+	for {
+		line, err := p.readLine(timeout)
+		if err != nil {
+			return "", err
+		}
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == token {
+			return line, nil
+		}
+	}
+}
+
+// isReady waits until the engine has processed the commands sent so far
+func (p *UCIPlayer) isReady() error {
+	p.send("isready")
+	_, err := p.waitFor("readyok", UCI_TIMEOUT)
+	return err
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// NewPlayer starts the player described by config
+func NewPlayer(config PlayerConfig) (Player, error) {
+	if config.Command == INTERNAL_ENGINE {
+		return newEnginePlayer(config)
+	}
+	return newUCIPlayer(config)
+}
+
+// ParsePlayerConfig parses a player given as comma separated key=value pairs, as in name=dev,cmd=internal,option.Threads=2
+// keys are name, cmd, arg (repeatable), dir and option.<uci option name>
+func ParsePlayerConfig(spec string) (PlayerConfig, error) {
+	config := PlayerConfig{Command: INTERNAL_ENGINE, Options: map[string]string{}}
+
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		i := strings.Index(pair, "=")
+		if i < 0 {
+			return config, fmt.Errorf("expected key=value in %s", pair)
+		}
+		key, value := strings.TrimSpace(pair[:i]), pair[i+1:]
+
+		switch {
+		case key == "name":
+			config.Name = value
+		case key == "cmd":
+			config.Command = value
+		case key == "arg":
+			config.Args = append(config.Args, value)
+		case key == "dir":
+			config.Dir = value
+		case strings.HasPrefix(key, "option."):
+			config.Options[strings.TrimPrefix(key, "option.")] = value
+		default:
+			return config, fmt.Errorf("unknown player key %s", key)
+		}
+	}
+
+	return config, nil
+}
+
+// newEnginePlayer returns a player with a new engine of this repository
+func newEnginePlayer(config PlayerConfig) (*EnginePlayer, error) {
+	p := &EnginePlayer{
+		Engine: bengine.NewEngine(nil, nil, bengine.Options{Threads: 1}),
+		name:   config.Name,
+	}
+	if p.name == "" {
+		p.name = INTERNAL_ENGINE
+	}
+
+	for name, value := range config.Options {
+		if err := p.setOption(name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// newUCIPlayer starts the uci engine of config and sets its options
+func newUCIPlayer(config PlayerConfig) (*UCIPlayer, error) {
+	cmd := exec.Command(config.Command, config.Args...)
+	cmd.Dir = config.Dir
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &UCIPlayer{
+		config: config,
+		name:   config.Name,
+		cmd:    cmd,
+		stdin:  stdin,
+		lines:  make(chan string, 256),
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			p.lines <- scanner.Text()
+		}
+		close(p.lines)
+	}()
+
+	if p.name == "" {
+		p.name = config.Command
+	}
+
+	p.send("uci")
+	for {
+		line, err := p.readLine(UCI_TIMEOUT)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		if line = strings.TrimSpace(line); line == "uciok" {
+			break
+		}
+		if strings.HasPrefix(line, "id name ") && config.Name == "" {
+			p.name = strings.TrimPrefix(line, "id name ")
+		}
+	}
+
+	for name, value := range config.Options {
+		p.send("setoption name " + name + " value " + value)
+	}
+
+	if err := p.isReady(); err != nil {
+		p.Close()
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// positionCommand returns the uci position command of pos with the moves played since it was set up
+func positionCommand(pos *butils.Position) string {
+	pos = pos.Clone()
+
+	moves := pos.MoveHistory()
+	for range moves {
+		pos.UndoMove()
+	}
+
+	cmd := "position fen " + pos.String()
+	if len(moves) > 0 {
+		cmd += " moves"
+		for _, move := range moves {
+			cmd += " " + pos.MoveToUCI(move)
+			pos.DoMove(move)
+		}
+	}
+
+	return cmd
+}
+
+// parseInfo updates info with the depth and score of the fields of an uci info line
+func parseInfo(fields []string, info *MoveInfo) {
+	for i := 0; i+1 < len(fields); i++ {
+		switch fields[i] {
+		case "depth":
+			if depth, err := strconv.Atoi(fields[i+1]); err == nil {
+				info.Depth = depth
+			}
+		case "score":
+			if i+2 >= len(fields) {
+				return
+			}
+			value, err := strconv.Atoi(fields[i+2])
+			if err != nil {
+				continue
+			}
+			if fields[i+1] == "mate" {
+				info.Mate = value
+			} else if fields[i+1] == "cp" {
+				info.Score, info.Mate = int32(value), 0
+			}
+		case "pv", "string":
+			// the rest of the line are moves or free text
+			return
+		}
+	}
+}
+
+// legalMoveFromUCI returns the legal move of pos written s in uci format
+func legalMoveFromUCI(pos *butils.Position, s string) (butils.Move, error) {
+	for _, move := range pos.LegalMoves() {
+		if pos.MoveToUCI(move) == s {
+			return move, nil
+		}
+	}
+	return butils.NullMove, fmt.Errorf("%w %s in %s", utils.ErrIllegalMove, s, pos)
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,142 @@
+package match
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"math"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Games returns the number of games scored
+func (s Score) Games() int {
+	return s.Wins + s.Draws + s.Losses
+}
+
+// Points returns the points scored, a draw is half a point
+func (s Score) Points() float64 {
+	return float64(s.Wins) + float64(s.Draws)/2
+}
+
+// Add counts the pgn result of a game, white tells whether the player had the white pieces
+// unfinished games are not counted
+func (s *Score) Add(result string, white bool) {
+	switch {
+	case result == "1/2-1/2":
+		s.Draws++
+	case result == "1-0" && white, result == "0-1" && !white:
+		s.Wins++
+	case result == "1-0", result == "0-1":
+		s.Losses++
+	}
+}
+
+// String returns the score as in +12 =30 -8
+func (s Score) String() string {
+	return fmt.Sprintf("+%d =%d -%d", s.Wins, s.Draws, s.Losses)
+}
+
+// Elo returns the elo difference to the opponent implied by the score and its 95% confidence margin
+// an all won or all lost score is an infinite difference
+func (s Score) Elo() (float64, float64) {
+	n := float64(s.Games())
+	if n == 0 {
+		return 0, 0
+	}
+
+	mean, variance := s.meanAndVariance()
+	margin := 1.96 * math.Sqrt(variance/n)
+
+	elo := eloFromScore(mean)
+	if math.IsInf(elo, 0) {
+		return elo, math.Inf(1)
+	}
+	return elo, (eloFromScore(mean+margin) - eloFromScore(mean-margin)) / 2
+}
+
+// meanAndVariance returns the average points per game and their variance
+func (s Score) meanAndVariance() (float64, float64) {
+	n := float64(s.Games())
+	mean := s.Points() / n
+
+	w, d, l := float64(s.Wins)/n, float64(s.Draws)/n, float64(s.Losses)/n
+	variance := w*(1-mean)*(1-mean) + d*(0.5-mean)*(0.5-mean) + l*mean*mean
+
+	return mean, variance
+}
+
+// LLR returns the log likelihood ratio of the hypotheses of the test given score
+// uses the normal approximation of the trinomial distribution of the results
+func (t SPRT) LLR(score Score) float64 {
+	if score.Wins == 0 || score.Draws == 0 || score.Losses == 0 {
+		// like cutechess wait for all kinds of results, the variance is meaningless before
+		return 0
+	}
+
+	mean, variance := score.meanAndVariance()
+	s0, s1 := scoreFromElo(t.Elo0), scoreFromElo(t.Elo1)
+
+	return float64(score.Games()) * (s1 - s0) * (2*mean - s0 - s1) / (2 * variance)
+}
+
+// Bounds returns the llr below which H0 and above which H1 is accepted
+func (t SPRT) Bounds() (float64, float64) {
+	return math.Log(t.Beta / (1 - t.Alpha)), math.Log((1 - t.Beta) / t.Alpha)
+}
+
+// Status returns SPRT_H0, SPRT_H1 or SPRT_CONTINUE for score
+func (t SPRT) Status(score Score) int {
+	llr := t.LLR(score)
+	lower, upper := t.Bounds()
+
+	switch {
+	case llr <= lower:
+		return SPRT_H0
+	case llr >= upper:
+		return SPRT_H1
+	}
+	return SPRT_CONTINUE
+}
+
+// String returns the hypotheses and error rates of the test
+func (t SPRT) String() string {
+	return fmt.Sprintf("elo0 %g elo1 %g alpha %g beta %g", t.Elo0, t.Elo1, t.Alpha, t.Beta)
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// NewSPRT returns a test with the default hypotheses and error rates
+func NewSPRT() *SPRT {
+	return &SPRT{
+		Elo0:  DEFAULT_SPRT_ELO0,
+		Elo1:  DEFAULT_SPRT_ELO1,
+		Alpha: DEFAULT_SPRT_ALPHA,
+		Beta:  DEFAULT_SPRT_BETA,
+	}
+}
+
+// eloFromScore returns the elo difference that gives an expected score of s points per game
+func eloFromScore(s float64) float64 {
+	if s <= 0 {
+		return math.Inf(-1)
+	}
+	if s >= 1 {
+		return math.Inf(1)
+	}
+	return 400 * math.Log10(s/(1-s))
+}
+
+// scoreFromElo returns the expected points per game of a player elo stronger than its opponent
+func scoreFromElo(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,156 @@
+package match
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/easychessanimations/gochess/butils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// String returns the time control in the format read by ParseTimeControl
+// this is also the value of the pgn TimeControl tag, "-" if there is no clock
+func (tc TimeControl) String() string {
+	if tc.Time == 0 {
+		return "-"
+	}
+
+	s := formatSeconds(tc.Time)
+	if tc.Moves > 0 {
+		s = strconv.Itoa(tc.Moves) + "/" + s
+	}
+	if tc.Inc > 0 {
+		s += "+" + formatSeconds(tc.Inc)
+	}
+	return s
+}
+
+// limits returns the search limits of the side to move
+func (c *clock) limits(color butils.Color) Limits {
+	limits := Limits{
+		MoveTime: c.tc.MoveTime,
+		Depth:    c.tc.Depth,
+		Nodes:    c.tc.Nodes,
+	}
+
+	if c.tc.Time > 0 {
+		limits.WTime, limits.WInc = c.remaining[butils.White], c.tc.Inc
+		limits.BTime, limits.BInc = c.remaining[butils.Black], c.tc.Inc
+		if c.tc.Moves > 0 {
+			limits.MovesToGo = c.tc.Moves - c.moves[color]%c.tc.Moves
+		}
+	}
+
+	return limits
+}
+
+// spend charges color for a move that took elapsed, returns false if its time ran out
+func (c *clock) spend(color butils.Color, elapsed time.Duration) bool {
+	c.moves[color]++
+
+	if c.tc.MoveTime > 0 && elapsed > c.tc.MoveTime+TIME_MARGIN {
+		return false
+	}
+
+	if c.tc.Time == 0 {
+		return true
+	}
+
+	if elapsed > c.remaining[color]+TIME_MARGIN {
+		return false
+	}
+
+	c.remaining[color] += c.tc.Inc - elapsed
+	if c.tc.Moves > 0 && c.moves[color]%c.tc.Moves == 0 {
+		c.remaining[color] += c.tc.Time
+	}
+
+	return true
+}
+
+// timeout returns how long color may think before it loses on time, 0 if there is no limit
+func (c *clock) timeout(color butils.Color) time.Duration {
+	switch {
+	case c.tc.MoveTime > 0:
+		return c.tc.MoveTime + TIME_MARGIN
+	case c.tc.Time > 0:
+		return c.remaining[color] + TIME_MARGIN
+	}
+	return 0
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// ParseTimeControl parses a time control written as moves/seconds+increment
+// moves and increment are optional, as in 40/60, 10+0.1 or 300
+// "-" or an empty string is no clock
+func ParseTimeControl(s string) (TimeControl, error) {
+	tc := TimeControl{}
+
+	s = strings.TrimSpace(s)
+	if s == "" || s == "-" {
+		return tc, nil
+	}
+
+	rest := s
+	if i := strings.Index(rest, "/"); i >= 0 {
+		moves, err := strconv.Atoi(rest[:i])
+		if err != nil || moves <= 0 {
+			return tc, fmt.Errorf("invalid moves in time control %s", s)
+		}
+		tc.Moves, rest = moves, rest[i+1:]
+	}
+
+	inc := ""
+	if i := strings.Index(rest, "+"); i >= 0 {
+		rest, inc = rest[:i], rest[i+1:]
+	}
+
+	var err error
+	if tc.Time, err = parseSeconds(rest); err != nil || tc.Time <= 0 {
+		return tc, fmt.Errorf("invalid time in time control %s", s)
+	}
+	if inc != "" {
+		if tc.Inc, err = parseSeconds(inc); err != nil || tc.Inc < 0 {
+			return tc, fmt.Errorf("invalid increment in time control %s", s)
+		}
+	}
+
+	return tc, nil
+}
+
+// newClock returns the clock of a game played with tc
+func newClock(tc TimeControl) *clock {
+	c := &clock{tc: tc}
+	c.remaining[butils.White] = tc.Time
+	c.remaining[butils.Black] = tc.Time
+	return c
+}
+
+// parseSeconds parses a decimal number of seconds
+func parseSeconds(s string) (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// formatSeconds formats d as a decimal number of seconds
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,156 @@
+package match
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/easychessanimations/gochess/bengine"
+	"github.com/easychessanimations/gochess/book"
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/pgn"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// types
+
+// TimeControl limits the search of each move of a game
+// a game with Moves > 0 gives Time again every Moves moves, otherwise Time is the whole game
+type TimeControl struct {
+	Moves    int           // moves per period, 0 if the whole game is one period
+	Time     time.Duration // time of a period, 0 for no clock
+	Inc      time.Duration // increment per move
+	MoveTime time.Duration // fixed time per move, 0 for none
+	Depth    int           // maximum search depth, 0 for no limit
+	Nodes    uint64        // maximum nodes searched per move, 0 for no limit
+}
+
+// Limits are the limits of the search of one move, as sent by the uci go command
+type Limits struct {
+	WTime, WInc time.Duration // time and increment of white, 0 if there is no clock
+	BTime, BInc time.Duration // time and increment of black
+	MovesToGo   int           // moves until the next time control, 0 if none
+	MoveTime    time.Duration
+	Depth       int
+	Nodes       uint64
+}
+
+// MoveInfo is a move played by a player with the result of its search
+type MoveInfo struct {
+	Move  butils.Move
+	Score int32 // centipawns from the point of view of the player, unless Mate is set
+	Mate  int   // moves to mate, negative if the player is mated, 0 if no mate was found
+	Depth int
+}
+
+// Player plays the moves of one side of the games of a match
+type Player interface {
+	// Name returns the name written to the pgn
+	Name() string
+	// NewGame prepares the player for a new game of variant
+	NewGame(variant utils.VariantKey, chess960 bool) error
+	// Move returns the move of the player in pos, pos must not be changed
+	Move(pos *butils.Position, limits Limits) (MoveInfo, error)
+	// Close releases the resources of the player
+	Close() error
+}
+
+// PlayerConfig describes a player, Players are created from it for each concurrent game
+type PlayerConfig struct {
+	Name    string
+	Command string            // path of an uci engine, or INTERNAL_ENGINE
+	Args    []string          // arguments of the uci engine
+	Dir     string            // working directory of the uci engine, empty for the current one
+	Options map[string]string // uci options, for the internal engine Threads, HandicapLevel, OwnBook, BookFile, TablebasePath and UseAB
+}
+
+// EnginePlayer plays with the engine of this repository in process
+// all internal players of a process share bengine.GlobalHashTable
+type EnginePlayer struct {
+	Engine     *bengine.Engine
+	name       string
+	bookLoader book.Loader
+}
+
+// UCIPlayer plays with an uci engine run as a subprocess
+type UCIPlayer struct {
+	config PlayerConfig
+	name   string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	lines  chan string // lines written by the engine, closed when it exits
+}
+
+// Opening is a start position of the games of a match, each opening is played with both colors
+type Opening struct {
+	Fen   string   // start position, the start position of the variant if empty
+	Moves []string // moves played from Fen in SAN
+}
+
+// Score counts the results of a player
+type Score struct {
+	Wins   int
+	Draws  int
+	Losses int
+}
+
+// SPRT is a sequential probability ratio test of whether the first player of a match
+// is Elo0 or Elo1 elo stronger, with false positive rate Alpha and false negative rate Beta
+type SPRT struct {
+	Elo0, Elo1  float64
+	Alpha, Beta float64
+}
+
+// GameResult is a finished game of a match
+type GameResult struct {
+	Number      int       // 1 based number of the game in the match
+	White       int       // index in Match.Players of the white player
+	Result      string    // pgn result
+	Termination string    // TERMINATION_NORMAL, TERMINATION_ADJUDICATION ...
+	Reason      string    // why the game ended, as in checkmate or fifty move rule
+	Game        *pgn.Game // the game with the search results of the moves as comments
+	Err         error     // error of a player that ended the game, nil if none
+}
+
+// Options controls a match
+type Options struct {
+	Variant     utils.VariantKey
+	Chess960    bool
+	TimeControl TimeControl
+	Openings    []Opening // openings played in turn, the start position if empty
+	Games       int       // number of games, rounded up to an even number
+	Concurrency int       // number of games played at the same time
+	MaxPlies    int       // plies after which a game is adjudicated a draw, never if 0
+	SPRT        *SPRT     // stops the match when the test is decided, nil for none
+	Event       string    // pgn Event tag
+	PGN         io.Writer // finished games are written here, nil for none
+	Log         func(string)
+}
+
+// Match plays games between two players and keeps the score of the first one
+type Match struct {
+	Options Options
+	Players [2]PlayerConfig
+	Score   Score        // score of the first player
+	Results []GameResult // finished games in the order they finished
+
+	mu      sync.Mutex
+	stopped bool // set when the sprt is decided
+	sprt    int  // outcome of the sprt
+}
+
+// clock keeps the remaining time of both sides of a game
+type clock struct {
+	tc        TimeControl
+	remaining [butils.ColorArraySize]time.Duration
+	moves     [butils.ColorArraySize]int // moves made by each side
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,19 @@
+package perft
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// constants
+
+const BACKEND_BOARD = "board"
+const BACKEND_BITBOARD = "bitboard"
+
+const DEFAULT_HASH_SIZE_MB = 64
+
+// positions closer to the leaves are not worth caching
+const MIN_HASH_DEPTH = 2
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,132 @@
+package perft
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+
+	"github.com/easychessanimations/gochess/board"
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+func (g *boardGenerator) Name() string {
+	return BACKEND_BOARD
+}
+
+func (g *boardGenerator) GenerateMoves() int {
+	ply := len(g.b.MoveStack)
+	for len(g.moves) <= ply {
+		g.moves = append(g.moves, nil)
+	}
+	g.moves[ply] = g.b.LegalMovesForAllPieces()
+	return len(g.moves[ply])
+}
+
+func (g *boardGenerator) DoMove(i int) {
+	g.b.Push(g.moves[len(g.b.MoveStack)][i], !board.ADD_SAN)
+}
+
+func (g *boardGenerator) UndoMove() {
+	g.b.Pop()
+}
+
+func (g *boardGenerator) MoveToUCI(i int) string {
+	return g.b.MoveToAlgeb(g.moves[len(g.b.MoveStack)][i])
+}
+
+func (g *boardGenerator) Zobrist() uint64 {
+	return g.b.Zobrist()
+}
+
+func (g *boardGenerator) Fen() string {
+	return g.b.ReportFen()
+}
+
+func (g *positionGenerator) Name() string {
+	return BACKEND_BITBOARD
+}
+
+func (g *positionGenerator) GenerateMoves() int {
+	ply := g.ply()
+	for len(g.moves) <= ply {
+		g.moves = append(g.moves, nil)
+	}
+	g.moves[ply] = g.pos.LegalMoves()
+	return len(g.moves[ply])
+}
+
+func (g *positionGenerator) DoMove(i int) {
+	g.pos.DoMove(g.moves[g.ply()][i])
+}
+
+func (g *positionGenerator) UndoMove() {
+	g.pos.UndoMove()
+}
+
+func (g *positionGenerator) MoveToUCI(i int) string {
+	return g.pos.MoveToUCI(g.moves[g.ply()][i])
+}
+
+func (g *positionGenerator) Zobrist() uint64 {
+	return g.pos.Zobrist()
+}
+
+func (g *positionGenerator) Fen() string {
+	return g.pos.String()
+}
+
+// ply returns the number of moves played since the generator was created
+func (g *positionGenerator) ply() int {
+	return g.pos.Ply - g.rootPly
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// NewBoardGenerator returns a generator of the mailbox board set up from fen
+func NewBoardGenerator(fen string, variant utils.VariantKey, chess960 bool) Generator {
+	b := &board.Board{}
+
+	b.Init(variant)
+
+	b.Chess960 = chess960
+
+	b.SetFromFen(fen)
+
+	return &boardGenerator{b: b}
+}
+
+// NewPositionGenerator returns a generator of the bitboard position set up from fen
+func NewPositionGenerator(fen string, variant utils.VariantKey, chess960 bool) (Generator, error) {
+	pos, err := butils.PositionFromFENAndVariant(fen, variant)
+	if err != nil {
+		return nil, err
+	}
+
+	pos.Chess960 = chess960
+
+	return &positionGenerator{pos: pos, rootPly: pos.Ply}, nil
+}
+
+// NewGenerator returns a generator of backend set up from fen
+func NewGenerator(backend string, fen string, variant utils.VariantKey, chess960 bool) (Generator, error) {
+	switch backend {
+	case BACKEND_BOARD:
+		return NewBoardGenerator(fen, variant, chess960), nil
+	case BACKEND_BITBOARD:
+		return NewPositionGenerator(fen, variant, chess960)
+	}
+
+	return nil, fmt.Errorf("unknown backend %s", backend)
+}
+
+/////////////////////////////////////////////////////////////////////
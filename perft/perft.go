@@ -0,0 +1,175 @@
+package perft
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"sort"
+	"unsafe"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// get returns the cached count of the position with key at depth
+func (ht *HashTable) get(key uint64, depth int) (uint64, bool) {
+	e := &ht.table[key&ht.mask]
+	if e.key == key && e.depth == depth {
+		return e.nodes, true
+	}
+	return 0, false
+}
+
+// put caches the count of the position with key at depth, deeper entries are kept
+func (ht *HashTable) put(key uint64, depth int, nodes uint64) {
+	e := &ht.table[key&ht.mask]
+	if e.key == key || e.depth <= depth {
+		*e = hashEntry{key: key, nodes: nodes, depth: depth}
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// NewHashTable returns a perft hash table of at most sizeMB megabytes
+func NewHashTable(sizeMB int) *HashTable {
+	entrySize := uint64(unsafe.Sizeof(hashEntry{}))
+	size := uint64(1)
+	for (size*2)*entrySize <= uint64(sizeMB)<<20 {
+		size *= 2
+	}
+
+	return &HashTable{
+		table: make([]hashEntry, size),
+		mask:  size - 1,
+	}
+}
+
+// Count returns the number of leaf nodes at depth below the current position of gen
+// ht caches the counts of subtrees, it can be nil
+func Count(gen Generator, depth int, ht *HashTable) uint64 {
+	if depth <= 0 {
+		return 1
+	}
+
+	var key uint64
+	if ht != nil && depth >= MIN_HASH_DEPTH {
+		key = gen.Zobrist()
+		if nodes, ok := ht.get(key, depth); ok {
+			return nodes
+		}
+	}
+
+	numMoves := gen.GenerateMoves()
+	if depth == 1 {
+		return uint64(numMoves)
+	}
+
+	nodes := uint64(0)
+	for i := 0; i < numMoves; i++ {
+		gen.DoMove(i)
+		nodes += Count(gen, depth-1, ht)
+		gen.UndoMove()
+	}
+
+	if ht != nil && depth >= MIN_HASH_DEPTH {
+		ht.put(key, depth, nodes)
+	}
+
+	return nodes
+}
+
+// Divide returns the number of leaf nodes at depth below each root move, sorted by move
+func Divide(gen Generator, depth int, ht *HashTable) []DivideEntry {
+	entries := []DivideEntry{}
+
+	if depth <= 0 {
+		return entries
+	}
+
+	numMoves := gen.GenerateMoves()
+	for i := 0; i < numMoves; i++ {
+		move := gen.MoveToUCI(i)
+		gen.DoMove(i)
+		entries = append(entries, DivideEntry{Move: move, Nodes: Count(gen, depth-1, ht)})
+		gen.UndoMove()
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Move < entries[j].Move
+	})
+
+	return entries
+}
+
+// Diff walks the trees of a and b to depth and returns the positions where they generate different moves
+// the subtrees of differing moves are not searched, at most maxDiffs differences are returned if maxDiffs > 0
+func Diff(a Generator, b Generator, depth int, maxDiffs int) []Difference {
+	diffs := []Difference{}
+	diffRec(a, b, depth, maxDiffs, []string{}, &diffs)
+	return diffs
+}
+
+// diffRec compares the moves of a and b at the current position and descends into the common ones
+func diffRec(a Generator, b Generator, depth int, maxDiffs int, path []string, diffs *[]Difference) {
+	if depth <= 0 || (maxDiffs > 0 && len(*diffs) >= maxDiffs) {
+		return
+	}
+
+	movesA := uciMoves(a)
+	movesB := uciMoves(b)
+
+	// a move generated twice by one generator only is a difference too
+	diff := Difference{}
+	for move, indices := range movesA {
+		for i := len(movesB[move]); i < len(indices); i++ {
+			diff.Extra = append(diff.Extra, move)
+		}
+	}
+	for move, indices := range movesB {
+		for i := len(movesA[move]); i < len(indices); i++ {
+			diff.Missing = append(diff.Missing, move)
+		}
+	}
+
+	if len(diff.Extra) > 0 || len(diff.Missing) > 0 {
+		sort.Strings(diff.Extra)
+		sort.Strings(diff.Missing)
+		diff.Fen = a.Fen()
+		diff.Path = append([]string{}, path...)
+		*diffs = append(*diffs, diff)
+	}
+
+	common := []string{}
+	for move := range movesA {
+		if _, ok := movesB[move]; ok {
+			common = append(common, move)
+		}
+	}
+	sort.Strings(common)
+
+	for _, move := range common {
+		a.DoMove(movesA[move][0])
+		b.DoMove(movesB[move][0])
+		diffRec(a, b, depth-1, maxDiffs, append(path, move), diffs)
+		a.UndoMove()
+		b.UndoMove()
+	}
+}
+
+// uciMoves generates the moves of gen and maps their uci notation to their indices
+func uciMoves(gen Generator) map[string][]int {
+	moves := map[string][]int{}
+	numMoves := gen.GenerateMoves()
+	for i := 0; i < numMoves; i++ {
+		move := gen.MoveToUCI(i)
+		moves[move] = append(moves[move], i)
+	}
+	return moves
+}
+
+/////////////////////////////////////////////////////////////////////
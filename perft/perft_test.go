@@ -0,0 +1,118 @@
+package perft
+
+import (
+	"os"
+	"testing"
+
+	"github.com/easychessanimations/gochess/epd"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+const KIWIPETE = "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"
+
+const EIGHTPIECE_START = "jlsesqkbnr/pppppppp/8/8/8/8/PPPPPPPP/JLneSQKBNR w KQkq - 0 1 -"
+
+// the board generator is slow, it is only checked at depths with at most this many nodes
+// the bitboard generator is checked at every listed depth
+const BOARD_MAX_NODES = 1000000
+
+func TestSuites(t *testing.T) {
+	for _, variant := range []utils.VariantKey{utils.VARIANT_STANDARD, utils.VARIANT_ATOMIC, utils.VARIANT_EIGHTPIECE} {
+		name := utils.VariantKeyToVariantKeyString(variant)
+
+		f, err := os.Open("suites/" + name + ".epd")
+		if err != nil {
+			t.Fatal(err)
+		}
+		records, err := epd.ReadEPD(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, record := range records {
+			for _, backend := range []string{BACKEND_BOARD, BACKEND_BITBOARD} {
+				gen, err := NewGenerator(backend, record.Fen, variant, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				ht := NewHashTable(DEFAULT_HASH_SIZE_MB)
+
+				counts := record.Perft()
+				for depth := 1; depth <= len(counts); depth++ {
+					expected := counts[depth-1]
+					if expected == 0 || (backend == BACKEND_BOARD && expected > BOARD_MAX_NODES) {
+						continue
+					}
+
+					if nodes := Count(gen, depth, ht); nodes != expected {
+						t.Errorf("%s %s %s depth %d nodes %d, expected %d", name, backend, record.Fen, depth, nodes, expected)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestHashAndDivide(t *testing.T) {
+	gen, err := NewPositionGenerator(KIWIPETE, utils.VARIANT_STANDARD, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ht := NewHashTable(1)
+
+	total := uint64(0)
+	for _, entry := range Divide(gen, 3, ht) {
+		total += entry.Nodes
+	}
+
+	if total != 97862 {
+		t.Errorf("divide 3 adds up to %d, expected 97862", total)
+	}
+
+	if nodes := Count(gen, 3, ht); nodes != 97862 {
+		t.Errorf("hashed perft 3 returned %d, expected 97862", nodes)
+	}
+
+	if fen := gen.Fen(); fen != KIWIPETE {
+		t.Errorf("fen %s changed to %s", KIWIPETE, fen)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := NewBoardGenerator(KIWIPETE, utils.VARIANT_STANDARD, false)
+
+	b, err := NewPositionGenerator(KIWIPETE, utils.VARIANT_STANDARD, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diffs := Diff(a, b, 2, 0); len(diffs) != 0 {
+		t.Errorf("expected no differences, got %v", diffs)
+	}
+
+	// the generators agree on the sentry and lancer rules
+	e := NewBoardGenerator(EIGHTPIECE_START, utils.VARIANT_EIGHTPIECE, false)
+
+	f, err := NewPositionGenerator(EIGHTPIECE_START, utils.VARIANT_EIGHTPIECE, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diffs := Diff(e, f, 3, 0); len(diffs) != 0 {
+		t.Errorf("expected no eightpiece differences, got %v", diffs)
+	}
+
+	// an extra pawn on a3 takes away moves from the second generator
+	c, err := NewPositionGenerator("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/P1N2Q1p/1PPBBPPP/R3K2R w KQkq - 0 1", utils.VARIANT_STANDARD, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diffs := Diff(a, c, 1, 0)
+	if len(diffs) != 1 || len(diffs[0].Extra) == 0 || len(diffs[0].Path) != 0 {
+		t.Errorf("expected a difference at the root, got %v", diffs)
+	}
+}
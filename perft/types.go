@@ -0,0 +1,76 @@
+package perft
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"github.com/easychessanimations/gochess/board"
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// types
+
+// Generator is a move generator walked by perft
+// moves are addressed by their index in the list generated at the current ply
+type Generator interface {
+	// Name returns the name of the backend
+	Name() string
+	// GenerateMoves generates the legal moves of the current position and returns their number
+	GenerateMoves() int
+	// DoMove plays the i-th move generated at the current ply
+	DoMove(i int)
+	// UndoMove takes back the last move
+	UndoMove()
+	// MoveToUCI returns the i-th move generated at the current ply in uci notation
+	MoveToUCI(i int) string
+	// Zobrist returns the hash key of the current position
+	Zobrist() uint64
+	// Fen returns the fen of the current position
+	Fen() string
+}
+
+// boardGenerator walks the mailbox board
+type boardGenerator struct {
+	b     *board.Board
+	moves [][]utils.Move
+}
+
+// positionGenerator walks the bitboard position
+type positionGenerator struct {
+	pos     *butils.Position
+	rootPly int
+	moves   [][]butils.Move
+}
+
+// DivideEntry is the number of leaf nodes below a root move
+type DivideEntry struct {
+	Move  string
+	Nodes uint64
+}
+
+// hashEntry is the number of leaf nodes below a position at depth
+type hashEntry struct {
+	key   uint64
+	nodes uint64
+	depth int
+}
+
+// HashTable caches the perft counts of positions
+type HashTable struct {
+	table []hashEntry
+	mask  uint64
+}
+
+// Difference is a position where the two generators disagree
+type Difference struct {
+	Fen     string   // fen of the position reported by the first generator
+	Path    []string // moves leading to the position from the root
+	Missing []string // moves generated only by the second generator
+	Extra   []string // moves generated only by the first generator
+}
+
+/////////////////////////////////////////////////////////////////////
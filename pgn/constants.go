@@ -0,0 +1,72 @@
+package pgn
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import "github.com/easychessanimations/gochess/utils"
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// constants
+
+const MAX_LINE_LENGTH = 80
+
+const RESULT_UNKNOWN = "*"
+
+var RESULTS = []string{"1-0", "0-1", "1/2-1/2", RESULT_UNKNOWN}
+
+var SEVEN_TAG_ROSTER = []Tag{
+	{"Event", "?"},
+	{"Site", "?"},
+	{"Date", "????.??.??"},
+	{"Round", "?"},
+	{"White", "?"},
+	{"Black", "?"},
+	{"Result", RESULT_UNKNOWN},
+}
+
+// suffix annotations and their equivalent numeric annotation glyphs
+var SUFFIX_ANNOTATION_TO_NAG = map[string]int{
+	"!":  1,
+	"?":  2,
+	"!!": 3,
+	"??": 4,
+	"!?": 5,
+	"?!": 6,
+}
+
+// variant tag values in lower case, chess960 is played with standard rules
+var VARIANT_TAG_TO_VARIANT_KEY = map[string]utils.VariantKey{
+	"":               utils.VARIANT_STANDARD,
+	"standard":       utils.VARIANT_STANDARD,
+	"chess":          utils.VARIANT_STANDARD,
+	"from position":  utils.VARIANT_STANDARD,
+	"chess960":       utils.VARIANT_STANDARD,
+	"chess 960":      utils.VARIANT_STANDARD,
+	"fischerandom":   utils.VARIANT_STANDARD,
+	"fischer random": utils.VARIANT_STANDARD,
+	"atomic":         utils.VARIANT_ATOMIC,
+	"seirawan":       utils.VARIANT_SEIRAWAN,
+	"s-chess":        utils.VARIANT_SEIRAWAN,
+	"schess":         utils.VARIANT_SEIRAWAN,
+	"eightpiece":     utils.VARIANT_EIGHTPIECE,
+}
+
+var CHESS960_VARIANT_TAGS = map[string]bool{
+	"chess960":       true,
+	"chess 960":      true,
+	"fischerandom":   true,
+	"fischer random": true,
+}
+
+var VARIANT_KEY_TO_VARIANT_TAG = map[utils.VariantKey]string{
+	utils.VARIANT_STANDARD:   "Standard",
+	utils.VARIANT_ATOMIC:     "Atomic",
+	utils.VARIANT_SEIRAWAN:   "Seirawan",
+	utils.VARIANT_EIGHTPIECE: "Eightpiece",
+}
+
+const CHESS960_VARIANT_TAG = "Chess960"
+
+/////////////////////////////////////////////////////////////////////
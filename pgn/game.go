@@ -0,0 +1,112 @@
+package pgn
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Tag returns the value of tag name, or empty string if the game has no such tag
+func (g *Game) Tag(name string) string {
+	for _, tag := range g.Tags {
+		if tag.Name == name {
+			return tag.Value
+		}
+	}
+	return ""
+}
+
+// SetTag sets the value of tag name, adding the tag if needed
+func (g *Game) SetTag(name string, value string) {
+	for i, tag := range g.Tags {
+		if tag.Name == name {
+			g.Tags[i].Value = value
+			return
+		}
+	}
+	g.Tags = append(g.Tags, Tag{name, value})
+}
+
+// Variant returns the variant key of the game and whether it is a chess960 game
+func (g *Game) Variant() (utils.VariantKey, bool, error) {
+	return VariantFromTag(g.Tag("Variant"))
+}
+
+// SetVariant sets the Variant tag, standard games without chess960 castling need no tag
+func (g *Game) SetVariant(variant utils.VariantKey, chess960 bool) {
+	if chess960 && variant == utils.VARIANT_STANDARD {
+		g.SetTag("Variant", CHESS960_VARIANT_TAG)
+	} else if variant != utils.VARIANT_STANDARD {
+		g.SetTag("Variant", VARIANT_KEY_TO_VARIANT_TAG[variant])
+	}
+}
+
+// StartFen returns the FEN tag if present, otherwise the start position of the variant
+func (g *Game) StartFen() (string, error) {
+	if fen := g.Tag("FEN"); fen != "" {
+		return fen, nil
+	}
+
+	variant, _, err := g.Variant()
+	if err != nil {
+		return "", err
+	}
+
+	return utils.StartFenForVariant(variant), nil
+}
+
+// SetStartFen sets the FEN and SetUp tags if fen is not the start position of the variant
+func (g *Game) SetStartFen(variant utils.VariantKey, fen string) {
+	if fen != utils.StartFenForVariant(variant) {
+		g.SetTag("SetUp", "1")
+		g.SetTag("FEN", fen)
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// VariantFromTag returns the variant key for the value of a Variant tag and whether it denotes chess960
+func VariantFromTag(value string) (utils.VariantKey, bool, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	variant, ok := VARIANT_TAG_TO_VARIANT_KEY[value]
+	if !ok {
+		return utils.VARIANT_STANDARD, false, fmt.Errorf("unsupported variant %s", value)
+	}
+
+	return variant, CHESS960_VARIANT_TAGS[value], nil
+}
+
+// startPly returns the ply of the position given by fen, 0 for white to move at move 1
+func startPly(fen string) int {
+	fields := strings.Fields(fen)
+
+	ply := 0
+
+	if len(fields) > 5 {
+		if fullmove, err := strconv.Atoi(fields[5]); err == nil && fullmove > 0 {
+			ply = (fullmove - 1) * 2
+		}
+	}
+
+	if len(fields) > 1 && fields[1] == "b" {
+		ply++
+	}
+
+	return ply
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,316 @@
+package pgn
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	return p.src[p.pos]
+}
+
+func (p *parser) advance() byte {
+	c := p.src[p.pos]
+	if c == '\n' {
+		p.line++
+	}
+	p.pos++
+	return c
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("pgn line %d: %s", p.line+1, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips white space and escape lines starting with %
+func (p *parser) skipSpace() {
+	for !p.eof() {
+		c := p.peek()
+		if c == '%' && (p.pos == 0 || p.src[p.pos-1] == '\n') {
+			p.readUntil('\n')
+			continue
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return
+		}
+		p.advance()
+	}
+}
+
+// readUntil reads up to the terminator, the terminator is consumed but not returned
+func (p *parser) readUntil(terminator byte) (string, bool) {
+	start := p.pos
+	for !p.eof() {
+		if p.advance() == terminator {
+			return p.src[start : p.pos-1], true
+		}
+	}
+	return p.src[start:], false
+}
+
+// readToken reads a symbol up to white space or a delimiter
+func (p *parser) readToken() string {
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n{};()[]$", rune(p.peek())) {
+		p.advance()
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *parser) parseTag() (Tag, error) {
+	// skip [
+	p.advance()
+	p.skipSpace()
+
+	name := p.readToken()
+	if name == "" {
+		return Tag{}, p.errorf("missing tag name")
+	}
+
+	p.skipSpace()
+	if p.eof() || p.peek() != '"' {
+		return Tag{}, p.errorf("missing value for tag %s", name)
+	}
+	p.advance()
+
+	value := []byte{}
+	for {
+		if p.eof() {
+			return Tag{}, p.errorf("unterminated value for tag %s", name)
+		}
+		c := p.advance()
+		if c == '"' {
+			break
+		}
+		if c == '\\' && !p.eof() {
+			c = p.advance()
+		}
+		value = append(value, c)
+	}
+
+	p.skipSpace()
+	if p.eof() || p.peek() != ']' {
+		return Tag{}, p.errorf("missing ] after tag %s", name)
+	}
+	p.advance()
+
+	return Tag{name, string(value)}, nil
+}
+
+func (p *parser) parseGame() (*Game, error) {
+	g := &Game{}
+
+	for p.skipSpace(); !p.eof() && p.peek() == '['; p.skipSpace() {
+		tag, err := p.parseTag()
+		if err != nil {
+			return nil, err
+		}
+		g.Tags = append(g.Tags, tag)
+	}
+
+	// line is the move list being appended to, stack holds the enclosing lines of variations
+	line := &g.Moves
+	stack := []*[]*Move{}
+	pendingComment := ""
+
+	lastMove := func() *Move {
+		if len(*line) == 0 {
+			return nil
+		}
+		return (*line)[len(*line)-1]
+	}
+
+	addComment := func(comment string) {
+		comment = strings.TrimSpace(comment)
+		if comment == "" {
+			return
+		}
+		if m := lastMove(); m != nil {
+			m.Comment = joinComments(m.Comment, comment)
+		} else if len(stack) == 0 {
+			g.Comment = joinComments(g.Comment, comment)
+		} else {
+			pendingComment = joinComments(pendingComment, comment)
+		}
+	}
+
+	for {
+		p.skipSpace()
+
+		if p.eof() || p.peek() == '[' {
+			// game without result
+			if len(stack) > 0 {
+				return nil, p.errorf("unterminated variation")
+			}
+			g.Result = g.Tag("Result")
+			if g.Result == "" {
+				g.Result = RESULT_UNKNOWN
+			}
+			return g, nil
+		}
+
+		switch p.peek() {
+		case '{':
+			p.advance()
+			comment, ok := p.readUntil('}')
+			if !ok {
+				return nil, p.errorf("unterminated comment")
+			}
+			addComment(comment)
+		case ';':
+			p.advance()
+			comment, _ := p.readUntil('\n')
+			addComment(comment)
+		case '(':
+			p.advance()
+			m := lastMove()
+			if m == nil {
+				return nil, p.errorf("variation without preceding move")
+			}
+			m.Variations = append(m.Variations, []*Move{})
+			stack = append(stack, line)
+			line = &m.Variations[len(m.Variations)-1]
+		case ')':
+			p.advance()
+			if len(stack) == 0 {
+				return nil, p.errorf("unexpected )")
+			}
+			// a comment of an empty variation has no move to belong to
+			pendingComment = ""
+			line = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+		case '$':
+			p.advance()
+			token := p.readToken()
+			nag, err := strconv.Atoi(token)
+			if err != nil {
+				return nil, p.errorf("invalid nag $%s", token)
+			}
+			m := lastMove()
+			if m == nil {
+				return nil, p.errorf("nag $%d without preceding move", nag)
+			}
+			m.Nags = append(m.Nags, nag)
+		case ']', '}':
+			return nil, p.errorf("unexpected %c", p.advance())
+		default:
+			token := p.readToken()
+
+			if IsResult(token) {
+				if len(stack) > 0 {
+					return nil, p.errorf("result %s inside variation", token)
+				}
+				g.Result = token
+				return g, nil
+			}
+
+			token = stripMoveNumber(token)
+
+			san, nag := splitSuffixAnnotation(token)
+
+			if san != "" {
+				*line = append(*line, &Move{
+					San:        san,
+					PreComment: pendingComment,
+				})
+				pendingComment = ""
+			}
+
+			if nag != 0 {
+				m := lastMove()
+				if m == nil {
+					return nil, p.errorf("annotation %s without preceding move", token)
+				}
+				m.Nags = append(m.Nags, nag)
+			}
+		}
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// Parse parses all games of a PGN file
+func Parse(r io.Reader) ([]*Game, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseString(string(data))
+}
+
+// ParseString parses all games of a PGN string
+func ParseString(src string) ([]*Game, error) {
+	p := &parser{src: src}
+
+	games := []*Game{}
+
+	for p.skipSpace(); !p.eof(); p.skipSpace() {
+		g, err := p.parseGame()
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, g)
+	}
+
+	return games, nil
+}
+
+// IsResult tells whether token is a game termination marker
+func IsResult(token string) bool {
+	for _, result := range RESULTS {
+		if token == result {
+			return true
+		}
+	}
+	return false
+}
+
+// stripMoveNumber removes move number indication, as in 12. or 12... , from the start of token
+func stripMoveNumber(token string) string {
+	i := 0
+	for i < len(token) && token[i] >= '0' && token[i] <= '9' {
+		i++
+	}
+	if i == 0 || i == len(token) || token[i] != '.' {
+		// castling written with zeros also starts with a digit
+		return token
+	}
+	return strings.TrimLeft(token[i:], ".")
+}
+
+// splitSuffixAnnotation splits a trailing !, ?, !!, ??, !? or ?! off token and returns it as a nag
+func splitSuffixAnnotation(token string) (string, int) {
+	san := strings.TrimRight(token, "!?")
+	if nag, ok := SUFFIX_ANNOTATION_TO_NAG[token[len(san):]]; ok {
+		return san, nag
+	}
+	return token, 0
+}
+
+func joinComments(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + " " + b
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,131 @@
+package pgn
+
+import (
+	"testing"
+
+	"github.com/easychessanimations/gochess/board"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+const TEST_PGN = `[Event "Test"]
+[White "A \"quoted\" name"]
+[Result "1-0"]
+
+{Ruy Lopez} 1. e4 e5 2. Nf3 {develops} (2. f4 exf4 (2... d5 $1) 3. Nf3) 2... Nc6
+3. Bb5! a6?! 4. Ba4 Nf6 5. O-O Be7 $6 ; rest of line comment
+6. Re1 b5 7. Bb3 d6 8. c3 O-O 1-0
+
+[Variant "Atomic"]
+1. Nf3 f6 2. Nd4 e6 3. Nxe6 *
+
+[Variant "Chess960"]
+[FEN "bqnbrkrn/pppppppp/8/8/8/8/PPPPPPPP/BQNBRKRN w GEge - 0 1"]
+1. O-O O-O 2. e4 e5 *
+`
+
+var TEST_PGN_FENS = []string{
+	"r1bq1rk1/2p1bppp/p1np1n2/1p2p3/4P3/1BP2N2/PP1P1PPP/RNBQR1K1 w - - 1 9",
+	"rnbqkbnr/pppp2pp/5p2/8/8/8/PPPPPPPP/RNBQKB1R b KQkq - 0 3",
+	"bqnbrrkn/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/BQNBRRKN w - - 0 3",
+}
+
+func TestParse(t *testing.T) {
+	games, err := ParseString(TEST_PGN)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(games) != 3 {
+		t.Fatalf("expected 3 games, got %d", len(games))
+	}
+
+	g := games[0]
+
+	if g.Tag("White") != `A "quoted" name` || g.Result != "1-0" || g.Comment != "Ruy Lopez" {
+		t.Errorf("wrong tags or comment %v %s %s", g.Tags, g.Result, g.Comment)
+	}
+
+	if len(g.Moves) != 16 {
+		t.Fatalf("expected 16 moves, got %d", len(g.Moves))
+	}
+
+	if m := g.Moves[2]; m.Comment != "develops" || len(m.Variations) != 1 || len(m.Variations[0]) != 3 || len(m.Variations[0][1].Variations) != 1 {
+		t.Errorf("wrong comment or variations %+v", m)
+	}
+
+	if m := g.Moves[4]; m.San != "Bb5" || len(m.Nags) != 1 || m.Nags[0] != 1 {
+		t.Errorf("wrong suffix annotation %+v", m)
+	}
+
+	// writing and parsing again gives back the same game
+	again, err := ParseString(g.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again[0].String() != g.String() {
+		t.Errorf("round trip changed game\n%s\n%s", g.String(), again[0].String())
+	}
+}
+
+func TestReplay(t *testing.T) {
+	games, err := ParseString(TEST_PGN)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, g := range games {
+		b, err := g.ReplayBoard()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if fen := b.ReportFen(); fen != TEST_PGN_FENS[i] {
+			t.Errorf("board replay of game %d gave %s, expected %s", i, fen, TEST_PGN_FENS[i])
+		}
+
+		pos, err := g.ReplayPosition()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if fen := pos.String(); fen != TEST_PGN_FENS[i] {
+			t.Errorf("position replay of game %d gave %s, expected %s", i, fen, TEST_PGN_FENS[i])
+		}
+
+		if FromBoard(b).String() != FromPosition(pos).String() {
+			t.Errorf("board and position write game %d differently", i)
+		}
+	}
+}
+
+func TestEightpieceRoundTrip(t *testing.T) {
+	b := &board.Board{}
+	b.Init(utils.VARIANT_EIGHTPIECE)
+	b.Reset()
+
+	// play the first legal move for a while, covers lancers and sentries
+	for i := 0; i < 40; i++ {
+		lms := b.LegalMovesForAllPieces()
+		if len(lms) == 0 {
+			break
+		}
+		b.Push(lms[(i*7)%len(lms)], board.ADD_SAN)
+	}
+
+	g := FromBoard(b)
+
+	games, err := ParseString(g.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayed, err := games[0].ReplayBoard()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if replayed.ReportFen() != b.ReportFen() {
+		t.Errorf("replay gave %s, expected %s", replayed.ReportFen(), b.ReportFen())
+	}
+}
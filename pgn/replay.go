@@ -0,0 +1,152 @@
+package pgn
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+
+	"github.com/easychessanimations/gochess/board"
+	"github.com/easychessanimations/gochess/butils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// ReplayBoard plays the main line of the game on a new board
+// the move stack of the board holds the moves with their san
+func (g *Game) ReplayBoard() (*board.Board, error) {
+	variant, chess960, err := g.Variant()
+	if err != nil {
+		return nil, err
+	}
+
+	fen, err := g.StartFen()
+	if err != nil {
+		return nil, err
+	}
+
+	b := &board.Board{}
+	b.Init(variant)
+	b.Chess960 = chess960
+	b.SetFromFen(fen)
+
+	ply := startPly(fen)
+
+	for _, m := range g.Moves {
+		move, err := b.SanToMove(m.San)
+		if err != nil {
+			return nil, fmt.Errorf("move %s: %w", moveNumberString(ply, m.San), err)
+		}
+
+		b.Push(move, board.ADD_SAN)
+
+		ply++
+	}
+
+	return b, nil
+}
+
+// ReplayPosition plays the main line of the game on a new position
+func (g *Game) ReplayPosition() (*butils.Position, error) {
+	variant, chess960, err := g.Variant()
+	if err != nil {
+		return nil, err
+	}
+
+	fen, err := g.StartFen()
+	if err != nil {
+		return nil, err
+	}
+
+	pos, err := butils.PositionFromFENAndVariant(fen, variant)
+	if err != nil {
+		return nil, err
+	}
+	pos.Chess960 = chess960
+
+	ply := startPly(fen)
+
+	for _, m := range g.Moves {
+		move, err := pos.SanToMove(m.San)
+		if err != nil {
+			return nil, fmt.Errorf("move %s: %w", moveNumberString(ply, m.San), err)
+		}
+
+		pos.DoMove(move)
+
+		ply++
+	}
+
+	return pos, nil
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// FromBoard returns the game played on b since it was set up
+func FromBoard(b *board.Board) *Game {
+	g := &Game{Result: RESULT_UNKNOWN}
+
+	g.SetVariant(b.Variant, b.Chess960)
+
+	// work on a copy so that b is left untouched
+	tb := *b
+	tb.MoveStack = nil
+
+	if len(b.MoveStack) > 0 {
+		tb.Pos = b.MoveStack[0].Pos
+	}
+
+	g.SetStartFen(b.Variant, tb.ReportFen())
+
+	for _, msi := range b.MoveStack {
+		san := msi.San
+
+		if san == "?" {
+			// pushed without san
+			tb.Pos = msi.Pos
+			san = tb.MoveToSan(msi.Move)
+		}
+
+		g.Moves = append(g.Moves, &Move{San: san})
+	}
+
+	return g
+}
+
+// FromPosition returns the game played on pos since it was set up
+func FromPosition(pos *butils.Position) *Game {
+	g := &Game{Result: RESULT_UNKNOWN}
+
+	g.SetVariant(pos.Variant.Key, pos.Chess960)
+
+	moves := pos.MoveHistory()
+
+	for range moves {
+		pos.UndoMove()
+	}
+
+	g.SetStartFen(pos.Variant.Key, pos.String())
+
+	for _, move := range moves {
+		g.Moves = append(g.Moves, &Move{San: pos.MoveToSan(move)})
+
+		pos.DoMove(move)
+	}
+
+	return g
+}
+
+func moveNumberString(ply int, san string) string {
+	if ply%2 == 0 {
+		return fmt.Sprintf("%d. %s", ply/2+1, san)
+	}
+	return fmt.Sprintf("%d... %s", ply/2+1, san)
+}
+
+/////////////////////////////////////////////////////////////////////
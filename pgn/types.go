@@ -0,0 +1,36 @@
+package pgn
+
+/////////////////////////////////////////////////////////////////////
+// types
+
+// Tag is a PGN tag pair, as in [Event "Casual game"]
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// Move is a move of the movetext with its annotations
+// Variations are alternatives to this move
+type Move struct {
+	San        string
+	Nags       []int
+	PreComment string
+	Comment    string
+	Variations [][]*Move
+}
+
+// Game is a parsed PGN game
+type Game struct {
+	Tags    []Tag
+	Comment string
+	Moves   []*Move
+	Result  string
+}
+
+type parser struct {
+	src  string
+	pos  int
+	line int
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,166 @@
+package pgn
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// String returns the game in PGN export format
+func (g *Game) String() string {
+	buff := ""
+
+	result := g.Result
+	if result == "" {
+		result = RESULT_UNKNOWN
+	}
+
+	// the seven tag roster comes first and in order
+	for _, rosterTag := range SEVEN_TAG_ROSTER {
+		value := g.Tag(rosterTag.Name)
+		if rosterTag.Name == "Result" {
+			value = result
+		} else if value == "" {
+			value = rosterTag.Value
+		}
+		buff += formatTag(Tag{rosterTag.Name, value})
+	}
+
+	for _, tag := range g.Tags {
+		if !isRosterTag(tag.Name) {
+			buff += formatTag(tag)
+		}
+	}
+
+	tokens := []string{}
+
+	if g.Comment != "" {
+		tokens = append(tokens, formatComment(g.Comment))
+	}
+
+	fen, _ := g.StartFen()
+
+	tokens = appendMoveTokens(tokens, g.Moves, startPly(fen))
+
+	tokens = append(tokens, result)
+
+	return buff + "\n" + wrapTokens(tokens) + "\n"
+}
+
+// Write writes the game in PGN export format
+func (g *Game) Write(w io.Writer) error {
+	_, err := io.WriteString(w, g.String())
+	return err
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// Write writes games in PGN export format separated by empty lines
+func Write(w io.Writer, games []*Game) error {
+	for i, g := range games {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if err := g.Write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isRosterTag(name string) bool {
+	for _, rosterTag := range SEVEN_TAG_ROSTER {
+		if rosterTag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func formatTag(tag Tag) string {
+	value := strings.Replace(tag.Value, `\`, `\\`, -1)
+	value = strings.Replace(value, `"`, `\"`, -1)
+	return fmt.Sprintf("[%s \"%s\"]\n", tag.Name, value)
+}
+
+func formatComment(comment string) string {
+	// comments cannot be nested
+	return "{" + strings.Replace(comment, "}", ")", -1) + "}"
+}
+
+// appendMoveTokens appends the movetext tokens of moves starting at ply
+// black moves get a move number when they open a line or follow a comment or variation
+func appendMoveTokens(tokens []string, moves []*Move, ply int) []string {
+	needNumber := true
+
+	for _, m := range moves {
+		if m.PreComment != "" {
+			tokens = append(tokens, formatComment(m.PreComment))
+		}
+
+		if ply%2 == 0 {
+			tokens = append(tokens, fmt.Sprintf("%d.", ply/2+1))
+		} else if needNumber || m.PreComment != "" {
+			tokens = append(tokens, fmt.Sprintf("%d...", ply/2+1))
+		}
+
+		tokens = append(tokens, m.San)
+
+		for _, nag := range m.Nags {
+			tokens = append(tokens, fmt.Sprintf("$%d", nag))
+		}
+
+		needNumber = false
+
+		if m.Comment != "" {
+			tokens = append(tokens, formatComment(m.Comment))
+			needNumber = true
+		}
+
+		for _, variation := range m.Variations {
+			tokens = append(tokens, "(")
+			tokens = appendMoveTokens(tokens, variation, ply)
+			tokens = append(tokens, ")")
+			needNumber = true
+		}
+
+		ply++
+	}
+
+	return tokens
+}
+
+// wrapTokens joins tokens into lines not longer than MAX_LINE_LENGTH where possible
+func wrapTokens(tokens []string) string {
+	lines := []string{}
+	line := ""
+
+	for i, token := range tokens {
+		sep := " "
+		if line == "" || token == ")" || (i > 0 && tokens[i-1] == "(") {
+			sep = ""
+		}
+		if line != "" && len(line)+len(sep)+len(token) > MAX_LINE_LENGTH {
+			lines = append(lines, line)
+			line, sep = "", ""
+		}
+		line += sep + token
+	}
+
+	return strings.Join(append(lines, line), "\n")
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,273 @@
+package render
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/easychessanimations/gochess/board"
+	"github.com/easychessanimations/gochess/pgn"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// types
+
+// slide is a piece moving between two squares during a move
+type slide struct {
+	Piece    utils.Piece
+	Landing  utils.Piece // the piece shown in the second half of the slide, as the promoted piece
+	From, To utils.Square
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// BoardFrames returns the frames of the moves of the move stack of b
+// the first frame is the position before the first move, then each move has its sliding frames,
+// its explosion frames in atomic and the position after it
+// notes are written after the san in the caption of the move of the same index, they may be shorter than the move stack
+func BoardFrames(b *board.Board, notes []string, options AnimationOptions) []Frame {
+	n := len(b.MoveStack)
+
+	frames := []Frame{{Image: frameImage(boardDiagram(b, 0), options, ""), Delay: options.Delay}}
+
+	for i := 0; i < n; i++ {
+		item := b.MoveStack[i]
+		move := item.Move
+
+		caption := moveCaption(b, i)
+		if i < len(notes) && notes[i] != "" {
+			caption += " " + notes[i]
+		}
+
+		before, after := boardDiagram(b, i), boardDiagram(b, i+1)
+
+		slides := moveSlides(b, item, before)
+
+		base := *before
+		for _, s := range slides {
+			base.Pieces[s.From.Rank][s.From.File] = utils.NO_PIECE
+		}
+		base.LastMove = []utils.Square{move.FromSq, move.ToSq}
+		base.Check = utils.NO_SQUARE
+
+		for k := 1; k <= options.SlideFrames; k++ {
+			img := frameImage(&base, options, caption)
+			t := float64(k) / float64(options.SlideFrames+1)
+			for _, s := range slides {
+				from, to := base.squareOrigin(s.From, options.Options), base.squareOrigin(s.To, options.Options)
+				p := s.Piece
+				if t >= 0.5 {
+					p = s.Landing
+				}
+				drawPiece(img, p, Point{from.X + (to.X-from.X)*t, from.Y + (to.Y-from.Y)*t}, float64(options.SquareSize))
+			}
+			frames = append(frames, Frame{Image: img, Delay: options.SlideDelay})
+		}
+
+		if b.IS_ATOMIC() && move.IsCapture() {
+			for k := 1; k <= EXPLOSION_FRAMES; k++ {
+				img := frameImage(after, options, caption)
+				drawExplosion(img, after.squareCenter(move.ToSq, options.Options), float64(options.SquareSize), float64(k)/EXPLOSION_FRAMES)
+				frames = append(frames, Frame{Image: img, Delay: options.SlideDelay})
+			}
+		}
+
+		frames = append(frames, Frame{Image: frameImage(after, options, caption), Delay: options.Delay})
+	}
+
+	return frames
+}
+
+// GameFrames returns the frames of the moves of g, the captions have the evals of the move comments
+// the result of the game is added to the caption of the last move
+func GameFrames(g *pgn.Game, options AnimationOptions) ([]Frame, error) {
+	b, err := g.ReplayBoard()
+	if err != nil {
+		return nil, err
+	}
+
+	notes := []string{}
+	for _, m := range g.Moves {
+		notes = append(notes, EvalFromComment(m.Comment))
+	}
+
+	if n := len(notes); n > 0 && g.Result != "" && g.Result != "*" {
+		notes[n-1] = strings.TrimSpace(notes[n-1] + " " + g.Result)
+	}
+
+	return BoardFrames(b, notes, options), nil
+}
+
+var evalTagRegexp = regexp.MustCompile(`\[%eval\s+([^\s\]]+)`)
+var evalCommentRegexp = regexp.MustCompile(`^([+-]?(?:M\d+|\d+(?:\.\d+)?))/\d+`)
+
+// EvalFromComment returns the eval in a move comment, either in an [%eval 0.35] command
+// or at the start of the comment as in +0.35/12 0.52s, empty if there is none
+func EvalFromComment(comment string) string {
+	if m := evalTagRegexp.FindStringSubmatch(comment); m != nil {
+		return m[1]
+	}
+
+	if m := evalCommentRegexp.FindStringSubmatch(strings.TrimSpace(comment)); m != nil {
+		return m[1]
+	}
+
+	return ""
+}
+
+// EncodeGIF returns frames as an animated gif looping forever
+func EncodeGIF(frames []Frame) *gif.GIF {
+	g := &gif.GIF{}
+
+	for _, frame := range frames {
+		g.Image = append(g.Image, paletted(frame.Image))
+		g.Delay = append(g.Delay, int(frame.Delay/(10*time.Millisecond)))
+	}
+
+	return g
+}
+
+// WriteGIF writes frames as an animated gif to w
+func WriteGIF(w io.Writer, frames []Frame) error {
+	return gif.EncodeAll(w, EncodeGIF(frames))
+}
+
+// WritePNGFrames writes frames as numbered png files to dir at a constant rate of fps frames per second,
+// as expected by video encoders like ffmpeg -framerate fps -i frame%05d.png, a frame is repeated to match its delay
+// it returns the number of files written
+func WritePNGFrames(dir string, frames []Frame, fps int) (int, error) {
+	count := 0
+
+	for _, frame := range frames {
+		buff := bytes.Buffer{}
+		if err := png.Encode(&buff, frame.Image); err != nil {
+			return count, err
+		}
+
+		repeat := int(math.Max(1, math.Round(frame.Delay.Seconds()*float64(fps))))
+		for i := 0; i < repeat; i++ {
+			if err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("frame%05d.png", count)), buff.Bytes(), 0644); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// boardDiagram returns the diagram of the position of b before the move of the move stack at index i
+// with that position the last one if i is the length of the move stack
+func boardDiagram(b *board.Board, i int) *Diagram {
+	c := *b
+	c.MoveStack = nil
+
+	if i < len(b.MoveStack) {
+		c.Pos = b.MoveStack[i].Pos.Clone()
+	}
+	if i > 0 {
+		c.MoveStack = []board.MoveStackItem{b.MoveStack[i-1]}
+	}
+
+	return FromBoard(&c)
+}
+
+// moveCaption returns the move of the move stack at index i with its move number, as in 12... Nf6
+func moveCaption(b *board.Board, i int) string {
+	item := b.MoveStack[i]
+
+	san := item.San
+	if san == "" || san == "?" {
+		c := *b
+		c.Pos = item.Pos.Clone()
+		c.MoveStack = nil
+		san = c.MoveToSan(item.Move)
+	}
+
+	if item.Pos.Turn == utils.WHITE {
+		return fmt.Sprintf("%d. %s", item.Pos.FullmoveNumber, san)
+	}
+
+	return fmt.Sprintf("%d... %s", item.Pos.FullmoveNumber, san)
+}
+
+// moveSlides returns the pieces moving during the move of item, before is the diagram of the position before it
+func moveSlides(b *board.Board, item board.MoveStackItem, before *Diagram) []slide {
+	move := item.Move
+	turn := item.Pos.Turn
+
+	mover := before.Pieces[move.FromSq.Rank][move.FromSq.File]
+
+	if move.Castling {
+		return []slide{
+			{Piece: mover, Landing: mover, From: move.FromSq, To: b.KingCastlingTargetSq(turn, move.CastlingSide)},
+			{Piece: move.RookOrigPiece, Landing: move.RookOrigPiece, From: move.ToSq, To: b.RookCastlingTargetSq(turn, move.CastlingSide)},
+		}
+	}
+
+	if move.SentryPush {
+		pushed := before.Pieces[move.ToSq.Rank][move.ToSq.File]
+		return []slide{
+			{Piece: pushed, Landing: move.PromotionPiece, From: move.ToSq, To: move.PromotionSquare},
+			{Piece: mover, Landing: mover, From: move.FromSq, To: move.ToSq},
+		}
+	}
+
+	landing := mover
+	if move.IsPromotion() {
+		landing = move.PromotionPiece
+	}
+
+	return []slide{{Piece: mover, Landing: landing, From: move.FromSq, To: move.ToSq}}
+}
+
+// frameImage returns the picture of d with a caption strip below it if the options ask for one
+func frameImage(d *Diagram, options AnimationOptions, caption string) *image.RGBA {
+	picture := d.Image(options.Options)
+
+	if !options.Caption {
+		return picture
+	}
+
+	size := float64(options.SquareSize)
+	bounds := picture.Bounds()
+	strip := int(math.Round(CAPTION_HEIGHT * size))
+
+	img := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()+strip))
+	draw.Draw(img, img.Bounds(), image.NewUniform(parseColor(BACKGROUND_COLOR)), image.Point{}, draw.Src)
+	draw.Draw(img, bounds, picture, image.Point{}, draw.Src)
+
+	drawTextCentered(img, caption, Point{float64(bounds.Dx()) / 2, float64(bounds.Dy()) + float64(strip)/2}, fontScale(size*0.3), parseColor(CAPTION_COLOR))
+
+	return img
+}
+
+// drawExplosion draws an atomic explosion centered at center, grown to the given part of its full size
+// at full size it covers the squares around the capture square
+func drawExplosion(img *image.RGBA, center Point, size float64, grown float64) {
+	radius := 1.5 * size * grown
+
+	fillShape(img, Shape{Points: []Point{center}, Radius: radius}, parseColor(EXPLOSION_COLOR), 0.75)
+	fillShape(img, Shape{Points: []Point{center}, Radius: radius / 2}, parseColor(EXPLOSION_CORE_COLOR), 0.9)
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,141 @@
+package render
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"time"
+
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// constants
+
+// GLYPH_SIZE is the size of the square the glyph shapes are drawn in
+const GLYPH_SIZE = 100
+
+// COORDINATE_MARGIN is the width of the coordinate border relative to the square size
+const COORDINATE_MARGIN = 0.4
+
+const LIGHT_SQUARE_COLOR = "#f0d9b5"
+const DARK_SQUARE_COLOR = "#b58863"
+const LAST_MOVE_COLOR = "#cdd26a"
+const CHECK_COLOR = "#e83c3c"
+const JAILED_COLOR = "#5b4a8a"
+const HIGHLIGHT_COLOR = "#15781b"
+const ARROW_COLOR = "#15781b"
+const COORDINATE_COLOR = "#404040"
+const WHITE_PIECE_COLOR = "#ffffff"
+const BLACK_PIECE_COLOR = "#202020"
+const OUTLINE_COLOR = "#000000"
+const BACKGROUND_COLOR = "#ffffff"
+const CAPTION_COLOR = "#202020"
+const EXPLOSION_COLOR = "#ff8c1a"
+const EXPLOSION_CORE_COLOR = "#ffe14d"
+
+// CAPTION_HEIGHT is the height of the caption strip below an animation frame relative to the square size
+const CAPTION_HEIGHT = 0.6
+
+// EXPLOSION_FRAMES is the number of frames showing an atomic explosion
+const EXPLOSION_FRAMES = 3
+
+var DEFAULT_OPTIONS = Options{
+	SquareSize:  45,
+	Coordinates: true,
+}
+
+var DEFAULT_ANIMATION_OPTIONS = AnimationOptions{
+	Options:     DEFAULT_OPTIONS,
+	Delay:       time.Second,
+	SlideFrames: 4,
+	SlideDelay:  40 * time.Millisecond,
+	Caption:     true,
+}
+
+// base of most glyphs
+var GLYPH_BASE = Shape{Points: []Point{{22, 90}, {78, 90}, {78, 82}, {22, 82}}}
+
+// GLYPHS are the shapes of the pieces by kind, details are drawn after the body
+var GLYPHS = map[utils.PieceKind][]Shape{
+	utils.Pawn: {
+		GLYPH_BASE,
+		{Points: []Point{{36, 82}, {64, 82}, {58, 52}, {42, 52}}},
+		{Points: []Point{{50, 34}}, Radius: 13},
+	},
+	utils.Knight: {
+		GLYPH_BASE,
+		{Points: []Point{{30, 82}, {76, 82}, {74, 52}, {66, 30}, {54, 18}, {52, 10}, {44, 20}, {34, 26}, {20, 50}, {24, 60}, {32, 56}, {44, 48}, {48, 54}, {34, 70}}},
+		{Points: []Point{{42, 32}}, Radius: 3, Detail: true},
+	},
+	utils.Bishop: {
+		GLYPH_BASE,
+		{Points: []Point{{36, 82}, {64, 82}, {58, 64}, {66, 44}, {50, 20}, {34, 44}, {42, 64}}},
+		{Points: []Point{{50, 15}}, Radius: 6},
+		{Points: []Point{{47, 34}, {53, 34}, {53, 40}, {59, 40}, {59, 46}, {53, 46}, {53, 54}, {47, 54}, {47, 46}, {41, 46}, {41, 40}, {47, 40}}, Detail: true},
+	},
+	utils.Rook: {
+		GLYPH_BASE,
+		{Points: []Point{{32, 82}, {68, 82}, {64, 40}, {72, 40}, {72, 18}, {63, 18}, {63, 26}, {55, 26}, {55, 18}, {45, 18}, {45, 26}, {37, 26}, {37, 18}, {28, 18}, {28, 40}, {36, 40}}},
+		{Points: []Point{{34, 40}, {66, 40}, {66, 45}, {34, 45}}, Detail: true},
+	},
+	utils.Queen: {
+		GLYPH_BASE,
+		{Points: []Point{{28, 82}, {72, 82}, {82, 30}, {64, 56}, {60, 22}, {50, 52}, {40, 22}, {36, 56}, {18, 30}}},
+		{Points: []Point{{18, 27}}, Radius: 6},
+		{Points: []Point{{40, 19}}, Radius: 6},
+		{Points: []Point{{60, 19}}, Radius: 6},
+		{Points: []Point{{82, 27}}, Radius: 6},
+	},
+	utils.King: {
+		GLYPH_BASE,
+		{Points: []Point{{30, 82}, {70, 82}, {80, 46}, {60, 50}, {56, 36}, {44, 36}, {40, 50}, {20, 46}}},
+		{Points: []Point{{46, 8}, {54, 8}, {54, 16}, {62, 16}, {62, 24}, {54, 24}, {54, 36}, {46, 36}, {46, 24}, {38, 24}, {38, 16}, {46, 16}}},
+	},
+	utils.Lancer: {
+		GLYPH_BASE,
+		{Points: []Point{{34, 82}, {66, 82}, {74, 40}, {50, 14}, {26, 40}}},
+	},
+	utils.Sentry: {
+		GLYPH_BASE,
+		{Points: []Point{{34, 82}, {66, 82}, {62, 40}, {72, 32}, {50, 12}, {28, 32}, {38, 40}}},
+		{Points: []Point{{50, 56}}, Radius: 9, Detail: true},
+		{Points: []Point{{50, 56}}, Radius: 4},
+	},
+	utils.Jailer: {
+		GLYPH_BASE,
+		{Points: []Point{{26, 82}, {74, 82}, {74, 30}, {50, 14}, {26, 30}}},
+		{Points: []Point{{35, 32}, {40, 30}, {40, 78}, {35, 78}}, Detail: true},
+		{Points: []Point{{47, 26}, {53, 26}, {53, 78}, {47, 78}}, Detail: true},
+		{Points: []Point{{60, 30}, {65, 32}, {65, 78}, {60, 78}}, Detail: true},
+	},
+	utils.Hawk: {
+		GLYPH_BASE,
+		{Points: []Point{{38, 82}, {62, 82}, {58, 54}, {84, 34}, {58, 40}, {50, 16}, {42, 40}, {16, 34}, {42, 54}}},
+	},
+	utils.Elephant: {
+		GLYPH_BASE,
+		{Points: []Point{{26, 82}, {40, 82}, {40, 70}, {60, 70}, {60, 82}, {74, 82}, {74, 50}, {62, 34}, {40, 34}, {26, 50}}},
+		{Points: []Point{{26, 50}, {16, 60}, {16, 76}, {22, 76}, {22, 62}, {34, 54}}},
+		{Points: []Point{{34, 44}}, Radius: 3, Detail: true},
+	},
+}
+
+// LANCER_ARROW is the direction arrow of lancers pointing north, it is turned to the lancer direction
+var LANCER_ARROW = Shape{Points: []Point{{50, 32}, {61, 48}, {54, 48}, {54, 74}, {46, 74}, {46, 48}, {39, 48}}, Detail: true}
+
+// LANCER_DIRECTIONS are the directions of the butils lancer figures, from LancerN to LancerNW
+var LANCER_DIRECTIONS = []utils.PieceDirection{
+	{File: 0, Rank: -1},
+	{File: 1, Rank: -1},
+	{File: 1, Rank: 0},
+	{File: 1, Rank: 1},
+	{File: 0, Rank: 1},
+	{File: -1, Rank: 1},
+	{File: -1, Rank: 0},
+	{File: -1, Rank: -1},
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,244 @@
+package render
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/easychessanimations/gochess/board"
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// AddArrow adds an arrow from and to squares given in algebraic notation, as e2 and e4
+func (d *Diagram) AddArrow(from, to string, color string) error {
+	fromSq, err := d.ParseSquare(from)
+	if err != nil {
+		return err
+	}
+
+	toSq, err := d.ParseSquare(to)
+	if err != nil {
+		return err
+	}
+
+	d.Arrows = append(d.Arrows, Arrow{From: fromSq, To: toSq, Color: color})
+
+	return nil
+}
+
+// ParseSquare parses a square of the diagram in algebraic notation, as e4
+func (d *Diagram) ParseSquare(algeb string) (utils.Square, error) {
+	if len(algeb) != 2 {
+		return utils.NO_SQUARE, fmt.Errorf("invalid square %s", algeb)
+	}
+
+	file, rank := int(algeb[0]-'a'), int(algeb[1]-'1')
+	if file < 0 || file >= d.Files || rank < 0 || rank >= d.Ranks {
+		return utils.NO_SQUARE, fmt.Errorf("invalid square %s", algeb)
+	}
+
+	return utils.Square{File: int8(file), Rank: int8(d.Ranks - 1 - rank)}, nil
+}
+
+// Size returns the width and height of the picture in pixels
+func (d *Diagram) Size(options Options) (int, int) {
+	margin := options.margin()
+
+	return int(math.Ceil(float64(d.Files)*float64(options.SquareSize) + margin)), int(math.Ceil(float64(d.Ranks)*float64(options.SquareSize) + margin))
+}
+
+// squareOrigin returns the top left corner of sq in the picture
+func (d *Diagram) squareOrigin(sq utils.Square, options Options) Point {
+	file, rank := int(sq.File), int(sq.Rank)
+	if options.Flipped {
+		file, rank = d.Files-1-file, d.Ranks-1-rank
+	}
+
+	size := float64(options.SquareSize)
+
+	return Point{options.margin() + float64(file)*size, float64(rank) * size}
+}
+
+// squareCenter returns the center of sq in the picture
+func (d *Diagram) squareCenter(sq utils.Square, options Options) Point {
+	origin := d.squareOrigin(sq, options)
+	half := float64(options.SquareSize) / 2
+
+	return Point{origin.X + half, origin.Y + half}
+}
+
+// isLight tells whether sq is a light square, a1 is dark
+func (d *Diagram) isLight(sq utils.Square) bool {
+	return (int(sq.File)+d.Ranks-1-int(sq.Rank))%2 == 1
+}
+
+// margin returns the width of the coordinate border in pixels
+func (options Options) margin() float64 {
+	if !options.Coordinates {
+		return 0
+	}
+
+	return math.Round(COORDINATE_MARGIN * float64(options.SquareSize))
+}
+
+// transform scales the shape from glyph units to a square of size at origin, turning it by angle degrees clockwise
+func (s Shape) transform(origin Point, size float64, angle float64) Shape {
+	scale := size / GLYPH_SIZE
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+
+	t := Shape{Radius: s.Radius * scale, Detail: s.Detail}
+
+	for _, p := range s.Points {
+		x, y := p.X-GLYPH_SIZE/2, p.Y-GLYPH_SIZE/2
+		x, y = x*cos-y*sin, x*sin+y*cos
+		t.Points = append(t.Points, Point{origin.X + (x+GLYPH_SIZE/2)*scale, origin.Y + (y+GLYPH_SIZE/2)*scale})
+	}
+
+	return t
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// NewDiagram returns an empty diagram of files x ranks squares
+func NewDiagram(files, ranks int) *Diagram {
+	d := &Diagram{Files: files, Ranks: ranks, Check: utils.NO_SQUARE}
+
+	for rank := range d.Pieces {
+		for file := range d.Pieces[rank] {
+			d.Pieces[rank][file] = utils.NO_PIECE
+		}
+	}
+
+	return d
+}
+
+// FromBoard returns the diagram of the position of b
+// the last move of the move stack and the king of the side to move if it is in check are highlighted
+func FromBoard(b *board.Board) *Diagram {
+	d := NewDiagram(int(b.NumFiles), int(b.NumRanks))
+
+	for rank := 0; rank < d.Ranks; rank++ {
+		for file := 0; file < d.Files; file++ {
+			p := b.Pos.Rep[rank][file]
+			d.Pieces[rank][file] = p
+			if p.Kind != utils.NO_PIECE_KIND && b.IS_EIGHTPIECE() {
+				d.Jailed[rank][file] = b.IsSquareJailedForColor(utils.Square{File: int8(file), Rank: int8(rank)}, p.Color)
+			}
+		}
+	}
+
+	if n := len(b.MoveStack); n > 0 {
+		move := b.MoveStack[n-1].Move
+		d.LastMove = []utils.Square{move.FromSq, move.ToSq}
+	}
+
+	if b.IsInCheck(b.Pos.Turn) {
+		d.Check = b.WhereIsKing(b.Pos.Turn)
+	}
+
+	return d
+}
+
+// FromPosition returns the diagram of pos
+// the last move and the king of the side to move if it is in check are highlighted
+func FromPosition(pos *butils.Position) *Diagram {
+	d := NewDiagram(8, 8)
+
+	for sq := butils.SquareMinValue; sq <= butils.SquareMaxValue; sq++ {
+		pi := pos.Get(sq)
+		if pi == butils.NoPiece {
+			continue
+		}
+
+		dsq := SquareFromButils(sq)
+		d.Pieces[dsq.Rank][dsq.File] = PieceFromButils(pi)
+		d.Jailed[dsq.Rank][dsq.File] = pos.IsSquareJailedForColor(sq, pi.Color())
+	}
+
+	if move := pos.LastMove(); move != butils.NullMove {
+		d.LastMove = []utils.Square{SquareFromButils(move.From()), SquareFromButils(move.To())}
+	}
+
+	if pos.ByPiece(pos.Us(), butils.King) != 0 && pos.IsChecked(pos.Us()) {
+		d.Check = SquareFromButils(pos.WhereIsOurKing())
+	}
+
+	return d
+}
+
+// SquareFromButils converts a butils square to a square of the board package
+func SquareFromButils(sq butils.Square) utils.Square {
+	return utils.Square{File: int8(sq.File()), Rank: int8(7 - sq.Rank())}
+}
+
+// PieceFromButils converts a butils piece to a piece of the board package
+func PieceFromButils(pi butils.Piece) utils.Piece {
+	p := utils.Piece{
+		Kind:  utils.PIECE_LETTER_TO_PIECE_KIND[pi.AlgebLetter()],
+		Color: utils.BLACK,
+	}
+
+	if pi.Color() == butils.White {
+		p.Color = utils.WHITE
+	}
+
+	if pi.Figure().IsLancer() {
+		p.Direction = LANCER_DIRECTIONS[pi.LancerDirection()]
+	}
+
+	return p
+}
+
+// pieceShapes returns the shapes of p drawn in a square of size at origin
+func pieceShapes(p utils.Piece, origin Point, size float64) []Shape {
+	shapes := []Shape{}
+
+	for _, s := range GLYPHS[p.Kind] {
+		shapes = append(shapes, s.transform(origin, size, 0))
+	}
+
+	if p.Kind == utils.Lancer && p.Direction != (utils.PieceDirection{}) {
+		angle := math.Atan2(float64(p.Direction.File), -float64(p.Direction.Rank)) * 180 / math.Pi
+		shapes = append(shapes, LANCER_ARROW.transform(origin, size, angle))
+	}
+
+	return shapes
+}
+
+// arrowShape returns the polygon of an arrow from and to the given points, width is the width of the shaft
+func arrowShape(from, to Point, width float64) Shape {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return Shape{}
+	}
+
+	// unit vectors along and across the arrow
+	ux, uy := dx/length, dy/length
+	vx, vy := -uy, ux
+
+	head := math.Min(2.5*width, length/2)
+	half, headHalf := width/2, 1.2*width
+
+	at := func(along, across float64) Point {
+		return Point{from.X + ux*along + vx*across, from.Y + uy*along + vy*across}
+	}
+
+	return Shape{Points: []Point{
+		at(0, -half), at(length-head, -half), at(length-head, -headHalf), at(length, 0),
+		at(length-head, headHalf), at(length-head, half), at(0, half),
+	}}
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,294 @@
+package render
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"math"
+	"sort"
+
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Image returns the picture of d drawn without antialiasing, it has the same layers as the svg
+func (d *Diagram) Image(options Options) *image.RGBA {
+	width, height := d.Size(options)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	d.drawBoard(img, options)
+	d.drawPieces(img, options)
+	d.drawArrows(img, options)
+
+	return img
+}
+
+// drawBoard draws the squares, the square highlights and the coordinates
+func (d *Diagram) drawBoard(img *image.RGBA, options Options) {
+	size := float64(options.SquareSize)
+
+	draw.Draw(img, img.Bounds(), image.NewUniform(parseColor(BACKGROUND_COLOR)), image.Point{}, draw.Src)
+
+	for rank := 0; rank < d.Ranks; rank++ {
+		for file := 0; file < d.Files; file++ {
+			sq := utils.Square{File: int8(file), Rank: int8(rank)}
+			color := DARK_SQUARE_COLOR
+			if d.isLight(sq) {
+				color = LIGHT_SQUARE_COLOR
+			}
+			fillSquare(img, d.squareOrigin(sq, options), size, 0, parseColor(color), 1)
+		}
+	}
+
+	for _, sq := range d.LastMove {
+		fillSquare(img, d.squareOrigin(sq, options), size, 0, parseColor(LAST_MOVE_COLOR), 0.8)
+	}
+
+	for rank := 0; rank < d.Ranks; rank++ {
+		for file := 0; file < d.Files; file++ {
+			if d.Jailed[rank][file] {
+				origin := d.squareOrigin(utils.Square{File: int8(file), Rank: int8(rank)}, options)
+				fillSquare(img, origin, size, 0, parseColor(JAILED_COLOR), 0.35)
+				strokeSquare(img, origin, size, 0, size/20, parseColor(JAILED_COLOR))
+			}
+		}
+	}
+
+	for _, sq := range d.Highlights {
+		strokeSquare(img, d.squareOrigin(sq, options), size, size/30, size/15, parseColor(HIGHLIGHT_COLOR))
+	}
+
+	if d.Check != utils.NO_SQUARE {
+		fillShape(img, Shape{Points: []Point{d.squareCenter(d.Check, options)}, Radius: size / 2}, parseColor(CHECK_COLOR), 0.7)
+	}
+
+	if options.Coordinates {
+		scale := fontScale(size * 0.28)
+		margin := options.margin()
+
+		for file := 0; file < d.Files; file++ {
+			center := d.squareCenter(utils.Square{File: int8(file), Rank: int8(d.Ranks - 1)}, options)
+			drawTextCentered(img, string(rune('a'+file)), Point{center.X, float64(d.Ranks)*size + margin/2}, scale, parseColor(COORDINATE_COLOR))
+		}
+
+		for rank := 0; rank < d.Ranks; rank++ {
+			center := d.squareCenter(utils.Square{File: 0, Rank: int8(rank)}, options)
+			drawTextCentered(img, fmt.Sprintf("%d", d.Ranks-rank), Point{margin / 2, center.Y}, scale, parseColor(COORDINATE_COLOR))
+		}
+	}
+}
+
+// drawPieces draws the pieces on their squares
+func (d *Diagram) drawPieces(img *image.RGBA, options Options) {
+	for rank := 0; rank < d.Ranks; rank++ {
+		for file := 0; file < d.Files; file++ {
+			p := d.Pieces[rank][file]
+			if p.Kind != utils.NO_PIECE_KIND {
+				drawPiece(img, p, d.squareOrigin(utils.Square{File: int8(file), Rank: int8(rank)}, options), float64(options.SquareSize))
+			}
+		}
+	}
+}
+
+// drawArrows draws the arrows
+func (d *Diagram) drawArrows(img *image.RGBA, options Options) {
+	size := float64(options.SquareSize)
+
+	for _, arrow := range d.Arrows {
+		c, ok := parseColorOk(arrow.Color)
+		if !ok {
+			c = parseColor(ARROW_COLOR)
+		}
+		fillShape(img, arrowShape(d.squareCenter(arrow.From, options), d.squareCenter(arrow.To, options), size/6), c, 0.8)
+	}
+}
+
+// translate returns the shape moved by dx, dy
+func (s Shape) translate(dx, dy float64) Shape {
+	t := Shape{Radius: s.Radius, Detail: s.Detail}
+
+	for _, p := range s.Points {
+		t.Points = append(t.Points, Point{p.X + dx, p.Y + dy})
+	}
+
+	return t
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// drawPiece draws p in a square of size at origin, the outline is drawn by offsetting the body shapes
+func drawPiece(img *image.RGBA, p utils.Piece, origin Point, size float64) {
+	body, detail := parseColor(WHITE_PIECE_COLOR), parseColor(OUTLINE_COLOR)
+	if p.Color == utils.BLACK {
+		body, detail = parseColor(BLACK_PIECE_COLOR), parseColor(WHITE_PIECE_COLOR)
+	}
+
+	outline := math.Max(1, math.Round(size/40))
+
+	for _, s := range pieceShapes(p, origin, size) {
+		if s.Detail {
+			fillShape(img, s, detail, 1)
+			continue
+		}
+
+		if s.Radius > 0 {
+			fillShape(img, Shape{Points: s.Points, Radius: s.Radius + outline}, parseColor(OUTLINE_COLOR), 1)
+		} else {
+			for _, offset := range []Point{{-outline, 0}, {outline, 0}, {0, -outline}, {0, outline}} {
+				fillShape(img, s.translate(offset.X, offset.Y), parseColor(OUTLINE_COLOR), 1)
+			}
+		}
+		fillShape(img, s, body, 1)
+	}
+}
+
+// fillShape fills a polygon or a circle with c, blending it with the picture by alpha
+// a pixel is covered if its center is inside the shape
+func fillShape(img *image.RGBA, s Shape, c color.RGBA, alpha float64) {
+	if len(s.Points) == 0 {
+		return
+	}
+
+	if s.Radius > 0 {
+		center := s.Points[0]
+		for y := int(math.Floor(center.Y - s.Radius)); y <= int(math.Ceil(center.Y+s.Radius)); y++ {
+			for x := int(math.Floor(center.X - s.Radius)); x <= int(math.Ceil(center.X+s.Radius)); x++ {
+				if math.Hypot(float64(x)+0.5-center.X, float64(y)+0.5-center.Y) <= s.Radius {
+					blendPixel(img, x, y, c, alpha)
+				}
+			}
+		}
+		return
+	}
+
+	top, bottom := s.Points[0].Y, s.Points[0].Y
+	for _, p := range s.Points {
+		top, bottom = math.Min(top, p.Y), math.Max(bottom, p.Y)
+	}
+
+	// scanlines through the pixel centers, filled between pairs of edge crossings
+	for y := int(math.Floor(top)); y <= int(math.Ceil(bottom)); y++ {
+		cy := float64(y) + 0.5
+		crossings := []float64{}
+		for i, j := 0, len(s.Points)-1; i < len(s.Points); j, i = i, i+1 {
+			a, b := s.Points[i], s.Points[j]
+			if (a.Y > cy) != (b.Y > cy) {
+				crossings = append(crossings, a.X+(cy-a.Y)*(b.X-a.X)/(b.Y-a.Y))
+			}
+		}
+		sort.Float64s(crossings)
+		for i := 0; i+1 < len(crossings); i += 2 {
+			for x := int(math.Ceil(crossings[i] - 0.5)); x < int(math.Ceil(crossings[i+1]-0.5)); x++ {
+				blendPixel(img, x, y, c, alpha)
+			}
+		}
+	}
+}
+
+// fillSquare fills a square of size at origin shrunk by inset on each side
+func fillSquare(img *image.RGBA, origin Point, size, inset float64, c color.RGBA, alpha float64) {
+	fillShape(img, squareShape(origin, size, inset), c, alpha)
+}
+
+// strokeSquare draws the border of width of a square of size at origin shrunk by inset on each side
+func strokeSquare(img *image.RGBA, origin Point, size, inset, width float64, c color.RGBA) {
+	x0, y0, x1, y1 := origin.X+inset, origin.Y+inset, origin.X+size-inset, origin.Y+size-inset
+
+	for _, side := range [][4]float64{{x0, y0, x1, y0 + width}, {x0, y1 - width, x1, y1}, {x0, y0, x0 + width, y1}, {x1 - width, y0, x1, y1}} {
+		fillShape(img, Shape{Points: []Point{{side[0], side[1]}, {side[2], side[1]}, {side[2], side[3]}, {side[0], side[3]}}}, c, 1)
+	}
+}
+
+// squareShape returns the polygon of a square of size at origin shrunk by inset on each side
+func squareShape(origin Point, size, inset float64) Shape {
+	x0, y0, x1, y1 := origin.X+inset, origin.Y+inset, origin.X+size-inset, origin.Y+size-inset
+
+	return Shape{Points: []Point{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}}}
+}
+
+// blendPixel mixes c into the pixel at x, y by alpha, pixels outside the picture are ignored
+func blendPixel(img *image.RGBA, x, y int, c color.RGBA, alpha float64) {
+	if !(image.Point{x, y}.In(img.Bounds())) {
+		return
+	}
+
+	if alpha >= 1 {
+		img.SetRGBA(x, y, c)
+		return
+	}
+
+	old := img.RGBAAt(x, y)
+	mix := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a)*(1-alpha) + float64(b)*alpha))
+	}
+
+	img.SetRGBA(x, y, color.RGBA{mix(old.R, c.R), mix(old.G, c.G), mix(old.B, c.B), 255})
+}
+
+// parseColorOk parses a color written as #rrggbb
+func parseColorOk(s string) (color.RGBA, bool) {
+	var r, g, b uint8
+
+	if len(s) != 7 {
+		return color.RGBA{}, false
+	}
+	if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return color.RGBA{}, false
+	}
+
+	return color.RGBA{r, g, b, 255}, true
+}
+
+// parseColor parses one of the color constants
+func parseColor(s string) color.RGBA {
+	c, _ := parseColorOk(s)
+
+	return c
+}
+
+// paletted converts img to a paletted picture, exactly if it has at most 256 colors
+func paletted(img *image.RGBA) *image.Paletted {
+	bounds := img.Bounds()
+
+	index := map[color.RGBA]uint8{}
+	pal := color.Palette{}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			if _, ok := index[c]; ok {
+				continue
+			}
+			if len(pal) == 256 {
+				p := image.NewPaletted(bounds, palette.Plan9)
+				draw.Draw(p, bounds, img, bounds.Min, draw.Src)
+				return p
+			}
+			index[c] = uint8(len(pal))
+			pal = append(pal, c)
+		}
+	}
+
+	p := image.NewPaletted(bounds, pal)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p.SetColorIndex(x, y, index[img.RGBAAt(x, y)])
+		}
+	}
+
+	return p
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,165 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/gif"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/easychessanimations/gochess/board"
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/pgn"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+// eightpiece position with a black lancer and pawn jailed on e5 and e7 and white in check from the lancer on h4
+const JAILED_FEN = "jlsesqkbnr/pppppppp/4J3/4lsw3/7lsw/8/PPPPP1PP/J1SQKBNR w Kkq - 0 1"
+
+func TestFromPosition(t *testing.T) {
+	pos, err := butils.PositionFromFENAndVariant(JAILED_FEN, utils.VARIANT_EIGHTPIECE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &board.Board{}
+	b.Init(utils.VARIANT_EIGHTPIECE)
+	b.SetFromFen(JAILED_FEN + " -")
+
+	// both representations give the same diagram
+	if p, q := FromPosition(pos), FromBoard(b); p.Pieces != q.Pieces || p.Jailed != q.Jailed || p.Check != q.Check {
+		t.Errorf("diagrams differ\n%v\n%v", p, q)
+	}
+
+	d := FromPosition(pos)
+
+	e5 := utils.Square{File: 4, Rank: 3}
+	if p := d.Pieces[e5.Rank][e5.File]; p.Kind != utils.Lancer || p.Color != utils.BLACK || p.Direction != (utils.PieceDirection{File: -1, Rank: 1}) {
+		t.Errorf("expected a black lancer pointing south west on e5, got %+v", p)
+	}
+	if !d.Jailed[e5.Rank][e5.File] || !d.Jailed[1][4] {
+		t.Errorf("expected the lancer on e5 and the pawn on e7 to be jailed")
+	}
+	if d.Jailed[1][3] || d.Jailed[0][4] {
+		t.Errorf("unexpected jailed pieces on d7 or e8, the jailer is not next to them")
+	}
+	if e1 := (utils.Square{File: 4, Rank: 7}); d.Check != e1 {
+		t.Errorf("expected the check on e1, got %v", d.Check)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	pos, _ := butils.PositionFromFENAndVariant(JAILED_FEN, utils.VARIANT_EIGHTPIECE)
+
+	d := FromPosition(pos)
+	if err := d.AddArrow("b1", "d3", "#882020"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.AddArrow("b1", "b9", ""); err == nil {
+		t.Errorf("expected an error for the square b9")
+	}
+	d.Highlights = append(d.Highlights, utils.Square{File: 4, Rank: 4})
+
+	for _, flipped := range []bool{false, true} {
+		options := DEFAULT_OPTIONS
+		options.Flipped = flipped
+		svg := d.SVG(options)
+
+		decoder := xml.NewDecoder(strings.NewReader(svg))
+		for {
+			if _, err := decoder.Token(); err != nil {
+				if err.Error() != "EOF" {
+					t.Fatalf("invalid svg %v\n%s", err, svg)
+				}
+				break
+			}
+		}
+
+		for _, s := range []string{`fill="#882020"`, `fill="` + CHECK_COLOR + `"`, `fill="` + JAILED_COLOR + `"`, `stroke="` + HIGHLIGHT_COLOR + `"`} {
+			if !strings.Contains(svg, s) {
+				t.Errorf("missing %s in\n%s", s, svg)
+			}
+		}
+
+		// the rank number next to the top row
+		top := "8"
+		if flipped {
+			top = "1"
+		}
+		found := false
+		for _, line := range strings.Split(svg, "\n") {
+			found = found || strings.HasPrefix(line, `<text x="9" y="22.5"`) && strings.HasSuffix(line, ">"+top+"</text>")
+		}
+		if !found {
+			t.Errorf("expected rank %s at the top in\n%s", top, svg)
+		}
+	}
+
+	options := Options{SquareSize: 40}
+	if w, h := d.Size(options); w != 320 || h != 320 {
+		t.Errorf("expected 320x320 without coordinates, got %dx%d", w, h)
+	}
+}
+
+func TestAnimation(t *testing.T) {
+	games, err := pgn.ParseString(`[Variant "Atomic"]
+
+1. e4 {+0.35/12 0.52s} d5 {[%eval -0.20]} 2. exd5 {+M3/4 0.1s} 1-0
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := DEFAULT_ANIMATION_OPTIONS
+	options.SquareSize = 20
+	options.SlideFrames = 2
+
+	frames, err := GameFrames(games[0], options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the start position, three moves with their slides and the explosion of the capture
+	if expected := 1 + 3*(options.SlideFrames+1) + EXPLOSION_FRAMES; len(frames) != expected {
+		t.Fatalf("expected %d frames, got %d", expected, len(frames))
+	}
+
+	width, height := FromBoard(&board.Board{NumFiles: 8, NumRanks: 8}).Size(options.Options)
+	if b := frames[0].Image.Bounds(); b.Dx() != width || b.Dy() <= height {
+		t.Errorf("expected a %d wide frame with a caption below the board, got %v", width, b)
+	}
+
+	// the capture square shows the explosion before the position after the capture
+	d5 := utils.Square{File: 3, Rank: 3}
+	center := FromBoard(&board.Board{NumFiles: 8, NumRanks: 8}).squareCenter(d5, options.Options)
+	explosion, after := frames[len(frames)-2].Image.RGBAAt(int(center.X), int(center.Y)), frames[len(frames)-1].Image.RGBAAt(int(center.X), int(center.Y))
+	// the explosion is orange, the highlighted empty square is not
+	if explosion.R < 200 || explosion.B > 100 || after.B <= 100 {
+		t.Errorf("expected the explosion on d5 and an empty d5 after it, got %v and %v", explosion, after)
+	}
+
+	buff := bytes.Buffer{}
+	if err := WriteGIF(&buff, frames); err != nil {
+		t.Fatal(err)
+	}
+	g, err := gif.DecodeAll(&buff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Image) != len(frames) || g.Delay[0] != int(options.Delay/(10*time.Millisecond)) || g.Delay[1] != int(options.SlideDelay/(10*time.Millisecond)) {
+		t.Errorf("unexpected gif of %d frames with delays %v", len(g.Image), g.Delay)
+	}
+
+	for comment, expected := range map[string]string{
+		"+0.35/12 0.52s":        "+0.35",
+		"-M3/20 1.2s":           "-M3",
+		"book":                  "",
+		"good move [%eval #-2]": "#-2",
+		"[%clk 0:01:00]":        "",
+	} {
+		if eval := EvalFromComment(comment); eval != expected {
+			t.Errorf("comment %q: expected eval %q, got %q", comment, expected, eval)
+		}
+	}
+}
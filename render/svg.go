@@ -0,0 +1,181 @@
+package render
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// SVG returns the picture of d as an svg document
+func (d *Diagram) SVG(options Options) string {
+	buff := strings.Builder{}
+
+	d.WriteSVG(&buff, options)
+
+	return buff.String()
+}
+
+// WriteSVG writes the picture of d as an svg document to w
+// the layers are squares, square highlights, coordinates, pieces and arrows, in this order
+func (d *Diagram) WriteSVG(w io.Writer, options Options) error {
+	width, height := d.Size(options)
+	size := float64(options.SquareSize)
+
+	buff := strings.Builder{}
+
+	fmt.Fprintf(&buff, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+
+	for rank := 0; rank < d.Ranks; rank++ {
+		for file := 0; file < d.Files; file++ {
+			sq := utils.Square{File: int8(file), Rank: int8(rank)}
+			color := DARK_SQUARE_COLOR
+			if d.isLight(sq) {
+				color = LIGHT_SQUARE_COLOR
+			}
+			writeSquare(&buff, d.squareOrigin(sq, options), size, fmt.Sprintf(`fill="%s"`, color))
+		}
+	}
+
+	for _, sq := range d.LastMove {
+		writeSquare(&buff, d.squareOrigin(sq, options), size, fmt.Sprintf(`fill="%s" fill-opacity="0.8"`, LAST_MOVE_COLOR))
+	}
+
+	for rank := 0; rank < d.Ranks; rank++ {
+		for file := 0; file < d.Files; file++ {
+			if d.Jailed[rank][file] {
+				sq := utils.Square{File: int8(file), Rank: int8(rank)}
+				writeSquare(&buff, d.squareOrigin(sq, options), size, fmt.Sprintf(`fill="%s" fill-opacity="0.35" stroke="%s" stroke-width="%s" stroke-dasharray="%s"`,
+					JAILED_COLOR, JAILED_COLOR, formatFloat(size/20), formatFloat(size/10)))
+			}
+		}
+	}
+
+	for _, sq := range d.Highlights {
+		inset := size / 30
+		origin := d.squareOrigin(sq, options)
+		fmt.Fprintf(&buff, `<rect x="%s" y="%s" width="%s" height="%s" fill="none" stroke="%s" stroke-width="%s"/>`+"\n",
+			formatFloat(origin.X+inset), formatFloat(origin.Y+inset), formatFloat(size-2*inset), formatFloat(size-2*inset), HIGHLIGHT_COLOR, formatFloat(2*inset))
+	}
+
+	if d.Check != utils.NO_SQUARE {
+		center := d.squareCenter(d.Check, options)
+		fmt.Fprintf(&buff, `<circle cx="%s" cy="%s" r="%s" fill="%s" fill-opacity="0.7"/>`+"\n",
+			formatFloat(center.X), formatFloat(center.Y), formatFloat(size/2), CHECK_COLOR)
+	}
+
+	if options.Coordinates {
+		d.writeCoordinates(&buff, options)
+	}
+
+	for rank := 0; rank < d.Ranks; rank++ {
+		for file := 0; file < d.Files; file++ {
+			p := d.Pieces[rank][file]
+			if p.Kind == utils.NO_PIECE_KIND {
+				continue
+			}
+			origin := d.squareOrigin(utils.Square{File: int8(file), Rank: int8(rank)}, options)
+			writePiece(&buff, p, origin, size)
+		}
+	}
+
+	for _, arrow := range d.Arrows {
+		color := arrow.Color
+		if color == "" {
+			color = ARROW_COLOR
+		}
+		shape := arrowShape(d.squareCenter(arrow.From, options), d.squareCenter(arrow.To, options), size/6)
+		if len(shape.Points) > 0 {
+			fmt.Fprintf(&buff, `<polygon points="%s" fill="%s" fill-opacity="0.8"/>`+"\n", formatPoints(shape.Points), color)
+		}
+	}
+
+	buff.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, buff.String())
+
+	return err
+}
+
+// writeCoordinates writes the file letters below the board and the rank numbers left of it
+func (d *Diagram) writeCoordinates(buff *strings.Builder, options Options) {
+	size := float64(options.SquareSize)
+	margin := options.margin()
+	fontSize := formatFloat(math.Round(size * 0.28))
+
+	for file := 0; file < d.Files; file++ {
+		center := d.squareCenter(utils.Square{File: int8(file), Rank: int8(d.Ranks - 1)}, options)
+		fmt.Fprintf(buff, `<text x="%s" y="%s" font-family="sans-serif" font-size="%s" fill="%s" text-anchor="middle" dominant-baseline="central">%c</text>`+"\n",
+			formatFloat(center.X), formatFloat(float64(d.Ranks)*size+margin/2), fontSize, COORDINATE_COLOR, 'a'+file)
+	}
+
+	for rank := 0; rank < d.Ranks; rank++ {
+		center := d.squareCenter(utils.Square{File: 0, Rank: int8(rank)}, options)
+		fmt.Fprintf(buff, `<text x="%s" y="%s" font-family="sans-serif" font-size="%s" fill="%s" text-anchor="middle" dominant-baseline="central">%d</text>`+"\n",
+			formatFloat(margin/2), formatFloat(center.Y), fontSize, COORDINATE_COLOR, d.Ranks-rank)
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// writeSquare writes a rect covering the square at origin with the given attributes
+func writeSquare(buff *strings.Builder, origin Point, size float64, attributes string) {
+	fmt.Fprintf(buff, `<rect x="%s" y="%s" width="%s" height="%s" %s/>`+"\n",
+		formatFloat(origin.X), formatFloat(origin.Y), formatFloat(size), formatFloat(size), attributes)
+}
+
+// writePiece writes the shapes of p as a group
+func writePiece(buff *strings.Builder, p utils.Piece, origin Point, size float64) {
+	body, detail := WHITE_PIECE_COLOR, OUTLINE_COLOR
+	if p.Color == utils.BLACK {
+		body, detail = BLACK_PIECE_COLOR, WHITE_PIECE_COLOR
+	}
+
+	fmt.Fprintf(buff, `<g stroke="%s" stroke-width="%s" stroke-linejoin="round">`+"\n", OUTLINE_COLOR, formatFloat(size/40))
+
+	for _, s := range pieceShapes(p, origin, size) {
+		fill, stroke := body, ""
+		if s.Detail {
+			fill, stroke = detail, ` stroke="none"`
+		}
+		if s.Radius > 0 {
+			fmt.Fprintf(buff, `<circle cx="%s" cy="%s" r="%s" fill="%s"%s/>`+"\n", formatFloat(s.Points[0].X), formatFloat(s.Points[0].Y), formatFloat(s.Radius), fill, stroke)
+		} else {
+			fmt.Fprintf(buff, `<polygon points="%s" fill="%s"%s/>`+"\n", formatPoints(s.Points), fill, stroke)
+		}
+	}
+
+	buff.WriteString("</g>\n")
+}
+
+// formatPoints formats the points of a polygon
+func formatPoints(points []Point) string {
+	parts := []string{}
+
+	for _, p := range points {
+		parts = append(parts, formatFloat(p.X)+","+formatFloat(p.Y))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// formatFloat formats x with at most two decimals
+func formatFloat(x float64) string {
+	return strconv.FormatFloat(math.Round(x*100)/100, 'f', -1, 64)
+}
+
+/////////////////////////////////////////////////////////////////////
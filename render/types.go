@@ -0,0 +1,71 @@
+package render
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"image"
+	"time"
+
+	"github.com/easychessanimations/gochess/board"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// types
+
+// Point is a point of a glyph or of the board picture
+type Point struct {
+	X, Y float64
+}
+
+// Shape is a part of a glyph, in units of GLYPH_SIZE with the origin at the top left corner of the square
+type Shape struct {
+	Points []Point // corners of a polygon, or the center of a circle
+	Radius float64 // radius of a circle, 0 for a polygon
+	Detail bool    // drawn in the color of the other side, on top of the body
+}
+
+// Arrow is an arrow drawn between the centers of two squares
+type Arrow struct {
+	From, To utils.Square
+	Color    string // svg color, ARROW_COLOR if empty
+}
+
+// Diagram is a board picture independent of the board representation
+// squares are given as in the board package, rank 0 is the top rank seen from white
+type Diagram struct {
+	Files, Ranks int
+	Pieces       [board.MAX_RANKS][board.MAX_FILES]utils.Piece
+	Jailed       [board.MAX_RANKS][board.MAX_FILES]bool // the piece on the square is jailed
+	LastMove     []utils.Square                         // from and to squares of the last move, empty for none
+	Check        utils.Square                           // square of the king in check, utils.NO_SQUARE for none
+	Arrows       []Arrow
+	Highlights   []utils.Square // squares marked with HIGHLIGHT_COLOR
+}
+
+// Options controls how a Diagram is drawn
+type Options struct {
+	SquareSize  int  // size of a square in pixels
+	Coordinates bool // draw file letters and rank numbers around the board
+	Flipped     bool // black at the bottom
+}
+
+// AnimationOptions controls how the frames of a game are drawn
+type AnimationOptions struct {
+	Options
+	Delay       time.Duration // how long the position after a move is shown
+	SlideFrames int           // number of frames of a piece sliding between its squares, 0 for none
+	SlideDelay  time.Duration // how long a sliding or explosion frame is shown
+	Caption     bool          // write the move and its eval below the board
+}
+
+// Frame is a picture of an animation
+type Frame struct {
+	Image *image.RGBA
+	Delay time.Duration
+}
+
+/////////////////////////////////////////////////////////////////////
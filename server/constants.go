@@ -0,0 +1,25 @@
+package server
+
+/////////////////////////////////////////////////////////////////////
+// constants
+
+// routes of the api
+const ROUTE_MOVES = "/api/moves"
+const ROUTE_PLAY = "/api/play"
+const ROUTE_PERFT = "/api/perft"
+const ROUTE_EVAL = "/api/eval"
+const ROUTE_ANALYSIS = "/api/analysis"
+
+// events of the analysis stream
+const EVENT_INFO = "info"
+const EVENT_BESTMOVE = "bestmove"
+
+// defaults of the server command, a perft deeper than 6 takes minutes in most positions
+var DEFAULT_OPTIONS = Options{
+	AllowOrigin:   "*",
+	MaxPerftDepth: 6,
+	MaxAnalyses:   1,
+	Threads:       1,
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,449 @@
+package server
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/easychessanimations/gochess/bengine"
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/perft"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// ServeHTTP answers the api calls, GET calls take their parameters from the query string and POST calls from a json body
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.options.AllowOrigin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", s.options.AllowOrigin)
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	}
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet, http.MethodPost:
+		s.mux.ServeHTTP(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// handleMoves answers the legal moves of the position
+func (s *Server) handleMoves(w http.ResponseWriter, r *http.Request) {
+	req, pos, played, err := readPosition(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, positionResponse(req, pos, played))
+}
+
+// handlePlay plays the moves of the request and answers the position reached with its legal moves
+func (s *Server) handlePlay(w http.ResponseWriter, r *http.Request) {
+	req, pos, played, err := readPosition(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(played) == 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("no moves to play"))
+		return
+	}
+
+	writeJSON(w, positionResponse(req, pos, played))
+}
+
+// handlePerft counts the leaf nodes at the depth of the request, per root move if divide is set
+func (s *Server) handlePerft(w http.ResponseWriter, r *http.Request) {
+	req, pos, _, err := readPosition(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Depth <= 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("perft needs a depth"))
+		return
+	}
+	if s.options.MaxPerftDepth > 0 && req.Depth > s.options.MaxPerftDepth {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("perft depth %d is deeper than %d", req.Depth, s.options.MaxPerftDepth))
+		return
+	}
+
+	res := PerftResponse{Fen: pos.String(), Depth: req.Depth}
+
+	gen, err := perft.NewGenerator(perft.BACKEND_BITBOARD, res.Fen, variantOf(req), req.Chess960)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	start := time.Now()
+
+	if req.Divide {
+		for _, entry := range perft.Divide(gen, req.Depth, nil) {
+			res.Divide = append(res.Divide, DivideEntry{Move: entry.Move, Nodes: entry.Nodes})
+			res.Nodes += entry.Nodes
+		}
+	} else {
+		res.Nodes = perft.Count(gen, req.Depth, nil)
+	}
+
+	res.Time = time.Since(start).Milliseconds()
+
+	writeJSON(w, res)
+}
+
+// handleEval answers the static evaluation of the position
+func (s *Server) handleEval(w http.ResponseWriter, r *http.Request) {
+	_, pos, _, err := readPosition(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	eval := bengine.Evaluate(pos)
+
+	writeJSON(w, EvalResponse{
+		Fen:   pos.String(),
+		White: accumOf(eval.Accum[butils.White]),
+		Black: accumOf(eval.Accum[butils.Black]),
+		All:   accumOf(eval.Accum[butils.NoColor]),
+		Phase: bengine.Phase(pos),
+		Score: bengine.NewEngine(pos, nil, bengine.Options{}).Score(),
+	})
+}
+
+// handleAnalysis searches the position and streams the lines of each depth as server sent events
+// the search is limited by the depth and movetime of the request and stops when the client goes away
+// without a limit it runs until then
+func (s *Server) handleAnalysis(w http.ResponseWriter, r *http.Request) {
+	req, pos, _, err := readPosition(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Depth < 0 || req.MoveTime < 0 || req.MultiPV < 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("negative analysis limit"))
+		return
+	}
+	if _, ok := w.(http.Flusher); !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	ctx := r.Context()
+
+	select {
+	case s.analyses <- struct{}{}:
+		defer func() { <-s.analyses }()
+	case <-ctx.Done():
+		return
+	}
+
+	tc := bengine.NewTimeControl(pos, false)
+	if req.Depth > 0 {
+		tc.Depth = int32(req.Depth)
+	}
+	moveTime := time.Duration(req.MoveTime) * time.Millisecond
+	if s.options.MaxMoveTime > 0 && (moveTime == 0 || moveTime > s.options.MaxMoveTime) {
+		moveTime = s.options.MaxMoveTime
+	}
+	if moveTime > 0 {
+		tc.WTime, tc.BTime, tc.MovesToGo = moveTime, moveTime, 1
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	logger := &streamLogger{w: w, pos: pos.Clone(), start: time.Now()}
+	eng := bengine.NewEngine(pos, logger, bengine.Options{MultiPV: req.MultiPV, Threads: s.options.Threads})
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			tc.Stop()
+		case <-done:
+		}
+	}()
+
+	tc.Start(false)
+	score, pv := eng.PlayMoves(tc, nil)
+
+	best := BestMove{Score: logger.score}
+	if len(pv) > 0 {
+		moves := lineOf(logger.pos, pv)
+		best.Move = &moves[0]
+		if len(moves) > 1 {
+			best.Ponder = &moves[1]
+		}
+		if best.Score == (Score{}) {
+			best.Score = scoreOf(score)
+		}
+	}
+
+	writeEvent(w, EVENT_BESTMOVE, best)
+}
+
+// BeginSearch does nothing
+func (l *streamLogger) BeginSearch() {
+}
+
+// EndSearch does nothing, the best move is sent after the search returns
+func (l *streamLogger) EndSearch() {
+}
+
+// PrintPV sends a line of the analysis as an info event
+func (l *streamLogger) PrintPV(stats bengine.Stats, multiPV int, score int32, pv []butils.Move) {
+	elapsed := time.Since(l.start)
+	if elapsed < time.Microsecond {
+		elapsed = time.Microsecond
+	}
+
+	info := Info{
+		Depth:    stats.Depth,
+		SelDepth: stats.SelDepth,
+		MultiPV:  multiPV,
+		Score:    scoreOf(score),
+		Nodes:    stats.Nodes,
+		Time:     elapsed.Milliseconds(),
+		NPS:      stats.Nodes * uint64(time.Second) / uint64(elapsed),
+		PV:       lineOf(l.pos, pv),
+	}
+
+	if multiPV == 1 {
+		l.score = info.Score
+	}
+
+	writeEvent(l.w, EVENT_INFO, info)
+}
+
+// CurrMove does nothing
+func (l *streamLogger) CurrMove(depth int, move butils.Move, num int) {
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// NewServer returns a server with the routes of the api
+func NewServer(options Options) *Server {
+	if options.MaxAnalyses <= 0 {
+		options.MaxAnalyses = 1
+	}
+
+	s := &Server{
+		options:  options,
+		mux:      http.NewServeMux(),
+		analyses: make(chan struct{}, options.MaxAnalyses),
+	}
+
+	s.mux.HandleFunc(ROUTE_MOVES, s.handleMoves)
+	s.mux.HandleFunc(ROUTE_PLAY, s.handlePlay)
+	s.mux.HandleFunc(ROUTE_PERFT, s.handlePerft)
+	s.mux.HandleFunc(ROUTE_EVAL, s.handleEval)
+	s.mux.HandleFunc(ROUTE_ANALYSIS, s.handleAnalysis)
+
+	return s
+}
+
+// ParseRequest reads the parameters of r, from the json body of a POST and from the query string otherwise
+func ParseRequest(r *http.Request) (Request, error) {
+	req := Request{}
+
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return req, fmt.Errorf("invalid json body: %w", err)
+		}
+		return req, nil
+	}
+
+	query := r.URL.Query()
+
+	req.Fen = query.Get("fen")
+	req.Variant = query.Get("variant")
+
+	for _, moves := range query["moves"] {
+		req.Moves = append(req.Moves, strings.FieldsFunc(moves, func(c rune) bool {
+			return c == ' ' || c == ','
+		})...)
+	}
+
+	var err error
+	for name, value := range map[string]*bool{"chess960": &req.Chess960, "divide": &req.Divide} {
+		if s := query.Get(name); s != "" {
+			if *value, err = strconv.ParseBool(s); err != nil {
+				return req, fmt.Errorf("invalid %s %s", name, s)
+			}
+		}
+	}
+	for name, value := range map[string]*int{"depth": &req.Depth, "movetime": &req.MoveTime, "multipv": &req.MultiPV} {
+		if s := query.Get(name); s != "" {
+			if *value, err = strconv.Atoi(s); err != nil {
+				return req, fmt.Errorf("invalid %s %s", name, s)
+			}
+		}
+	}
+
+	return req, nil
+}
+
+// readPosition parses the request of r and sets up its position, it returns the moves played from the fen
+func readPosition(r *http.Request) (Request, *butils.Position, []Move, error) {
+	req, err := ParseRequest(r)
+	if err != nil {
+		return req, nil, nil, err
+	}
+
+	if req.Variant == "" {
+		req.Variant = utils.VariantKeyToVariantKeyString(utils.VARIANT_STANDARD)
+	}
+	if _, ok := utils.VARIANT_KEY_STRING_TO_VARIANT_KEY[req.Variant]; !ok {
+		return req, nil, nil, fmt.Errorf("unknown variant %s", req.Variant)
+	}
+	if req.Fen == "" {
+		req.Fen = utils.StartFenForVariant(variantOf(req))
+	}
+
+	pos, err := butils.PositionFromFENAndVariant(req.Fen, variantOf(req))
+	if err != nil {
+		return req, nil, nil, err
+	}
+	pos.Chess960 = req.Chess960
+
+	played := []Move{}
+	for _, s := range req.Moves {
+		move, err := parseMove(pos, s)
+		if err != nil {
+			return req, nil, nil, err
+		}
+		played = append(played, Move{UCI: pos.MoveToUCI(move), SAN: pos.MoveToSan(move)})
+		pos.DoMove(move)
+	}
+
+	return req, pos, played, nil
+}
+
+// parseMove returns the legal move of pos written s in san or uci
+func parseMove(pos *butils.Position, s string) (butils.Move, error) {
+	move, err := pos.SanToMove(s)
+	if err == nil {
+		return move, nil
+	}
+
+	for _, m := range pos.LegalMoves() {
+		if pos.MoveToUCI(m) == strings.ToLower(s) {
+			return m, nil
+		}
+	}
+
+	return butils.NullMove, fmt.Errorf("invalid move %s in %s: %w", s, pos, err)
+}
+
+// variantOf returns the variant of req
+func variantOf(req Request) utils.VariantKey {
+	return utils.VariantKeyStringToVariantKey(req.Variant)
+}
+
+// positionResponse returns pos with its legal moves sorted by san
+func positionResponse(req Request, pos *butils.Position, played []Move) PositionResponse {
+	res := PositionResponse{
+		Fen:     pos.String(),
+		Variant: req.Variant,
+		Turn:    "white",
+		Check:   pos.IsChecked(pos.Us()),
+		Played:  played,
+		Moves:   []Move{},
+	}
+	if pos.Us() == butils.Black {
+		res.Turn = "black"
+	}
+
+	for _, move := range pos.LegalMoves() {
+		res.Moves = append(res.Moves, Move{UCI: pos.MoveToUCI(move), SAN: pos.MoveToSan(move)})
+	}
+
+	sort.Slice(res.Moves, func(i, j int) bool {
+		return res.Moves[i].SAN < res.Moves[j].SAN
+	})
+
+	return res
+}
+
+// lineOf returns the moves of line played from pos
+func lineOf(pos *butils.Position, line []butils.Move) []Move {
+	pos = pos.Clone()
+
+	moves := []Move{}
+	for _, move := range line {
+		moves = append(moves, Move{UCI: pos.MoveToUCI(move), SAN: pos.MoveToSan(move)})
+		pos.DoMove(move)
+	}
+
+	return moves
+}
+
+// scoreOf returns a search score, the mate distance is written as in the uci engine
+func scoreOf(score int32) Score {
+	if score > bengine.KnownWinScore {
+		mate := (bengine.MateScore - score + 1) / 2
+		return Score{Mate: &mate}
+	} else if score < bengine.KnownLossScore {
+		mate := (bengine.MatedScore - score) / 2
+		return Score{Mate: &mate}
+	}
+
+	return Score{CP: &score}
+}
+
+// accumOf returns a of an evaluation in centipawns and internal units
+func accumOf(a bengine.Accum) Accum {
+	return Accum{
+		M: Value{CP: bengine.ScaleToCentipawns(a.M), Nat: a.M},
+		E: Value{CP: bengine.ScaleToCentipawns(a.E), Nat: a.E},
+	}
+}
+
+// writeJSON writes v as a json answer
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as a json answer with status
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+}
+
+// writeEvent sends v as a server sent event
+func writeEvent(w http.ResponseWriter, event string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	w.(http.Flusher).Flush()
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// get calls the api of ts and decodes the json answer into v, it returns the status
+func get(t *testing.T, ts *httptest.Server, route string, query url.Values, v interface{}) int {
+	res, err := http.Get(ts.URL + route + "?" + query.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+
+	return res.StatusCode
+}
+
+func TestServer(t *testing.T) {
+	ts := httptest.NewServer(NewServer(DEFAULT_OPTIONS))
+	defer ts.Close()
+
+	moves := PositionResponse{}
+	if status := get(t, ts, ROUTE_MOVES, url.Values{}, &moves); status != http.StatusOK || len(moves.Moves) != 20 || moves.Turn != "white" {
+		t.Fatalf("start position: status %d, %d moves, turn %s", status, len(moves.Moves), moves.Turn)
+	}
+
+	// san and uci moves, posted as json
+	res, err := http.Post(ts.URL+ROUTE_PLAY, "application/json", strings.NewReader(`{"moves": ["e4", "e7e5", "Qh5", "Nc6", "Bc4", "Nf6", "Qxf7#"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	played := PositionResponse{}
+	json.NewDecoder(res.Body).Decode(&played)
+	res.Body.Close()
+	if !played.Check || len(played.Moves) != 0 || played.Played[6].UCI != "h5f7" || played.Played[6].SAN != "Qxf7#" {
+		t.Errorf("scholar's mate: %+v", played)
+	}
+
+	failed := errorResponse{}
+	for _, query := range []url.Values{
+		{"moves": {"e5"}},
+		{"variant": {"unknown"}},
+		{"fen": {"8/8/8 w - -"}},
+	} {
+		if status := get(t, ts, ROUTE_PLAY, query, &failed); status != http.StatusBadRequest || failed.Error == "" {
+			t.Errorf("%v: expected an error, got status %d", query, status)
+		}
+	}
+
+	perft := PerftResponse{}
+	if get(t, ts, ROUTE_PERFT, url.Values{"depth": {"3"}, "divide": {"true"}}, &perft); perft.Nodes != 8902 || len(perft.Divide) != 20 {
+		t.Errorf("perft 3: %d nodes, %d root moves", perft.Nodes, len(perft.Divide))
+	}
+	if status := get(t, ts, ROUTE_PERFT, url.Values{"depth": {"7"}}, &failed); status != http.StatusBadRequest {
+		t.Errorf("perft deeper than allowed: status %d", status)
+	}
+
+	// white is a rook up
+	eval := EvalResponse{}
+	get(t, ts, ROUTE_EVAL, url.Values{"fen": {"4k3/pppppppp/8/8/8/8/PPPPPPPP/R3K3 w - - 0 1"}}, &eval)
+	if eval.All.M.CP < 300 || eval.Score < 300 || eval.White.M.Nat <= eval.Black.M.Nat {
+		t.Errorf("rook up: %+v", eval)
+	}
+}
+
+func TestAnalysis(t *testing.T) {
+	ts := httptest.NewServer(NewServer(DEFAULT_OPTIONS))
+	defer ts.Close()
+
+	query := url.Values{"fen": {"6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1"}, "depth": {"4"}, "multipv": {"2"}}
+	res, err := http.Get(ts.URL + ROUTE_ANALYSIS + "?" + query.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type %s", ct)
+	}
+
+	infos := []Info{}
+	best := BestMove{}
+
+	event := ""
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: ") && event == EVENT_INFO:
+			info := Info{}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &info); err != nil {
+				t.Fatal(err)
+			}
+			infos = append(infos, info)
+		case strings.HasPrefix(line, "data: ") && event == EVENT_BESTMOVE:
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &best); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if len(infos) == 0 || infos[len(infos)-1].Depth != 4 || infos[len(infos)-1].MultiPV != 2 {
+		t.Errorf("expected the lines up to depth 4 with 2 pvs, got %+v", infos)
+	}
+	if best.Move == nil || best.Move.SAN != "Ra8#" || best.Score.Mate == nil || *best.Score.Mate != 1 {
+		t.Errorf("expected Ra8# mating in 1, got %+v", best)
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/easychessanimations/gochess/butils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// types
+
+// Options controls a server
+type Options struct {
+	AllowOrigin   string        // Access-Control-Allow-Origin header of the responses, none if empty
+	MaxPerftDepth int           // deepest perft allowed, no limit if 0
+	MaxMoveTime   time.Duration // longest analysis, also of analyses without a limit, no limit if 0
+	MaxAnalyses   int           // analyses running at the same time, further ones wait for a free slot
+	Threads       int           // search threads of an analysis
+}
+
+// Server serves the json api of the engine
+type Server struct {
+	options  Options
+	mux      *http.ServeMux
+	analyses chan struct{} // a token per running analysis
+}
+
+// Request is a position with the parameters of an api call, read from the query string or a json body
+// the position is fen with moves played from it, as in the uci position command
+type Request struct {
+	Fen      string   `json:"fen"`      // start position of the variant if empty
+	Variant  string   `json:"variant"`  // standard if empty
+	Chess960 bool     `json:"chess960"` // castling is written king takes rook
+	Moves    []string `json:"moves"`    // in san or uci, the query string may separate them with spaces or commas
+	Depth    int      `json:"depth"`    // of perft or analysis
+	MoveTime int      `json:"movetime"` // of analysis in milliseconds
+	MultiPV  int      `json:"multipv"`  // lines of analysis
+	Divide   bool     `json:"divide"`   // count perft per root move
+}
+
+// Move is a move in both notations
+type Move struct {
+	UCI string `json:"uci"`
+	SAN string `json:"san"`
+}
+
+// PositionResponse is a position with its legal moves, the answer of moves and play
+type PositionResponse struct {
+	Fen     string `json:"fen"`
+	Variant string `json:"variant"`
+	Turn    string `json:"turn"` // white or black
+	Check   bool   `json:"check"`
+	Played  []Move `json:"played"` // moves of the request
+	Moves   []Move `json:"moves"`  // legal moves, sorted by san
+}
+
+// DivideEntry is the perft count below a root move
+type DivideEntry struct {
+	Move  string `json:"move"`
+	Nodes uint64 `json:"nodes"`
+}
+
+// PerftResponse is the answer of perft
+type PerftResponse struct {
+	Fen    string        `json:"fen"`
+	Depth  int           `json:"depth"`
+	Nodes  uint64        `json:"nodes"`
+	Time   int64         `json:"time"` // milliseconds
+	Divide []DivideEntry `json:"divide,omitempty"`
+}
+
+// Value is an evaluation term in centipawns and in the internal units of the engine
+type Value struct {
+	CP  int32 `json:"cp"`
+	Nat int32 `json:"nat"`
+}
+
+// Accum is the middle game and end game value of an evaluation
+type Accum struct {
+	M Value `json:"m"`
+	E Value `json:"e"`
+}
+
+// EvalResponse is the static evaluation of a position, as shown by the interactive mode of the uci engine
+type EvalResponse struct {
+	Fen   string `json:"fen"`
+	White Accum  `json:"white"`
+	Black Accum  `json:"black"`
+	All   Accum  `json:"all"`   // white minus black
+	Phase int32  `json:"phase"` // of 256, 0 is the opening and 256 the end game
+	Score int32  `json:"score"` // centipawns from the point of view of the side to move
+}
+
+// Score is a search score, Mate is set instead of CP when a mate was found
+type Score struct {
+	CP   *int32 `json:"cp,omitempty"`
+	Mate *int32 `json:"mate,omitempty"` // moves to mate, negative if the side to move is mated
+}
+
+// Info is a line of an analysis, sent as an info event
+type Info struct {
+	Depth    int32  `json:"depth"`
+	SelDepth int32  `json:"seldepth"`
+	MultiPV  int    `json:"multipv"`
+	Score    Score  `json:"score"`
+	Nodes    uint64 `json:"nodes"`
+	Time     int64  `json:"time"` // milliseconds
+	NPS      uint64 `json:"nps"`
+	PV       []Move `json:"pv"`
+}
+
+// BestMove is the result of an analysis, sent as the last event
+// Move is nil if the game is over
+type BestMove struct {
+	Move   *Move `json:"move"`
+	Ponder *Move `json:"ponder,omitempty"`
+	Score  Score `json:"score"`
+}
+
+// errorResponse is the answer of a failed call
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// streamLogger sends the lines of an analysis as server sent events
+type streamLogger struct {
+	w     http.ResponseWriter
+	pos   *butils.Position // the analysed position, to write the lines in san
+	start time.Time
+	score Score // of the best line of the last depth
+}
+
+/////////////////////////////////////////////////////////////////////
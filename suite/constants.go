@@ -0,0 +1,11 @@
+package suite
+
+/////////////////////////////////////////////////////////////////////
+// constants
+
+// outcomes of a position
+const STATUS_UNTESTED = 0 // the record has no bm, am or perft operation to check
+const STATUS_SOLVED = 1
+const STATUS_FAILED = 2
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,305 @@
+package suite
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/epd"
+	"github.com/easychessanimations/gochess/match"
+	"github.com/easychessanimations/gochess/perft"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// String returns the summary as one line
+func (s Summary) String() string {
+	tested := s.Solved + s.Failed
+
+	percent := 0.0
+	if tested > 0 {
+		percent = 100 * float64(s.Solved) / float64(tested)
+	}
+
+	return fmt.Sprintf("solved %d of %d (%.1f%%), failed %d, untested %d, time %.2fs", s.Solved, tested, percent, s.Failed, s.Untested, s.Elapsed.Seconds())
+}
+
+// Expected returns what the position is checked against, as in bm Qd1+ or perft 1-3
+func (r Result) Expected() string {
+	parts := []string{}
+
+	for _, opcode := range []string{epd.OPCODE_BEST_MOVE, epd.OPCODE_AVOID_MOVE, epd.OPCODE_PREDICTED_MOVE} {
+		if moves := r.Record.Get(opcode); len(moves) > 0 {
+			parts = append(parts, opcode+" "+strings.Join(moves, " "))
+		}
+	}
+
+	if counts := r.Record.Perft(); len(counts) > 0 {
+		parts = append(parts, fmt.Sprintf("perft 1-%d", len(counts)))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Score returns the score of the search, as in +0.35 or -M3, empty if the position was not searched
+func (r Result) Score() string {
+	if !r.Searched {
+		return ""
+	}
+
+	if r.Info.Mate > 0 {
+		return fmt.Sprintf("+M%d", r.Info.Mate)
+	} else if r.Info.Mate < 0 {
+		return fmt.Sprintf("-M%d", -r.Info.Mate)
+	}
+
+	return fmt.Sprintf("%+.2f", float64(r.Info.Score)/100)
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// Run searches the records of a suite with player and checks their bm, am and perft operations
+// the search is bounded by options.Limits, the depth defaults to the acd of the record
+// an error of the player stops the run, a record that cannot be set up fails
+func Run(player match.Player, records []epd.Record, options Options) ([]Result, error) {
+	if err := player.NewGame(options.Variant, options.Chess960); err != nil {
+		return nil, err
+	}
+
+	results := []Result{}
+
+	for _, r := range records {
+		res, err := runRecord(player, r, options)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, res)
+
+		if options.Log != nil {
+			options.Log(res)
+		}
+	}
+
+	return results, nil
+}
+
+// Summarize counts the outcomes of results
+func Summarize(results []Result) Summary {
+	s := Summary{}
+
+	for _, res := range results {
+		switch res.Status {
+		case STATUS_SOLVED:
+			s.Solved++
+		case STATUS_FAILED:
+			s.Failed++
+		default:
+			s.Untested++
+		}
+		s.Elapsed += res.Elapsed
+	}
+
+	return s
+}
+
+// WriteTable writes results as a table with a row per position, followed by the summary
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "#\tid\texpected\tmove\tscore\tce\tdepth\ttime\tresult")
+
+	for i, res := range results {
+		ce := ""
+		if eval, ok := res.Record.Eval(); ok {
+			ce = fmt.Sprintf("%+.2f", float64(eval)/100)
+		}
+
+		depth := ""
+		if res.Searched {
+			depth = strconv.Itoa(res.Info.Depth)
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%.2fs\t%s\n",
+			i+1, res.Record.Id(), res.Expected(), res.Move, res.Score(), ce, depth, res.Elapsed.Seconds(), statusString(res))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintln(w, Summarize(results))
+
+	return err
+}
+
+// runRecord searches and counts the position of r
+func runRecord(player match.Player, r epd.Record, options Options) (Result, error) {
+	start := time.Now()
+
+	res := Result{Record: r}
+	failures := []string{}
+	tested := false
+
+	pos, err := butils.PositionFromFENAndVariant(r.Fen, options.Variant)
+	if err != nil {
+		res.Status, res.Reason = STATUS_FAILED, err.Error()
+		return res, nil
+	}
+	pos.Chess960 = options.Chess960
+
+	if counts := r.Perft(); len(counts) > 0 && options.PerftDepth > 0 {
+		gen, err := perft.NewGenerator(perft.BACKEND_BITBOARD, r.Fen, options.Variant, options.Chess960)
+		if err != nil {
+			res.Status, res.Reason = STATUS_FAILED, err.Error()
+			return res, nil
+		}
+
+		for depth, expected := range counts {
+			if expected == 0 || depth+1 > options.PerftDepth {
+				continue
+			}
+
+			tested = true
+			if nodes := perft.Count(gen, depth+1, nil); nodes != expected {
+				failures = append(failures, fmt.Sprintf("perft %d gave %d", depth+1, nodes))
+			}
+		}
+	}
+
+	if isSearched(r) {
+		bm, err := parseMoves(pos, r.BestMoves())
+		if err != nil {
+			res.Status, res.Reason = STATUS_FAILED, err.Error()
+			return res, nil
+		}
+		am, err := parseMoves(pos, r.AvoidMoves())
+		if err != nil {
+			res.Status, res.Reason = STATUS_FAILED, err.Error()
+			return res, nil
+		}
+
+		limits := options.Limits
+		if limits.Depth == 0 {
+			if acd, ok := r.Depth(); ok {
+				limits.Depth = acd
+			}
+		}
+		if limits == (match.Limits{}) {
+			return res, fmt.Errorf("no search limit for %s", r.Fen)
+		}
+
+		info, err := player.Move(pos, limits)
+		if err != nil {
+			return res, err
+		}
+
+		res.Searched, res.Info, res.Move = true, info, pos.MoveToSan(info.Move)
+
+		if bm != nil {
+			tested = true
+			if !containsMove(bm, info.Move) {
+				failures = append(failures, "not a best move")
+			}
+		}
+		if am != nil {
+			tested = true
+			if containsMove(am, info.Move) {
+				failures = append(failures, "avoided move")
+			}
+		}
+	}
+
+	res.Elapsed = time.Since(start)
+
+	switch {
+	case len(failures) > 0:
+		res.Status, res.Reason = STATUS_FAILED, strings.Join(failures, ", ")
+	case tested:
+		res.Status = STATUS_SOLVED
+	default:
+		res.Status = STATUS_UNTESTED
+	}
+
+	return res, nil
+}
+
+// isSearched tells whether the position of r is searched, only records with nothing but perft counts are not
+func isSearched(r epd.Record) bool {
+	if len(r.Perft()) == 0 {
+		return true
+	}
+
+	for _, opcode := range []string{epd.OPCODE_BEST_MOVE, epd.OPCODE_AVOID_MOVE, epd.OPCODE_PREDICTED_MOVE, epd.OPCODE_EVAL, epd.OPCODE_DEPTH} {
+		if r.Has(opcode) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseMoves parses moves of pos written in san or uci, nil if there are none
+func parseMoves(pos *butils.Position, moves []string) ([]butils.Move, error) {
+	if len(moves) == 0 {
+		return nil, nil
+	}
+
+	parsed := []butils.Move{}
+
+	for _, s := range moves {
+		move, err := pos.SanToMove(s)
+		if err != nil {
+			for _, m := range pos.LegalMoves() {
+				if pos.MoveToUCI(m) == strings.ToLower(s) {
+					move, err = m, nil
+				}
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid move %s: %w", s, err)
+		}
+
+		parsed = append(parsed, move)
+	}
+
+	return parsed, nil
+}
+
+// containsMove tells whether move is one of moves
+func containsMove(moves []butils.Move, move butils.Move) bool {
+	for _, m := range moves {
+		if m == move {
+			return true
+		}
+	}
+
+	return false
+}
+
+// statusString returns the result column of res
+func statusString(res Result) string {
+	switch res.Status {
+	case STATUS_SOLVED:
+		return "ok"
+	case STATUS_FAILED:
+		return "FAIL " + res.Reason
+	}
+
+	return "-"
+}
+
+/////////////////////////////////////////////////////////////////////
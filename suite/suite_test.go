@@ -0,0 +1,99 @@
+package suite
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/easychessanimations/gochess/epd"
+	"github.com/easychessanimations/gochess/match"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+func TestRun(t *testing.T) {
+	f, err := os.Open("../epd/suites/matein1.epd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := epd.ReadEPD(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	records = records[:5]
+
+	for _, line := range []string{
+		// the mate written in uci and avoided
+		`1k1r4/2p2ppp/8/8/Qb6/2R1Pn2/PP2KPPP/3r4 b - - am f3g1; id "avoid"`,
+		// counted, not searched
+		`rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - ;D1 20 ;D2 401; id "perft"`,
+		// searched at its acd, nothing to check
+		`rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - acd 2; id "analysis"`,
+	} {
+		r, err := epd.ParseEPD(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+
+	player, err := match.NewPlayer(match.PlayerConfig{Command: match.INTERNAL_ENGINE})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer player.Close()
+
+	logged := 0
+	options := Options{
+		Variant:    utils.VARIANT_STANDARD,
+		Limits:     match.Limits{Depth: 3},
+		PerftDepth: 2,
+		Log:        func(Result) { logged++ },
+	}
+
+	results, err := Run(player, records, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(records) || logged != len(records) {
+		t.Fatalf("expected %d results, got %d logged %d", len(records), len(results), logged)
+	}
+
+	for _, res := range results[:5] {
+		if res.Status != STATUS_SOLVED || !strings.HasSuffix(res.Move, "#") || res.Score() != "+M1" {
+			t.Errorf("%s: expected the mate %v, got %s %s %s", res.Record.Id(), res.Record.BestMoves(), res.Move, res.Score(), res.Reason)
+		}
+	}
+
+	for id, expected := range map[string]int{"avoid": STATUS_FAILED, "perft": STATUS_FAILED, "analysis": STATUS_UNTESTED} {
+		for _, res := range results {
+			if res.Record.Id() == id && res.Status != expected {
+				t.Errorf("%s: expected status %d, got %d %s", id, expected, res.Status, res.Reason)
+			}
+		}
+	}
+	if res := results[6]; res.Searched || res.Reason != "perft 2 gave 400" {
+		t.Errorf("expected only the perft count to fail, got %+v", res)
+	}
+	if s := Summarize(results); s.Solved != 5 || s.Failed != 2 || s.Untested != 1 {
+		t.Errorf("unexpected summary %s", s)
+	}
+
+	buff := bytes.Buffer{}
+	if err := WriteTable(&buff, results); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buff.String()), "\n"); len(lines) != len(records)+2 || !strings.HasPrefix(lines[len(lines)-1], "solved 5 of 7") {
+		t.Errorf("unexpected table\n%s", buff.String())
+	}
+
+	// without limits the depth is the acd, a search needs a limit
+	options.Limits = match.Limits{}
+	if results, err := Run(player, records[7:], options); err != nil || !results[0].Searched || results[0].Info.Depth != 2 {
+		t.Errorf("expected a search at the acd depth, got %+v %v", results, err)
+	}
+	if _, err := Run(player, records[:1], options); err == nil {
+		t.Errorf("expected an error without search limits")
+	}
+}
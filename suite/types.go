@@ -0,0 +1,47 @@
+package suite
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"time"
+
+	"github.com/easychessanimations/gochess/epd"
+	"github.com/easychessanimations/gochess/match"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// types
+
+// Options controls a suite run
+type Options struct {
+	Variant    utils.VariantKey
+	Chess960   bool
+	Limits     match.Limits // search of each position, Depth defaults to the acd of the record
+	PerftDepth int          // deepest perft count checked, perft operations are skipped if 0
+	Log        func(Result) // called after each position, nil for none
+}
+
+// Result is the outcome of a position of a suite
+type Result struct {
+	Record   epd.Record
+	Searched bool           // the position was searched, it has an operation other than perft
+	Move     string         // the move found in san
+	Info     match.MoveInfo // the search result of the move
+	Elapsed  time.Duration  // time of the search and the perft counts
+	Status   int            // STATUS_SOLVED, STATUS_FAILED or STATUS_UNTESTED
+	Reason   string         // why the position failed
+}
+
+// Summary counts the outcomes of a suite run
+type Summary struct {
+	Solved   int
+	Failed   int
+	Untested int
+	Elapsed  time.Duration
+}
+
+/////////////////////////////////////////////////////////////////////
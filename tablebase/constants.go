@@ -0,0 +1,64 @@
+package tablebase
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"github.com/easychessanimations/gochess/butils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// constants
+
+// tables have at least MIN_PIECES and at most MAX_PIECES pieces, kings included
+const MIN_PIECES = 3
+const MAX_PIECES = 4
+
+// outcome of a position for the side to move
+const WDL_LOSS = -1
+const WDL_DRAW = 0
+const WDL_WIN = 1
+
+// values of the dtm table, one byte per position
+// a value v >= DTM_OFFSET is a mate in v-DTM_OFFSET plies, won for the side to move if odd, lost if even
+// DTM_DRAW also marks the positions not yet resolved during generation
+const DTM_DRAW = 0
+const DTM_INVALID = 1
+const DTM_OFFSET = 2
+const MAX_DTM = 255 - DTM_OFFSET
+
+// values of the wdl table, two bits per position
+const PACKED_DRAW = 0
+const PACKED_LOSS = 1
+const PACKED_WIN = 2
+const PACKED_INVALID = 3
+
+const FILE_MAGIC = "GCTB"
+const FORMAT_DTM = 'D'
+const FORMAT_WDL = 'W'
+const DTM_EXTENSION = ".dtm"
+const WDL_EXTENSION = ".wdl"
+
+// a lancer slot stores the square and the direction of the lancer
+const NUM_SQUARES = 64
+const LANCER_SLOT_SIZE = NUM_SQUARES * butils.NUM_LANCER_DIRECTIONS
+
+// bits used by the count of one piece kind in a material signature
+const SIGNATURE_BITS = 3
+
+// MATERIAL_ORDER is the order of the pieces of a side in a material string, the king comes first
+var MATERIAL_ORDER = []butils.Figure{
+	butils.King,
+	butils.Queen,
+	butils.Rook,
+	butils.Bishop,
+	butils.Knight,
+	butils.Lancer,
+	butils.Sentry,
+	butils.Jailer,
+	butils.Pawn,
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,229 @@
+package tablebase
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Write writes the table in format FORMAT_DTM or FORMAT_WDL
+//
+// the file starts with FILE_MAGIC, the format, the variant and the material, each
+// string preceded by its length in a byte, and the number of positions in eight
+// bytes, followed by the gzipped values
+func (t *Table) Write(w io.Writer, format byte) error {
+	if t.dtm == nil {
+		return fmt.Errorf("table %s has no dtm values", t.Material)
+	}
+
+	variant := utils.VariantKeyToVariantKeyString(t.Variant)
+	material := t.Material.String()
+
+	header := []byte(FILE_MAGIC)
+	header = append(header, format, byte(len(variant)))
+	header = append(header, variant...)
+	header = append(header, byte(len(material)))
+	header = append(header, material...)
+	header = append(header, make([]byte, 8)...)
+	binary.LittleEndian.PutUint64(header[len(header)-8:], uint64(t.size))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	values := t.dtm
+	if format == FORMAT_WDL {
+		values = t.packWDL()
+	}
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(values); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// Save writes the dtm and wdl files of the table to dir
+func (t *Table) Save(dir string) error {
+	for _, format := range []byte{FORMAT_DTM, FORMAT_WDL} {
+		f, err := os.Create(filepath.Join(dir, t.fileName(format)))
+		if err != nil {
+			return err
+		}
+
+		if err := t.Write(f, format); err != nil {
+			f.Close()
+			return err
+		}
+
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fileName returns the name of the file of the table in format
+func (t *Table) fileName(format byte) string {
+	name := utils.VariantKeyToVariantKeyString(t.Variant) + "_" + t.Material.String()
+	if format == FORMAT_WDL {
+		return name + WDL_EXTENSION
+	}
+	return name + DTM_EXTENSION
+}
+
+// packWDL returns the outcomes of the positions, four per byte
+func (t *Table) packWDL() []byte {
+	packed := make([]byte, (t.size+3)/4)
+	for idx := 0; idx < t.size; idx++ {
+		code := byte(PACKED_INVALID)
+		if res, ok := t.result(idx); ok {
+			switch res.WDL {
+			case WDL_LOSS:
+				code = PACKED_LOSS
+			case WDL_WIN:
+				code = PACKED_WIN
+			default:
+				code = PACKED_DRAW
+			}
+		}
+		packed[idx/4] |= code << uint(2*(idx%4))
+	}
+	return packed
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// Read reads a table written by Write
+func Read(r io.Reader) (*Table, error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(FILE_MAGIC)+1)
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, err
+	}
+	if string(magic[:len(FILE_MAGIC)]) != FILE_MAGIC {
+		return nil, fmt.Errorf("not a table file")
+	}
+	format := magic[len(FILE_MAGIC)]
+	if format != FORMAT_DTM && format != FORMAT_WDL {
+		return nil, fmt.Errorf("unknown table format %c", format)
+	}
+
+	variant, err := readString(br)
+	if err != nil {
+		return nil, err
+	}
+	material, err := readString(br)
+	if err != nil {
+		return nil, err
+	}
+	var size uint64
+	if err := binary.Read(br, binary.LittleEndian, &size); err != nil {
+		return nil, err
+	}
+
+	m, err := ParseMaterial(material)
+	if err != nil {
+		return nil, err
+	}
+	t := newTable(utils.VariantKeyStringToVariantKey(variant), m)
+	if uint64(t.size) != size {
+		return nil, fmt.Errorf("table %s has %d positions, expected %d", material, size, t.size)
+	}
+
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	values, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == FORMAT_DTM && len(values) == t.size {
+		t.dtm = values
+	} else if format == FORMAT_WDL && len(values) == (t.size+3)/4 {
+		t.wdl = values
+	} else {
+		return nil, fmt.Errorf("table %s has %d bytes of values", material, len(values))
+	}
+
+	return t, nil
+}
+
+// Open loads the tables of all variants found in dir
+// the dtm file of a table is preferred over its wdl file
+func Open(dir string) (*Tablebase, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	tb := NewTablebase()
+
+	for _, ext := range []string{DTM_EXTENSION, WDL_EXTENSION} {
+		for _, file := range files {
+			if file.IsDir() || !strings.HasSuffix(file.Name(), ext) {
+				continue
+			}
+
+			t, err := readFile(filepath.Join(dir, file.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", file.Name(), err)
+			}
+
+			if old, _ := tb.lookup(t.Variant, t.signature); old == nil {
+				tb.Add(t)
+			}
+		}
+	}
+
+	return tb, nil
+}
+
+// readFile reads the table file at path
+func readFile(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(f)
+}
+
+// readString reads a string preceded by its length in a byte
+func readString(r *bufio.Reader) (string, error) {
+	n, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+/////////////////////////////////////////////////////////////////////
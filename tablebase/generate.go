@@ -0,0 +1,465 @@
+package tablebase
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+	"time"
+
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Generate generates the table of material m and the tables it depends on by retrograde analysis
+// tables already in tb are not generated again, new tables are added to tb
+// progress is written to log if it is not nil
+//
+// en passant captures and the fifty move rule are not taken into account
+func (tb *Tablebase) Generate(variant utils.VariantKey, m Material, log io.Writer) (*Table, error) {
+	vd, err := butils.GetVariantDescriptor(variant)
+	if err != nil {
+		return nil, err
+	}
+	if vd.Atomic {
+		return nil, fmt.Errorf("tables for %s are not supported", utils.VariantKeyToVariantKeyString(variant))
+	}
+	for _, color := range []butils.Color{butils.White, butils.Black} {
+		for _, fig := range m[color] {
+			if !vd.HasFigure(fig) {
+				return nil, fmt.Errorf("figure %s not allowed in %s", fig.Symbol(), utils.VariantKeyToVariantKeyString(variant))
+			}
+		}
+	}
+
+	m, _ = m.Canonical()
+	if t, _ := tb.lookup(variant, m.signature()); t != nil && t.dtm != nil {
+		return t, nil
+	}
+
+	g := &generator{
+		tb:    tb,
+		table: newTable(variant, m),
+		pos:   newEmptyPosition(variant),
+		log:   log,
+	}
+	if err := g.run(); err != nil {
+		return nil, err
+	}
+
+	tb.Add(g.table)
+
+	return g.table, nil
+}
+
+// Verify checks that the value of every position of t agrees with the values of its moves
+// the tables t depends on must be in tb
+func (tb *Tablebase) Verify(t *Table) error {
+	if t.dtm == nil {
+		return fmt.Errorf("table %s has no dtm values", t.Material)
+	}
+
+	g := &generator{
+		tb:    tb,
+		table: t,
+		pos:   newEmptyPosition(t.Variant),
+	}
+
+	for idx := 0; idx < t.size; idx++ {
+		if t.dtm[idx] == DTM_INVALID {
+			continue
+		}
+
+		p, _ := t.decode(idx)
+		g.setup(&p)
+
+		if v := g.value(); g.err != nil {
+			return g.err
+		} else if v != t.dtm[idx] {
+			return fmt.Errorf("%s has value %d, its moves give %d", g.pos, t.dtm[idx], v)
+		}
+	}
+
+	return nil
+}
+
+// run computes the values of all positions of the table
+func (g *generator) run() error {
+	t := g.table
+	start := time.Now()
+
+	t.dtm = make([]uint8, t.size)
+	g.done = make([]uint64, (t.size+63)/64)
+	g.next = make([]uint64, len(g.done))
+	g.buckets = map[int][]int{}
+
+	// evaluate every position from the positions its moves lead to, this finds the mates
+	for idx := 0; idx < t.size && g.err == nil; idx++ {
+		p, ok := t.decode(idx)
+		if ok {
+			g.setup(&p)
+			ok = !g.pos.IsChecked(g.pos.Them())
+		}
+		if !ok {
+			t.dtm[idx] = DTM_INVALID
+			setBit(g.done, idx)
+			continue
+		}
+		g.evaluate(idx, 0)
+	}
+
+	// resolve the positions won or lost in level plies
+	for level := 1; g.err == nil; level++ {
+		current := g.next
+		pending := g.buckets[level]
+		delete(g.buckets, level)
+
+		if isEmpty(current) && len(pending) == 0 && len(g.buckets) == 0 {
+			break
+		}
+		if level > MAX_DTM {
+			return fmt.Errorf("%s has mates longer than %d plies", t.Material, MAX_DTM)
+		}
+
+		g.next = make([]uint64, len(g.done))
+
+		for i, word := range current {
+			for ; word != 0 && g.err == nil; word &= word - 1 {
+				g.reevaluate(i*64+bits.TrailingZeros64(word), level)
+			}
+		}
+		for _, idx := range pending {
+			g.reevaluate(idx, level)
+		}
+	}
+
+	if g.err != nil {
+		return g.err
+	}
+
+	if g.log != nil {
+		stats := t.Stats()
+		fmt.Fprintf(g.log, "%s %s: %d wins %d draws %d losses, longest mate %d plies %s, %v\n",
+			utils.VariantKeyToVariantKeyString(t.Variant), t.Material,
+			stats.Wins, stats.Draws, stats.Losses, stats.MaxDTM, stats.MaxDTMFen,
+			time.Since(start).Round(time.Millisecond))
+	}
+
+	return nil
+}
+
+// setup sets up p on the position of the generator
+func (g *generator) setup(p *placement) {
+	for _, sq := range g.occupied {
+		g.pos.Remove(sq, g.pos.Get(sq))
+	}
+
+	g.table.put(g.pos, p)
+
+	g.occupied = append(g.occupied[:0], p.squares[:len(g.table.slots)]...)
+}
+
+// reevaluate evaluates position idx again at level if it is not resolved yet
+func (g *generator) reevaluate(idx int, level int) {
+	if hasBit(g.done, idx) {
+		return
+	}
+
+	p, _ := g.table.decode(idx)
+	g.setup(&p)
+	g.evaluate(idx, level)
+}
+
+// evaluate determines position idx, which is set up on the board, from the values of its moves
+// the position is resolved if it is won or lost in level plies, or scheduled for a later level
+func (g *generator) evaluate(idx int, level int) {
+	if v := g.value(); v >= DTM_OFFSET {
+		g.schedule(idx, int(v-DTM_OFFSET), level)
+	}
+}
+
+// value returns the value of the position set up on the board computed from the values of its moves
+// returns DTM_DRAW if the position is neither known to be won nor lost
+func (g *generator) value() uint8 {
+	pos := g.pos
+	us := pos.Us()
+
+	bestWin, longestLoss := MAX_DTM+1, 0
+	lost, hasMoves := true, false
+
+	g.moves = g.moves[:0]
+	pos.GenerateMoves(butils.Violent|butils.Quiet, &g.moves)
+
+	for _, move := range g.moves {
+		pos.DoMove(move)
+		if pos.IsChecked(us) {
+			pos.UndoMove()
+			continue
+		}
+		hasMoves = true
+		v := g.childValue()
+		pos.UndoMove()
+
+		switch {
+		case g.err != nil:
+			return DTM_INVALID
+		case v == DTM_DRAW:
+			lost = false
+		case v == DTM_INVALID:
+			g.err = fmt.Errorf("%s %s leads to an invalid position", pos, pos.MoveToUCI(move))
+			return DTM_INVALID
+		case (v-DTM_OFFSET)%2 == 0:
+			// the opponent is mated
+			if dtm := int(v-DTM_OFFSET) + 1; dtm < bestWin {
+				bestWin = dtm
+			}
+		default:
+			if dtm := int(v-DTM_OFFSET) + 1; dtm > longestLoss {
+				longestLoss = dtm
+			}
+		}
+	}
+
+	dtm := -1
+	switch {
+	case !hasMoves && pos.IsChecked(us):
+		dtm = 0
+	case !hasMoves:
+		// stalemate
+	case bestWin <= MAX_DTM:
+		dtm = bestWin
+	case lost:
+		dtm = longestLoss
+	}
+
+	if dtm < 0 {
+		return DTM_DRAW
+	}
+	if dtm > MAX_DTM {
+		g.err = fmt.Errorf("%s has mates longer than %d plies", g.table.Material, MAX_DTM)
+		return DTM_INVALID
+	}
+	return uint8(dtm + DTM_OFFSET)
+}
+
+// childValue returns the value of the position after a move
+// captures and promotions lead to other tables, which are generated if needed
+func (g *generator) childValue() uint8 {
+	t, pos := g.table, g.pos
+
+	if pos.ByPiece(pos.Us(), butils.King) == 0 {
+		// the king was taken by its own piece pushed by a sentry
+		return DTM_OFFSET
+	}
+
+	sig := positionSignature(pos)
+	flip := false
+	if sig != t.signature {
+		if sig == signatureUnit(butils.White, butils.King)+signatureUnit(butils.Black, butils.King) {
+			// bare kings
+			return DTM_DRAW
+		}
+
+		t, flip = g.tb.lookup(t.Variant, sig)
+		if t == nil || t.dtm == nil {
+			var err error
+			if t, err = g.tb.Generate(g.table.Variant, MaterialOf(pos), g.log); err != nil {
+				g.err = err
+				return DTM_INVALID
+			}
+			_, flip = MaterialOf(pos).Canonical()
+		}
+	}
+
+	p, ok := t.placementOf(pos, flip)
+	idx := 0
+	if ok {
+		idx, ok = t.encode(&p)
+	}
+	if !ok {
+		g.err = fmt.Errorf("cannot index %s in %s", pos, t.Material)
+		return DTM_INVALID
+	}
+
+	return t.dtm[idx]
+}
+
+// schedule resolves position idx with a mate in dtm plies now or at level dtm
+func (g *generator) schedule(idx int, dtm int, level int) {
+	if dtm <= level {
+		g.resolve(idx, dtm)
+	} else {
+		g.buckets[dtm] = append(g.buckets[dtm], idx)
+	}
+}
+
+// resolve stores the value of position idx and marks its predecessors for the next level
+func (g *generator) resolve(idx int, dtm int) {
+	g.table.dtm[idx] = uint8(dtm + DTM_OFFSET)
+	setBit(g.done, idx)
+	g.predecessors(idx)
+}
+
+// predecessors marks for evaluation at the next level the positions of the table
+// from which a move can lead to position idx
+//
+// the candidates are a superset of the actual predecessors: each piece of the side
+// that just moved is taken back along every line it could have used, and every
+// disabled move is tried, evaluate rejects the candidates that do not fit
+func (g *generator) predecessors(idx int) {
+	t := g.table
+	p, _ := t.decode(idx)
+	them := p.stm.Opposite()
+
+	occupied := butils.BbEmpty
+	for i := range t.slots {
+		occupied |= p.squares[i].Bitboard()
+	}
+
+	if p.pushed >= 0 {
+		// the last move was a sentry push, the sentry came to the square of the pushed piece
+		to, pushed := p.squares[p.sentry], p.squares[p.pushed]
+		from := butils.BishopMobility(to, occupied&^pushed.Bitboard()) & (^occupied | pushed.Bitboard())
+		for bb := from; bb != 0; {
+			q := p
+			q.stm = them
+			q.squares[p.sentry], q.squares[p.pushed] = bb.Pop(), to
+			g.markTurned(&q, p.pushed)
+		}
+		return
+	}
+
+	for k, s := range t.slots {
+		if s.color != them {
+			continue
+		}
+
+		to := p.squares[k]
+		var from butils.Bitboard
+		switch s.figure {
+		case butils.Pawn:
+			from = pawnOrigins(them, to, occupied)
+		case butils.Knight:
+			from = butils.KnightMobility(to)
+		case butils.King:
+			from = butils.KingMobility(to)
+		case butils.Bishop, butils.Sentry:
+			from = butils.BishopMobility(to, occupied)
+		case butils.Rook, butils.Jailer:
+			from = butils.RookMobility(to, occupied)
+		case butils.Queen:
+			from = butils.QueenMobility(to, occupied)
+		case butils.Lancer:
+			// lancers jump over their own pieces and may turn
+			from = butils.SuperQueenMobility(to)
+		}
+
+		for bb := from &^ occupied; bb != 0; {
+			q := p
+			q.stm = them
+			q.squares[k] = bb.Pop()
+			g.markTurned(&q, k)
+		}
+	}
+
+	if g.pos.Variant.KingPass && g.hasFigure(butils.Jailer) {
+		// a jailed king passed
+		q := p
+		q.stm = them
+		g.mark(&q)
+	}
+}
+
+// markTurned marks q with every direction of slot k if it is a lancer
+func (g *generator) markTurned(q *placement, k int) {
+	if g.table.slots[k].figure != butils.Lancer {
+		g.mark(q)
+		return
+	}
+	for dir := 0; dir < butils.NUM_LANCER_DIRECTIONS; dir++ {
+		q.dirs[k] = dir
+		g.mark(q)
+	}
+}
+
+// mark marks q with every disabled move for evaluation at the next level
+func (g *generator) mark(q *placement) {
+	t := g.table
+	for d := 0; d <= len(t.pairs[q.stm]); d++ {
+		c := *q
+		c.pushed, c.sentry = -1, -1
+		if d > 0 {
+			c.pushed, c.sentry = t.pairs[q.stm][d-1].pushed, t.pairs[q.stm][d-1].sentry
+		}
+		t.canonicalize(&c)
+		if !t.valid(&c) {
+			continue
+		}
+		if idx, ok := t.encode(&c); ok && !hasBit(g.done, idx) {
+			setBit(g.next, idx)
+		}
+	}
+}
+
+// hasFigure tells whether the table has a piece with figure fig
+func (g *generator) hasFigure(fig butils.Figure) bool {
+	for _, s := range g.table.slots {
+		if s.figure == fig {
+			return true
+		}
+	}
+	return false
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// pawnOrigins returns the squares from which a pawn of color can advance to to
+func pawnOrigins(color butils.Color, to butils.Square, occupied butils.Bitboard) butils.Bitboard {
+	origins := butils.BbEmpty
+	if color == butils.White {
+		if to.Rank() >= 2 {
+			origins |= (to - 8).Bitboard()
+			if to.Rank() == 3 && !occupied.Has(to-8) {
+				origins |= (to - 16).Bitboard()
+			}
+		}
+	} else if to.Rank() <= 5 {
+		origins |= (to + 8).Bitboard()
+		if to.Rank() == 4 && !occupied.Has(to+8) {
+			origins |= (to + 16).Bitboard()
+		}
+	}
+	return origins
+}
+
+// setBit sets bit i of set
+func setBit(set []uint64, i int) {
+	set[i/64] |= 1 << uint(i%64)
+}
+
+// hasBit tells whether bit i of set is set
+func hasBit(set []uint64, i int) bool {
+	return set[i/64]&(1<<uint(i%64)) != 0
+}
+
+// isEmpty tells whether no bit of set is set
+func isEmpty(set []uint64) bool {
+	for _, word := range set {
+		if word != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,309 @@
+package tablebase
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Size returns the number of positions of the table
+func (t *Table) Size() int {
+	return t.size
+}
+
+// slotSize returns the number of values of slot i
+func (t *Table) slotSize(i int) int {
+	if t.slots[i].figure == butils.Lancer {
+		return LANCER_SLOT_SIZE
+	}
+	return NUM_SQUARES
+}
+
+// encode returns the index of a canonical placement, false if its disabled move cannot be stored
+func (t *Table) encode(p *placement) (int, bool) {
+	d := 0
+	if p.pushed >= 0 {
+		d = -1
+		for i, pair := range t.pairs[p.stm] {
+			if pair.pushed == p.pushed && pair.sentry == p.sentry {
+				d = i + 1
+				break
+			}
+		}
+		if d < 0 {
+			return 0, false
+		}
+	}
+
+	idx := (colorIndex(p.stm)*t.numDisabled + d) * t.numPlacements
+	for i, s := range t.slots {
+		v := int(p.squares[i])
+		if s.figure == butils.Lancer {
+			v = v*butils.NUM_LANCER_DIRECTIONS + p.dirs[i]
+		}
+		idx += v * t.strides[i]
+	}
+
+	return idx, true
+}
+
+// decode returns the placement of index idx and whether it is a valid position of the table
+// a valid placement may still have the side not to move in check
+func (t *Table) decode(idx int) (placement, bool) {
+	p := placement{pushed: -1, sentry: -1}
+
+	rest := idx / t.numPlacements
+	d := rest % t.numDisabled
+
+	p.stm = butils.White
+	if rest/t.numDisabled == 1 {
+		p.stm = butils.Black
+	}
+
+	for i, s := range t.slots {
+		v := (idx % t.numPlacements) / t.strides[i] % t.slotSize(i)
+		if s.figure == butils.Lancer {
+			p.squares[i] = butils.Square(v / butils.NUM_LANCER_DIRECTIONS)
+			p.dirs[i] = v % butils.NUM_LANCER_DIRECTIONS
+		} else {
+			p.squares[i] = butils.Square(v)
+		}
+	}
+
+	if d > 0 {
+		if d > len(t.pairs[p.stm]) {
+			return p, false
+		}
+		pair := t.pairs[p.stm][d-1]
+		p.pushed, p.sentry = pair.pushed, pair.sentry
+	}
+
+	return p, t.valid(&p)
+}
+
+// valid tells whether p is a canonical placement that can be set up on a board
+func (t *Table) valid(p *placement) bool {
+	occupied := butils.BbEmpty
+	for i, s := range t.slots {
+		sq := p.squares[i]
+		if occupied.Has(sq) {
+			return false
+		}
+		occupied |= sq.Bitboard()
+
+		if s.figure == butils.Pawn && (sq.Rank() == 0 || sq.Rank() == 7) {
+			return false
+		}
+
+		// pieces of the same kind are stored in the order of their squares
+		if i > 0 && t.slots[i-1] == s && p.squares[i-1] > sq {
+			return false
+		}
+	}
+
+	if p.pushed >= 0 {
+		// the disabled move of a sliding piece must have a direction
+		switch t.slots[p.pushed].figure {
+		case butils.Pawn, butils.Knight, butils.King:
+		default:
+			if _, err := butils.NormalizedDelta(p.squares[p.pushed], p.squares[p.sentry]); err != nil {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// canonicalize orders the pieces of the same kind by square
+func (t *Table) canonicalize(p *placement) {
+	for i := 1; i < len(t.slots); i++ {
+		for j := i; j > 0 && t.slots[j-1] == t.slots[j] && p.squares[j-1] > p.squares[j]; j-- {
+			p.swap(j-1, j)
+		}
+	}
+}
+
+// placementOf returns the placement of pos, which must have the material of the table
+// if flip is true the colors of pos are swapped and the board mirrored vertically
+// returns false if the disabled move of pos cannot be stored
+func (t *Table) placementOf(pos *butils.Position, flip bool) (placement, bool) {
+	p := placement{pushed: -1, sentry: -1}
+
+	var used [butils.ColorArraySize][butils.FigureArraySize]int
+	for bb := pos.ByColor(butils.White) | pos.ByColor(butils.Black); bb != 0; {
+		sq := bb.Pop()
+		pi := pos.Get(sq)
+
+		color, fig, dir := pi.Color(), pi.BaseFigure(), 0
+		if fig == butils.Lancer {
+			dir = pi.LancerDirection()
+		}
+		if flip {
+			color, sq, dir = color.Opposite(), flipSquare(sq), flipDirection(dir)
+		}
+
+		k := t.first[color][orderIndex[fig]] + used[color][fig]
+		used[color][fig]++
+		p.squares[k], p.dirs[k] = sq, dir
+	}
+
+	p.stm = pos.Us()
+	if flip {
+		p.stm = p.stm.Opposite()
+	}
+
+	if from, to, ok := pos.DisabledMove(); ok {
+		if flip {
+			from, to = flipSquare(from), flipSquare(to)
+		}
+		p.pushed, p.sentry = t.slotAt(&p, from), t.slotAt(&p, to)
+		if p.pushed < 0 || p.sentry < 0 {
+			return p, false
+		}
+	}
+
+	t.canonicalize(&p)
+
+	return p, true
+}
+
+// put puts the pieces of p on pos and sets the side to move and the disabled move
+// pos must be empty and without castling rights and en passant square
+func (t *Table) put(pos *butils.Position, p *placement) {
+	for i, s := range t.slots {
+		pi := butils.ColorFigure(s.color, s.figure)
+		if s.figure == butils.Lancer {
+			pi = butils.MakeLancer(s.color, p.dirs[i])
+		}
+		pos.Put(p.squares[i], pi)
+	}
+
+	pos.SetSideToMove(p.stm)
+
+	if p.pushed >= 0 {
+		pos.SetDisabledMove(p.squares[p.pushed], p.squares[p.sentry])
+	} else {
+		pos.ClearDisabledMove()
+	}
+
+	pos.RecalculateState()
+}
+
+// fen returns the fen of position idx
+func (t *Table) fen(idx int) string {
+	p, _ := t.decode(idx)
+	pos := newEmptyPosition(t.Variant)
+	t.put(pos, &p)
+	return pos.String()
+}
+
+// swap swaps the pieces of slots i and j
+func (p *placement) swap(i, j int) {
+	p.squares[i], p.squares[j] = p.squares[j], p.squares[i]
+	p.dirs[i], p.dirs[j] = p.dirs[j], p.dirs[i]
+	for _, k := range []*int{&p.pushed, &p.sentry} {
+		if *k == i {
+			*k = j
+		} else if *k == j {
+			*k = i
+		}
+	}
+}
+
+// slotAt returns the slot of p on sq, -1 if none
+func (t *Table) slotAt(p *placement, sq butils.Square) int {
+	for i := range t.slots {
+		if p.squares[i] == sq {
+			return i
+		}
+	}
+	return -1
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// newTable returns a table of material m without values
+func newTable(variant utils.VariantKey, m Material) *Table {
+	t := &Table{
+		Variant:   variant,
+		Material:  m,
+		signature: m.signature(),
+	}
+
+	for _, color := range []butils.Color{butils.White, butils.Black} {
+		t.first[color] = make([]int, len(MATERIAL_ORDER))
+		for i := range t.first[color] {
+			t.first[color][i] = -1
+		}
+		for _, fig := range m[color] {
+			if t.first[color][orderIndex[fig]] < 0 {
+				t.first[color][orderIndex[fig]] = len(t.slots)
+			}
+			t.slots = append(t.slots, slot{color, fig})
+		}
+	}
+
+	t.strides = make([]int, len(t.slots))
+	t.numPlacements = 1
+	for i := len(t.slots) - 1; i >= 0; i-- {
+		t.strides[i] = t.numPlacements
+		t.numPlacements *= t.slotSize(i)
+	}
+
+	// a piece of the side to move may have been pushed by a sentry of the other side
+	t.numDisabled = 1
+	for _, color := range []butils.Color{butils.White, butils.Black} {
+		for i, pushed := range t.slots {
+			for j, sentry := range t.slots {
+				if pushed.color == color && sentry.color != color && sentry.figure == butils.Sentry {
+					t.pairs[color] = append(t.pairs[color], disabledPair{i, j})
+				}
+			}
+		}
+		if n := 1 + len(t.pairs[color]); n > t.numDisabled {
+			t.numDisabled = n
+		}
+	}
+
+	t.size = 2 * t.numDisabled * t.numPlacements
+
+	return t
+}
+
+// newEmptyPosition returns an empty board played by the rules of variant
+func newEmptyPosition(variant utils.VariantKey) *butils.Position {
+	pos := butils.NewPosition()
+	pos.SetVariant(variant)
+	return pos
+}
+
+// colorIndex returns 0 for white and 1 for black
+func colorIndex(color butils.Color) int {
+	if color == butils.White {
+		return 0
+	}
+	return 1
+}
+
+// flipSquare mirrors sq vertically
+func flipSquare(sq butils.Square) butils.Square {
+	return sq ^ 56
+}
+
+// flipDirection mirrors a lancer direction vertically
+func flipDirection(dir int) int {
+	return (butils.NUM_LANCER_DIRECTIONS + 4 - dir) % butils.NUM_LANCER_DIRECTIONS
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,198 @@
+package tablebase
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/easychessanimations/gochess/butils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global variables
+
+// orderIndex maps base figures to their index in MATERIAL_ORDER, -1 if not in tables
+var orderIndex [butils.FigureArraySize]int
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// String returns the material in the form KQvKR
+func (m Material) String() string {
+	s := ""
+	for _, color := range []butils.Color{butils.White, butils.Black} {
+		if color == butils.Black {
+			s += "v"
+		}
+		for _, fig := range m[color] {
+			s += strings.ToUpper(fig.Symbol())
+		}
+	}
+	return s
+}
+
+// NumPieces returns the number of pieces, kings included
+func (m Material) NumPieces() int {
+	return len(m[butils.White]) + len(m[butils.Black])
+}
+
+// Flip returns the material with the colors swapped
+func (m Material) Flip() Material {
+	var flipped Material
+	flipped[butils.White], flipped[butils.Black] = m[butils.Black], m[butils.White]
+	return flipped
+}
+
+// Canonical returns the orientation tables of m are stored in and whether it is flipped
+// the side with more pieces, or with the stronger pieces in MATERIAL_ORDER, plays white
+func (m Material) Canonical() (Material, bool) {
+	white, black := m[butils.White], m[butils.Black]
+	if len(white) != len(black) {
+		if len(white) < len(black) {
+			return m.Flip(), true
+		}
+		return m, false
+	}
+	for i := range white {
+		if wi, bi := orderIndex[white[i]], orderIndex[black[i]]; wi != bi {
+			if wi > bi {
+				return m.Flip(), true
+			}
+			return m, false
+		}
+	}
+	return m, false
+}
+
+// signature packs the counts of each figure of both colors
+func (m Material) signature() uint64 {
+	sig := uint64(0)
+	for _, color := range []butils.Color{butils.White, butils.Black} {
+		for _, fig := range m[color] {
+			sig += signatureUnit(color, fig)
+		}
+	}
+	return sig
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// ParseMaterial parses a material like KQvKR or KLvK, white pieces first
+// pieces are given by their upper case fen letters, lancers without direction
+func ParseMaterial(s string) (Material, error) {
+	var m Material
+
+	sides := strings.Split(s, "v")
+	if len(sides) != 2 {
+		return m, fmt.Errorf("material %s should have the form KQvK", s)
+	}
+
+	for i, color := range []butils.Color{butils.White, butils.Black} {
+		for _, letter := range sides[i] {
+			fig := butils.SymbolToFigure(strings.ToLower(string(letter)))
+			if fig == butils.NoFigure || orderIndex[fig] < 0 || string(letter) != strings.ToUpper(string(letter)) {
+				return m, fmt.Errorf("invalid piece %c in material %s", letter, s)
+			}
+			m[color] = append(m[color], fig)
+		}
+
+		kings := 0
+		for _, fig := range m[color] {
+			if fig == butils.King {
+				kings++
+			}
+		}
+		if kings != 1 {
+			return m, fmt.Errorf("material %s should have one king per side", s)
+		}
+
+		sortFigures(m[color])
+	}
+
+	if n := m.NumPieces(); n < MIN_PIECES || n > MAX_PIECES {
+		return m, fmt.Errorf("material %s has %d pieces, tables have %d to %d", s, n, MIN_PIECES, MAX_PIECES)
+	}
+
+	return m, nil
+}
+
+// MaterialOf returns the material of pos
+func MaterialOf(pos *butils.Position) Material {
+	var m Material
+	for _, color := range []butils.Color{butils.White, butils.Black} {
+		for _, fig := range MATERIAL_ORDER {
+			for bb := piecesOf(pos, color, fig); bb != 0; bb.Pop() {
+				m[color] = append(m[color], fig)
+			}
+		}
+	}
+	return m
+}
+
+// piecesOf returns the pieces of color with base figure fig
+func piecesOf(pos *butils.Position, color butils.Color, fig butils.Figure) butils.Bitboard {
+	if fig == butils.Lancer {
+		return butils.Lancers(pos, color)
+	}
+	return pos.ByPiece(color, fig)
+}
+
+// positionSignature returns the material signature of pos
+func positionSignature(pos *butils.Position) uint64 {
+	sig := uint64(0)
+	for bb := pos.ByColor(butils.White) | pos.ByColor(butils.Black); bb != 0; {
+		pi := pos.Get(bb.Pop())
+		sig += signatureUnit(pi.Color(), pi.BaseFigure())
+	}
+	return sig
+}
+
+// signatureUnit returns the signature of a single piece
+func signatureUnit(color butils.Color, fig butils.Figure) uint64 {
+	shift := SIGNATURE_BITS * orderIndex[fig]
+	if color == butils.Black {
+		shift += SIGNATURE_BITS * len(MATERIAL_ORDER)
+	}
+	return 1 << uint(shift)
+}
+
+// flipSignature swaps the colors of a signature
+func flipSignature(sig uint64) uint64 {
+	half := uint(SIGNATURE_BITS * len(MATERIAL_ORDER))
+	mask := uint64(1)<<half - 1
+	return sig>>half | (sig&mask)<<half
+}
+
+// sortFigures sorts figures in MATERIAL_ORDER
+func sortFigures(figures []butils.Figure) {
+	for i := 1; i < len(figures); i++ {
+		for j := i; j > 0 && orderIndex[figures[j]] < orderIndex[figures[j-1]]; j-- {
+			figures[j], figures[j-1] = figures[j-1], figures[j]
+		}
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// init
+
+func init() {
+	for fig := range orderIndex {
+		orderIndex[fig] = -1
+	}
+	for i, fig := range MATERIAL_ORDER {
+		orderIndex[fig] = i
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,209 @@
+package tablebase
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"sort"
+
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Add adds t to the tablebase, replacing the table of the same material
+func (tb *Tablebase) Add(t *Table) {
+	tb.tables[tableKey{t.Variant, t.signature}] = t
+}
+
+// Tables returns the tables of the tablebase ordered by variant and material
+func (tb *Tablebase) Tables() []*Table {
+	tables := []*Table{}
+	for _, t := range tb.tables {
+		tables = append(tables, t)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		if tables[i].Variant != tables[j].Variant {
+			return tables[i].Variant < tables[j].Variant
+		}
+		return tables[i].Material.String() < tables[j].Material.String()
+	})
+	return tables
+}
+
+// lookup returns the table of the material with signature sig and whether it is stored flipped, nil if none
+func (tb *Tablebase) lookup(variant utils.VariantKey, sig uint64) (*Table, bool) {
+	if t, ok := tb.tables[tableKey{variant, sig}]; ok {
+		return t, false
+	}
+	if t, ok := tb.tables[tableKey{variant, flipSignature(sig)}]; ok {
+		return t, true
+	}
+	return nil, false
+}
+
+// Probe returns the outcome of pos for the side to move, false if no table covers pos
+// positions with castling rights or an en passant square are not covered
+func (tb *Tablebase) Probe(pos *butils.Position) (Result, bool) {
+	all := pos.ByColor(butils.White) | pos.ByColor(butils.Black)
+	if n := all.Count(); n < MIN_PIECES || n > MAX_PIECES {
+		return Result{}, false
+	}
+	if pos.CastlingAbility() != butils.NoCastle || pos.EnpassantSquare() != butils.SquareA1 {
+		return Result{}, false
+	}
+
+	t, flip := tb.lookup(pos.Variant.Key, positionSignature(pos))
+	if t == nil {
+		return Result{}, false
+	}
+
+	p, ok := t.placementOf(pos, flip)
+	idx := 0
+	if ok {
+		idx, ok = t.encode(&p)
+	}
+	if !ok {
+		return Result{}, false
+	}
+
+	return t.result(idx)
+}
+
+// BestMove returns the move of pos with the best outcome and that outcome
+// wins are converted by the shortest mate, losses are delayed by the longest
+// if rootMoves is not nil only those moves are considered
+// returns false if pos or one of its moves is not covered by a dtm table
+func (tb *Tablebase) BestMove(pos *butils.Position, rootMoves []butils.Move) (butils.Move, Result, bool) {
+	if res, ok := tb.Probe(pos); !ok || !res.HasDTM {
+		return butils.NullMove, Result{}, false
+	}
+
+	if rootMoves == nil {
+		rootMoves = pos.LegalMoves()
+	}
+
+	bestMove, best, bestRank := butils.NullMove, Result{}, 0
+	for _, move := range rootMoves {
+		pos.DoMove(move)
+		res, ok := tb.childResult(pos)
+		pos.UndoMove()
+
+		if !ok {
+			return butils.NullMove, Result{}, false
+		}
+
+		res = Result{WDL: -res.WDL, DTM: res.DTM + 1, HasDTM: true}
+		if res.WDL == WDL_DRAW {
+			res.DTM = 0
+		}
+		if rank := res.rank(); bestMove == butils.NullMove || rank > bestRank {
+			bestMove, best, bestRank = move, res, rank
+		}
+	}
+
+	return bestMove, best, bestMove != butils.NullMove
+}
+
+// childResult returns the outcome of a position reached from a covered position
+// mates, taken kings and bare kings are recognized without tables
+func (tb *Tablebase) childResult(pos *butils.Position) (Result, bool) {
+	if pos.ByPiece(pos.Us(), butils.King) == 0 {
+		return Result{WDL: WDL_LOSS, HasDTM: true}, true
+	}
+
+	if !pos.HasLegalMoves() {
+		if pos.IsChecked(pos.Us()) {
+			return Result{WDL: WDL_LOSS, HasDTM: true}, true
+		}
+		return Result{WDL: WDL_DRAW, HasDTM: true}, true
+	}
+
+	if all := pos.ByColor(butils.White) | pos.ByColor(butils.Black); all.Count() < MIN_PIECES {
+		return Result{WDL: WDL_DRAW, HasDTM: true}, true
+	}
+
+	res, ok := tb.Probe(pos)
+	return res, ok && res.HasDTM
+}
+
+// result returns the outcome of position idx
+func (t *Table) result(idx int) (Result, bool) {
+	if t.dtm == nil {
+		switch (t.wdl[idx/4] >> uint(2*(idx%4))) & 3 {
+		case PACKED_LOSS:
+			return Result{WDL: WDL_LOSS}, true
+		case PACKED_WIN:
+			return Result{WDL: WDL_WIN}, true
+		case PACKED_DRAW:
+			return Result{WDL: WDL_DRAW}, true
+		}
+		return Result{}, false
+	}
+
+	switch v := t.dtm[idx]; {
+	case v == DTM_INVALID:
+		return Result{}, false
+	case v == DTM_DRAW:
+		return Result{WDL: WDL_DRAW, HasDTM: true}, true
+	case (v-DTM_OFFSET)%2 == 0:
+		return Result{WDL: WDL_LOSS, DTM: int(v - DTM_OFFSET), HasDTM: true}, true
+	default:
+		return Result{WDL: WDL_WIN, DTM: int(v - DTM_OFFSET), HasDTM: true}, true
+	}
+}
+
+// Stats counts the outcomes of the positions of t and finds its longest mate
+func (t *Table) Stats() Stats {
+	stats := Stats{}
+	longest := -1
+	for idx := 0; idx < t.size; idx++ {
+		res, ok := t.result(idx)
+		switch {
+		case !ok:
+			stats.Invalid++
+		case res.WDL == WDL_WIN:
+			stats.Wins++
+		case res.WDL == WDL_LOSS:
+			stats.Losses++
+		default:
+			stats.Draws++
+		}
+		if ok && res.WDL != WDL_DRAW && res.DTM > stats.MaxDTM {
+			stats.MaxDTM, longest = res.DTM, idx
+		}
+	}
+	if longest >= 0 {
+		stats.MaxDTMFen = t.fen(longest)
+	}
+	return stats
+}
+
+// rank orders results, quicker wins and slower losses are better
+func (res Result) rank() int {
+	switch res.WDL {
+	case WDL_WIN:
+		return 2*MAX_DTM - res.DTM
+	case WDL_LOSS:
+		return -2*MAX_DTM + res.DTM
+	}
+	return 0
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// NewTablebase returns an empty tablebase
+func NewTablebase() *Tablebase {
+	return &Tablebase{
+		tables: map[tableKey]*Table{},
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,146 @@
+package tablebase
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+// kqk is the tablebase with the KQvK table shared by the tests
+var kqk *Tablebase
+
+func kqkTablebase(t *testing.T) *Tablebase {
+	if kqk == nil {
+		tb := NewTablebase()
+		generate(t, tb, utils.VARIANT_STANDARD, "KQvK")
+		kqk = tb
+	}
+	return kqk
+}
+
+func generate(t *testing.T, tb *Tablebase, variant utils.VariantKey, s string) *Table {
+	m, err := ParseMaterial(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := tb.Generate(variant, m, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return table
+}
+
+func probe(t *testing.T, tb *Tablebase, variant utils.VariantKey, fen string) Result {
+	pos, err := butils.PositionFromFENAndVariant(fen, variant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, ok := tb.Probe(pos)
+	if !ok {
+		t.Fatalf("%s not covered", fen)
+	}
+	return res
+}
+
+func TestParseMaterial(t *testing.T) {
+	m, err := ParseMaterial("KvKRP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.String() != "KvKRP" {
+		t.Errorf("expected KvKRP, got %s", m)
+	}
+	if c, flip := m.Canonical(); !flip || c.String() != "KRPvK" {
+		t.Errorf("expected flipped KRPvK, got %s %v", c, flip)
+	}
+
+	for _, s := range []string{"KQK", "KQvQ", "KKvK", "KvK", "KQRBvK", "KxvK"} {
+		if _, err := ParseMaterial(s); err == nil {
+			t.Errorf("expected an error for %s", s)
+		}
+	}
+}
+
+func TestKQvK(t *testing.T) {
+	tb := kqkTablebase(t)
+	table := generate(t, tb, utils.VARIANT_STANDARD, "KQvK")
+
+	// the longest win of the queen is a mate in 10 moves
+	if stats := table.Stats(); stats.MaxDTM != 20 {
+		t.Errorf("expected longest mate of 20 plies, got %d", stats.MaxDTM)
+	}
+	if err := tb.Verify(table); err != nil {
+		t.Error(err)
+	}
+
+	for _, test := range []struct {
+		fen string
+		wdl int
+		dtm int
+	}{
+		{"7k/8/6K1/8/8/8/Q7/8 w - - 0 1", WDL_WIN, 1},
+		{"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", WDL_DRAW, 0},
+		{"7k/6Q1/6K1/8/8/8/8/8 b - - 0 1", WDL_LOSS, 0},
+		// the same positions with the colors swapped
+		{"8/q7/8/8/8/6k1/8/7K b - - 0 1", WDL_WIN, 1},
+		{"7K/6q1/6k1/8/8/8/8/8 w - - 0 1", WDL_LOSS, 0},
+	} {
+		if res := probe(t, tb, utils.VARIANT_STANDARD, test.fen); res.WDL != test.wdl || res.DTM != test.dtm {
+			t.Errorf("%s expected wdl %d dtm %d, got %+v", test.fen, test.wdl, test.dtm, res)
+		}
+	}
+
+	pos, _ := butils.PositionFromFEN("7k/8/6K1/8/8/8/Q7/8 w - - 0 1")
+	if move, res, ok := tb.BestMove(pos, nil); !ok || pos.MoveToUCI(move) != "a2a8" || res.WDL != WDL_WIN || res.DTM != 1 {
+		t.Errorf("expected mate by a2a8, got %s %+v", pos.MoveToUCI(move), res)
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	table := generate(t, kqkTablebase(t), utils.VARIANT_STANDARD, "KQvK")
+
+	for _, format := range []byte{FORMAT_DTM, FORMAT_WDL} {
+		buf := &bytes.Buffer{}
+		if err := table.Write(buf, format); err != nil {
+			t.Fatal(err)
+		}
+		read, err := Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if read.Material.String() != "KQvK" || read.Variant != utils.VARIANT_STANDARD {
+			t.Fatalf("read %s %v", read.Material, read.Variant)
+		}
+
+		for idx := 0; idx < table.size; idx++ {
+			want, wantOk := table.result(idx)
+			got, gotOk := read.result(idx)
+			if format == FORMAT_WDL {
+				want.DTM, want.HasDTM = 0, false
+			}
+			if want != got || wantOk != gotOk {
+				t.Fatalf("format %c position %d expected %+v, got %+v", format, idx, want, got)
+			}
+		}
+	}
+}
+
+func TestEightpiece(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping eightpiece generation in short mode")
+	}
+
+	tb := NewTablebase()
+	table := generate(t, tb, utils.VARIANT_EIGHTPIECE, "KJvK")
+
+	// the jailed king cannot take the king mating it
+	if res := probe(t, tb, utils.VARIANT_EIGHTPIECE, "8/8/8/8/8/8/7k/6KJ b - - 0 1 -"); res.WDL != WDL_LOSS || res.DTM != 0 {
+		t.Errorf("expected mate, got %+v", res)
+	}
+
+	if err := tb.Verify(table); err != nil {
+		t.Error(err)
+	}
+}
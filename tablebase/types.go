@@ -0,0 +1,110 @@
+package tablebase
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"io"
+
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// types
+
+// Material lists the figures of a table by color, each side has the king first
+// and the other figures in MATERIAL_ORDER, lancers are listed by base figure
+type Material [butils.ColorArraySize][]butils.Figure
+
+// slot is a piece of a table
+type slot struct {
+	color  butils.Color
+	figure butils.Figure // base figure
+}
+
+// disabledPair is a disabled move a table can store
+// the piece of slot pushed was pushed last move by the sentry of slot sentry
+type disabledPair struct {
+	pushed int
+	sentry int
+}
+
+// placement is a position of a table
+type placement struct {
+	stm     butils.Color              // side to move
+	squares [MAX_PIECES]butils.Square // square of each slot
+	dirs    [MAX_PIECES]int           // direction of each lancer slot
+	pushed  int                       // slot of the piece with a disabled move, -1 if none
+	sentry  int                       // slot of the sentry that pushed it
+}
+
+// Table holds the values of all positions of a material for one side to move
+// positions are indexed by side to move, disabled move and the placement of the pieces
+type Table struct {
+	Variant  utils.VariantKey
+	Material Material
+
+	dtm []uint8 // one value per position, nil if only the wdl table was loaded
+	wdl []uint8 // four packed values per byte, only set if the dtm table was not loaded
+
+	slots         []slot
+	first         [butils.ColorArraySize][]int          // first slot of a color by MATERIAL_ORDER index
+	strides       []int                                 // index stride of each slot
+	pairs         [butils.ColorArraySize][]disabledPair // disabled moves by side to move
+	numDisabled   int                                   // no disabled move and one per pair
+	numPlacements int                                   // placements of the pieces
+	size          int                                   // number of positions
+	signature     uint64                                // material signature
+}
+
+// tableKey identifies a table in a tablebase
+type tableKey struct {
+	variant   utils.VariantKey
+	signature uint64
+}
+
+// Tablebase is a set of tables, tables are stored with the stronger side as white
+// and probed for both colors
+type Tablebase struct {
+	tables map[tableKey]*Table
+}
+
+// Result is the outcome of a position for the side to move
+type Result struct {
+	WDL    int  // WDL_LOSS, WDL_DRAW or WDL_WIN
+	DTM    int  // plies to mate if won or lost
+	HasDTM bool // false if the table only knows the outcome
+}
+
+// Stats summarizes the positions of a table
+type Stats struct {
+	Wins      int
+	Draws     int
+	Losses    int
+	Invalid   int    // positions that cannot occur
+	MaxDTM    int    // longest mate in plies
+	MaxDTMFen string // a position with the longest mate
+}
+
+// generator computes a table by retrograde analysis
+//
+// every position is evaluated once from its moves, then positions are resolved level by level:
+// at level n the positions won or lost in n plies are found by evaluating again the
+// predecessors of the positions resolved at level n-1
+type generator struct {
+	tb       *Tablebase
+	table    *Table
+	pos      *butils.Position
+	moves    []butils.Move
+	occupied []butils.Square // squares of the pieces put on pos
+	done     []uint64        // bitset of resolved positions
+	next     []uint64        // bitset of positions to evaluate at the next level
+	buckets  map[int][]int   // positions to evaluate at a later level
+	log      io.Writer       // progress, nil for none
+	err      error           // first error of the generation
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,36 @@
+package tuner
+
+/////////////////////////////////////////////////////////////////////
+// constants
+
+// DEFAULT_ITERATIONS is the default number of passes over the training set
+const DEFAULT_ITERATIONS = 1000
+
+// DEFAULT_RATE is the default learning rate, weights are in 1/256 centipawn units
+const DEFAULT_RATE = 200
+
+// DEFAULT_VALIDATION is the default fraction of samples used for validation
+const DEFAULT_VALIDATION = 0.1
+
+// DEFAULT_SKIP_PLIES is the default number of opening plies left out of games
+const DEFAULT_SKIP_PLIES = 8
+
+// PHASE_MAX is the phase of a late end game
+const PHASE_MAX = 256
+
+// WEIGHTS_PER_LINE is the number of weights per line of the generated weights file
+const WEIGHTS_PER_LINE = 8
+
+// K_MIN and K_MAX bound the search for the scaling constant
+const K_MIN = 0.1
+const K_MAX = 5.0
+
+// K_PRECISION is the precision the scaling constant is fitted to
+const K_PRECISION = 1e-4
+
+// Adam optimizer parameters
+const ADAM_BETA1 = 0.9
+const ADAM_BETA2 = 0.999
+const ADAM_EPSILON = 1e-8
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,164 @@
+package tuner
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/easychessanimations/gochess/bengine"
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/epd"
+	"github.com/easychessanimations/gochess/pgn"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// NewSample extracts the features of pos labelled with result from White's point of view
+func NewSample(pos *butils.Position, result float64) Sample {
+	sample := Sample{
+		Phase:  bengine.Phase(pos),
+		Result: result,
+	}
+
+	for index, value := range bengine.Features(pos) {
+		if value != 0 {
+			sample.Features = append(sample.Features, Feature{Index: index, Value: value})
+		}
+	}
+
+	return sample
+}
+
+// ParseResult converts a game result to a score from White's point of view
+// accepts PGN results and the numbers 1, 0.5 and 0
+func ParseResult(result string) (float64, error) {
+	switch result {
+	case "1-0", "1", "1.0":
+		return 1, nil
+	case "0-1", "0", "0.0":
+		return 0, nil
+	case "1/2-1/2", "0.5", ".5":
+		return 0.5, nil
+	}
+
+	return 0, fmt.Errorf("invalid result %s", result)
+}
+
+// ParseEPD parses an EPD line holding a position and the result of the game it was taken from
+// the result is either given by the c9 opcode, as in c9 "1-0";
+// or ends the line as a number in brackets, as in [0.5], or as a PGN result
+func ParseEPD(line string, variant utils.VariantKey) (*butils.Position, float64, error) {
+	result, found := 0.0, false
+
+	// the trailing result is not an epd operation, cut it before parsing the rest
+	if fields := strings.Fields(line); len(fields) > 0 {
+		last := fields[len(fields)-1]
+		bracketed := strings.HasPrefix(last, "[")
+		token := strings.Trim(last, "[]")
+		if r, err := ParseResult(token); err == nil && (bracketed || pgn.IsResult(token)) {
+			result, found = r, true
+			line = strings.TrimSpace(line[:strings.LastIndex(line, last)])
+		}
+	}
+
+	record, err := epd.ParseEPD(line)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if !found {
+		if c9 := record.Get(epd.OPCODE_RESULT); len(c9) > 0 {
+			result, err = ParseResult(c9[0])
+			found = err == nil
+		}
+	}
+	if !found {
+		return nil, 0, fmt.Errorf("no result in %s", line)
+	}
+
+	pos, err := butils.PositionFromFENAndVariant(record.Fen, variant)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return pos, result, nil
+}
+
+// ReadEPD reads labelled positions from r, one EPD per line
+// empty lines and lines starting with # are skipped
+func ReadEPD(r io.Reader, variant utils.VariantKey) ([]Sample, error) {
+	samples := []Sample{}
+
+	scanner := bufio.NewScanner(r)
+	for num := 1; scanner.Scan(); num++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		pos, result, err := ParseEPD(line, variant)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", num, err)
+		}
+
+		samples = append(samples, NewSample(pos, result))
+	}
+
+	return samples, scanner.Err()
+}
+
+// SamplesFromGames extracts labelled positions from the main lines of finished games of variant
+// the first skipPlies plies of each game are left out, so are positions
+// where the side to move is in check or the move played is a capture or a promotion
+func SamplesFromGames(games []*pgn.Game, variant utils.VariantKey, skipPlies int) ([]Sample, error) {
+	samples := []Sample{}
+
+	for i, g := range games {
+		gameVariant, chess960, err := g.Variant()
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+
+		result, err := ParseResult(g.Result)
+		if gameVariant != variant || err != nil {
+			continue
+		}
+
+		fen, err := g.StartFen()
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+
+		pos, err := butils.PositionFromFENAndVariant(fen, variant)
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+		pos.Chess960 = chess960
+
+		for ply, m := range g.Moves {
+			move, err := pos.SanToMove(m.San)
+			if err != nil {
+				return nil, fmt.Errorf("game %d ply %d: %w", i+1, ply+1, err)
+			}
+
+			quiet := move.Capture() == butils.NoPiece && move.MoveType() != butils.Promotion
+			if ply >= skipPlies && quiet && !pos.IsChecked(pos.Us()) {
+				samples = append(samples, NewSample(pos, result))
+			}
+
+			pos.DoMove(move)
+		}
+	}
+
+	return samples, nil
+}
+
+/////////////////////////////////////////////////////////////////////
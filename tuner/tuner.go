@@ -0,0 +1,244 @@
+package tuner
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"github.com/easychessanimations/gochess/bengine"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Evaluate returns the evaluation of sample in centipawns with the weights being tuned
+func (t *Tuner) Evaluate(sample *Sample) float64 {
+	m, e := 0.0, 0.0
+	for _, f := range sample.Features {
+		m += t.M[f.Index] * float64(f.Value)
+		e += t.E[f.Index] * float64(f.Value)
+	}
+	phase := float64(sample.Phase) / PHASE_MAX
+	return (m*(1-phase) + e*phase) / 256
+}
+
+// Errors returns the mean squared error of the training and of the validation set
+func (t *Tuner) Errors() (float64, float64) {
+	return t.meanSquaredError(t.Train), t.meanSquaredError(t.Validate)
+}
+
+// FitK finds the scaling constant which minimizes the training error with the initial weights
+func (t *Tuner) FitK() float64 {
+	lo, hi := K_MIN, K_MAX
+	ratio := (math.Sqrt(5) - 1) / 2
+
+	errorAt := func(k float64) float64 {
+		t.Options.K = k
+		return t.meanSquaredError(t.Train)
+	}
+
+	// golden section search, the error is unimodal in k
+	a, b := hi-ratio*(hi-lo), lo+ratio*(hi-lo)
+	errA, errB := errorAt(a), errorAt(b)
+	for hi-lo > K_PRECISION {
+		if errA < errB {
+			hi, b, errB = b, a, errA
+			a = hi - ratio*(hi-lo)
+			errA = errorAt(a)
+		} else {
+			lo, a, errA = a, b, errB
+			b = lo + ratio*(hi-lo)
+			errB = errorAt(b)
+		}
+	}
+
+	t.Options.K = (lo + hi) / 2
+	return t.Options.K
+}
+
+// Tune minimizes the logistic loss of the training set with the Adam optimizer
+func (t *Tuner) Tune() {
+	if len(t.Train) == 0 {
+		return
+	}
+
+	if t.Options.K == 0 {
+		t.FitK()
+		t.log(fmt.Sprintf("fitted K %.4f", t.Options.K))
+	}
+
+	optM, optE := newAdam(len(t.M)), newAdam(len(t.E))
+
+	for iter := 1; iter <= t.Options.Iterations; iter++ {
+		gradM, gradE := t.gradient()
+		optM.step(t.M, gradM, t.Options.Rate, iter)
+		optE.step(t.E, gradE, t.Options.Rate, iter)
+
+		if t.Options.LogEvery > 0 && (iter%t.Options.LogEvery == 0 || iter == t.Options.Iterations) {
+			train, validate := t.Errors()
+			t.log(fmt.Sprintf("iteration %d train error %.8f validation error %.8f", iter, train, validate))
+		}
+	}
+
+	t.TrainError, t.ValidError = t.Errors()
+}
+
+// Weights returns the tuned weights rounded to integers
+func (t *Tuner) Weights() []bengine.Score {
+	weights := make([]bengine.Score, len(t.M))
+	for i := range weights {
+		weights[i] = bengine.Score{
+			M: int32(math.Round(t.M[i])),
+			E: int32(math.Round(t.E[i])),
+		}
+	}
+	return weights
+}
+
+// sigmoid maps an evaluation in centipawns to the expected result
+func (t *Tuner) sigmoid(score float64) float64 {
+	return 1 / (1 + math.Pow(10, -t.Options.K*score/400))
+}
+
+// meanSquaredError returns the mean squared difference of expected and actual results of samples
+func (t *Tuner) meanSquaredError(samples []Sample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	sums := t.parallel(samples, func(part []Sample, _ []float64, _ []float64) float64 {
+		sum := 0.0
+		for i := range part {
+			diff := t.sigmoid(t.Evaluate(&part[i])) - part[i].Result
+			sum += diff * diff
+		}
+		return sum
+	}, false)
+
+	return sums.total / float64(len(samples))
+}
+
+// gradient returns the gradient of the mean logistic loss of the training set
+// for the loss -r*log(s)-(1-r)*log(1-s) of a sample the derivative wrt. the score is (s-r)*K*ln(10)/400
+func (t *Tuner) gradient() ([]float64, []float64) {
+	scale := t.Options.K * math.Ln10 / 400 / 256 / float64(len(t.Train))
+
+	sums := t.parallel(t.Train, func(part []Sample, gradM []float64, gradE []float64) float64 {
+		for i := range part {
+			sample := &part[i]
+			diff := (t.sigmoid(t.Evaluate(sample)) - sample.Result) * scale
+			phase := float64(sample.Phase) / PHASE_MAX
+			for _, f := range sample.Features {
+				gradM[f.Index] += diff * float64(f.Value) * (1 - phase)
+				gradE[f.Index] += diff * float64(f.Value) * phase
+			}
+		}
+		return 0
+	}, true)
+
+	return sums.gradM, sums.gradE
+}
+
+// parallel runs work on parts of samples in Threads goroutines and adds up the results
+func (t *Tuner) parallel(samples []Sample, work func([]Sample, []float64, []float64) float64, withGradient bool) partialSums {
+	threads := t.Options.Threads
+	if threads <= 0 {
+		threads = runtime.NumCPU()
+	}
+
+	size := (len(samples) + threads - 1) / threads
+	parts := make([]partialSums, threads)
+
+	var wg sync.WaitGroup
+	for i := 0; i < threads && i*size < len(samples); i++ {
+		end := (i + 1) * size
+		if end > len(samples) {
+			end = len(samples)
+		}
+		if withGradient {
+			parts[i].gradM = make([]float64, len(t.M))
+			parts[i].gradE = make([]float64, len(t.E))
+		}
+		wg.Add(1)
+		go func(part *partialSums, samples []Sample) {
+			defer wg.Done()
+			part.total = work(samples, part.gradM, part.gradE)
+		}(&parts[i], samples[i*size:end])
+	}
+	wg.Wait()
+
+	sums := parts[0]
+	for _, part := range parts[1:] {
+		sums.total += part.total
+		for i := range part.gradM {
+			sums.gradM[i] += part.gradM[i]
+			sums.gradE[i] += part.gradE[i]
+		}
+	}
+	return sums
+}
+
+// log reports the progress of the tuning
+func (t *Tuner) log(content string) {
+	if t.Options.Log != nil {
+		t.Options.Log(content)
+	}
+}
+
+// step moves weights against the gradient
+func (a *adam) step(weights []float64, gradient []float64, rate float64, iter int) {
+	correction1 := 1 - math.Pow(ADAM_BETA1, float64(iter))
+	correction2 := 1 - math.Pow(ADAM_BETA2, float64(iter))
+	for i, g := range gradient {
+		a.m[i] = ADAM_BETA1*a.m[i] + (1-ADAM_BETA1)*g
+		a.v[i] = ADAM_BETA2*a.v[i] + (1-ADAM_BETA2)*g*g
+		weights[i] -= rate * (a.m[i] / correction1) / (math.Sqrt(a.v[i]/correction2) + ADAM_EPSILON)
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// NewTuner returns a tuner starting from weights, samples are shuffled and split into training and validation sets
+func NewTuner(samples []Sample, weights []bengine.Score, options Options) *Tuner {
+	if options.Iterations == 0 {
+		options.Iterations = DEFAULT_ITERATIONS
+	}
+	if options.Rate == 0 {
+		options.Rate = DEFAULT_RATE
+	}
+
+	shuffled := append([]Sample{}, samples...)
+	rand.New(rand.NewSource(options.Seed)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	numValidate := int(float64(len(shuffled)) * options.Validation)
+
+	t := &Tuner{
+		Options:  options,
+		Train:    shuffled[numValidate:],
+		Validate: shuffled[:numValidate],
+		M:        make([]float64, len(weights)),
+		E:        make([]float64, len(weights)),
+	}
+	for i, w := range weights {
+		t.M[i], t.E[i] = float64(w.M), float64(w.E)
+	}
+	return t
+}
+
+// newAdam returns an optimizer for n weights
+func newAdam(n int) *adam {
+	return &adam{m: make([]float64, n), v: make([]float64, n)}
+}
+
+/////////////////////////////////////////////////////////////////////
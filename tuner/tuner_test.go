@@ -0,0 +1,112 @@
+package tuner
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/easychessanimations/gochess/bengine"
+	"github.com/easychessanimations/gochess/pgn"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+var EPD_TESTS = []struct {
+	line    string
+	variant utils.VariantKey
+	fen     string
+	result  float64
+}{
+	{`rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - c9 "1-0";`, utils.VARIANT_STANDARD, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1", 1},
+	{`8/8/4k3/8/8/4K3/4P3/8 w - - 0 40 [0.5]`, utils.VARIANT_STANDARD, "8/8/4k3/8/8/4K3/4P3/8 w - - 0 40", 0.5},
+	{`8/8/4k3/8/8/4K3/8/q7 w - - 0-1`, utils.VARIANT_STANDARD, "8/8/4k3/8/8/4K3/8/q7 w - - 0 1", 0},
+	{utils.StartFenForVariant(utils.VARIANT_EIGHTPIECE) + ` c9 "1/2-1/2";`, utils.VARIANT_EIGHTPIECE, utils.StartFenForVariant(utils.VARIANT_EIGHTPIECE), 0.5},
+}
+
+const TEST_TUNER_PGN = `[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 4. Ba4 Nf6 5. O-O Be7 1-0
+
+[Result "*"]
+
+1. d4 d5 2. c4 e6 *
+`
+
+func TestParseEPD(t *testing.T) {
+	for _, test := range EPD_TESTS {
+		pos, result, err := ParseEPD(test.line, test.variant)
+		if err != nil {
+			t.Fatalf("%s: %v", test.line, err)
+		}
+		if fen := pos.String(); fen != test.fen {
+			t.Errorf("%s: expected fen %s, got %s", test.line, test.fen, fen)
+		}
+		if result != test.result {
+			t.Errorf("%s: expected result %v, got %v", test.line, test.result, result)
+		}
+	}
+
+	if _, _, err := ParseEPD("8/8/4k3/8/8/4K3/8/8 w - - hmvc 0;", utils.VARIANT_STANDARD); err == nil {
+		t.Errorf("expected an error for an epd without result")
+	}
+}
+
+func TestSamplesFromGames(t *testing.T) {
+	games, err := pgn.ParseString(TEST_TUNER_PGN)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	samples, err := SamplesFromGames(games, utils.VARIANT_STANDARD, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// plies 3 to 10 of the finished game, all of them quiet
+	if len(samples) != 8 {
+		t.Fatalf("expected 8 samples, got %d", len(samples))
+	}
+	for _, sample := range samples {
+		if sample.Result != 1 {
+			t.Errorf("expected result 1, got %v", sample.Result)
+		}
+	}
+}
+
+func TestTuneReducesError(t *testing.T) {
+	epd := `rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - [0.5]
+rnb1kbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - [1.0]
+rnb1kbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - [1.0]
+rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNB1KBNR w KQkq - [0.0]
+rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNB1KBNR b KQkq - [0.0]
+`
+	samples, err := ReadEPD(strings.NewReader(epd), utils.VARIANT_STANDARD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples[0].Features) != 0 {
+		t.Errorf("expected no features for the symmetrical start position, got %v", samples[0].Features)
+	}
+
+	tuner := NewTuner(samples, make([]bengine.Score, len(bengine.Weights)), Options{
+		Iterations: 50,
+		K:          1,
+		Threads:    2,
+	})
+	before, _ := tuner.Errors()
+	tuner.Tune()
+
+	if tuner.TrainError >= before {
+		t.Errorf("expected the error to decrease from %v, got %v", before, tuner.TrainError)
+	}
+}
+
+func TestFormatWeights(t *testing.T) {
+	src, err := ioutil.ReadFile("../bengine/weights.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := FormatWeights(bengine.Weights, 0.05679009, 0.05702872); got != string(src) {
+		t.Errorf("formatted weights differ from bengine/weights.go")
+	}
+}
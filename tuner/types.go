@@ -0,0 +1,52 @@
+package tuner
+
+/////////////////////////////////////////////////////////////////////
+// types
+
+// Feature is a non zero input of the evaluation network
+type Feature struct {
+	Index int   // index in bengine.Weights
+	Value int32 // white's count minus black's count
+}
+
+// Sample is a labelled position
+type Sample struct {
+	Features []Feature
+	Phase    int32   // 0 is opening, 256 is late end game
+	Result   float64 // 1 for a white win, 0.5 for a draw, 0 for a black win
+}
+
+// Options controls the tuning
+type Options struct {
+	Iterations int     // number of passes over the training set
+	Rate       float64 // learning rate, in weight units
+	K          float64 // scaling of the evaluation, fitted before tuning if 0
+	Validation float64 // fraction of the samples held out for validation
+	Threads    int     // number of goroutines computing the gradient
+	Seed       int64   // seed used to shuffle samples
+	LogEvery   int     // log the errors every LogEvery iterations, never if 0
+	Log        func(string)
+}
+
+// Tuner fits the mid and end game weights of the evaluation to labelled positions
+type Tuner struct {
+	Options    Options
+	Train      []Sample
+	Validate   []Sample
+	M, E       []float64 // weights being tuned
+	TrainError float64   // mean squared error of the training set
+	ValidError float64   // mean squared error of the validation set
+}
+
+// partialSums holds the error sum and the gradient of a part of the samples
+type partialSums struct {
+	total        float64
+	gradM, gradE []float64
+}
+
+// adam keeps the moments of the Adam optimizer for one set of weights
+type adam struct {
+	m, v []float64
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,66 @@
+package tuner
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/easychessanimations/gochess/bengine"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// FormatWeights returns the source of the bengine weights file holding weights
+func FormatWeights(weights []bengine.Score, trainError float64, validError float64) string {
+	var sb strings.Builder
+
+	sb.WriteString("package bengine\n\n")
+	sb.WriteString("/////////////////////////////////////////////////////////////////////\n")
+	sb.WriteString("// weights\n\n")
+	sb.WriteString("// Weights stores the network parameters\n")
+	sb.WriteString(fmt.Sprintf("// network has train error %.8f and validation error %.8f\n", trainError, validError))
+	sb.WriteString("var Weights = []Score{\n")
+
+	for i := 0; i < len(weights); i += WEIGHTS_PER_LINE {
+		line := []string{}
+		for j := i; j < i+WEIGHTS_PER_LINE && j < len(weights); j++ {
+			line = append(line, fmt.Sprintf("{M: %d, E: %d},", weights[j].M, weights[j].E))
+		}
+		sb.WriteString("\t" + strings.Join(line, " ") + "\n")
+	}
+
+	sb.WriteString("}\n\n")
+	sb.WriteString("/////////////////////////////////////////////////////////////////////\n")
+
+	return sb.String()
+}
+
+// WriteWeights writes the bengine weights file holding weights to w
+func WriteWeights(w io.Writer, weights []bengine.Score, trainError float64, validError float64) error {
+	_, err := io.WriteString(w, FormatWeights(weights, trainError, validError))
+	return err
+}
+
+// SaveWeights writes the bengine weights file holding weights to path
+func SaveWeights(path string, weights []bengine.Score, trainError float64, validError float64) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteWeights(f, weights, trainError, validError); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,112 @@
+package main
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/easychessanimations/gochess/pgn"
+	"github.com/easychessanimations/gochess/render"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// flags
+
+var (
+	pgnFlag         = flag.String("pgn", "", "pgn file of the game to animate")
+	gameFlag        = flag.Int("game", 1, "number of the game in the pgn file")
+	outFlag         = flag.String("out", "game.gif", "gif file to write, none if empty")
+	framesFlag      = flag.String("frames", "", "directory to write the frames to as numbered png files for a video encoder")
+	fpsFlag         = flag.Int("fps", 25, "frame rate of the png frames")
+	sizeFlag        = flag.Int("size", render.DEFAULT_ANIMATION_OPTIONS.SquareSize, "size of a square in pixels")
+	coordinatesFlag = flag.Bool("coordinates", render.DEFAULT_ANIMATION_OPTIONS.Coordinates, "draw file letters and rank numbers")
+	flipFlag        = flag.Bool("flip", false, "draw the board from black's side")
+	delayFlag       = flag.Duration("delay", render.DEFAULT_ANIMATION_OPTIONS.Delay, "how long the position after a move is shown")
+	slideFramesFlag = flag.Int("slide-frames", render.DEFAULT_ANIMATION_OPTIONS.SlideFrames, "number of frames of a piece sliding between its squares")
+	slideDelayFlag  = flag.Duration("slide-delay", render.DEFAULT_ANIMATION_OPTIONS.SlideDelay, "how long a sliding or explosion frame is shown")
+	captionFlag     = flag.Bool("caption", render.DEFAULT_ANIMATION_OPTIONS.Caption, "write the move and its eval below the board")
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// readGame reads the game of the flags
+func readGame() (*pgn.Game, error) {
+	f, err := os.Open(*pgnFlag)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	games, err := pgn.Parse(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if *gameFlag < 1 || *gameFlag > len(games) {
+		return nil, fmt.Errorf("no game %d in %s, it has %d games", *gameFlag, *pgnFlag, len(games))
+	}
+
+	return games[*gameFlag-1], nil
+}
+
+func main() {
+	flag.Parse()
+
+	if *pgnFlag == "" {
+		log.Fatal("no pgn file given")
+	}
+
+	g, err := readGame()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	options := render.AnimationOptions{
+		Options:     render.Options{SquareSize: *sizeFlag, Coordinates: *coordinatesFlag, Flipped: *flipFlag},
+		Delay:       *delayFlag,
+		SlideFrames: *slideFramesFlag,
+		SlideDelay:  *slideDelayFlag,
+		Caption:     *captionFlag,
+	}
+
+	frames, err := render.GameFrames(g, options)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *outFlag != "" {
+		f, err := os.Create(*outFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := render.WriteGIF(f, frames); err != nil {
+			log.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("wrote %d frames to %s", len(frames), *outFlag)
+	}
+
+	if *framesFlag != "" {
+		if err := os.MkdirAll(*framesFlag, 0755); err != nil {
+			log.Fatal(err)
+		}
+		n, err := render.WritePNGFrames(*framesFlag, frames, *fpsFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("wrote %d png frames to %s", n, *framesFlag)
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,124 @@
+package main
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/easychessanimations/gochess/board"
+	"github.com/easychessanimations/gochess/render"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// flags
+
+var (
+	variantFlag     = flag.String("variant", "standard", "variant of the position")
+	chess960Flag    = flag.Bool("chess960", false, "castling is written king takes rook and fen castling may use file letters")
+	fenFlag         = flag.String("fen", "", "position to draw, the start position of the variant if empty")
+	movesFlag       = flag.String("moves", "", "space separated uci moves played from the position, the last one is highlighted")
+	arrowsFlag      = flag.String("arrows", "", "comma separated arrows as e2e4, a color may follow after a colon as e2e4:#882020")
+	highlightsFlag  = flag.String("highlights", "", "comma separated squares to mark, as e4,d5")
+	sizeFlag        = flag.Int("size", render.DEFAULT_OPTIONS.SquareSize, "size of a square in pixels")
+	coordinatesFlag = flag.Bool("coordinates", render.DEFAULT_OPTIONS.Coordinates, "draw file letters and rank numbers")
+	flipFlag        = flag.Bool("flip", false, "draw the board from black's side")
+	outFlag         = flag.String("out", "", "svg file to write, standard output if empty")
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// newDiagram sets up the position of the flags and returns its diagram
+func newDiagram() (*render.Diagram, error) {
+	variant := utils.VariantKeyStringToVariantKey(*variantFlag)
+
+	fen := *fenFlag
+	if fen == "" {
+		fen = utils.StartFenForVariant(variant)
+	}
+
+	b := &board.Board{}
+	b.Init(variant)
+	b.Chess960 = *chess960Flag
+	b.SetFromFen(fen)
+
+	for _, algeb := range strings.Fields(*movesFlag) {
+		move := b.AlgebToMove(algeb)
+		if move == board.NO_MOVE {
+			return nil, fmt.Errorf("illegal move %s", algeb)
+		}
+		b.Push(move, false)
+	}
+
+	d := render.FromBoard(b)
+
+	for _, arrow := range strings.Split(*arrowsFlag, ",") {
+		if arrow = strings.TrimSpace(arrow); arrow == "" {
+			continue
+		}
+
+		color := ""
+		if i := strings.Index(arrow, ":"); i >= 0 {
+			arrow, color = arrow[:i], arrow[i+1:]
+		}
+
+		if len(arrow) != 4 {
+			return nil, fmt.Errorf("invalid arrow %s", arrow)
+		}
+		if err := d.AddArrow(arrow[:2], arrow[2:], color); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, algeb := range strings.Split(*highlightsFlag, ",") {
+		if algeb = strings.TrimSpace(algeb); algeb == "" {
+			continue
+		}
+
+		sq, err := d.ParseSquare(algeb)
+		if err != nil {
+			return nil, err
+		}
+		d.Highlights = append(d.Highlights, sq)
+	}
+
+	return d, nil
+}
+
+func main() {
+	flag.Parse()
+
+	d, err := newDiagram()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *outFlag != "" {
+		f, err := os.Create(*outFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	options := render.Options{SquareSize: *sizeFlag, Coordinates: *coordinatesFlag, Flipped: *flipFlag}
+
+	if err := d.WriteSVG(out, options); err != nil {
+		log.Fatal(err)
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,121 @@
+package main
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/easychessanimations/gochess/lichess"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// flags
+
+var (
+	urlFlag           = flag.String("url", lichess.DEFAULT_OPTIONS.URL, "address of the lichess api")
+	tokenFlag         = flag.String("token", os.Getenv("LICHESS_BOT_TOKEN"), "api token of the bot account, LICHESS_BOT_TOKEN if not given")
+	variantsFlag      = flag.String("variants", strings.Join(lichess.DEFAULT_OPTIONS.Variants, ","), "comma separated lichess keys of the variants accepted")
+	maxGamesFlag      = flag.Int("max-games", lichess.DEFAULT_OPTIONS.MaxGames, "games played at the same time")
+	moveOverheadFlag  = flag.Duration("move-overhead", lichess.DEFAULT_OPTIONS.MoveOverhead, "kept off the clock for the network lag")
+	moveTimeFlag      = flag.Duration("movetime", lichess.DEFAULT_OPTIONS.MoveTime, "search time per move of correspondence and unlimited games")
+	threadsFlag       = flag.Int("threads", lichess.DEFAULT_OPTIONS.Threads, "search threads of a game")
+	mockFlag          = flag.Int("mock", 0, "number of games to play offline against a local mock server instead of lichess")
+	mockVariantFlag   = flag.String("mock-variant", "standard", "lichess key of the variant of the mock games")
+	mockLimitFlag     = flag.Int("mock-limit", 60, "clock of the mock games in seconds, unlimited if 0")
+	mockIncrementFlag = flag.Int("mock-increment", 1, "increment of the mock games in seconds")
+	mockPliesFlag     = flag.Int("mock-plies", 100, "plies after which the mock opponent resigns, never if 0")
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// playMock plays the mock games and logs their results
+func playMock(options lichess.Options) {
+	mock := lichess.NewMockServer("mock", "gochess-bot")
+	defer mock.Close()
+	mock.MaxPlies = *mockPliesFlag
+
+	options.URL, options.Token = mock.URL, mock.Token
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() {
+		stopped <- lichess.NewBot(options).Run(ctx)
+	}()
+
+	tc := lichess.ChallengeTimeControl{Type: "clock", Limit: *mockLimitFlag, Increment: *mockIncrementFlag}
+	if *mockLimitFlag == 0 {
+		tc = lichess.ChallengeTimeControl{}
+	}
+
+	for i := 0; i < *mockFlag; {
+		id := mock.Challenge(lichess.Challenge{Variant: lichess.Variant{Key: *mockVariantFlag}, TimeControl: tc})
+
+		game, err := mock.WaitGame(id, time.Hour)
+		if mock.ChallengeStatus(id) == lichess.DECLINE_LATER {
+			// the bot has not seen the end of the last game yet
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		i++
+		log.Printf("game %s: %s, winner %s, %d plies: %s", id, game.Status, game.Winner, len(game.Moves), strings.Join(game.Moves, " "))
+	}
+
+	cancel()
+	if err := <-stopped; err != nil {
+		log.Fatal(err)
+	}
+}
+
+func main() {
+	flag.Parse()
+
+	options := lichess.Options{
+		URL:          *urlFlag,
+		Token:        *tokenFlag,
+		Variants:     strings.Split(*variantsFlag, ","),
+		MaxGames:     *maxGamesFlag,
+		MoveOverhead: *moveOverheadFlag,
+		MoveTime:     *moveTimeFlag,
+		Threads:      *threadsFlag,
+		Log:          func(line string) { log.Println(line) },
+	}
+
+	if *mockFlag > 0 {
+		playMock(options)
+		return
+	}
+
+	if options.Token == "" {
+		log.Fatal("no api token given")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		log.Println("stopping, the games being played are abandoned")
+		cancel()
+	}()
+
+	if err := lichess.NewBot(options).Run(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
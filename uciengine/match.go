@@ -0,0 +1,140 @@
+package main
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/easychessanimations/gochess/match"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// flags
+
+var (
+	engine1Flag     = flag.String("engine1", "cmd=internal", "first player, the one scored, see usage")
+	engine2Flag     = flag.String("engine2", "cmd=internal", "second player, see usage")
+	variantFlag     = flag.String("variant", "standard", "variant of the games")
+	chess960Flag    = flag.Bool("chess960", false, "castling is written king takes rook and fen castling may use file letters")
+	openingsFlag    = flag.String("openings", "", "opening suite, pgn if the file name ends with .pgn, epd otherwise")
+	pliesFlag       = flag.Int("plies", 0, "plies of the pgn openings played, all if 0")
+	gamesFlag       = flag.Int("games", match.DEFAULT_GAMES, "number of games, each opening is played with both colors")
+	concurrencyFlag = flag.Int("concurrency", 1, "number of games played at the same time")
+	tcFlag          = flag.String("tc", "", "time control as moves/seconds+increment, as in 40/60, 10+0.1 or 300")
+	moveTimeFlag    = flag.Duration("movetime", 0, "fixed time per move, as in 100ms")
+	depthFlag       = flag.Int("depth", 0, "maximum search depth per move")
+	nodesFlag       = flag.Uint64("nodes", 0, "maximum nodes searched per move")
+	maxPliesFlag    = flag.Int("maxplies", match.DEFAULT_MAX_PLIES, "adjudicate a draw after this many plies, never if 0")
+	pgnOutFlag      = flag.String("pgnout", "", "append the games to this pgn file")
+	eventFlag       = flag.String("event", "gochess match", "pgn Event tag")
+	sprtFlag        = flag.Bool("sprt", false, "stop the match when the sprt of elo0 against elo1 is decided")
+	elo0Flag        = flag.Float64("elo0", match.DEFAULT_SPRT_ELO0, "sprt elo difference of H0")
+	elo1Flag        = flag.Float64("elo1", match.DEFAULT_SPRT_ELO1, "sprt elo difference of H1")
+	alphaFlag       = flag.Float64("alpha", match.DEFAULT_SPRT_ALPHA, "sprt false positive rate")
+	betaFlag        = flag.Float64("beta", match.DEFAULT_SPRT_BETA, "sprt false negative rate")
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// readOpenings reads the opening suite of the openings flag
+func readOpenings(variant utils.VariantKey) ([]match.Opening, error) {
+	if *openingsFlag == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(*openingsFlag)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.ToLower(filepath.Ext(*openingsFlag)) == ".pgn" {
+		return match.ReadPGNOpenings(f, variant, *pliesFlag)
+	}
+	return match.ReadEPDOpenings(f, variant)
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags]\n"+
+			"players are written as comma separated key=value pairs, as in\n"+
+			"  name=dev,cmd=internal,option.Threads=2\n"+
+			"  cmd=/usr/bin/stockfish,arg=-x,dir=/tmp,option.Hash=64\n"+
+			"cmd=internal plays the engine of this repository in process\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	variant := utils.VariantKeyStringToVariantKey(*variantFlag)
+
+	m := &match.Match{}
+
+	for i, spec := range []string{*engine1Flag, *engine2Flag} {
+		config, err := match.ParsePlayerConfig(spec)
+		if err != nil {
+			log.Fatalf("engine%d: %v", i+1, err)
+		}
+		m.Players[i] = config
+	}
+
+	tc, err := match.ParseTimeControl(*tcFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tc.MoveTime, tc.Depth, tc.Nodes = *moveTimeFlag, *depthFlag, *nodesFlag
+
+	openings, err := readOpenings(variant)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var pgnOut io.Writer
+	if *pgnOutFlag != "" {
+		f, err := os.OpenFile(*pgnOutFlag, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		pgnOut = f
+	}
+
+	m.Options = match.Options{
+		Variant:     variant,
+		Chess960:    *chess960Flag,
+		TimeControl: tc,
+		Openings:    openings,
+		Games:       *gamesFlag,
+		Concurrency: *concurrencyFlag,
+		MaxPlies:    *maxPliesFlag,
+		Event:       *eventFlag,
+		PGN:         pgnOut,
+		Log: func(line string) {
+			fmt.Printf("%s %s\n", time.Now().Format("15:04:05"), line)
+		},
+	}
+
+	if *sprtFlag {
+		m.Options.SPRT = &match.SPRT{Elo0: *elo0Flag, Elo1: *elo1Flag, Alpha: *alphaFlag, Beta: *betaFlag}
+		fmt.Printf("sprt %s\n", m.Options.SPRT)
+	}
+
+	if err := m.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,170 @@
+package main
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/easychessanimations/gochess/epd"
+	"github.com/easychessanimations/gochess/perft"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// flags
+
+var (
+	variantFlag  = flag.String("variant", "standard", "variant of the positions")
+	chess960Flag = flag.Bool("chess960", false, "castling is written king takes rook and fen castling may use file letters")
+	fenFlag      = flag.String("fen", "", "position to count, the start position of the variant if empty")
+	depthFlag    = flag.Int("depth", 4, "perft depth")
+	backendFlag  = flag.String("backend", perft.BACKEND_BITBOARD, "move generator, board (mailbox) or bitboard")
+	divideFlag   = flag.Bool("divide", false, "print the count below each root move")
+	hashFlag     = flag.Int("hash", perft.DEFAULT_HASH_SIZE_MB, "size of the perft hash in megabytes, no hashing if 0")
+	suiteFlag    = flag.String("suite", "", "epd perft suite to run, lines are fen ;D1 20 ;D2 400 ...")
+	diffFlag     = flag.Bool("diff", false, "compare the moves of the board and bitboard generators up to depth")
+	maxDiffsFlag = flag.Int("max-diffs", 10, "stop diffing after this many differences, never if 0")
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// newHashTable returns the perft hash requested by the flags, nil if hashing is disabled
+func newHashTable() *perft.HashTable {
+	if *hashFlag <= 0 {
+		return nil
+	}
+	return perft.NewHashTable(*hashFlag)
+}
+
+// runPerft counts or divides fen
+func runPerft(fen string, variant utils.VariantKey) error {
+	gen, err := perft.NewGenerator(*backendFlag, fen, variant, *chess960Flag)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	nodes := uint64(0)
+
+	if *divideFlag {
+		for _, entry := range perft.Divide(gen, *depthFlag, newHashTable()) {
+			fmt.Printf("%s %d\n", entry.Move, entry.Nodes)
+			nodes += entry.Nodes
+		}
+	} else {
+		nodes = perft.Count(gen, *depthFlag, newHashTable())
+	}
+
+	elapsed := time.Since(start)
+	fmt.Printf("perft %d nodes %d time %d nps %.0f\n", *depthFlag, nodes, elapsed.Milliseconds(), float64(nodes)/elapsed.Seconds())
+
+	return nil
+}
+
+// runSuite checks the counts of an epd suite up to depth, returns the number of failures
+func runSuite(path string, variant utils.VariantKey) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	records, err := epd.ReadEPD(f)
+	if err != nil {
+		return 0, err
+	}
+
+	ht := newHashTable()
+	failures := 0
+
+	for _, record := range records {
+		gen, err := perft.NewGenerator(*backendFlag, record.Fen, variant, *chess960Flag)
+		if err != nil {
+			return failures, err
+		}
+
+		for depth, expected := range record.Perft() {
+			if expected == 0 || depth+1 > *depthFlag {
+				continue
+			}
+
+			if nodes := perft.Count(gen, depth+1, ht); nodes != expected {
+				fmt.Printf("FAIL %s depth %d nodes %d expected %d\n", record.Fen, depth+1, nodes, expected)
+				failures++
+			} else {
+				fmt.Printf("ok   %s depth %d nodes %d\n", record.Fen, depth+1, nodes)
+			}
+		}
+	}
+
+	return failures, nil
+}
+
+// runDiff prints the positions where the board and bitboard generators disagree, returns their number
+func runDiff(fen string, variant utils.VariantKey) (int, error) {
+	mailbox := perft.NewBoardGenerator(fen, variant, *chess960Flag)
+
+	bitboard, err := perft.NewPositionGenerator(fen, variant, *chess960Flag)
+	if err != nil {
+		return 0, err
+	}
+
+	diffs := perft.Diff(mailbox, bitboard, *depthFlag, *maxDiffsFlag)
+	for _, diff := range diffs {
+		fmt.Printf("fen %s\n", diff.Fen)
+		fmt.Printf("  moves %s\n", strings.Join(diff.Path, " "))
+		fmt.Printf("  only %s %s\n", mailbox.Name(), strings.Join(diff.Extra, " "))
+		fmt.Printf("  only %s %s\n", bitboard.Name(), strings.Join(diff.Missing, " "))
+	}
+	fmt.Printf("%d differences up to depth %d\n", len(diffs), *depthFlag)
+
+	return len(diffs), nil
+}
+
+func main() {
+	flag.Parse()
+
+	variant := utils.VariantKeyStringToVariantKey(*variantFlag)
+
+	fen := *fenFlag
+	if fen == "" {
+		fen = utils.StartFenForVariant(variant)
+	}
+
+	switch {
+	case *suiteFlag != "":
+		failures, err := runSuite(*suiteFlag, variant)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if failures > 0 {
+			fmt.Printf("%d failures\n", failures)
+			os.Exit(1)
+		}
+	case *diffFlag:
+		diffs, err := runDiff(fen, variant)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if diffs > 0 {
+			os.Exit(1)
+		}
+	default:
+		if err := runPerft(fen, variant); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,49 @@
+package main
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/easychessanimations/gochess/server"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// flags
+
+var (
+	addrFlag          = flag.String("addr", "localhost:8080", "address to listen on")
+	allowOriginFlag   = flag.String("allow-origin", server.DEFAULT_OPTIONS.AllowOrigin, "Access-Control-Allow-Origin header for web front-ends, none if empty")
+	maxPerftDepthFlag = flag.Int("max-perft-depth", server.DEFAULT_OPTIONS.MaxPerftDepth, "deepest perft allowed, no limit if 0")
+	maxMoveTimeFlag   = flag.Duration("max-movetime", server.DEFAULT_OPTIONS.MaxMoveTime, "longest analysis, as in 30s, no limit if 0")
+	maxAnalysesFlag   = flag.Int("max-analyses", server.DEFAULT_OPTIONS.MaxAnalyses, "analyses running at the same time")
+	threadsFlag       = flag.Int("threads", server.DEFAULT_OPTIONS.Threads, "search threads of an analysis")
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+func main() {
+	flag.Parse()
+
+	s := server.NewServer(server.Options{
+		AllowOrigin:   *allowOriginFlag,
+		MaxPerftDepth: *maxPerftDepthFlag,
+		MaxMoveTime:   *maxMoveTimeFlag,
+		MaxAnalyses:   *maxAnalysesFlag,
+		Threads:       *threadsFlag,
+	})
+
+	log.Printf("serving the engine api on http://%s", *addrFlag)
+
+	log.Fatal(http.ListenAndServe(*addrFlag, s))
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,101 @@
+package main
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/easychessanimations/gochess/epd"
+	"github.com/easychessanimations/gochess/match"
+	"github.com/easychessanimations/gochess/suite"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// flags
+
+var (
+	suiteFlag      = flag.String("suite", "epd/suites/matein1.epd", "epd test suite to run")
+	engineFlag     = flag.String("engine", "cmd=internal", "player searching the positions, written as for the match command")
+	variantFlag    = flag.String("variant", "standard", "variant of the positions")
+	chess960Flag   = flag.Bool("chess960", false, "castling is written king takes rook and fen castling may use file letters")
+	moveTimeFlag   = flag.Duration("movetime", time.Second, "search time per position, as in 500ms, none if 0")
+	depthFlag      = flag.Int("depth", 0, "search depth per position, the acd of a position if 0")
+	nodesFlag      = flag.Uint64("nodes", 0, "maximum nodes searched per position")
+	perftDepthFlag = flag.Int("perft", 2, "deepest perft count of the positions checked, none if 0")
+	quietFlag      = flag.Bool("quiet", false, "print only the table, not the progress")
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// readSuite reads the records of the suite flag
+func readSuite() ([]epd.Record, error) {
+	f, err := os.Open(*suiteFlag)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return epd.ReadEPD(f)
+}
+
+func main() {
+	flag.Parse()
+
+	records, err := readSuite()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	config, err := match.ParsePlayerConfig(*engineFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	player, err := match.NewPlayer(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer player.Close()
+
+	options := suite.Options{
+		Variant:    utils.VariantKeyStringToVariantKey(*variantFlag),
+		Chess960:   *chess960Flag,
+		Limits:     match.Limits{MoveTime: *moveTimeFlag, Depth: *depthFlag, Nodes: *nodesFlag},
+		PerftDepth: *perftDepthFlag,
+	}
+
+	if !*quietFlag {
+		done := 0
+		options.Log = func(res suite.Result) {
+			done++
+			fmt.Printf("%d/%d %s %s %s\n", done, len(records), res.Record.Id(), res.Move, res.Score())
+		}
+	}
+
+	results, err := suite.Run(player, records, options)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := suite.WriteTable(os.Stdout, results); err != nil {
+		log.Fatal(err)
+	}
+
+	if suite.Summarize(results).Failed > 0 {
+		player.Close()
+		os.Exit(1)
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,117 @@
+package main
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/tablebase"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// flags
+
+var (
+	variantFlag = flag.String("variant", "standard", "variant of the tables")
+	dirFlag     = flag.String("dir", ".", "directory of the table files")
+	verifyFlag  = flag.Bool("verify", false, "check every position of the generated tables against its moves")
+	fenFlag     = flag.String("fen", "", "probe this position with the tables in dir instead of generating")
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// generate generates the tables of materials and the tables they depend on and saves them to dir
+func generate(variant utils.VariantKey, materials []string) error {
+	tb, err := tablebase.Open(*dirFlag)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range materials {
+		m, err := tablebase.ParseMaterial(s)
+		if err != nil {
+			return err
+		}
+		if _, err := tb.Generate(variant, m, os.Stdout); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range tb.Tables() {
+		if *verifyFlag {
+			if err := tb.Verify(t); err != nil {
+				return fmt.Errorf("%s: %v", t.Material, err)
+			}
+			fmt.Printf("verified %s\n", t.Material)
+		}
+		if err := t.Save(*dirFlag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// probe prints the outcome and the best move of fen
+func probe(variant utils.VariantKey, fen string) error {
+	tb, err := tablebase.Open(*dirFlag)
+	if err != nil {
+		return err
+	}
+
+	pos, err := butils.PositionFromFENAndVariant(fen, variant)
+	if err != nil {
+		return err
+	}
+
+	res, ok := tb.Probe(pos)
+	if !ok {
+		return fmt.Errorf("no table covers %s", fen)
+	}
+	fmt.Printf("wdl %d dtm %d\n", res.WDL, res.DTM)
+
+	if move, res, ok := tb.BestMove(pos, nil); ok {
+		fmt.Printf("bestmove %s wdl %d dtm %d\n", pos.MoveToUCI(move), res.WDL, res.DTM)
+	}
+
+	return nil
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] material...\nmaterials are written like KQvK or KJvKP\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	variant := utils.VariantKeyStringToVariantKey(*variantFlag)
+
+	if *fenFlag != "" {
+		if err := probe(variant, *fenFlag); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := generate(variant, flag.Args()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+/////////////////////////////////////////////////////////////////////
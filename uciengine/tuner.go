@@ -0,0 +1,122 @@
+package main
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/easychessanimations/gochess/bengine"
+	"github.com/easychessanimations/gochess/pgn"
+	"github.com/easychessanimations/gochess/tuner"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// flags
+
+var (
+	variantFlag    = flag.String("variant", "standard", "variant of the positions, games of other variants are skipped")
+	outFlag        = flag.String("out", "weights.go", "regenerated weights file, replaces bengine/weights.go")
+	iterationsFlag = flag.Int("iterations", tuner.DEFAULT_ITERATIONS, "number of passes over the training set")
+	rateFlag       = flag.Float64("rate", tuner.DEFAULT_RATE, "learning rate")
+	kFlag          = flag.Float64("k", 0, "scaling of the evaluation, fitted to the data if 0")
+	validationFlag = flag.Float64("validation", tuner.DEFAULT_VALIDATION, "fraction of the positions held out for validation")
+	threadsFlag    = flag.Int("threads", 0, "number of threads, all cpus if 0")
+	skipFlag       = flag.Int("skip", tuner.DEFAULT_SKIP_PLIES, "number of opening plies left out of pgn games")
+	seedFlag       = flag.Int64("seed", 1, "seed used to split the positions")
+	logEveryFlag   = flag.Int("log-every", 10, "log the errors every n iterations")
+	zeroFlag       = flag.Bool("zero", false, "start from zero weights instead of the current ones")
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// readSamples reads labelled positions from a pgn file or, for any other extension, from an epd file
+func readSamples(path string, variant utils.VariantKey) ([]tuner.Sample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.ToLower(filepath.Ext(path)) != ".pgn" {
+		return tuner.ReadEPD(f, variant)
+	}
+
+	games, err := pgn.Parse(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return tuner.SamplesFromGames(games, variant, *skipFlag)
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] file.epd|file.pgn ...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	variant := utils.VariantKeyStringToVariantKey(*variantFlag)
+
+	samples := []tuner.Sample{}
+	for _, path := range flag.Args() {
+		fileSamples, err := readSamples(path, variant)
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+		log.Printf("read %d positions from %s", len(fileSamples), path)
+		samples = append(samples, fileSamples...)
+	}
+
+	weights := bengine.Weights
+	if *zeroFlag {
+		weights = make([]bengine.Score, len(bengine.Weights))
+	}
+
+	t := tuner.NewTuner(samples, weights, tuner.Options{
+		Iterations: *iterationsFlag,
+		Rate:       *rateFlag,
+		K:          *kFlag,
+		Validation: *validationFlag,
+		Threads:    *threadsFlag,
+		Seed:       *seedFlag,
+		LogEvery:   *logEveryFlag,
+		Log: func(content string) {
+			log.Println(content)
+		},
+	})
+
+	if t.Options.K == 0 {
+		log.Printf("fitted K %.4f", t.FitK())
+	}
+
+	train, validate := t.Errors()
+	log.Printf("tuning %d weights on %d positions, %d held out", len(weights), len(t.Train), len(t.Validate))
+	log.Printf("initial train error %.8f validation error %.8f", train, validate)
+
+	t.Tune()
+
+	if err := tuner.SaveWeights(*outFlag, t.Weights(), t.TrainError, t.ValidError); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %s", *outFlag)
+}
+
+/////////////////////////////////////////////////////////////////////
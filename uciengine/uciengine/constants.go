@@ -12,4 +12,20 @@ const ENGINE_NAME = "gochess"
 const ENGINE_DESCRIPTION = "multi variant multi platform uci engine"
 const ENGINE_AUTHOR = "easychessanimations"
 
+// GO_ARGUMENTS are the arguments of the go command, they terminate the move list of searchmoves
+var GO_ARGUMENTS = map[string]bool{
+	"searchmoves": true,
+	"ponder":      true,
+	"wtime":       true,
+	"btime":       true,
+	"winc":        true,
+	"binc":        true,
+	"movestogo":   true,
+	"depth":       true,
+	"nodes":       true,
+	"mate":        true,
+	"movetime":    true,
+	"infinite":    true,
+}
+
 /////////////////////////////////////////////////////////////////////
@@ -5,6 +5,8 @@ package uciengine
 
 import (
 	minboard "github.com/easychessanimations/gochess/bboard"
+	"github.com/easychessanimations/gochess/bengine"
+	"github.com/easychessanimations/gochess/butils"
 )
 
 /////////////////////////////////////////////////////////////////////
@@ -20,4 +22,12 @@ type UciEngine struct {
 	Interactive bool
 }
 
+// GoCommand holds the arguments of the UCI go command
+type GoCommand struct {
+	TimeControl *bengine.TimeControl
+	RootMoves   []butils.Move
+	Ponder      bool
+	Infinite    bool
+}
+
 /////////////////////////////////////////////////////////////////////
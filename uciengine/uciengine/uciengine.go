@@ -9,8 +9,11 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	minboard "github.com/easychessanimations/gochess/bboard"
+	"github.com/easychessanimations/gochess/bengine"
+	"github.com/easychessanimations/gochess/butils"
 	"github.com/easychessanimations/gochess/utils"
 )
 
@@ -199,36 +202,119 @@ func (eng *UciEngine) ListUci() {
 	}
 }
 
-func (eng *UciEngine) Go(args []string) {
-	depth := minboard.DEFAULT_SEARCH_DEPTH
+// hasGoValue tells whether the go argument at i is followed by a value, that is a token which is not a go argument
+func hasGoValue(args []string, i int) bool {
+	return i+1 < len(args) && !GO_ARGUMENTS[args[i+1]]
+}
 
-	for len(args) > 0 {
-		if args[0] == "depth" {
-			if len(args) > 1 {
-				parseDepth, err := strconv.ParseInt(args[1], 10, 32)
-				if err != nil {
-					fmt.Printf("invalid depth argument to go command, assuming depth %d\n", depth)
+// parseGoInt parses the value of a numeric go argument, reports and returns false if it is missing or invalid
+func parseGoInt(args []string, i int) (int64, bool) {
+	if !hasGoValue(args, i) {
+		fmt.Printf("missing value for %s argument to go command\n", args[i])
+		return 0, false
+	}
+
+	value, err := strconv.ParseInt(args[i+1], 10, 64)
+	if err != nil || value < 0 {
+		fmt.Printf("invalid value %s for %s argument to go command\n", args[i+1], args[i])
+		return 0, false
+	}
+
+	return value, true
+}
+
+// ParseGo parses the arguments of the UCI go command, invalid arguments are reported and ignored
+func (eng *UciEngine) ParseGo(args []string) GoCommand {
+	tc := bengine.NewTimeControl(eng.Board.Pos, false)
+	rootMoves := []butils.Move{}
+	ponder := false
+	infinite := false
+	limited := false
+
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "searchmoves":
+			for i+1 < len(args) && !GO_ARGUMENTS[args[i+1]] {
+				i++
+				if move := eng.Board.AlgebToMove(args[i]); move != butils.NullMove {
+					rootMoves = append(rootMoves, move)
 				} else {
-					depth = int(parseDepth)
+					fmt.Printf("illegal move %s in searchmoves, ignoring\n", args[i])
 				}
-				args = args[2:]
-			} else {
-				fmt.Printf("empty depth argument to go command, assuming depth %d\n", depth)
-				args = args[1:]
 			}
-		} else {
-			args = args[1:]
+		case "ponder":
+			ponder = true
+		case "infinite":
+			infinite = true
+		case "wtime", "btime", "winc", "binc", "movestogo", "movetime", "depth", "nodes", "mate":
+			value, ok := parseGoInt(args, i)
+			if hasGoValue(args, i) {
+				// skip the value even if it is invalid
+				i++
+			}
+			if !ok {
+				continue
+			}
+			limited = true
+			duration := time.Duration(value) * time.Millisecond
+			switch arg {
+			case "wtime":
+				tc.WTime = duration
+			case "btime":
+				tc.BTime = duration
+			case "winc":
+				tc.WInc = duration
+			case "binc":
+				tc.BInc = duration
+			case "movestogo":
+				tc.MovesToGo = int32(value)
+			case "movetime":
+				tc.WTime, tc.WInc = duration, 0
+				tc.BTime, tc.BInc = duration, 0
+				tc.MovesToGo = 1
+			case "depth":
+				tc.Depth = int32(value)
+			case "nodes":
+				tc.Nodes = uint64(value)
+			case "mate":
+				tc.Mate = int32(value)
+			}
+		default:
+			fmt.Printf("unknown go argument %s, ignoring\n", arg)
 		}
 	}
 
-	go eng.Board.Go(depth)
+	if !limited && !infinite && !ponder {
+		// a bare go searches to the default depth
+		tc.Depth = minboard.DEFAULT_SEARCH_DEPTH
+	}
+
+	return GoCommand{
+		TimeControl: tc,
+		RootMoves:   rootMoves,
+		Ponder:      ponder,
+		Infinite:    infinite,
+	}
+}
+
+// Go starts a search in the background, accepts all the arguments of the UCI go command
+func (eng *UciEngine) Go(args []string) {
+	gc := eng.ParseGo(args)
+
+	eng.Board.StartSearch(gc.TimeControl, gc.RootMoves, gc.Ponder, gc.Infinite)
 }
 
 func (eng *UciEngine) ExecuteUciCommand(command string) {
 	if (command == "stop") || (command == "s") {
 		eng.Stop()
+	} else if command == "ponderhit" {
+		eng.Board.PonderHit()
 	} else if command == "uci" {
 		eng.Uci()
+	} else if command == "isready" {
+		fmt.Println("readyok")
+	} else if command == "ucinewgame" {
+		eng.Board.ResetVariantFromUciOption()
 	} else if command == "l" {
 		eng.ListUci()
 	} else if command == "i" {
@@ -269,7 +355,12 @@ func (eng *UciEngine) UciLoop() {
 	reader := bufio.NewReader(os.Stdin)
 
 	for {
-		text, _ := reader.ReadString('\n')
+		text, err := reader.ReadString('\n')
+
+		if err != nil && text == "" {
+			// stdin closed
+			break
+		}
 
 		command := strings.Trim(text, "\r\n")
 
@@ -0,0 +1,107 @@
+package uciengine
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	minboard "github.com/easychessanimations/gochess/bboard"
+	"github.com/easychessanimations/gochess/bengine"
+)
+
+var GO_TESTS = []struct {
+	args      string
+	set       func(tc *bengine.TimeControl) // changes of the default time control
+	rootMoves []string
+	ponder    bool
+	infinite  bool
+	report    string // what is printed about invalid arguments
+}{
+	{"", func(tc *bengine.TimeControl) { tc.Depth = minboard.DEFAULT_SEARCH_DEPTH }, nil, false, false, ""},
+	{"movetime 1000", func(tc *bengine.TimeControl) {
+		tc.WTime, tc.BTime, tc.MovesToGo = time.Second, time.Second, 1
+	}, nil, false, false, ""},
+	{"wtime 60000 btime 50000 winc 1000 binc 500 movestogo 20", func(tc *bengine.TimeControl) {
+		tc.WTime, tc.BTime, tc.WInc, tc.BInc, tc.MovesToGo = time.Minute, 50*time.Second, time.Second, 500*time.Millisecond, 20
+	}, nil, false, false, ""},
+	{"depth 5", func(tc *bengine.TimeControl) { tc.Depth = 5 }, nil, false, false, ""},
+	{"nodes 10000", func(tc *bengine.TimeControl) { tc.Nodes = 10000 }, nil, false, false, ""},
+	{"mate 3", func(tc *bengine.TimeControl) { tc.Mate = 3 }, nil, false, false, ""},
+	{"infinite", func(tc *bengine.TimeControl) {}, nil, false, true, ""},
+	{"ponder wtime 1000 btime 2000", func(tc *bengine.TimeControl) {
+		tc.WTime, tc.BTime = time.Second, 2*time.Second
+	}, nil, true, false, ""},
+	{"searchmoves e2e4 d2d4 depth 3", func(tc *bengine.TimeControl) { tc.Depth = 3 }, []string{"e2e4", "d2d4"}, false, false, ""},
+	// illegal root moves are ignored
+	{"searchmoves e2e4 e2e5 infinite", func(tc *bengine.TimeControl) {}, []string{"e2e4"}, false, true, "illegal move e2e5 in searchmoves, ignoring"},
+	// invalid values are skipped, the search is not limited by them
+	{"wtime abc btime 2000", func(tc *bengine.TimeControl) { tc.BTime = 2 * time.Second }, nil, false, false, "invalid value abc for wtime argument to go command"},
+	{"depth -3 nodes 500", func(tc *bengine.TimeControl) { tc.Nodes = 500 }, nil, false, false, "invalid value -3 for depth argument to go command"},
+	{"depth abc", func(tc *bengine.TimeControl) { tc.Depth = minboard.DEFAULT_SEARCH_DEPTH }, nil, false, false, "invalid value abc for depth argument to go command"},
+	// a missing value does not swallow the next argument
+	{"wtime btime 2000", func(tc *bengine.TimeControl) { tc.BTime = 2 * time.Second }, nil, false, false, "missing value for wtime argument to go command"},
+	{"depth", func(tc *bengine.TimeControl) { tc.Depth = minboard.DEFAULT_SEARCH_DEPTH }, nil, false, false, "missing value for depth argument to go command"},
+	{"movetime 1000 foo", func(tc *bengine.TimeControl) {
+		tc.WTime, tc.BTime, tc.MovesToGo = time.Second, time.Second, 1
+	}, nil, false, false, "unknown go argument foo, ignoring"},
+}
+
+// captureStdout returns what f prints to stdout
+func captureStdout(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+
+	f()
+
+	os.Stdout = stdout
+	w.Close()
+
+	out, _ := ioutil.ReadAll(r)
+
+	return string(out)
+}
+
+func TestParseGo(t *testing.T) {
+	eng := UciEngine{}
+	eng.Init()
+	eng.Board.LogFunc = func(string) {}
+
+	for _, test := range GO_TESTS {
+		var gc GoCommand
+		report := captureStdout(t, func() {
+			gc = eng.ParseGo(strings.Fields(test.args))
+		})
+
+		if strings.TrimSpace(report) != test.report {
+			t.Errorf("go %s: reported %q, expected %q", test.args, report, test.report)
+		}
+
+		expected := bengine.NewTimeControl(eng.Board.Pos, false)
+		test.set(expected)
+
+		if !reflect.DeepEqual(gc.TimeControl, expected) {
+			t.Errorf("go %s: time control %+v, expected %+v", test.args, gc.TimeControl, expected)
+		}
+
+		rootMoves := []string{}
+		for _, move := range gc.RootMoves {
+			rootMoves = append(rootMoves, eng.Board.Pos.MoveToUCI(move))
+		}
+
+		if strings.Join(rootMoves, " ") != strings.Join(test.rootMoves, " ") {
+			t.Errorf("go %s: root moves %v, expected %v", test.args, rootMoves, test.rootMoves)
+		}
+
+		if gc.Ponder != test.ponder || gc.Infinite != test.infinite {
+			t.Errorf("go %s: ponder %v infinite %v, expected %v %v", test.args, gc.Ponder, gc.Infinite, test.ponder, test.infinite)
+		}
+	}
+}
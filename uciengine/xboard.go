@@ -0,0 +1,23 @@
+package main
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"os"
+
+	"github.com/easychessanimations/gochess/xboard"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+func main() {
+	xb := xboard.NewXBoardEngine(os.Stdout)
+
+	xb.Loop(os.Stdin)
+}
+
+/////////////////////////////////////////////////////////////////////
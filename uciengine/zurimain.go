@@ -22,6 +22,7 @@ import (
 	"time"
 
 	. "github.com/easychessanimations/gochess/bengine"
+	"github.com/easychessanimations/gochess/book"
 	. "github.com/easychessanimations/gochess/butils"
 )
 
@@ -30,6 +31,7 @@ var errQuit = errors.New("quit")
 const (
 	maxMultiPV       = 16
 	maxHandicapLevel = 20
+	defaultBookFile  = "book.bin"
 )
 
 // uciLogger outputs search in uci format.
@@ -116,6 +118,9 @@ type UCI struct {
 	predicted uint64
 	// root moves to search; empty to search all of them.
 	rootMoves []Move
+	// path of the opening book loaded when OwnBook is set.
+	bookFile   string
+	bookLoader book.Loader
 }
 
 func NewUCI() *UCI {
@@ -125,6 +130,7 @@ func NewUCI() *UCI {
 		timeControl: nil,
 		idle:        make(chan struct{}, 1),
 		ponder:      make(chan struct{}, 1),
+		bookFile:    defaultBookFile,
 	}
 }
 
@@ -199,9 +205,15 @@ func (uci *UCI) uci(line string) error {
 	fmt.Printf("\n")
 	fmt.Printf("option name Hash type spin default %v min 1 max 65536\n", DefaultHashTableSizeMB)
 	fmt.Printf("option name MultiPV type spin default %d min 1 max %d\n", uci.Engine.Options.MultiPV, maxMultiPV)
+	fmt.Printf("option name Threads type spin default 1 min 1 max %d\n", MaxThreads)
 	fmt.Printf("option name Ponder type check default true\n")
 	fmt.Printf("option name Handicap Level type spin default %d min 0 max %d\n", uci.Engine.Options.HandicapLevel, maxHandicapLevel)
 	fmt.Printf("option name UCI_AnalyseMode type check default false\n")
+	fmt.Printf("option name OwnBook type check default %v\n", uci.Engine.Options.OwnBook)
+	fmt.Printf("option name BookFile type string default %s\n", defaultBookFile)
+	fmt.Printf("option name BookBestMove type check default %v\n", uci.Engine.Options.BookBestMove)
+	fmt.Printf("option name RandomBonus type check default %v\n", RandomBonus)
+	fmt.Printf("option name TablebasePath type string default <empty>\n")
 	fmt.Println("uciok")
 	return nil
 }
@@ -275,8 +287,21 @@ var validGoCommands = map[string]bool{
 	"infinite":    true,
 }
 
+// goValueCommands are the go commands followed by a value
+var goValueCommands = map[string]bool{
+	"wtime":     true,
+	"winc":      true,
+	"btime":     true,
+	"binc":      true,
+	"movestogo": true,
+	"movetime":  true,
+	"depth":     true,
+	"nodes":     true,
+	"mate":      true,
+	"perft":     true,
+}
+
 func (uci *UCI) go_(line string) error {
-	// TODO: Handle panic for `go depth`
 	predicted := uci.predicted == uci.Engine.Position.Zobrist()
 	uci.timeControl = NewTimeControl(uci.Engine.Position, predicted)
 	uci.rootMoves = uci.rootMoves[:0]
@@ -285,6 +310,10 @@ func (uci *UCI) go_(line string) error {
 
 	args := strings.Fields(line)[1:]
 	for i := 0; i < len(args); i++ {
+		if goValueCommands[args[i]] && i+1 >= len(args) {
+			return fmt.Errorf("missing value for go command %s", args[i])
+		}
+
 		switch args[i] {
 		case "searchmoves":
 			for j := i + 1; j < len(args) && !validGoCommands[args[j]]; j++ {
@@ -331,9 +360,14 @@ func (uci *UCI) go_(line string) error {
 			i++
 			d, _ := strconv.Atoi(args[i])
 			uci.timeControl.Depth = int32(d)
-		case "nodes", "mate":
-			log.Println(args[i], "not implemented. Ignoring")
+		case "nodes":
 			i++
+			n, _ := strconv.ParseUint(args[i], 10, 64)
+			uci.timeControl.Nodes = n
+		case "mate":
+			i++
+			m, _ := strconv.Atoi(args[i])
+			uci.timeControl.Mate = int32(m)
 		case "perft":
 			i++
 			perft, _ = strconv.Atoi(args[i])
@@ -348,6 +382,15 @@ func (uci *UCI) go_(line string) error {
 		return nil
 	}
 
+	if uci.Engine.Options.OwnBook {
+		// The book is loaded on first use so that OwnBook and BookFile can be set in any order.
+		bk, err := uci.bookLoader.Load(uci.bookFile)
+		if err != nil {
+			log.Println(err)
+		}
+		uci.Engine.Book = bk
+	}
+
 	if ponder {
 		// Ponder was requested, so fill the channel.
 		// Next write to uci.ponder will block.
@@ -388,6 +431,10 @@ func (uci *UCI) stop(line string) error {
 func (uci *UCI) play() {
 	_, moves := uci.Engine.PlayMoves(uci.timeControl, uci.rootMoves)
 
+	if uci.timeControl.Mate > 0 {
+		fmt.Printf("info string %s\n", uci.Engine.LastMate.Summary(uci.Engine.Position, uci.timeControl.Mate))
+	}
+
 	if len(moves) >= 2 {
 		uci.Engine.Position.DoMove(moves[0])
 		uci.Engine.Position.DoMove(moves[1])
@@ -464,6 +511,15 @@ func (uci *UCI) setoption(line string) error {
 			return fmt.Errorf("MultiPV must be between 1 and %d", maxMultiPV)
 		}
 		return nil
+	case "Threads":
+		if threads, err := strconv.ParseInt(option[3], 10, 64); err != nil {
+			return err
+		} else if 1 <= threads && threads <= MaxThreads {
+			uci.Engine.Options.Threads = int(threads)
+		} else {
+			return fmt.Errorf("Threads must be between 1 and %d", MaxThreads)
+		}
+		return nil
 	case "Handicap Level":
 		if handicap, err := strconv.ParseInt(option[3], 10, 64); err != nil {
 			return err
@@ -473,6 +529,36 @@ func (uci *UCI) setoption(line string) error {
 			return fmt.Errorf("Handicap Level must be between 0 and %d", maxHandicapLevel)
 		}
 		return nil
+	case "OwnBook":
+		if ownBook, err := strconv.ParseBool(option[3]); err != nil {
+			return err
+		} else {
+			uci.Engine.Options.OwnBook = ownBook
+		}
+		return nil
+	case "BookFile":
+		uci.bookFile = option[3]
+		return nil
+	case "BookBestMove":
+		if bestMove, err := strconv.ParseBool(option[3]); err != nil {
+			return err
+		} else {
+			uci.Engine.Options.BookBestMove = bestMove
+		}
+		return nil
+	case "RandomBonus":
+		if randomBonus, err := strconv.ParseBool(option[3]); err != nil {
+			return err
+		} else {
+			RandomBonus = randomBonus
+		}
+		return nil
+	case "TablebasePath":
+		path := option[3]
+		if path == "<empty>" {
+			path = ""
+		}
+		return uci.Engine.SetTablebasePath(path)
 	case "Ponder":
 		return nil
 	default:
@@ -595,13 +681,21 @@ func (uci *UCI) MakeMoveByIndex(cmd string) error {
 		if cmd != "" {
 			// try to look up move by notation
 			for testI, lmbi := range lmb {
-				if (lmbi.San == cmd) || (lmbi.Algeb == cmd) {
+				if lmbi.Algeb == cmd {
 					foundMoveIndex = testI
 				}
 			}
 			if foundMoveIndex < 0 {
-				fmt.Println("illegal move")
-				return nil
+				move, err := pos.SanToMove(cmd)
+				if err != nil {
+					fmt.Println(err)
+					return nil
+				}
+				for testI, lmbi := range lmb {
+					if lmbi.Move == move {
+						foundMoveIndex = testI
+					}
+				}
 			}
 		}
 
@@ -0,0 +1,89 @@
+package utils
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// https://en.wikipedia.org/wiki/Fischer_random_chess_numbering_scheme
+
+// Chess960BackRank returns the white back rank of Chess960 start position number n ( 0 - 959 )
+// position 518 is the standard start position RNBQKBNR
+func Chess960BackRank(n int) string {
+	n = ((n % CHESS960_NUM_POSITIONS) + CHESS960_NUM_POSITIONS) % CHESS960_NUM_POSITIONS
+
+	rank := make([]byte, 8)
+
+	// light squared bishop
+	rank[(n%4)*2+1] = 'B'
+	n /= 4
+
+	// dark squared bishop
+	rank[(n%4)*2] = 'B'
+	n /= 4
+
+	// queen on the nth empty square
+	placeOnEmpty(rank, n%6, 'Q')
+	n /= 6
+
+	// knights, remove the second knight first so that the first index stays valid
+	knights := CHESS960_KNIGHT_PLACEMENTS[n]
+	placeOnEmpty(rank, knights[1], 'x')
+	placeOnEmpty(rank, knights[0], 'N')
+	for i, piece := range rank {
+		if piece == 'x' {
+			rank[i] = 'N'
+		}
+	}
+
+	// king between the rooks
+	placeOnEmpty(rank, 0, 'R')
+	placeOnEmpty(rank, 0, 'K')
+	placeOnEmpty(rank, 0, 'R')
+
+	return string(rank)
+}
+
+// placeOnEmpty puts piece on the nth empty square of rank
+func placeOnEmpty(rank []byte, n int, piece byte) {
+	for i := range rank {
+		if rank[i] == 0 {
+			if n == 0 {
+				rank[i] = piece
+				return
+			}
+			n--
+		}
+	}
+}
+
+// DoubleFischerRandomStartFen returns the start fen having white back rank number white and black back rank number black
+func DoubleFischerRandomStartFen(white int, black int) string {
+	return fmt.Sprintf("%s/pppppppp/8/8/8/8/PPPPPPPP/%s w KQkq - 0 1", strings.ToLower(Chess960BackRank(black)), Chess960BackRank(white))
+}
+
+// Chess960StartFen returns the start fen of Chess960 position number n
+func Chess960StartFen(n int) string {
+	return DoubleFischerRandomStartFen(n, n)
+}
+
+// RandomChess960StartFen returns a random Chess960 start fen
+func RandomChess960StartFen() string {
+	return Chess960StartFen(rand.Intn(CHESS960_NUM_POSITIONS))
+}
+
+// RandomDoubleFischerRandomStartFen returns a random Double Fischer Random start fen
+func RandomDoubleFischerRandomStartFen() string {
+	return DoubleFischerRandomStartFen(rand.Intn(CHESS960_NUM_POSITIONS), rand.Intn(CHESS960_NUM_POSITIONS))
+}
+
+/////////////////////////////////////////////////////////////////////
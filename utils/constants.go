@@ -3,6 +3,11 @@ package utils
 /////////////////////////////////////////////////////////////////////
 // imports
 
+import (
+	"errors"
+	"regexp"
+)
+
 /////////////////////////////////////////////////////////////////////
 
 /////////////////////////////////////////////////////////////////////
@@ -83,6 +88,13 @@ var PIECE_LETTER_TO_PIECE_KIND = map[string]PieceKind{
 
 const STANDARD_START_FEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
 
+const CHESS960_NUM_POSITIONS = 960
+
+// knight placements on the five squares left empty after placing bishops and queen
+var CHESS960_KNIGHT_PLACEMENTS = [10][2]int{
+	{0, 1}, {0, 2}, {0, 3}, {0, 4}, {1, 2}, {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4},
+}
+
 var START_FENS = map[VariantKey]string{
 	VARIANT_STANDARD:   STANDARD_START_FEN,
 	VARIANT_ATOMIC:     "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
@@ -157,18 +169,20 @@ var LANCER_DIRECTIONS = []PieceDirection{
 	PieceDirection{-1, -1},
 }
 
+var KNIGHT_DIRECTIONS = []PieceDirection{
+	PieceDirection{1, 2},
+	PieceDirection{-1, 2},
+	PieceDirection{1, -2},
+	PieceDirection{-1, -2},
+	PieceDirection{2, 1},
+	PieceDirection{-2, 1},
+	PieceDirection{2, -1},
+	PieceDirection{-2, -1},
+}
+
 var PIECE_KIND_TO_PIECE_DESCRIPTOR = map[PieceKind]PieceDescriptor{
 	Knight: PieceDescriptor{
-		Directions: []PieceDirection{
-			PieceDirection{1, 2},
-			PieceDirection{-1, 2},
-			PieceDirection{1, -2},
-			PieceDirection{-1, -2},
-			PieceDirection{2, 1},
-			PieceDirection{-2, 1},
-			PieceDirection{2, -1},
-			PieceDirection{-2, -1},
-		},
+		Directions:          KNIGHT_DIRECTIONS,
 		Sliding:             false,
 		CanJumpOverOwnPiece: true,
 		CanCapture:          true,
@@ -225,6 +239,30 @@ var PIECE_KIND_TO_PIECE_DESCRIPTOR = map[PieceKind]PieceDescriptor{
 		CanJumpOverOwnPiece: false,
 		CanCapture:          true,
 	},
+	Hawk: PieceDescriptor{
+		Directions: []PieceDirection{
+			PieceDirection{1, 1},
+			PieceDirection{1, -1},
+			PieceDirection{-1, 1},
+			PieceDirection{-1, -1},
+		},
+		LeaperDirections:    KNIGHT_DIRECTIONS,
+		Sliding:             true,
+		CanJumpOverOwnPiece: false,
+		CanCapture:          true,
+	},
+	Elephant: PieceDescriptor{
+		Directions: []PieceDirection{
+			PieceDirection{1, 0},
+			PieceDirection{-1, 0},
+			PieceDirection{0, 1},
+			PieceDirection{0, -1},
+		},
+		LeaperDirections:    KNIGHT_DIRECTIONS,
+		Sliding:             true,
+		CanJumpOverOwnPiece: false,
+		CanCapture:          true,
+	},
 	Sentry: PieceDescriptor{
 		Directions: []PieceDirection{
 			PieceDirection{1, 1},
@@ -257,4 +295,14 @@ var PIECE_KIND_TO_PIECE_DESCRIPTOR = map[PieceKind]PieceDescriptor{
 
 const MAX_PIECE_KINDS = 20
 
+// piece letter, from file and rank, capture, to square, promotion or lancer direction, sentry push square
+var SAN_REGEXP = regexp.MustCompile(`^([A-Z])?([a-h])?([1-8])?[x:-]?([a-h])([1-8])(?:=?([A-Za-z]+))?(?:@([a-h])([1-8]))?$`)
+
+// seirawan gating piece letter and optional gating square
+var SAN_GATING_REGEXP = regexp.MustCompile(`^([A-Z])(?:([a-h])([1-8]))?$`)
+
+var ErrInvalidSan = errors.New("invalid san")
+var ErrIllegalMove = errors.New("illegal move")
+var ErrAmbiguousMove = errors.New("ambiguous move")
+
 /////////////////////////////////////////////////////////////////////
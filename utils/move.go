@@ -24,6 +24,10 @@ func (m *Move) IsPromotion() bool {
 	return m.PromotionPiece != NO_PIECE
 }
 
+func (m *Move) IsGating() bool {
+	return m.GatingPiece != NO_PIECE
+}
+
 func (m *Move) RoughlyEqualTo(testm Move) bool {
 	return m.FromSq.EqualTo(testm.FromSq) && m.ToSq.EqualTo(testm.ToSq)
 }
@@ -0,0 +1,182 @@
+package utils
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"fmt"
+	"strings"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Matches tells whether sm denotes the generated move gen, made from fromFile and fromRank
+// parts missing from sm match anything, except for gating which has to be given explicitly
+func (sm SanMove) Matches(gen SanMove, fromFile int8, fromRank int8) bool {
+	if sm.Castling != gen.Castling {
+		return false
+	}
+
+	if sm.Gating != gen.Gating {
+		return false
+	}
+
+	if sm.GatingFile >= 0 && gen.GatingFile >= 0 && (sm.GatingFile != gen.GatingFile || sm.GatingRank != gen.GatingRank) {
+		return false
+	}
+
+	if sm.Castling {
+		return sm.CastlingSide == gen.CastlingSide
+	}
+
+	if (sm.Piece != gen.Piece && !sm.Long) || sm.ToFile != gen.ToFile || sm.ToRank != gen.ToRank {
+		return false
+	}
+
+	if (sm.FromFile >= 0 && sm.FromFile != fromFile) || (sm.FromRank >= 0 && sm.FromRank != fromRank) {
+		return false
+	}
+
+	if sm.Promotion != "" && sm.Promotion != gen.Promotion {
+		return false
+	}
+
+	if sm.PushFile >= 0 && (sm.PushFile != gen.PushFile || sm.PushRank != gen.PushRank) {
+		return false
+	}
+
+	return true
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// NewSanMove returns a SanMove with no squares given
+func NewSanMove() SanMove {
+	return SanMove{
+		FromFile:   -1,
+		FromRank:   -1,
+		ToFile:     -1,
+		ToRank:     -1,
+		PushFile:   -1,
+		PushRank:   -1,
+		GatingFile: -1,
+		GatingRank: -1,
+	}
+}
+
+// ParseSan breaks san into its parts
+// tolerates check and mate marks, annotations, e.p., zero castling, missing = and long algebraic notation
+// long algebraic notation may omit the piece letter, like g1f3, then the from square decides the piece
+func ParseSan(san string) (SanMove, error) {
+	sm := NewSanMove()
+
+	str := strings.TrimRight(strings.TrimSpace(san), "+#!?")
+
+	for _, ep := range []string{"e.p.", "ep"} {
+		if trimmed := strings.TrimSpace(strings.TrimSuffix(str, ep)); len(trimmed) > 0 && len(trimmed) < len(str) && strings.ContainsAny(trimmed[len(trimmed)-1:], "12345678") {
+			str = trimmed
+		}
+	}
+
+	str = strings.TrimRight(str, "+#!?")
+
+	if index := strings.Index(str, "/"); index >= 0 {
+		parts := SAN_GATING_REGEXP.FindStringSubmatch(str[index+1:])
+		if parts == nil {
+			return sm, fmt.Errorf("%w %s", ErrInvalidSan, san)
+		}
+
+		sm.Gating = parts[1]
+
+		if parts[2] != "" {
+			sm.GatingFile, sm.GatingRank = fileRankFromAlgeb(parts[2], parts[3])
+		}
+
+		str = str[:index]
+	}
+
+	castling := strings.Map(func(r rune) rune {
+		switch r {
+		case '0', 'o', 'O':
+			return 'O'
+		case '-':
+			return -1
+		}
+		return r
+	}, str)
+
+	if castling == "OO" || castling == "OOO" {
+		sm.Castling = true
+		sm.CastlingSide = KING_SIDE
+		if castling == "OOO" {
+			sm.CastlingSide = QUEEN_SIDE
+		}
+		return sm, nil
+	}
+
+	parts := SAN_REGEXP.FindStringSubmatch(str)
+	if parts == nil {
+		return sm, fmt.Errorf("%w %s", ErrInvalidSan, san)
+	}
+
+	sm.Piece = parts[1]
+
+	if parts[2] != "" {
+		sm.FromFile, _ = fileRankFromAlgeb(parts[2], "")
+	}
+
+	if parts[3] != "" {
+		_, sm.FromRank = fileRankFromAlgeb("", parts[3])
+	}
+
+	sm.Long = sm.Piece == "" && sm.FromFile >= 0 && sm.FromRank >= 0
+
+	sm.Capture = strings.ContainsAny(str, "x:")
+
+	sm.ToFile, sm.ToRank = fileRankFromAlgeb(parts[4], parts[5])
+
+	if parts[6] != "" {
+		sm.Promotion = normalizePromotion(parts[6], sm.Piece)
+	}
+
+	if parts[7] != "" {
+		sm.PushFile, sm.PushRank = fileRankFromAlgeb(parts[7], parts[8])
+	}
+
+	return sm, nil
+}
+
+// normalizePromotion returns promotion as an upper case piece letter followed by lower case lancer direction
+// a bare direction is accepted for lancer moves
+func normalizePromotion(promotion string, piece string) string {
+	lower := strings.ToLower(promotion)
+
+	if _, isDirection := DIRECTION_STRING_TO_PIECE_DIRECTION[lower]; isDirection && piece == "L" {
+		return "L" + lower
+	}
+
+	return strings.ToUpper(lower[0:1]) + lower[1:]
+}
+
+// fileRankFromAlgeb converts algebraic file and rank letters to numbers counted from a1, -1 if the letter is empty
+func fileRankFromAlgeb(file string, rank string) (int8, int8) {
+	f, r := int8(-1), int8(-1)
+
+	if file != "" {
+		f = int8(file[0] - 'a')
+	}
+
+	if rank != "" {
+		r = int8(rank[0] - '1')
+	}
+
+	return f, r
+}
+
+/////////////////////////////////////////////////////////////////////
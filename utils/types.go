@@ -52,6 +52,7 @@ type Piece struct {
 
 type PieceDescriptor struct {
 	Directions          []PieceDirection
+	LeaperDirections    []PieceDirection
 	Sliding             bool
 	CanJumpOverOwnPiece bool
 	CanCapture          bool
@@ -76,6 +77,8 @@ type Move struct {
 	RookOrigPiece   Piece
 	SentryPush      bool
 	AsIs            bool
+	GatingPiece     Piece
+	GatingSquare    Square
 }
 
 type MoveList []Move
@@ -97,6 +100,27 @@ func (meb MoveEvalBuff) Less(i, j int) bool {
 	return meb[i].Eval > meb[j].Eval
 }
 
+// SanMove is a move in standard algebraic notation broken into its parts
+// files and ranks are counted from a1, -1 means not given
+// Long marks long algebraic notation without piece letter, the piece is then implied by the from square
+type SanMove struct {
+	Castling     bool
+	CastlingSide CastlingSide
+	Piece        string
+	FromFile     int8
+	FromRank     int8
+	Long         bool
+	Capture      bool
+	ToFile       int8
+	ToRank       int8
+	Promotion    string
+	PushFile     int8
+	PushRank     int8
+	Gating       string
+	GatingFile   int8
+	GatingRank   int8
+}
+
 type MoveBuffItem struct {
 	Move  Move
 	San   string
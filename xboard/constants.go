@@ -0,0 +1,55 @@
+package xboard
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"time"
+
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// constants
+
+const ENGINE_NAME = "gochess"
+
+// XBOARD_MATE_SCORE is the thinking output score of a mate in 0, a mate in n moves is XBOARD_MATE_SCORE + n
+const XBOARD_MATE_SCORE = 100000
+
+// DEFAULT_MOVE_TIME is the time per move when the gui set neither level, st nor sd
+const DEFAULT_MOVE_TIME = 5 * time.Second
+
+// XBOARD_VARIANTS are the variants announced in the variants feature, in this order
+// variants without a move generator are left out when the features are sent
+var XBOARD_VARIANTS = []XBoardVariant{
+	{"normal", utils.VARIANT_STANDARD, false},
+	{"fischerandom", utils.VARIANT_STANDARD, true},
+	{"atomic", utils.VARIANT_ATOMIC, false},
+	{"seirawan", utils.VARIANT_SEIRAWAN, false},
+	{"eightpiece", utils.VARIANT_EIGHTPIECE, false},
+}
+
+// EIGHTPIECE_PIECE_TO_CHAR maps the piece types of the gui, PNBRQFEACWMOHIJGDVLSUK, to letters
+// the jailer, lancer and sentry take the slots of the gui pieces with the same letters
+const EIGHTPIECE_PIECE_TO_CHAR = "PNBRQ.........J...LS.Kpnbrq.........j...ls.k"
+
+// EIGHTPIECE_PIECES are the betza moves of the fairy pieces of eightpiece sent with the piece command
+// the gui cannot know the direction of a lancer, so lancers are shown moving like queens
+var EIGHTPIECE_PIECES = []string{
+	"J& mR",
+	"L& Q",
+	"S& B",
+}
+
+// options announced with the option feature
+var XBOARD_OPTIONS = []string{
+	"OwnBook -check 0",
+	"BookFile -file ",
+	"TablebasePath -path ",
+	"Clear Hash -button",
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,72 @@
+package xboard
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/easychessanimations/gochess/bengine"
+	"github.com/easychessanimations/gochess/book"
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// types
+
+// XBoardVariant is a variant name of the xboard protocol
+type XBoardVariant struct {
+	Name     string
+	Variant  utils.VariantKey
+	Chess960 bool
+}
+
+// XBoardEngine drives bengine.Engine with the CECP / xboard protocol
+type XBoardEngine struct {
+	Engine *bengine.Engine
+	Out    io.Writer // commands to the gui
+
+	logger *thinkingLogger
+
+	pos      *butils.Position // the game, the engine searches a clone of it
+	variant  utils.VariantKey
+	chess960 bool
+
+	protover    int
+	force       bool         // play neither color
+	engineColor butils.Color // color played by the engine when not in force mode
+	analyzing   bool
+	post        bool // send thinking output
+
+	// clock set by level, st, sd, time and otim
+	mps           int
+	base, inc     time.Duration
+	moveTime      time.Duration
+	depth         int
+	time, otim    time.Duration
+	startFullmove int // fullmove number of the position set by new or setboard
+
+	// search running in the background, nil if idle
+	tc      *bengine.TimeControl
+	done    chan struct{}
+	discard bool // set to throw away the move of the running search
+
+	mu    sync.Mutex // guards discard
+	outMu sync.Mutex // serializes writes to Out
+
+	bookLoader book.Loader // loads the book set by the BookFile option
+}
+
+// thinkingLogger sends the thinking output of the engine
+type thinkingLogger struct {
+	xb    *XBoardEngine
+	root  *butils.Position // position searched, for writing the moves of the pv
+	start time.Time
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,607 @@
+package xboard
+
+/////////////////////////////////////////////////////////////////////
+// imports
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/easychessanimations/gochess/bengine"
+	"github.com/easychessanimations/gochess/butils"
+	"github.com/easychessanimations/gochess/utils"
+)
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// member functions
+
+// Loop executes the commands read from r until quit or the end of r
+func (xb *XBoardEngine) Loop(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "quit" {
+			break
+		}
+		xb.Execute(scanner.Text())
+	}
+
+	xb.stopSearch(true)
+}
+
+// Execute executes a command of the gui
+func (xb *XBoardEngine) Execute(line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+
+	cmd, args := fields[0], fields[1:]
+
+	// commands that leave a running search alone
+	switch cmd {
+	case "xboard", "accepted", "rejected", "random", "computer", "name", "rating", "ics", "hard", "easy", "draw", "hint", "bk", ".":
+		return
+	case "protover":
+		xb.protover, _ = strconv.Atoi(argument(args, 0))
+		if xb.protover >= 2 {
+			xb.sendFeatures()
+		}
+		return
+	case "post":
+		xb.post = true
+		return
+	case "nopost":
+		xb.post = false
+		return
+	case "time", "otim":
+		centis, err := strconv.Atoi(argument(args, 0))
+		if err != nil {
+			xb.send("Error (invalid %s): %s", cmd, line)
+			return
+		}
+		if cmd == "time" {
+			xb.time = time.Duration(centis) * 10 * time.Millisecond
+		} else {
+			xb.otim = time.Duration(centis) * 10 * time.Millisecond
+		}
+		return
+	case "?":
+		// the running search plays its best move so far
+		if xb.tc != nil && !xb.analyzing {
+			xb.tc.Stop()
+		}
+		return
+	case "ping":
+		if !xb.analyzing {
+			// pong comes after the move being thought about
+			xb.wait()
+		}
+		xb.send("pong %s", argument(args, 0))
+		return
+	}
+
+	// the other commands change the game, a running search is abandoned
+	xb.stopSearch(true)
+
+	switch cmd {
+	case "new":
+		xb.newGame()
+	case "variant":
+		if err := xb.setVariant(argument(args, 0)); err != nil {
+			xb.send("Error (%v): %s", err, line)
+			return
+		}
+	case "setboard":
+		if err := xb.setBoard(strings.Join(args, " ")); err != nil {
+			xb.send("tellusererror Illegal position: %v", err)
+			return
+		}
+	case "force", "result":
+		xb.force = true
+	case "go":
+		xb.force, xb.engineColor = false, xb.pos.Us()
+	case "playother":
+		xb.force, xb.engineColor = false, xb.pos.Them()
+	case "level":
+		if err := xb.setLevel(args); err != nil {
+			xb.send("Error (%v): %s", err, line)
+			return
+		}
+	case "st":
+		seconds, err := strconv.ParseFloat(argument(args, 0), 64)
+		if err != nil {
+			xb.send("Error (invalid st): %s", line)
+			return
+		}
+		xb.moveTime = time.Duration(seconds * float64(time.Second))
+	case "sd":
+		depth, err := strconv.Atoi(argument(args, 0))
+		if err != nil {
+			xb.send("Error (invalid sd): %s", line)
+			return
+		}
+		xb.depth = depth
+	case "usermove":
+		if !xb.userMove(argument(args, 0)) {
+			return
+		}
+	case "undo", "remove":
+		n := 1
+		if cmd == "remove" {
+			n = 2
+		}
+		for i := 0; i < n && len(xb.pos.MoveHistory()) > 0; i++ {
+			xb.pos.UndoMove()
+		}
+	case "analyze":
+		xb.analyzing = true
+	case "exit":
+		xb.analyzing = false
+	case "memory":
+		mb, err := strconv.Atoi(argument(args, 0))
+		if err != nil || mb <= 0 {
+			xb.send("Error (invalid memory): %s", line)
+			return
+		}
+		bengine.GlobalHashTable = bengine.NewHashTable(mb)
+	case "cores":
+		cores, err := strconv.Atoi(argument(args, 0))
+		if err != nil || cores <= 0 {
+			xb.send("Error (invalid cores): %s", line)
+			return
+		}
+		if cores > bengine.MaxThreads {
+			cores = bengine.MaxThreads
+		}
+		xb.Engine.Options.Threads = cores
+	case "option":
+		if err := xb.setOption(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "option"))); err != nil {
+			xb.send("Error (%v): %s", err, line)
+			return
+		}
+	default:
+		// protocol 1 guis send bare moves
+		if _, err := xb.parseMove(cmd); err != nil || !xb.userMove(cmd) {
+			xb.send("Error (unknown command): %s", cmd)
+			return
+		}
+	}
+
+	xb.resume()
+}
+
+// sendFeatures announces the features of protocol version 2
+func (xb *XBoardEngine) sendFeatures() {
+	variants := []string{}
+	for _, v := range XBOARD_VARIANTS {
+		if _, err := butils.GetVariantDescriptor(v.Variant); err == nil {
+			variants = append(variants, v.Name)
+		}
+	}
+
+	xb.send("feature done=0")
+	xb.send("feature myname=\"%s\" variants=\"%s\"", ENGINE_NAME, strings.Join(variants, ","))
+	xb.send("feature ping=1 setboard=1 playother=1 san=0 usermove=1 time=1 draw=0 sigint=0 sigterm=0 reuse=1 analyze=1")
+	xb.send("feature colors=0 ics=0 name=0 pause=0 nps=0 debug=1 memory=1 smp=1 exclude=0")
+	for _, option := range XBOARD_OPTIONS {
+		xb.send("feature option=\"%s\"", option)
+	}
+	xb.send("feature done=1")
+}
+
+// newGame sets up the start position of the standard variant with the engine playing black
+func (xb *XBoardEngine) newGame() {
+	xb.variant, xb.chess960 = utils.VARIANT_STANDARD, false
+	xb.setStartPosition()
+
+	xb.force, xb.engineColor = false, butils.Black
+	xb.moveTime, xb.depth = 0, 0
+	xb.time, xb.otim = xb.base, xb.base
+
+	bengine.GlobalHashTable.Clear()
+}
+
+// setVariant switches to the xboard variant name and sets up its start position
+// eightpiece is explained to the gui with the setup and piece commands
+func (xb *XBoardEngine) setVariant(name string) error {
+	for _, v := range XBOARD_VARIANTS {
+		if v.Name != name {
+			continue
+		}
+		if _, err := butils.GetVariantDescriptor(v.Variant); err != nil {
+			break
+		}
+
+		xb.variant, xb.chess960 = v.Variant, v.Chess960
+		xb.setStartPosition()
+
+		if xb.variant == utils.VARIANT_EIGHTPIECE {
+			xb.send("setup (%s) 8x8+0_fairy %s", EIGHTPIECE_PIECE_TO_CHAR, XBoardFen(xb.pos))
+			for _, piece := range EIGHTPIECE_PIECES {
+				xb.send("piece %s", piece)
+			}
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("unsupported variant %s", name)
+}
+
+// setStartPosition sets up the start position of the current variant
+func (xb *XBoardEngine) setStartPosition() {
+	pos, _ := butils.PositionFromFENAndVariant(utils.StartFenForVariant(xb.variant), xb.variant)
+	pos.Chess960 = xb.chess960
+	xb.pos, xb.startFullmove = pos, pos.FullmoveCounter()
+}
+
+// setBoard sets up fen in the current variant
+func (xb *XBoardEngine) setBoard(fen string) error {
+	pos, err := butils.PositionFromFENAndVariant(fen, xb.variant)
+	if err != nil {
+		return err
+	}
+	pos.Chess960 = xb.chess960
+	xb.pos, xb.startFullmove = pos, pos.FullmoveCounter()
+	return nil
+}
+
+// setLevel sets a conventional or incremental clock from the arguments of level, MPS BASE INC
+// BASE is in minutes or minutes:seconds, INC in seconds
+func (xb *XBoardEngine) setLevel(args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("expected level MPS BASE INC")
+	}
+
+	mps, err := strconv.Atoi(args[0])
+	if err != nil || mps < 0 {
+		return fmt.Errorf("invalid moves per session %s", args[0])
+	}
+
+	minutes, seconds := args[1], "0"
+	if i := strings.Index(minutes, ":"); i >= 0 {
+		minutes, seconds = minutes[:i], minutes[i+1:]
+	}
+	m, errM := strconv.Atoi(minutes)
+	s, errS := strconv.Atoi(seconds)
+	if errM != nil || errS != nil {
+		return fmt.Errorf("invalid base time %s", args[1])
+	}
+
+	inc, err := strconv.ParseFloat(args[2], 64)
+	if err != nil {
+		return fmt.Errorf("invalid increment %s", args[2])
+	}
+
+	xb.mps = mps
+	xb.base = time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+	xb.inc = time.Duration(inc * float64(time.Second))
+	xb.moveTime = 0
+	xb.time, xb.otim = xb.base, xb.base
+
+	return nil
+}
+
+// setOption sets an option announced in the features, given as NAME=VALUE or NAME for buttons
+func (xb *XBoardEngine) setOption(option string) error {
+	name, value := option, ""
+	if i := strings.Index(option, "="); i >= 0 {
+		name, value = option[:i], option[i+1:]
+	}
+
+	eng := xb.Engine
+
+	switch name {
+	case "OwnBook":
+		eng.Options.OwnBook = value == "1"
+	case "BookFile":
+		bk, err := xb.bookLoader.Load(value)
+		if err != nil {
+			return err
+		}
+		eng.Book = bk
+	case "TablebasePath":
+		return eng.SetTablebasePath(value)
+	case "Clear Hash":
+		bengine.GlobalHashTable.Clear()
+	default:
+		return fmt.Errorf("unknown option %s", name)
+	}
+
+	return nil
+}
+
+// userMove plays the move of the opponent or of the user in force mode, reports it if illegal
+func (xb *XBoardEngine) userMove(s string) bool {
+	move, err := xb.parseMove(s)
+	if err != nil {
+		xb.send("Illegal move: %s", s)
+		return false
+	}
+
+	xb.pos.DoMove(move)
+
+	if !xb.analyzing {
+		xb.sendResult()
+	}
+
+	return true
+}
+
+// parseMove returns the legal move written s in coordinate notation or san
+// a lancer move given without direction keeps the direction of the lancer
+func (xb *XBoardEngine) parseMove(s string) (butils.Move, error) {
+	pos := xb.pos
+
+	candidates := []butils.Move{}
+	for _, move := range pos.LegalMoves() {
+		uci := pos.MoveToUCI(move)
+		if uci == s {
+			return move, nil
+		}
+		if len(s) == 4 && strings.HasPrefix(uci, s) && move.Target() == move.Piece() {
+			candidates = append(candidates, move)
+		}
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	return pos.SanToMove(s)
+}
+
+// resume starts analyzing or thinking on the move of the engine if it is its turn
+func (xb *XBoardEngine) resume() {
+	if xb.analyzing {
+		xb.startSearch(true)
+		return
+	}
+
+	if !xb.force && xb.pos.Us() == xb.engineColor {
+		if result, _ := GameResult(xb.pos); result == "" {
+			xb.startSearch(false)
+		}
+	}
+}
+
+// startSearch searches the current position in the background
+// unless analyzing, the best move is played and sent when the search ends
+func (xb *XBoardEngine) startSearch(analyze bool) {
+	tc := xb.timeControl(analyze)
+	tc.Start(false)
+
+	xb.logger.root = xb.pos.Clone()
+	xb.Engine.SetPosition(xb.pos.Clone())
+
+	done := make(chan struct{})
+	xb.tc, xb.done, xb.discard = tc, done, false
+
+	go func() {
+		defer close(done)
+
+		_, moves := xb.Engine.PlayMoves(tc, nil)
+
+		xb.mu.Lock()
+		discard := xb.discard
+		xb.mu.Unlock()
+
+		if analyze || discard || len(moves) == 0 {
+			return
+		}
+
+		uci := xb.pos.MoveToUCI(moves[0])
+		xb.pos.DoMove(moves[0])
+		xb.send("move %s", uci)
+		xb.sendResult()
+	}()
+}
+
+// stopSearch stops the running search, its move is not played if discard is set
+func (xb *XBoardEngine) stopSearch(discard bool) {
+	if xb.done == nil {
+		return
+	}
+
+	xb.mu.Lock()
+	xb.discard = discard
+	xb.mu.Unlock()
+
+	xb.tc.Stop()
+	xb.wait()
+}
+
+// wait waits for the running search to end
+func (xb *XBoardEngine) wait() {
+	if xb.done == nil {
+		return
+	}
+
+	<-xb.done
+	xb.tc, xb.done = nil, nil
+}
+
+// timeControl returns the limits of a search of the current position
+func (xb *XBoardEngine) timeControl(analyze bool) *bengine.TimeControl {
+	tc := bengine.NewTimeControl(xb.pos, false)
+	if analyze {
+		return tc
+	}
+
+	switch {
+	case xb.moveTime > 0:
+		tc.WTime, tc.BTime, tc.MovesToGo = xb.moveTime, xb.moveTime, 1
+	case xb.base > 0:
+		if xb.pos.Us() == butils.White {
+			tc.WTime, tc.BTime = xb.time, xb.otim
+		} else {
+			tc.WTime, tc.BTime = xb.otim, xb.time
+		}
+		tc.WInc, tc.BInc = xb.inc, xb.inc
+		if xb.mps > 0 {
+			played := xb.pos.FullmoveCounter() - xb.startFullmove
+			tc.MovesToGo = int32(xb.mps - played%xb.mps)
+		}
+	case xb.depth == 0:
+		tc.WTime, tc.BTime, tc.MovesToGo = DEFAULT_MOVE_TIME, DEFAULT_MOVE_TIME, 1
+	}
+
+	if xb.depth > 0 {
+		tc.Depth = int32(xb.depth)
+	}
+
+	return tc
+}
+
+// sendResult sends the result if the game is over
+func (xb *XBoardEngine) sendResult() {
+	if result, comment := GameResult(xb.pos); result != "" {
+		xb.send("%s {%s}", result, comment)
+	}
+}
+
+// send sends a command to the gui
+func (xb *XBoardEngine) send(format string, args ...interface{}) {
+	xb.outMu.Lock()
+	defer xb.outMu.Unlock()
+
+	fmt.Fprintf(xb.Out, format+"\n", args...)
+}
+
+// BeginSearch starts the clock of the thinking output
+func (tl *thinkingLogger) BeginSearch() {
+	tl.start = time.Now()
+}
+
+// EndSearch does nothing
+func (tl *thinkingLogger) EndSearch() {
+}
+
+// PrintPV sends the thinking output of a finished depth, as in 12 35 104 1500432 e2e4 e7e5
+func (tl *thinkingLogger) PrintPV(stats bengine.Stats, multiPV int, score int32, pv []butils.Move) {
+	if !tl.xb.post && !tl.xb.analyzing {
+		return
+	}
+
+	switch {
+	case score > bengine.KnownWinScore:
+		score = XBOARD_MATE_SCORE + (bengine.MateScore-score+1)/2
+	case score < bengine.KnownLossScore:
+		score = -XBOARD_MATE_SCORE + (bengine.MatedScore-score)/2
+	}
+
+	moves := []string{}
+	for _, move := range pv {
+		moves = append(moves, tl.root.MoveToUCI(move))
+		tl.root.DoMove(move)
+	}
+	for range pv {
+		tl.root.UndoMove()
+	}
+
+	centis := time.Since(tl.start) / (10 * time.Millisecond)
+	tl.xb.send("%d %d %d %d %s", stats.Depth, score, centis, stats.Nodes, strings.Join(moves, " "))
+}
+
+// CurrMove does nothing
+func (tl *thinkingLogger) CurrMove(depth int, move butils.Move, num int) {
+}
+
+/////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////
+// global functions
+
+// NewXBoardEngine returns a driver writing to out, set up for a new standard game
+func NewXBoardEngine(out io.Writer) *XBoardEngine {
+	xb := &XBoardEngine{Out: out}
+	xb.logger = &thinkingLogger{xb: xb}
+	xb.Engine = bengine.NewEngine(nil, xb.logger, bengine.Options{Threads: 1})
+	xb.newGame()
+	return xb
+}
+
+// GameResult returns the xboard result and its comment if the game ended in pos, an empty result otherwise
+func GameResult(pos *butils.Position) (string, string) {
+	us := pos.Us()
+
+	lost := "0-1"
+	winner := "Black"
+	if us == butils.Black {
+		lost, winner = "1-0", "White"
+	}
+
+	switch {
+	case pos.ByPiece(us, butils.King) == 0:
+		return lost, winner + " captured the king"
+	case !pos.HasLegalMoves():
+		if pos.IsChecked(us) {
+			return lost, winner + " mates"
+		}
+		return "1/2-1/2", "Stalemate"
+	case pos.InsufficientMaterial():
+		return "1/2-1/2", "Insufficient material"
+	case pos.FiftyMoveRule():
+		return "1/2-1/2", "Fifty move rule"
+	case pos.ThreeFoldRepetition() >= 3:
+		return "1/2-1/2", "Draw by repetition"
+	}
+
+	return "", ""
+}
+
+// XBoardFen returns the fen of pos as understood by the gui
+// lancers are written without direction and the disabled move field of eightpiece is left out
+func XBoardFen(pos *butils.Position) string {
+	placement := ""
+	for r := 7; r >= 0; r-- {
+		space := 0
+		for f := 0; f < 8; f++ {
+			pi := pos.Get(butils.RankFile(r, f))
+			if pi == butils.NoPiece {
+				space++
+				continue
+			}
+			if space != 0 {
+				placement += strconv.Itoa(space)
+				space = 0
+			}
+			if pi.Color() == butils.White {
+				placement += strings.ToUpper(pi.AlgebLetter())
+			} else {
+				placement += pi.AlgebLetter()
+			}
+		}
+		if space != 0 {
+			placement += strconv.Itoa(space)
+		}
+		if r != 0 {
+			placement += "/"
+		}
+	}
+
+	fields := strings.Fields(pos.String())
+	if len(fields) > 6 {
+		fields = fields[:6]
+	}
+	fields[0] = placement
+
+	return strings.Join(fields, " ")
+}
+
+// argument returns args[i], or an empty string if there are fewer arguments
+func argument(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
+
+/////////////////////////////////////////////////////////////////////
@@ -0,0 +1,155 @@
+package xboard
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// output collects the commands sent to the gui
+type output struct {
+	mu   sync.Mutex
+	buff bytes.Buffer
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buff.Write(p)
+}
+
+// unread puts back lines not yet expected
+func (o *output) unread(lines []string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	rest := o.buff.String()
+	o.buff.Reset()
+	for _, line := range lines {
+		o.buff.WriteString(line + "\n")
+	}
+	o.buff.WriteString(rest)
+}
+
+// lines returns the lines sent so far and forgets them
+func (o *output) lines() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	s := strings.TrimSpace(o.buff.String())
+	o.buff.Reset()
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+func newTestEngine(commands ...string) (*XBoardEngine, *output) {
+	out := &output{}
+	xb := NewXBoardEngine(out)
+	for _, command := range commands {
+		xb.Execute(command)
+	}
+	return xb, out
+}
+
+// expectLine waits for a line starting with prefix and returns it, the lines after it are kept
+func expectLine(t *testing.T, out *output, prefix string) string {
+	t.Helper()
+	seen := []string{}
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		lines := out.lines()
+		for i, line := range lines {
+			if strings.HasPrefix(line, prefix) {
+				out.unread(lines[i+1:])
+				return line
+			}
+			seen = append(seen, line)
+		}
+	}
+	t.Fatalf("no line starting with %q in %q", prefix, seen)
+	return ""
+}
+
+func TestFeatures(t *testing.T) {
+	_, out := newTestEngine("xboard", "protover 2")
+
+	s := strings.Join(out.lines(), "\n")
+	for _, feature := range []string{"usermove=1", "setboard=1", "analyze=1", "ping=1", "done=1"} {
+		if !strings.Contains(s, feature) {
+			t.Errorf("missing feature %s in\n%s", feature, s)
+		}
+	}
+	if !strings.Contains(s, `variants="normal,fischerandom,atomic,eightpiece"`) {
+		t.Errorf("unexpected variants in\n%s", s)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	xb, out := newTestEngine("new", "sd 2", "post", "usermove e2e5")
+	expectLine(t, out, "Illegal move: e2e5")
+
+	xb.Execute("usermove e2e4")
+	move := expectLine(t, out, "move ")
+
+	xb.Execute("ping 7")
+	expectLine(t, out, "pong 7")
+
+	if n := len(xb.pos.MoveHistory()); n != 2 {
+		t.Fatalf("expected 2 moves after %s, got %d", move, n)
+	}
+
+	xb.Execute("force")
+	xb.Execute("remove")
+	if n := len(xb.pos.MoveHistory()); n != 0 {
+		t.Errorf("expected no moves after remove, got %d", n)
+	}
+}
+
+func TestMate(t *testing.T) {
+	_, out := newTestEngine("new", "force", "setboard 7k/8/6K1/8/8/8/8/1Q6 w - - 0 1", "sd 4", "go")
+
+	if move := expectLine(t, out, "move "); move != "move b1b8" {
+		t.Errorf("expected move b1b8, got %s", move)
+	}
+	expectLine(t, out, "1-0 {White mates}")
+}
+
+func TestAnalyze(t *testing.T) {
+	xb, out := newTestEngine("new", "force", "analyze")
+
+	if fields := strings.Fields(expectLine(t, out, "1 ")); len(fields) < 5 {
+		t.Errorf("expected depth score time nodes pv, got %v", fields)
+	}
+
+	xb.Execute("exit")
+	out.lines()
+	xb.Execute("usermove e2e4")
+	time.Sleep(50 * time.Millisecond)
+	if lines := out.lines(); len(lines) != 0 {
+		t.Errorf("unexpected output after exit %q", lines)
+	}
+}
+
+func TestEightpiece(t *testing.T) {
+	xb, out := newTestEngine("new", "variant eightpiece")
+
+	setup := expectLine(t, out, "setup ")
+	if !strings.HasSuffix(setup, "8x8+0_fairy jlsqkbnr/pppppppp/8/8/8/8/PPPPPPPP/JLSQKBNR w KQkq - 0 1") {
+		t.Errorf("unexpected setup %s", setup)
+	}
+
+	expectLine(t, out, "piece J& ")
+	expectLine(t, out, "piece L& ")
+	expectLine(t, out, "piece S& ")
+
+	// a lancer move without direction keeps the direction
+	xb.Execute("force")
+	xb.Execute("usermove b1d3")
+	if lines := out.lines(); len(lines) != 0 {
+		t.Errorf("unexpected output %q", lines)
+	}
+	if n := len(xb.pos.MoveHistory()); n != 1 {
+		t.Errorf("expected the lancer move to be played")
+	}
+}